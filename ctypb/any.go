@@ -0,0 +1,715 @@
+package ctypb
+
+import (
+	"fmt"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// DecodeAny decodes the serialized message from a google.protobuf.Any
+// value, given in the same form as in the object that FromProtobufMessage
+// returns for fields of that type, and returns an equivalent cty value.
+//
+// By default, FromProtobufMessage represents an Any value as an object with
+// a "type_url" string attribute and a "value" attribute containing the
+// base64-encoded serialized message. A caller can pass those two attributes
+// to DecodeAny to decode the message value itself, using the given resolver
+// to find a message type corresponding to the type URL. If resolver is nil
+// then DecodeAny uses protoregistry.GlobalTypes.
+//
+// The options are passed on to FromProtobufMessage when converting the
+// decoded message, and so the result conforms to the type that
+// ImpliedTypeForMessageDesc would return for the resolved message type with
+// those same options. WithBase64Encoding also decides how to decode the
+// given value.
+func DecodeAny(typeURL, value string, resolver protoregistry.MessageTypeResolver, opts ...Option) (cty.Value, error) {
+	if resolver == nil {
+		resolver = protoregistry.GlobalTypes
+	}
+	mt, err := resolver.FindMessageByURL(typeURL)
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("unsupported message type %q", typeURL)
+	}
+	raw, err := makeOptions(opts).base64().DecodeString(value)
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("value must contain base64-encoded bytes")
+	}
+	return decodeAnyMessage(mt, raw, opts)
+}
+
+// decodeAnyMessage is the part of DecodeAny that follows decoding the
+// base64, shared with DecodeAllAnys.
+func decodeAnyMessage(mt protoreflect.MessageType, raw []byte, opts []Option) (cty.Value, error) {
+	msg := mt.New()
+	err := proto.Unmarshal(raw, msg.Interface())
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("invalid %s message: %w", mt.Descriptor().FullName(), err)
+	}
+	return FromProtobufMessage(msg, opts...)
+}
+
+// DecodeAnyFunc returns a cty function that wraps DecodeAny, for use in
+// applications that embed a cty-based expression language such as HCL.
+//
+// The function takes a single argument, which must be an object with
+// "type_url" and "value" string attributes in the same form that DecodeAny
+// expects, and returns the decoded message value. The resolver and the
+// options are used in the same way as for DecodeAny.
+//
+// If the type URL is known during type checking, the function's return
+// type is the implied type of the resolved message type, and so callers can
+// detect errors in expressions that use the result before calling it.
+// Otherwise the return type is cty.DynamicPseudoType.
+func DecodeAnyFunc(resolver protoregistry.MessageTypeResolver, opts ...Option) function.Function {
+	if resolver == nil {
+		resolver = protoregistry.GlobalTypes
+	}
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name:             "any",
+				Type:             cty.DynamicPseudoType,
+				AllowDynamicType: true,
+			},
+		},
+		Type: func(args []cty.Value) (cty.Type, error) {
+			obj, err := convert.Convert(args[0], anyObjectType)
+			if err != nil {
+				return cty.NilType, function.NewArgError(0, err)
+			}
+			typeURL := obj.GetAttr("type_url")
+			if !typeURL.IsKnown() {
+				return cty.DynamicPseudoType, nil
+			}
+			if typeURL.IsNull() {
+				return cty.NilType, function.NewArgErrorf(0, "type_url must not be null")
+			}
+			mt, err := resolver.FindMessageByURL(typeURL.AsString())
+			if err != nil {
+				return cty.NilType, function.NewArgErrorf(0, "unsupported message type %q", typeURL.AsString())
+			}
+			return ImpliedTypeForMessageDesc(mt.Descriptor(), opts...)
+		},
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			obj, err := convert.Convert(args[0], anyObjectType)
+			if err != nil {
+				return cty.NilVal, function.NewArgError(0, err)
+			}
+			if !obj.IsWhollyKnown() {
+				return cty.UnknownVal(retType), nil
+			}
+			typeURL := obj.GetAttr("type_url")
+			value := obj.GetAttr("value")
+			if typeURL.IsNull() || value.IsNull() {
+				return cty.NilVal, function.NewArgErrorf(0, "type_url and value must not be null")
+			}
+			v, err := DecodeAny(typeURL.AsString(), value.AsString(), resolver, opts...)
+			if err != nil {
+				return cty.NilVal, function.NewArgError(0, err)
+			}
+			return v, nil
+		},
+	})
+}
+
+// anyObjectType is the type of the object that represents a
+// google.protobuf.Any message, both by default and with NewAnyJSONHandler,
+// although the content of the "value" attribute differs between the two.
+// Other options can change this type, so DecodeAllAnys and EncodeAllAnys
+// derive it from their options instead.
+var anyObjectType = cty.Object(map[string]cty.Type{
+	"type_url": cty.String,
+	"value":    cty.String,
+})
+
+// EncodeAny is the inverse of DecodeAny, converting the given cty value to
+// the message type corresponding to the given type URL and then returning
+// the base64-encoded serialization of that message, suitable for use as the
+// "value" attribute of the object representing a google.protobuf.Any value.
+//
+// The given value must conform to the type that ImpliedTypeForMessageDesc
+// would return for the resolved message type, as with ToProtobufMessage,
+// and the given options are passed on to ToProtobufMessage.
+// WithBase64Encoding also decides how to encode the result. If resolver is
+// nil then EncodeAny uses protoregistry.GlobalTypes.
+func EncodeAny(v cty.Value, typeURL string, resolver protoregistry.MessageTypeResolver, opts ...Option) (string, error) {
+	if resolver == nil {
+		resolver = protoregistry.GlobalTypes
+	}
+	mt, err := resolver.FindMessageByURL(typeURL)
+	if err != nil {
+		return "", fmt.Errorf("unsupported message type %q", typeURL)
+	}
+	raw, err := encodeAnyMessage(v, mt, opts)
+	if err != nil {
+		return "", err
+	}
+	return makeOptions(opts).base64().EncodeToString(raw), nil
+}
+
+// encodeAnyMessage is the part of EncodeAny that precedes encoding the
+// base64, shared with EncodeAllAnys.
+func encodeAnyMessage(v cty.Value, mt protoreflect.MessageType, opts []Option) ([]byte, error) {
+	msg := mt.New()
+	err := ToProtobufMessage(v, msg, opts...)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg.Interface())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s message: %w", mt.Descriptor().FullName(), err)
+	}
+	return raw, nil
+}
+
+// DecodeAllAnys finds all of the objects representing google.protobuf.Any
+// values within the given value, which must conform to the given type, and
+// replaces each of them with the result of DecodeAny for that object. This
+// saves callers from finding the Any values scattered throughout a large
+// message themselves, including those inside nested objects and
+// collections.
+//
+// The given type is usually the one that ImpliedTypeForMessageDesc returned
+// for the converted message type, and DecodeAllAnys treats any part of the
+// value whose type in the given type is the type that represents
+// google.protobuf.Any as an Any value to decode. Where the given type is
+// cty.DynamicPseudoType, DecodeAllAnys instead uses the type of the value.
+// The type that represents Any depends on the options in the same way as for
+// ImpliedTypeForMessageDesc, and so DecodeAllAnys returns an error if the
+// options make it cty.DynamicPseudoType, such as with a WellKnownHandler
+// whose type is cty.DynamicPseudoType, because then Any values can't be told
+// apart from other values.
+//
+// The decoded values can have different types, so a list or set whose
+// elements don't all have the same type after decoding becomes a tuple, and
+// likewise a map becomes an object. A null Any value becomes a null value
+// of cty.DynamicPseudoType, and an unknown one becomes cty.DynamicVal.
+//
+// A decoded message can itself contain Any values, which DecodeAllAnys also
+// decodes, using the same resolver and options. With WithMaxDepth, the
+// limit also applies to how deeply Any values are nested inside the decoded
+// messages of other Any values, where an Any value in the given value is at
+// depth one, so that a chain of Any values can't recurse without bound.
+//
+// The resolver and the options are used in the same way as for DecodeAny.
+// If decoding fails, the error is a cty.PathError for the path of the Any
+// value within the given value. EncodeAllAnys is the inverse of this
+// function.
+func DecodeAllAnys(v cty.Value, ty cty.Type, resolver protoregistry.MessageTypeResolver, opts ...Option) (cty.Value, error) {
+	d, err := newAnyDecoder(resolver, opts)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	return d.decodeAll(v, ty, 0, make(cty.Path, 0, 4))
+}
+
+// anyDecoder is the state of a single call to DecodeAllAnys or
+// EncodeAllAnys.
+type anyDecoder struct {
+	resolver protoregistry.MessageTypeResolver
+	opts     []Option
+	maxDepth int
+
+	// anyType is the type that represents google.protobuf.Any with the
+	// options in opts.
+	anyType cty.Type
+}
+
+func newAnyDecoder(resolver protoregistry.MessageTypeResolver, opts []Option) (*anyDecoder, error) {
+	if resolver == nil {
+		resolver = protoregistry.GlobalTypes
+	}
+	anyType, err := ImpliedTypeForMessageDesc((*anypb.Any)(nil).ProtoReflect().Descriptor(), opts...)
+	if err != nil {
+		return nil, err
+	}
+	if anyType.HasDynamicTypes() {
+		return nil, fmt.Errorf("can't find %s values when they're represented as %s", anyFullName, anyType.FriendlyName())
+	}
+	return &anyDecoder{
+		resolver: resolver,
+		opts:     opts,
+		maxDepth: makeOptions(opts).maxDepth,
+		anyType:  anyType,
+	}, nil
+}
+
+// anyMessage returns the Any message that the given known, non-null value
+// of the decoder's Any type represents.
+func (d *anyDecoder) anyMessage(v cty.Value, path cty.Path) (*anypb.Any, error) {
+	msg := &anypb.Any{}
+	err := ToProtobufMessage(v, msg.ProtoReflect(), d.opts...)
+	if err != nil {
+		if pathErr, ok := err.(cty.PathError); ok {
+			// The error's path is relative to the Any value.
+			pathErr.Path = append(path.Copy(), pathErr.Path...)
+			return nil, pathErr
+		}
+		return nil, path.NewError(err)
+	}
+	return msg, nil
+}
+
+// messageType returns the message type for the given type URL.
+func (d *anyDecoder) messageType(typeURL string, path cty.Path) (protoreflect.MessageType, error) {
+	mt, err := d.resolver.FindMessageByURL(typeURL)
+	if err != nil {
+		return nil, path.NewErrorf("unsupported message type %q", typeURL)
+	}
+	return mt, nil
+}
+
+// decodeAll is the recursive implementation of DecodeAllAnys, where depth
+// is the number of Any values whose decoded messages contain the given
+// value.
+func (d *anyDecoder) decodeAll(v cty.Value, ty cty.Type, depth int, path cty.Path) (cty.Value, error) {
+	if ty == cty.DynamicPseudoType {
+		ty = v.Type()
+	}
+	if ty.Equals(d.anyType) {
+		switch {
+		case !v.IsWhollyKnown():
+			return cty.DynamicVal, nil
+		case v.IsNull():
+			return cty.NullVal(cty.DynamicPseudoType), nil
+		}
+		msg, err := d.anyMessage(v, path)
+		if err != nil {
+			return cty.NilVal, err
+		}
+		if d.maxDepth > 0 && depth >= d.maxDepth {
+			return cty.NilVal, path.NewErrorf("Any value is nested more than %d levels deep", d.maxDepth)
+		}
+		mt, err := d.messageType(msg.TypeUrl, path)
+		if err != nil {
+			return cty.NilVal, err
+		}
+		ret, err := decodeAnyMessage(mt, msg.Value, d.opts)
+		if err != nil {
+			return cty.NilVal, path.NewError(err)
+		}
+		// The decoded message might contain more Any values, whose paths
+		// continue from the path of this one.
+		return d.decodeAll(ret, cty.DynamicPseudoType, depth+1, path)
+	}
+	if !d.typeContainsAny(ty) || !v.IsKnown() || v.IsNull() {
+		return v, nil
+	}
+
+	path = reservePath(path)
+	switch {
+	case ty.IsObjectType():
+		atys := ty.AttributeTypes()
+		attrs := make(map[string]cty.Value, len(atys))
+		for it := v.ElementIterator(); it.Next(); {
+			k, av := it.Element()
+			name := k.AsString()
+			path := append(path, cty.GetAttrStep{Name: name})
+			av, err := d.decodeAll(av, atys[name], depth, path)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			attrs[name] = av
+		}
+		return cty.ObjectVal(attrs), nil
+	case ty.IsMapType():
+		elems := make(map[string]cty.Value, v.LengthInt())
+		same := true
+		var firstTy cty.Type
+		for it := v.ElementIterator(); it.Next(); {
+			k, ev := it.Element()
+			path := append(path, cty.IndexStep{Key: k})
+			ev, err := d.decodeAll(ev, ty.ElementType(), depth, path)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			if len(elems) == 0 {
+				firstTy = ev.Type()
+			} else if !ev.Type().Equals(firstTy) {
+				same = false
+			}
+			elems[k.AsString()] = ev
+		}
+		switch {
+		case len(elems) == 0:
+			return v, nil
+		case same:
+			return cty.MapVal(elems), nil
+		default:
+			return cty.ObjectVal(elems), nil
+		}
+	default:
+		// The remaining types that can contain the Any type are all
+		// sequences, and we can treat sets as sequences too because a
+		// set's iteration order is consistent.
+		elems := make([]cty.Value, 0, v.LengthInt())
+		same := true
+		for it := v.ElementIterator(); it.Next(); {
+			k, ev := it.Element()
+			var ety cty.Type
+			if ty.IsTupleType() {
+				ety = ty.TupleElementType(len(elems))
+			} else {
+				ety = ty.ElementType()
+			}
+			path := append(path, cty.IndexStep{Key: k})
+			ev, err := d.decodeAll(ev, ety, depth, path)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			if len(elems) != 0 && !ev.Type().Equals(elems[0].Type()) {
+				same = false
+			}
+			elems = append(elems, ev)
+		}
+		switch {
+		case len(elems) == 0:
+			return v, nil
+		case ty.IsListType() && same:
+			return cty.ListVal(elems), nil
+		case ty.IsSetType() && same:
+			return cty.SetVal(elems), nil
+		default:
+			return cty.TupleVal(elems), nil
+		}
+	}
+}
+
+// EncodeAllAnys is the inverse of DecodeAllAnys, replacing each decoded
+// message value within the given value with an object representing a
+// google.protobuf.Any value containing that message, so that the result
+// conforms to the given type again and can be passed to ToProtobufMessage.
+//
+// The decoded values don't record the type URLs of the Any values they came
+// from, so EncodeAllAnys takes them from the original value that was given
+// to DecodeAllAnys, by matching the structure of the two values: attributes
+// and map elements by name, and the elements of lists, sets, and tuples by
+// position. A list of Any values that DecodeAllAnys turned into a tuple is
+// therefore rebuilt as a list in the same order and with the same type
+// URLs. EncodeAllAnys returns an error for a non-null decoded value that has
+// no corresponding Any value in the original value.
+//
+// The given type, the resolver, and the options are used in the same way
+// as for DecodeAllAnys, and the options are also passed on to EncodeAny.
+// If encoding fails, the error is a cty.PathError for the path of the Any
+// value within the given value.
+func EncodeAllAnys(v, orig cty.Value, ty cty.Type, resolver protoregistry.MessageTypeResolver, opts ...Option) (cty.Value, error) {
+	d, err := newAnyDecoder(resolver, opts)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	return d.encodeAll(v, orig, ty, 0, make(cty.Path, 0, 4))
+}
+
+// encodeAll is the recursive implementation of EncodeAllAnys, where orig is
+// the part of the original value corresponding to the given value, or a
+// null value of cty.DynamicPseudoType if there is no such part.
+func (d *anyDecoder) encodeAll(v, orig cty.Value, ty cty.Type, depth int, path cty.Path) (cty.Value, error) {
+	if ty == cty.DynamicPseudoType {
+		// The original value has the type that DecodeAllAnys used in this
+		// case, if there is one.
+		ty = orig.Type()
+		if ty == cty.DynamicPseudoType {
+			return v, nil
+		}
+	}
+	if ty.Equals(d.anyType) {
+		switch {
+		case !v.IsKnown():
+			return cty.UnknownVal(d.anyType), nil
+		case v.IsNull():
+			return cty.NullVal(d.anyType), nil
+		case !orig.IsKnown() || orig.IsNull():
+			return cty.NilVal, path.NewErrorf("there is no original Any value to take the type URL from")
+		case !orig.IsWhollyKnown():
+			return cty.NilVal, path.NewErrorf("the original Any value must be wholly known")
+		}
+		anyMsg, err := d.anyMessage(orig, path)
+		if err != nil {
+			return cty.NilVal, err
+		}
+		if d.maxDepth > 0 && depth >= d.maxDepth {
+			return cty.NilVal, path.NewErrorf("Any value is nested more than %d levels deep", d.maxDepth)
+		}
+		mt, err := d.messageType(anyMsg.TypeUrl, path)
+		if err != nil {
+			return cty.NilVal, err
+		}
+		// We decode the original message too, so that any Any values
+		// inside the decoded message can take their type URLs from it.
+		origMsg, err := decodeAnyMessage(mt, anyMsg.Value, d.opts)
+		if err != nil {
+			return cty.NilVal, path.NewError(err)
+		}
+		msg, err := d.encodeAll(v, origMsg, cty.DynamicPseudoType, depth+1, path)
+		if err != nil {
+			return cty.NilVal, err
+		}
+		anyMsg.Value, err = encodeAnyMessage(msg, mt, d.opts)
+		if err != nil {
+			return cty.NilVal, path.NewError(err)
+		}
+		// Converting the original Any message back again preserves anything
+		// else it carried, such as unknown fields under
+		// WithPreserveUnknownFields.
+		ret, err := FromProtobufMessage(anyMsg.ProtoReflect(), d.opts...)
+		if err != nil {
+			return cty.NilVal, path.NewError(err)
+		}
+		return ret, nil
+	}
+	if !d.typeContainsAny(ty) || !v.IsKnown() || v.IsNull() {
+		return v, nil
+	}
+	if !orig.IsKnown() || orig.IsNull() {
+		orig = cty.NullVal(cty.DynamicPseudoType)
+	}
+
+	path = reservePath(path)
+	switch {
+	case ty.IsObjectType() || ty.IsMapType():
+		if !(v.Type().IsObjectType() || v.Type().IsMapType()) {
+			return cty.NilVal, path.NewErrorf("an object or map is required")
+		}
+		elems := make(map[string]cty.Value, v.LengthInt())
+		same := true
+		var firstTy cty.Type
+		for it := v.ElementIterator(); it.Next(); {
+			k, ev := it.Element()
+			name := k.AsString()
+			var ety cty.Type
+			var step cty.PathStep
+			if ty.IsObjectType() {
+				if !ty.HasAttribute(name) {
+					// Not a field of the message, so there can't be
+					// any Any values inside it.
+					elems[name] = ev
+					continue
+				}
+				ety = ty.AttributeType(name)
+				step = cty.GetAttrStep{Name: name}
+			} else {
+				ety = ty.ElementType()
+				step = cty.IndexStep{Key: k}
+			}
+			eorig := cty.NullVal(cty.DynamicPseudoType)
+			switch {
+			case orig.Type().IsObjectType() && orig.Type().HasAttribute(name):
+				eorig = orig.GetAttr(name)
+			case orig.Type().IsMapType() && orig.HasIndex(k).True():
+				eorig = orig.Index(k)
+			}
+			path := append(path, step)
+			ev, err := d.encodeAll(ev, eorig, ety, depth, path)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			if len(elems) == 0 {
+				firstTy = ev.Type()
+			} else if !ev.Type().Equals(firstTy) {
+				same = false
+			}
+			elems[name] = ev
+		}
+		switch {
+		case ty.IsObjectType():
+			return cty.ObjectVal(elems), nil
+		case len(elems) == 0:
+			return cty.MapValEmpty(ty.ElementType()), nil
+		case same:
+			return cty.MapVal(elems), nil
+		default:
+			return cty.ObjectVal(elems), nil
+		}
+	default:
+		if !(v.Type().IsListType() || v.Type().IsSetType() || v.Type().IsTupleType()) {
+			return cty.NilVal, path.NewErrorf("a list, set, or tuple is required")
+		}
+		var origElems []cty.Value
+		if oty := orig.Type(); oty.IsListType() || oty.IsSetType() || oty.IsTupleType() {
+			origElems = orig.AsValueSlice()
+		}
+		elems := make([]cty.Value, 0, v.LengthInt())
+		same := true
+		for it := v.ElementIterator(); it.Next(); {
+			k, ev := it.Element()
+			i := len(elems)
+			var ety cty.Type
+			if ty.IsTupleType() {
+				ety = ty.TupleElementType(i)
+			} else {
+				ety = ty.ElementType()
+			}
+			eorig := cty.NullVal(cty.DynamicPseudoType)
+			if i < len(origElems) {
+				eorig = origElems[i]
+			}
+			path := append(path, cty.IndexStep{Key: k})
+			ev, err := d.encodeAll(ev, eorig, ety, depth, path)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			if i != 0 && !ev.Type().Equals(elems[0].Type()) {
+				same = false
+			}
+			elems = append(elems, ev)
+		}
+		switch {
+		case len(elems) == 0 && ty.IsListType():
+			return cty.ListValEmpty(ty.ElementType()), nil
+		case len(elems) == 0 && ty.IsSetType():
+			return cty.SetValEmpty(ty.ElementType()), nil
+		case ty.IsListType() && same:
+			return cty.ListVal(elems), nil
+		case ty.IsSetType() && same:
+			return cty.SetVal(elems), nil
+		default:
+			return cty.TupleVal(elems), nil
+		}
+	}
+}
+
+// typeContainsAny returns true if the given type is, or contains, the type
+// that represents google.protobuf.Any, or if it's cty.DynamicPseudoType and
+// so might contain it.
+func (d *anyDecoder) typeContainsAny(ty cty.Type) bool {
+	switch {
+	case ty == cty.DynamicPseudoType || ty.Equals(d.anyType):
+		return true
+	case ty.IsObjectType():
+		for _, aty := range ty.AttributeTypes() {
+			if d.typeContainsAny(aty) {
+				return true
+			}
+		}
+		return false
+	case ty.IsTupleType():
+		for _, ety := range ty.TupleElementTypes() {
+			if d.typeContainsAny(ety) {
+				return true
+			}
+		}
+		return false
+	case ty.IsCollectionType():
+		return d.typeContainsAny(ty.ElementType())
+	default:
+		return false
+	}
+}
+
+// anyFullName is the full name of the well-known message type
+// google.protobuf.Any.
+const anyFullName protoreflect.FullName = "google.protobuf.Any"
+
+// NewAnyJSONHandler returns a WellKnownHandler for google.protobuf.Any which
+// represents the embedded message using its protojson form, rather than as
+// base64-encoded bytes, so that the message content is human-readable.
+//
+// To use it, register it in a WellKnownHandlers registry under the full
+// name of the Any message type and then pass that registry to the
+// conversion functions using WithWellKnownHandlers:
+//
+//	handlers := ctypb.NewWellKnownHandlers()
+//	handlers.Register("google.protobuf.Any", ctypb.NewAnyJSONHandler(nil))
+//
+// An Any message is then represented as an object with a "type_url"
+// attribute, as usual, and a "value" attribute containing a JSON string.
+// The given registry is used to find the message types for type URLs, both
+// for the outer message and for any Any messages nested inside it. If it's
+// nil then the handler uses protoregistry.GlobalTypes. The attribute names
+// are always "type_url" and "value", regardless of WithFieldNameFunc.
+// DecodeAllAnys and EncodeAllAnys also accept options that include this
+// handler, and then read and write the embedded messages as JSON.
+//
+// The exact formatting of the JSON strings is not stable, so callers should
+// not compare them byte-for-byte.
+func NewAnyJSONHandler(types *protoregistry.Types) WellKnownHandler {
+	if types == nil {
+		types = protoregistry.GlobalTypes
+	}
+	return anyJSONHandler{types: types}
+}
+
+// anyJSONHandler is the WellKnownHandler returned by NewAnyJSONHandler.
+type anyJSONHandler struct {
+	types *protoregistry.Types
+}
+
+func (h anyJSONHandler) Type(desc protoreflect.MessageDescriptor) (cty.Type, bool) {
+	if desc.FullName() != anyFullName {
+		return cty.NilType, false
+	}
+	return anyObjectType, true
+}
+
+func (h anyJSONHandler) FromProto(msg protoreflect.Message) (cty.Value, error) {
+	fields := msg.Descriptor().Fields()
+	typeURL := msg.Get(fields.ByName("type_url")).String()
+	raw := msg.Get(fields.ByName("value")).Bytes()
+	if typeURL == "" && len(raw) == 0 {
+		// An empty Any message has no embedded message to render.
+		return cty.ObjectVal(map[string]cty.Value{
+			"type_url": cty.StringVal(""),
+			"value":    cty.StringVal(""),
+		}), nil
+	}
+
+	mt, err := h.types.FindMessageByURL(typeURL)
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("unsupported message type %q", typeURL)
+	}
+	embedded := mt.New()
+	err = proto.UnmarshalOptions{Resolver: h.types}.Unmarshal(raw, embedded.Interface())
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("invalid %s message: %w", mt.Descriptor().FullName(), err)
+	}
+	js, err := protojson.MarshalOptions{Resolver: h.types}.Marshal(embedded.Interface())
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("failed to encode %s message as JSON: %w", mt.Descriptor().FullName(), err)
+	}
+	return cty.ObjectVal(map[string]cty.Value{
+		"type_url": cty.StringVal(typeURL),
+		"value":    cty.StringVal(string(js)),
+	}), nil
+}
+
+func (h anyJSONHandler) ToProto(v cty.Value, msg protoreflect.Message) error {
+	if !v.Type().Equals(anyObjectType) {
+		return fmt.Errorf("an object with string attributes \"type_url\" and \"value\" is required")
+	}
+	typeURLV := v.GetAttr("type_url")
+	jsV := v.GetAttr("value")
+	if typeURLV.IsNull() || jsV.IsNull() || !typeURLV.IsKnown() || !jsV.IsKnown() {
+		return fmt.Errorf("attributes \"type_url\" and \"value\" must both be known and non-null")
+	}
+	typeURL := typeURLV.AsString()
+	js := jsV.AsString()
+	if typeURL == "" && js == "" {
+		return nil // leave the message empty
+	}
+
+	mt, err := h.types.FindMessageByURL(typeURL)
+	if err != nil {
+		return fmt.Errorf("unsupported message type %q", typeURL)
+	}
+	embedded := mt.New()
+	err = protojson.UnmarshalOptions{Resolver: h.types}.Unmarshal([]byte(js), embedded.Interface())
+	if err != nil {
+		return fmt.Errorf("invalid JSON for %s message: %w", mt.Descriptor().FullName(), err)
+	}
+	raw, err := proto.MarshalOptions{Deterministic: true}.Marshal(embedded.Interface())
+	if err != nil {
+		return fmt.Errorf("failed to encode %s message: %w", mt.Descriptor().FullName(), err)
+	}
+	fields := msg.Descriptor().Fields()
+	msg.Set(fields.ByName("type_url"), protoreflect.ValueOfString(typeURL))
+	msg.Set(fields.ByName("value"), protoreflect.ValueOfBytes(raw))
+	return nil
+}
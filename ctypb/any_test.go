@@ -0,0 +1,722 @@
+package ctypb
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty-debug/ctydebug"
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/zclconf/go-cty-protobuf/internal/testproto"
+)
+
+func TestDecodeAny(t *testing.T) {
+	tests := map[string]struct {
+		TypeURL string
+		Value   string
+		Want    cty.Value
+		WantErr string
+	}{
+		"simple": {
+			TypeURL: "type.googleapis.com/testproto.Simple",
+			Value:   "CgA=",
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"foo": cty.EmptyObjectVal,
+			}),
+		},
+		"empty": {
+			TypeURL: "type.googleapis.com/testproto.Empty",
+			Value:   "",
+			Want:    cty.EmptyObjectVal,
+		},
+		"unknown type": {
+			TypeURL: "type.googleapis.com/testproto.Nonexistent",
+			Value:   "",
+			WantErr: `unsupported message type "type.googleapis.com/testproto.Nonexistent"`,
+		},
+		"invalid base64": {
+			TypeURL: "type.googleapis.com/testproto.Simple",
+			Value:   "!!!",
+			WantErr: `value must contain base64-encoded bytes`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := DecodeAny(test.TypeURL, test.Value, protoregistry.GlobalTypes)
+
+			if test.WantErr != "" {
+				if err == nil {
+					t.Fatalf("succeeded; want error\nwant: %s", test.WantErr)
+				}
+				if got, want := err.Error(), test.WantErr; got != want {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+
+			if !test.Want.RawEquals(got) {
+				t.Errorf(
+					"wrong result\ngot: %s\nwant: %s",
+					ctydebug.ValueString(got),
+					ctydebug.ValueString(test.Want),
+				)
+			}
+		})
+	}
+}
+
+func TestEncodeAny(t *testing.T) {
+	tests := map[string]struct {
+		Value   cty.Value
+		TypeURL string
+		Want    string
+		WantErr string
+	}{
+		"simple": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"foo": cty.EmptyObjectVal,
+			}),
+			TypeURL: "type.googleapis.com/testproto.Simple",
+			Want:    "CgA=",
+		},
+		"empty": {
+			Value:   cty.EmptyObjectVal,
+			TypeURL: "type.googleapis.com/testproto.Empty",
+			Want:    "",
+		},
+		"unknown type": {
+			Value:   cty.EmptyObjectVal,
+			TypeURL: "type.googleapis.com/testproto.Nonexistent",
+			WantErr: `unsupported message type "type.googleapis.com/testproto.Nonexistent"`,
+		},
+		"wrong value type": {
+			Value:   cty.StringVal("hello"),
+			TypeURL: "type.googleapis.com/testproto.Simple",
+			WantErr: `an object is required`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := EncodeAny(test.Value, test.TypeURL, nil)
+
+			if test.WantErr != "" {
+				if err == nil {
+					t.Fatalf("succeeded; want error\nwant: %s", test.WantErr)
+				}
+				if got, want := err.Error(), test.WantErr; got != want {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+
+			if got != test.Want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestAnyJSONHandler(t *testing.T) {
+	handlers := NewWellKnownHandlers()
+	handlers.Register("google.protobuf.Any", NewAnyJSONHandler(nil))
+	opts := []Option{WithWellKnownHandlers(handlers)}
+
+	embedded, err := anypb.New(&testproto.WithEnum{
+		TString: "hello",
+		TEnum:   testproto.WithEnum_C,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := &testproto.WithAny{
+		TString: "outer",
+		TAny:    embedded,
+		TAnyList: []*anypb.Any{
+			{}, // an empty Any has no embedded message
+		},
+	}
+
+	got, err := FromProtobufMessage(msg.ProtoReflect(), opts...)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	anyV := got.GetAttr("t_any")
+	if got, want := anyV.GetAttr("type_url"), cty.StringVal("type.googleapis.com/testproto.WithEnum"); !want.RawEquals(got) {
+		t.Errorf("wrong type_url\ngot:  %#v\nwant: %#v", got, want)
+	}
+	// The exact JSON formatting is unstable, so we compare the decoded
+	// result instead.
+	var gotJSON interface{}
+	if err := json.Unmarshal([]byte(anyV.GetAttr("value").AsString()), &gotJSON); err != nil {
+		t.Fatalf("value is not valid JSON: %s", err)
+	}
+	wantJSON := map[string]interface{}{
+		"tString": "hello",
+		"tEnum":   "C",
+	}
+	if diff := cmp.Diff(wantJSON, gotJSON); diff != "" {
+		t.Errorf("wrong JSON value\n%s", diff)
+	}
+
+	into := &testproto.WithAny{}
+	err = ToProtobufMessage(got, into.ProtoReflect(), opts...)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if diff := cmp.Diff(msg, into, protocmp.Transform()); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+
+	// Invalid JSON is reported with the path of the Any attribute.
+	bad := got.AsValueMap()
+	bad["t_any"] = cty.ObjectVal(map[string]cty.Value{
+		"type_url": cty.StringVal("type.googleapis.com/testproto.WithEnum"),
+		"value":    cty.StringVal(`{"tEnum": "Z"}`),
+	})
+	err = ToProtobufMessage(cty.ObjectVal(bad), (&testproto.WithAny{}).ProtoReflect(), opts...)
+	if err == nil {
+		t.Fatalf("succeeded with invalid JSON; want error")
+	}
+	if pathErr, ok := err.(cty.PathError); !ok || !pathErr.Path.Equals(cty.GetAttrPath("t_any")) {
+		t.Errorf("error does not refer to the t_any attribute: %s", err)
+	}
+}
+
+func TestDecodeAnyFunc(t *testing.T) {
+	f := DecodeAnyFunc(nil)
+	simpleTy := cty.Object(map[string]cty.Type{
+		"foo": cty.EmptyObject,
+	})
+
+	// The type depends on the type URL, so the argument type alone isn't
+	// enough to determine the return type.
+	gotTy, err := f.ReturnType([]cty.Type{anyObjectType})
+	if err != nil {
+		t.Fatalf("unexpected error from ReturnType\ngot: %s", err.Error())
+	}
+	if gotTy != cty.DynamicPseudoType {
+		t.Errorf("wrong return type for argument type alone %#v", gotTy)
+	}
+
+	tests := map[string]struct {
+		Arg      cty.Value
+		WantType cty.Type
+		Want     cty.Value
+		WantErr  string
+	}{
+		"simple": {
+			Arg: cty.ObjectVal(map[string]cty.Value{
+				"type_url": cty.StringVal("type.googleapis.com/testproto.Simple"),
+				"value":    cty.StringVal("CgA="),
+			}),
+			WantType: simpleTy,
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"foo": cty.EmptyObjectVal,
+			}),
+		},
+		"map argument": {
+			Arg: cty.MapVal(map[string]cty.Value{
+				"type_url": cty.StringVal("type.googleapis.com/testproto.Empty"),
+				"value":    cty.StringVal(""),
+			}),
+			WantType: cty.EmptyObject,
+			Want:     cty.EmptyObjectVal,
+		},
+		"unknown value": {
+			Arg: cty.ObjectVal(map[string]cty.Value{
+				"type_url": cty.StringVal("type.googleapis.com/testproto.Simple"),
+				"value":    cty.UnknownVal(cty.String),
+			}),
+			WantType: simpleTy,
+			Want:     cty.UnknownVal(simpleTy),
+		},
+		"unknown type URL": {
+			Arg: cty.ObjectVal(map[string]cty.Value{
+				"type_url": cty.UnknownVal(cty.String),
+				"value":    cty.StringVal(""),
+			}),
+			WantType: cty.DynamicPseudoType,
+			Want:     cty.DynamicVal,
+		},
+		"unsupported type URL": {
+			Arg: cty.ObjectVal(map[string]cty.Value{
+				"type_url": cty.StringVal("type.googleapis.com/testproto.Nonexistent"),
+				"value":    cty.StringVal(""),
+			}),
+			WantErr: `unsupported message type "type.googleapis.com/testproto.Nonexistent"`,
+		},
+		"invalid base64": {
+			Arg: cty.ObjectVal(map[string]cty.Value{
+				"type_url": cty.StringVal("type.googleapis.com/testproto.Simple"),
+				"value":    cty.StringVal("!!!"),
+			}),
+			WantType: simpleTy,
+			WantErr:  `value must contain base64-encoded bytes`,
+		},
+		"missing attribute": {
+			Arg: cty.ObjectVal(map[string]cty.Value{
+				"type_url": cty.StringVal("type.googleapis.com/testproto.Simple"),
+			}),
+			WantErr: `attribute "value" is required`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := f.Call([]cty.Value{test.Arg})
+			if test.WantErr != "" {
+				if err == nil {
+					t.Fatalf("succeeded; want error\nwant: %s", test.WantErr)
+				}
+				if got, want := err.Error(), test.WantErr; got != want {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+
+			if !test.WantType.Equals(got.Type()) {
+				t.Errorf("wrong result type\ngot:  %#v\nwant: %#v", got.Type(), test.WantType)
+			}
+			if !test.Want.RawEquals(got) {
+				t.Errorf(
+					"wrong result\ngot: %s\nwant: %s",
+					ctydebug.ValueString(got),
+					ctydebug.ValueString(test.Want),
+				)
+			}
+		})
+	}
+}
+
+func TestDecodeAllAnys(t *testing.T) {
+	mustAny := func(msg proto.Message) *anypb.Any {
+		ret, err := anypb.New(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ret
+	}
+	simple := &testproto.Simple{Foo: &testproto.Empty{}}
+	msg := &testproto.WithAny{
+		TString: "hello",
+		TAny:    mustAny(simple),
+		TAnyList: []*anypb.Any{
+			mustAny(&testproto.Empty{}),
+			mustAny(&testproto.Empty{}),
+		},
+		TAnyMapString: map[string]*anypb.Any{
+			"a": mustAny(simple),
+			"b": mustAny(&testproto.Empty{}),
+		},
+		TAnyMapNumber: map[int64]*anypb.Any{
+			1: mustAny(simple),
+		},
+	}
+	ty, err := ImpliedTypeForMessageDesc(msg.ProtoReflect().Descriptor())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	v, err := FromProtobufMessage(msg.ProtoReflect())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+
+	got, err := DecodeAllAnys(v, ty, nil)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	simpleVal := cty.ObjectVal(map[string]cty.Value{
+		"foo": cty.EmptyObjectVal,
+	})
+	want := cty.ObjectVal(map[string]cty.Value{
+		"t_string": cty.StringVal("hello"),
+		"t_any":    simpleVal,
+		// The elements all have the same type, so this is still a list.
+		"t_any_list": cty.ListVal([]cty.Value{
+			cty.EmptyObjectVal,
+			cty.EmptyObjectVal,
+		}),
+		// The elements have different types, so this becomes an object.
+		"t_any_map_string": cty.ObjectVal(map[string]cty.Value{
+			"a": simpleVal,
+			"b": cty.EmptyObjectVal,
+		}),
+		"t_any_map_number": cty.SetVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{
+				"key":   cty.NumberIntVal(1),
+				"value": simpleVal,
+			}),
+		}),
+	})
+	if !want.RawEquals(got) {
+		t.Errorf(
+			"wrong result\ngot: %s\nwant: %s",
+			ctydebug.ValueString(got),
+			ctydebug.ValueString(want),
+		)
+	}
+
+	t.Run("null and empty", func(t *testing.T) {
+		v, err := FromProtobufMessage((&testproto.WithAny{}).ProtoReflect())
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		got, err := DecodeAllAnys(v, ty, nil)
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		want := cty.ObjectVal(map[string]cty.Value{
+			"t_string":         cty.StringVal(""),
+			"t_any":            cty.NullVal(cty.DynamicPseudoType),
+			"t_any_list":       v.GetAttr("t_any_list"),
+			"t_any_map_string": v.GetAttr("t_any_map_string"),
+			"t_any_map_number": v.GetAttr("t_any_map_number"),
+		})
+		if !want.RawEquals(got) {
+			t.Errorf(
+				"wrong result\ngot: %s\nwant: %s",
+				ctydebug.ValueString(got),
+				ctydebug.ValueString(want),
+			)
+		}
+	})
+	t.Run("dynamic type", func(t *testing.T) {
+		got, err := DecodeAllAnys(v, cty.DynamicPseudoType, nil)
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		if !want.RawEquals(got) {
+			t.Errorf(
+				"wrong result\ngot: %s\nwant: %s",
+				ctydebug.ValueString(got),
+				ctydebug.ValueString(want),
+			)
+		}
+	})
+	t.Run("error", func(t *testing.T) {
+		bad := &testproto.WithAny{
+			TAnyList: []*anypb.Any{
+				mustAny(&testproto.Empty{}),
+				{TypeUrl: "type.googleapis.com/testproto.Nonexistent"},
+			},
+		}
+		v, err := FromProtobufMessage(bad.ProtoReflect())
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		_, err = DecodeAllAnys(v, ty, nil)
+		if err == nil {
+			t.Fatalf("succeeded with unsupported message type; want error")
+		}
+		if got, want := err.Error(), `unsupported message type "type.googleapis.com/testproto.Nonexistent"`; got != want {
+			t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+		}
+		pathErr, ok := err.(cty.PathError)
+		if !ok {
+			t.Fatalf("error is %T, not cty.PathError: %s", err, err.Error())
+		}
+		wantPath := cty.GetAttrPath("t_any_list").IndexInt(1)
+		if !pathErr.Path.Equals(wantPath) {
+			t.Errorf("wrong error path\ngot:  %#v\nwant: %#v", pathErr.Path, wantPath)
+		}
+	})
+}
+
+func TestDecodeAllAnysNested(t *testing.T) {
+	mustAny := func(msg proto.Message) *anypb.Any {
+		ret, err := anypb.New(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ret
+	}
+	// The outer Any wraps a message that has an Any field of its own, and
+	// that one wraps another Any directly, so decoding must re-enter the
+	// Any type twice to reach the innermost message.
+	inner := &testproto.WithAny{
+		TString: "inner",
+		TAny:    mustAny(mustAny(&testproto.Simple{Foo: &testproto.Empty{}})),
+	}
+	msg := &testproto.WithAny{
+		TString: "outer",
+		TAny:    mustAny(inner),
+	}
+	ty, err := ImpliedTypeForMessageDesc(msg.ProtoReflect().Descriptor())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	v, err := FromProtobufMessage(msg.ProtoReflect())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+
+	t.Run("all levels", func(t *testing.T) {
+		got, err := DecodeAllAnys(v, ty, nil)
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		emptyCollections := map[string]cty.Value{
+			"t_any_list":       cty.ListValEmpty(anyObjectType),
+			"t_any_map_string": cty.MapValEmpty(anyObjectType),
+			"t_any_map_number": cty.SetValEmpty(cty.Object(map[string]cty.Type{
+				"key":   cty.Number,
+				"value": anyObjectType,
+			})),
+		}
+		withAny := func(s string, any cty.Value) cty.Value {
+			attrs := map[string]cty.Value{
+				"t_string": cty.StringVal(s),
+				"t_any":    any,
+			}
+			for k, v := range emptyCollections {
+				attrs[k] = v
+			}
+			return cty.ObjectVal(attrs)
+		}
+		want := withAny("outer", withAny("inner", cty.ObjectVal(map[string]cty.Value{
+			"foo": cty.EmptyObjectVal,
+		})))
+		if !want.RawEquals(got) {
+			t.Errorf(
+				"wrong result\ngot: %s\nwant: %s",
+				ctydebug.ValueString(got),
+				ctydebug.ValueString(want),
+			)
+		}
+	})
+	t.Run("max depth", func(t *testing.T) {
+		_, err := DecodeAllAnys(v, ty, nil, WithMaxDepth(2))
+		if err == nil {
+			t.Fatalf("succeeded with Any values nested too deeply; want error")
+		}
+		if got, want := err.Error(), "Any value is nested more than 2 levels deep"; got != want {
+			t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+		}
+		pathErr, ok := err.(cty.PathError)
+		if !ok {
+			t.Fatalf("error is %T, not cty.PathError: %s", err, err.Error())
+		}
+		wantPath := cty.GetAttrPath("t_any").GetAttr("t_any")
+		if !pathErr.Path.Equals(wantPath) {
+			t.Errorf("wrong error path\ngot:  %#v\nwant: %#v", pathErr.Path, wantPath)
+		}
+	})
+}
+
+func TestEncodeAllAnys(t *testing.T) {
+	mustAny := func(msg proto.Message) *anypb.Any {
+		ret, err := anypb.New(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ret
+	}
+	msg := &testproto.WithAny{
+		TString: "hello",
+		TAny: mustAny(&testproto.WithAny{
+			TString: "inner",
+			TAny:    mustAny(&testproto.Simple{Foo: &testproto.Empty{}}),
+		}),
+		// The two elements have different message types, so DecodeAllAnys
+		// turns this list into a tuple.
+		TAnyList: []*anypb.Any{
+			mustAny(&testproto.Simple{Foo: &testproto.Empty{}}),
+			mustAny(&testproto.Assorted_Nested{TNestedField: "before"}),
+		},
+		TAnyMapString: map[string]*anypb.Any{
+			"a": mustAny(&testproto.Empty{}),
+		},
+	}
+	ty, err := ImpliedTypeForMessageDesc(msg.ProtoReflect().Descriptor())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	orig, err := FromProtobufMessage(msg.ProtoReflect())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	decoded, err := DecodeAllAnys(orig, ty, nil)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if got := decoded.GetAttr("t_any_list").Type(); !got.IsTupleType() {
+		t.Fatalf("decoded t_any_list has type %s; want a tuple type", ctydebug.TypeString(got))
+	}
+
+	// Change the second element of the tuple, which must then be written
+	// back into the second Any value with its original type URL.
+	attrs := decoded.AsValueMap()
+	elems := attrs["t_any_list"].AsValueSlice()
+	elems[1] = cty.ObjectVal(map[string]cty.Value{
+		"t_nested_field": cty.StringVal("after"),
+	})
+	attrs["t_any_list"] = cty.TupleVal(elems)
+	decoded = cty.ObjectVal(attrs)
+
+	got, err := EncodeAllAnys(decoded, orig, ty, nil)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if !got.Type().Equals(ty) {
+		t.Fatalf(
+			"wrong result type\ngot: %s\nwant: %s",
+			ctydebug.TypeString(got.Type()),
+			ctydebug.TypeString(ty),
+		)
+	}
+	into := &testproto.WithAny{}
+	err = ToProtobufMessage(got, into.ProtoReflect())
+	if err != nil {
+		t.Fatalf("unexpected error from ToProtobufMessage\ngot: %s", err.Error())
+	}
+	want := proto.Clone(msg).(*testproto.WithAny)
+	want.TAnyList[1] = mustAny(&testproto.Assorted_Nested{TNestedField: "after"})
+	if diff := cmp.Diff(want, into, protocmp.Transform()); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+	for i, elem := range into.TAnyList {
+		if got, want := elem.TypeUrl, msg.TAnyList[i].TypeUrl; got != want {
+			t.Errorf("wrong type URL for element %d\ngot:  %s\nwant: %s", i, got, want)
+		}
+	}
+
+	t.Run("no original", func(t *testing.T) {
+		attrs := decoded.AsValueMap()
+		elems := append(attrs["t_any_list"].AsValueSlice(), cty.EmptyObjectVal)
+		attrs["t_any_list"] = cty.TupleVal(elems)
+		_, err := EncodeAllAnys(cty.ObjectVal(attrs), orig, ty, nil)
+		if err == nil {
+			t.Fatalf("succeeded with extra element; want error")
+		}
+		if got, want := err.Error(), "there is no original Any value to take the type URL from"; got != want {
+			t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+		}
+		pathErr, ok := err.(cty.PathError)
+		if !ok {
+			t.Fatalf("error is %T, not cty.PathError: %s", err, err.Error())
+		}
+		wantPath := cty.GetAttrPath("t_any_list").IndexInt(2)
+		if !pathErr.Path.Equals(wantPath) {
+			t.Errorf("wrong error path\ngot:  %#v\nwant: %#v", pathErr.Path, wantPath)
+		}
+	})
+}
+
+func TestDecodeAllAnysOptions(t *testing.T) {
+	// The type that represents an Any value depends on the options, and
+	// DecodeAllAnys and EncodeAllAnys must find Any values in any of
+	// those forms.
+	jsonHandlers := NewWellKnownHandlers()
+	jsonHandlers.Register("google.protobuf.Any", NewAnyJSONHandler(nil))
+	tests := map[string]struct {
+		Opts []Option
+		Attr string
+		// KeepUnknown is true if the unknown fields of the Any message
+		// survive the conversion.
+		KeepUnknown bool
+	}{
+		"default":                 {nil, "t_any", false},
+		"preserve unknown fields": {[]Option{WithPreserveUnknownFields()}, "t_any", true},
+		"bytes capsule":           {[]Option{WithBytesCapsule()}, "t_any", false},
+		"bytes as number lists":   {[]Option{WithBytesAsNumberLists()}, "t_any", false},
+		"JSON field names":        {[]Option{WithFieldNameFunc(JSONFieldName)}, "tAny", false},
+		"JSON handler":            {[]Option{WithWellKnownHandlers(jsonHandlers)}, "t_any", false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			opts := test.Opts
+			inner := &testproto.Assorted_Nested{TNestedField: "hello"}
+			anyMsg, err := anypb.New(inner)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Unknown fields in the Any message itself must survive the
+			// round trip when they're preserved.
+			anyMsg.ProtoReflect().SetUnknown(protowire.AppendVarint(protowire.AppendTag(nil, 99, protowire.VarintType), 1))
+			msg := &testproto.WithAny{TAny: anyMsg}
+
+			ty, err := ImpliedTypeForMessageDesc(msg.ProtoReflect().Descriptor(), opts...)
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			orig, err := FromProtobufMessage(msg.ProtoReflect(), opts...)
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			decoded, err := DecodeAllAnys(orig, ty, nil, opts...)
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			want, err := FromProtobufMessage(inner.ProtoReflect(), opts...)
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			if got := decoded.GetAttr(test.Attr); !want.RawEquals(got) {
+				t.Errorf("wrong decoded value\ngot:  %#v\nwant: %#v", got, want)
+			}
+
+			encoded, err := EncodeAllAnys(decoded, orig, ty, nil, opts...)
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			into := &testproto.WithAny{}
+			err = ToProtobufMessage(encoded, into.ProtoReflect(), opts...)
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			wantMsg := msg
+			if !test.KeepUnknown {
+				wantMsg = proto.Clone(msg).(*testproto.WithAny)
+				wantMsg.TAny.ProtoReflect().SetUnknown(nil)
+			}
+			if diff := cmp.Diff(wantMsg, into, protocmp.Transform()); diff != "" {
+				t.Errorf("wrong result\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDecodeAllAnysDynamicType(t *testing.T) {
+	// If Any values have a dynamic type then there's no way to find them.
+	handlers := NewWellKnownHandlers()
+	handlers.Register("google.protobuf.Any", dynamicAnyHandler{})
+	_, err := DecodeAllAnys(cty.EmptyObjectVal, cty.EmptyObject, nil, WithWellKnownHandlers(handlers))
+	if err == nil {
+		t.Fatalf("succeeded; want error")
+	}
+	if got, want := err.Error(), "can't find google.protobuf.Any values when they're represented as dynamic"; got != want {
+		t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+// dynamicAnyHandler is a WellKnownHandler for google.protobuf.Any whose
+// type is cty.DynamicPseudoType, for testing DecodeAllAnys.
+type dynamicAnyHandler struct{}
+
+func (dynamicAnyHandler) Type(desc protoreflect.MessageDescriptor) (cty.Type, bool) {
+	return cty.DynamicPseudoType, true
+}
+
+func (dynamicAnyHandler) FromProto(msg protoreflect.Message) (cty.Value, error) {
+	return cty.EmptyObjectVal, nil
+}
+
+func (dynamicAnyHandler) ToProto(v cty.Value, msg protoreflect.Message) error {
+	return nil
+}
@@ -0,0 +1,36 @@
+package ctypb
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// BytesCapsuleType is a cty capsule type that wraps a Go byte slice, used
+// to represent fields of the protocol buffers bytes kind when the
+// WithBytesCapsule option is in effect.
+//
+// Two values of this type are equal if their byte slices have the same
+// contents, regardless of whether they share the same underlying array.
+var BytesCapsuleType = cty.CapsuleWithOps("bytes", reflect.TypeOf([]byte(nil)), &cty.CapsuleOps{
+	GoString: func(val interface{}) string {
+		return fmt.Sprintf("ctypb.BytesCapsuleVal(%#v)", *val.(*[]byte))
+	},
+	TypeGoString: func(_ reflect.Type) string {
+		return "ctypb.BytesCapsuleType"
+	},
+	RawEquals: func(a, b interface{}) bool {
+		return bytes.Equal(*a.(*[]byte), *b.(*[]byte))
+	},
+})
+
+// BytesCapsuleVal returns a value of BytesCapsuleType wrapping the given
+// byte slice.
+//
+// The result refers to the given slice's underlying array, so the caller
+// must not modify the contents of the slice after calling BytesCapsuleVal.
+func BytesCapsuleVal(b []byte) cty.Value {
+	return cty.CapsuleVal(BytesCapsuleType, &b)
+}
@@ -0,0 +1,33 @@
+package ctypb
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestBytesCapsuleType(t *testing.T) {
+	a := BytesCapsuleVal([]byte("hello"))
+	b := BytesCapsuleVal([]byte("hello"))
+	c := BytesCapsuleVal([]byte("world"))
+
+	if !a.RawEquals(b) {
+		t.Errorf("values with same bytes are not RawEquals")
+	}
+	if a.RawEquals(c) {
+		t.Errorf("values with different bytes are RawEquals")
+	}
+	if got, want := a.Equals(b), cty.True; !got.RawEquals(want) {
+		t.Errorf("wrong result for equal values\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if got, want := a.Equals(c), cty.False; !got.RawEquals(want) {
+		t.Errorf("wrong result for unequal values\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	if got, want := a.GoString(), `ctypb.BytesCapsuleVal([]byte{0x68, 0x65, 0x6c, 0x6c, 0x6f})`; got != want {
+		t.Errorf("wrong value GoString\ngot:  %s\nwant: %s", got, want)
+	}
+	if got, want := BytesCapsuleType.GoString(), `ctypb.BytesCapsuleType`; got != want {
+		t.Errorf("wrong type GoString\ngot:  %s\nwant: %s", got, want)
+	}
+}
@@ -0,0 +1,61 @@
+package ctypb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// CheckConformance verifies that the type of the given value conforms to
+// the type that ImpliedTypeForMessageDesc would return for the given
+// message descriptor with the same options, without actually converting
+// the value into a message.
+//
+// This is intended as a fast way to report problems with a value before
+// trying to use it with ToProtobufMessage, but ToProtobufMessage might
+// still return an error for a value that passes this check, because it
+// also considers details of the value itself, such as whether numbers are
+// in range for their corresponding fields.
+//
+// If the type doesn't conform, the result is a ConformanceError describing
+// all of the problems that were found.
+func CheckConformance(v cty.Value, desc protoreflect.MessageDescriptor, opts ...Option) error {
+	wantTy, err := ImpliedTypeForMessageDesc(desc, opts...)
+	if err != nil {
+		return err
+	}
+	errs := v.Type().TestConformance(wantTy)
+	if len(errs) != 0 {
+		return ConformanceError{Errors: errs}
+	}
+	return nil
+}
+
+// ConformanceError is the error type returned by CheckConformance when a
+// value's type doesn't conform to the expected type.
+type ConformanceError struct {
+	// Errors contains one error for each problem that was found. These are
+	// usually cty.PathError values indicating which part of the value the
+	// problem relates to.
+	Errors []error
+}
+
+func (e ConformanceError) Error() string {
+	if len(e.Errors) == 1 {
+		return formatConformanceError(e.Errors[0])
+	}
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = formatConformanceError(err)
+	}
+	return fmt.Sprintf("%d problems: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+func formatConformanceError(err error) string {
+	if pathErr, ok := err.(cty.PathError); ok && len(pathErr.Path) != 0 {
+		return fmt.Sprintf("%s: %s", formatTypePath(pathErr.Path), err)
+	}
+	return err.Error()
+}
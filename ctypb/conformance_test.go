@@ -0,0 +1,121 @@
+package ctypb
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/zclconf/go-cty-protobuf/internal/testproto"
+)
+
+func TestCheckConformance(t *testing.T) {
+	tests := map[string]struct {
+		Value    cty.Value
+		Desc     protoreflect.MessageDescriptor
+		WantErrs int
+		WantErr  string
+	}{
+		"conforming": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"foo": cty.EmptyObjectVal,
+			}),
+			Desc: (*testproto.Simple)(nil).ProtoReflect().Descriptor(),
+		},
+		"conforming null": {
+			Value: cty.NullVal(cty.Object(map[string]cty.Type{
+				"foo": cty.EmptyObject,
+			})),
+			Desc: (*testproto.Simple)(nil).ProtoReflect().Descriptor(),
+		},
+		"not an object": {
+			Value:    cty.StringVal("hello"),
+			Desc:     (*testproto.Simple)(nil).ProtoReflect().Descriptor(),
+			WantErrs: 1,
+			WantErr:  `object required, but received string`,
+		},
+		"nested problem": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_strings": cty.ListVal([]cty.Value{cty.True}),
+				"t_message": cty.ListValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_map_string_bool": cty.MapValEmpty(cty.Bool),
+				"t_map_number_bool": cty.SetValEmpty(cty.Object(map[string]cty.Type{
+					"key":   cty.Number,
+					"value": cty.Bool,
+				})),
+				"t_map_string_message": cty.MapValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_map_number_message": cty.SetValEmpty(cty.Object(map[string]cty.Type{
+					"key": cty.Number,
+					"value": cty.Object(map[string]cty.Type{
+						"t_nested_field": cty.String,
+					}),
+				})),
+			}),
+			Desc:     (*testproto.WithRepeated)(nil).ProtoReflect().Descriptor(),
+			WantErrs: 1,
+			WantErr:  `t_strings[*]: string required, but received bool`,
+		},
+		"several problems": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_strings": cty.ListValEmpty(cty.String),
+				"t_message": cty.ListValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.Number,
+				})),
+				"t_map_string_bool": cty.MapValEmpty(cty.Bool),
+				"t_map_number_bool": cty.SetValEmpty(cty.Object(map[string]cty.Type{
+					"key":   cty.Number,
+					"value": cty.Bool,
+				})),
+				"t_map_string_message": cty.MapValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_map_number_message": cty.SetValEmpty(cty.Object(map[string]cty.Type{
+					"key": cty.Number,
+					"value": cty.Object(map[string]cty.Type{
+						"t_nested_field": cty.String,
+						"extra":          cty.String,
+					}),
+				})),
+			}),
+			Desc:     (*testproto.WithRepeated)(nil).ProtoReflect().Descriptor(),
+			WantErrs: 2,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := CheckConformance(test.Value, test.Desc)
+
+			if test.WantErrs == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error\ngot: %s", err.Error())
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("succeeded; want %d errors", test.WantErrs)
+			}
+			confErr, ok := err.(ConformanceError)
+			if !ok {
+				t.Fatalf("wrong error type %T", err)
+			}
+			if got, want := len(confErr.Errors), test.WantErrs; got != want {
+				t.Fatalf("wrong number of errors %d; want %d\n%s", got, want, err.Error())
+			}
+			for _, err := range confErr.Errors {
+				if _, ok := err.(cty.PathError); !ok {
+					t.Errorf("error is %T, not cty.PathError: %s", err, err.Error())
+				}
+			}
+			if test.WantErr != "" {
+				if got, want := err.Error(), test.WantErr; got != want {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+				}
+			}
+		})
+	}
+}
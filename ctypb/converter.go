@@ -0,0 +1,75 @@
+package ctypb
+
+import (
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Converter is a reusable set of conversion settings, for applications that
+// perform many conversions using the same options.
+//
+// The package-level functions FromProtobufMessage, ToProtobufMessage, and
+// ImpliedTypeForMessageDesc are equivalent to the methods of a Converter
+// constructed with the same options, except that they share a single
+// package-level cache of implied types whereas each Converter created by
+// NewConverter has its own cache, which is discarded along with the
+// Converter itself.
+//
+// The zero value of Converter is ready to use, and uses the default options
+// and the package-level cache of implied types, just as the package-level
+// functions do when called without any options.
+//
+// A Converter is safe for concurrent use by multiple goroutines, as long as
+// any registries passed to it in options, such as with WithExtensions or
+// WithWellKnownHandlers, are not modified after it is constructed.
+type Converter struct {
+	opts *options
+}
+
+// NewConverter returns a new Converter that will use the given options for
+// all of its conversions.
+//
+// The new Converter initially has an empty cache of implied types, which it
+// populates on first use of each message type. Callers that want to avoid
+// that cost during later conversions can call ImpliedType for each of the
+// message types of interest immediately after construction.
+func NewConverter(opts ...Option) *Converter {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	o.typeCache = &typeCache{}
+	return &Converter{opts: o}
+}
+
+// FromMessage is equivalent to FromProtobufMessage using the converter's
+// options.
+func (c Converter) FromMessage(msg protoreflect.Message) (cty.Value, error) {
+	return fromProtobufMessageRoot(msg, c.options())
+}
+
+// ToMessage is equivalent to ToProtobufMessage using the converter's
+// options.
+func (c Converter) ToMessage(obj cty.Value, into protoreflect.Message) error {
+	return toProtobufMessageRoot(obj, into, c.options())
+}
+
+// ImpliedType is equivalent to ImpliedTypeForMessageDesc using the
+// converter's options.
+func (c Converter) ImpliedType(desc protoreflect.MessageDescriptor) (cty.Type, error) {
+	opts := c.options()
+	if handler, ty := wellKnownHandlerFor(desc, opts); handler != nil {
+		return ty, nil
+	}
+	path := make(cty.Path, 0, 4) // four levels deep without further allocation
+	return impliedTypeForMessageDesc(desc, opts, path)
+}
+
+// options returns the options for the converter's conversions, which are
+// the default options for the zero value of Converter.
+func (c Converter) options() *options {
+	if c.opts == nil {
+		return defaultOptions
+	}
+	return c.opts
+}
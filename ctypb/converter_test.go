@@ -0,0 +1,214 @@
+package ctypb
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/zclconf/go-cty-protobuf/internal/testproto"
+)
+
+func TestConverter(t *testing.T) {
+	c := NewConverter(WithOmitDefaults(), WithBytesCapsule())
+	desc := (*testproto.Assorted)(nil).ProtoReflect().Descriptor()
+
+	ty, err := c.ImpliedType(desc)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	wantTy, err := ImpliedTypeForMessageDesc(desc, WithOmitDefaults(), WithBytesCapsule())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if !wantTy.Equals(ty) {
+		t.Errorf("wrong type\ngot:  %#v\nwant: %#v", ty, wantTy)
+	}
+	if _, ok := c.opts.typeCache.get(desc, c.opts); !ok {
+		t.Errorf("converter's own cache does not contain the implied type")
+	}
+
+	msg := &testproto.Assorted{
+		TString: "hello",
+		TBytes:  []byte("world"),
+	}
+	// Multiple goroutines can use the same converter concurrently.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			v, err := c.FromMessage(msg.ProtoReflect())
+			if err != nil {
+				t.Errorf("unexpected error from FromMessage\ngot: %s", err.Error())
+				return
+			}
+			if got, want := v.GetAttr("t_int32"), cty.NullVal(cty.Number); !want.RawEquals(got) {
+				t.Errorf("wrong t_int32\ngot:  %#v\nwant: %#v", got, want)
+			}
+			if got, want := v.GetAttr("t_bytes"), BytesCapsuleVal([]byte("world")); !want.RawEquals(got) {
+				t.Errorf("wrong t_bytes\ngot:  %#v\nwant: %#v", got, want)
+			}
+
+			into := &testproto.Assorted{}
+			err = c.ToMessage(v, into.ProtoReflect())
+			if err != nil {
+				t.Errorf("unexpected error from ToMessage\ngot: %s", err.Error())
+				return
+			}
+			if diff := cmp.Diff(msg, into, protocmp.Transform()); diff != "" {
+				t.Errorf("wrong result\n%s", diff)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestConverterZeroValue(t *testing.T) {
+	// The zero value uses the default options, like the package-level
+	// functions called without any options.
+	var c Converter
+	desc := (*testproto.Assorted)(nil).ProtoReflect().Descriptor()
+
+	ty, err := c.ImpliedType(desc)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	wantTy, err := ImpliedTypeForMessageDesc(desc)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if !wantTy.Equals(ty) {
+		t.Errorf("wrong type\ngot:  %#v\nwant: %#v", ty, wantTy)
+	}
+
+	msg := &testproto.Assorted{
+		TString: "hello",
+		TBytes:  []byte("world"),
+	}
+	v, err := c.FromMessage(msg.ProtoReflect())
+	if err != nil {
+		t.Fatalf("unexpected error from FromMessage\ngot: %s", err.Error())
+	}
+	want, err := FromProtobufMessage(msg.ProtoReflect())
+	if err != nil {
+		t.Fatalf("unexpected error from FromProtobufMessage\ngot: %s", err.Error())
+	}
+	if !want.RawEquals(v) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", v, want)
+	}
+
+	into := &testproto.Assorted{}
+	err = c.ToMessage(v, into.ProtoReflect())
+	if err != nil {
+		t.Fatalf("unexpected error from ToMessage\ngot: %s", err.Error())
+	}
+	if diff := cmp.Diff(msg, into, protocmp.Transform()); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+}
+
+func TestConverterJSONFieldName(t *testing.T) {
+	c := NewConverter(WithFieldNameFunc(JSONFieldName))
+	desc := (*testproto.WithRepeated)(nil).ProtoReflect().Descriptor()
+
+	ty, err := c.ImpliedType(desc)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	for _, name := range []string{"tStrings", "tMessage", "tMapStringBool", "tMapNumberBool", "tMapStringMessage", "tMapNumberMessage"} {
+		if !ty.HasAttribute(name) {
+			t.Errorf("implied type has no attribute %q", name)
+		}
+	}
+	nestedTy := ty.AttributeType("tMessage").ElementType()
+	if !nestedTy.HasAttribute("tNestedField") {
+		t.Errorf("nested message type has no attribute \"tNestedField\"")
+	}
+
+	msg := &testproto.WithRepeated{
+		TStrings: []string{"a"},
+		TMessage: []*testproto.WithRepeated_Nested{
+			{TNestedField: "b"},
+		},
+		TMapNumberMessage: map[int64]*testproto.WithRepeated_Nested{
+			1: {TNestedField: "c"},
+		},
+	}
+	v, err := c.FromMessage(msg.ProtoReflect())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if !v.Type().Equals(ty) {
+		t.Errorf("value does not have the implied type\ngot:  %#v\nwant: %#v", v.Type(), ty)
+	}
+
+	into := &testproto.WithRepeated{}
+	err = c.ToMessage(v, into.ProtoReflect())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if diff := cmp.Diff(msg, into, protocmp.Transform()); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+}
+
+func TestConverterFieldNumberAliases(t *testing.T) {
+	// Imagine that t_nested_field was previously named "old_nested", and
+	// t_strings was previously named "old_strings".
+	nestedDesc := (*testproto.WithRepeated_Nested)(nil).ProtoReflect().Descriptor()
+	desc := (*testproto.WithRepeated)(nil).ProtoReflect().Descriptor()
+	nameFunc := FieldNumberAliases(nestedDesc, map[protoreflect.FieldNumber]string{
+		1: "old_nested",
+	}, FieldNumberAliases(desc, map[protoreflect.FieldNumber]string{
+		1: "old_strings",
+	}, nil))
+	c := NewConverter(WithFieldNameFunc(nameFunc))
+
+	ty, err := c.ImpliedType(desc)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	for _, name := range []string{"old_strings", "t_message", "t_map_string_bool"} {
+		if !ty.HasAttribute(name) {
+			t.Errorf("implied type has no attribute %q", name)
+		}
+	}
+	if ty.HasAttribute("t_strings") {
+		t.Errorf("implied type has attribute for the new name \"t_strings\"")
+	}
+	// The nested message has its own alias for its field number 1, rather
+	// than the alias for the top-level message's field of the same number.
+	nestedTy := ty.AttributeType("t_message").ElementType()
+	if !nestedTy.Equals(cty.Object(map[string]cty.Type{"old_nested": cty.String})) {
+		t.Errorf("wrong nested message type %#v", nestedTy)
+	}
+
+	msg := &testproto.WithRepeated{
+		TStrings: []string{"a"},
+		TMessage: []*testproto.WithRepeated_Nested{
+			{TNestedField: "b"},
+		},
+	}
+	v, err := c.FromMessage(msg.ProtoReflect())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	want := cty.ListVal([]cty.Value{cty.StringVal("a")})
+	if got := v.GetAttr("old_strings"); !want.RawEquals(got) {
+		t.Errorf("wrong value for old_strings\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	into := &testproto.WithRepeated{}
+	err = c.ToMessage(v, into.ProtoReflect())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if diff := cmp.Diff(msg, into, protocmp.Transform()); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+}
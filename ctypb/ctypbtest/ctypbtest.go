@@ -0,0 +1,56 @@
+// Package ctypbtest contains helpers for testing code that uses package
+// ctypb, such as applications that define their own options or well-known
+// type handlers.
+package ctypbtest
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/zclconf/go-cty-protobuf/ctypb"
+)
+
+// AssertRoundTrip converts the given message to a cty value using
+// ctypb.FromProtobufMessage and then converts that value back into a new
+// message of the same type using ctypb.ToProtobufMessage, reporting test
+// failures if either conversion fails or if the resulting message is not
+// equal to the original.
+//
+// It also reports a test failure if the intermediate value doesn't conform
+// to the type that ctypb.ImpliedTypeForMessageDesc returns for the message
+// type.
+//
+// The given options are used for all of the conversions. AssertRoundTrip
+// returns the intermediate value, so that the caller can make further
+// assertions about it. If the conversion to cty fails then the result is
+// cty.NilVal.
+func AssertRoundTrip(t testing.TB, msg proto.Message, opts ...ctypb.Option) cty.Value {
+	t.Helper()
+
+	src := msg.ProtoReflect()
+	v, err := ctypb.FromProtobufMessage(src, opts...)
+	if err != nil {
+		t.Errorf("conversion from protobuf failed: %s", err)
+		return cty.NilVal
+	}
+
+	if err := ctypb.CheckConformance(v, src.Descriptor(), opts...); err != nil {
+		t.Errorf("converted value does not conform to implied type: %s", err)
+	}
+
+	dst := src.New()
+	err = ctypb.ToProtobufMessage(v, dst, opts...)
+	if err != nil {
+		t.Errorf("conversion to protobuf failed: %s", err)
+		return v
+	}
+
+	if diff := cmp.Diff(msg, dst.Interface(), protocmp.Transform()); diff != "" {
+		t.Errorf("round-trip produced a different message\n%s", diff)
+	}
+	return v
+}
@@ -0,0 +1,138 @@
+package ctypbtest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/zclconf/go-cty-protobuf/ctypb"
+	"github.com/zclconf/go-cty-protobuf/internal/testproto"
+)
+
+func TestAssertRoundTrip(t *testing.T) {
+	tests := map[string]struct {
+		msg  proto.Message
+		opts []ctypb.Option
+	}{
+		"empty": {
+			&testproto.Assorted{},
+			nil,
+		},
+		"assorted": {
+			&testproto.Assorted{
+				TDouble:  1.5,
+				TInt64:   -12,
+				TUint64:  12,
+				TBool:    true,
+				TString:  "hello",
+				TBytes:   []byte("world"),
+				TMessage: &testproto.Assorted_Nested{TNestedField: "nested"},
+			},
+			nil,
+		},
+		"repeated and maps": {
+			&testproto.WithRepeated{
+				TStrings:       []string{"a", "b"},
+				TMapStringBool: map[string]bool{"a": true},
+				TMapNumberBool: map[int64]bool{1: true, -2: false},
+				TMapNumberMessage: map[int64]*testproto.WithRepeated_Nested{
+					3: {TNestedField: "three"},
+				},
+			},
+			nil,
+		},
+		"well-known types": {
+			&testproto.WithWellKnown{
+				TTimestamp:   timestamppb.New(time.Date(2021, 6, 1, 12, 30, 0, 500, time.UTC)),
+				TDuration:    durationpb.New(1500000000),
+				TStringValue: wrapperspb.String("hi"),
+			},
+			[]ctypb.Option{ctypb.WithWellKnownHandlers(ctypb.NewWellKnownHandlers())},
+		},
+		"well-known struct collections": {
+			&testproto.WithStructCollections{
+				TValueMap: map[string]*structpb.Value{
+					"a": structpb.NewNumberValue(1),
+					"b": structpb.NewStringValue("x"),
+				},
+				TValueNumberMap: map[int64]*structpb.Value{
+					1: structpb.NewBoolValue(true),
+					2: structpb.NewNullValue(),
+				},
+				TValues: []*structpb.Value{
+					structpb.NewNumberValue(1),
+					structpb.NewStringValue("x"),
+				},
+				TStructs: []*testproto.WithStruct{
+					{TValue: structpb.NewNumberValue(1)},
+					{TValue: structpb.NewStringValue("x")},
+				},
+			},
+			[]ctypb.Option{ctypb.WithWellKnownStruct()},
+		},
+		"omit defaults": {
+			&testproto.WithOptional{StringReq: "a"},
+			[]ctypb.Option{ctypb.WithOmitDefaults()},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := AssertRoundTrip(t, test.msg, test.opts...)
+			if !v.IsKnown() || v.IsNull() {
+				t.Errorf("wrong intermediate value %#v", v)
+			}
+		})
+	}
+}
+
+func TestAssertRoundTripFailure(t *testing.T) {
+	// A handler that discards the value on the way back into protobuf
+	// makes the round-trip lossy, which AssertRoundTrip must report.
+	handlers := ctypb.NewWellKnownHandlers()
+	handlers.Register("google.protobuf.Duration", lossyHandler{})
+	msg := &testproto.WithWellKnown{
+		TDuration: durationpb.New(1500000000),
+	}
+
+	rec := &recordingTB{TB: t}
+	AssertRoundTrip(rec, msg, ctypb.WithWellKnownHandlers(handlers))
+	if len(rec.errors) != 1 {
+		t.Fatalf("wrong number of reported errors %d; want 1\n%q", len(rec.errors), rec.errors)
+	}
+}
+
+type lossyHandler struct{}
+
+func (lossyHandler) Type(desc protoreflect.MessageDescriptor) (cty.Type, bool) {
+	return cty.String, true
+}
+
+func (lossyHandler) FromProto(msg protoreflect.Message) (cty.Value, error) {
+	return cty.StringVal("lost"), nil
+}
+
+func (lossyHandler) ToProto(v cty.Value, msg protoreflect.Message) error {
+	return nil
+}
+
+// recordingTB collects the errors reported through it instead of failing
+// the test that it wraps.
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
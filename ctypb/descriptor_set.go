@@ -0,0 +1,110 @@
+package ctypb
+
+import (
+	"fmt"
+
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// LoadMessageDesc parses the given serialized google.protobuf.FileDescriptorSet
+// message, such as a file generated by protoc's --descriptor_set_out option,
+// and returns the descriptor for the message type with the given full name.
+//
+// The descriptor set must be self-contained, including all of the files that
+// the files within it import. When using protoc, use the --include_imports
+// option to produce a suitable file.
+//
+// This allows using the other functions in this package with message types
+// whose schemas are available only at runtime, without any generated Go
+// code. NewProtobufMessage can then create new messages of the returned
+// type.
+func LoadMessageDesc(fdset []byte, fullName string) (protoreflect.MessageDescriptor, error) {
+	var set descriptorpb.FileDescriptorSet
+	err := proto.Unmarshal(fdset, &set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptor set: %w", err)
+	}
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		// This also catches files whose dependencies are missing from
+		// the set.
+		return nil, fmt.Errorf("invalid descriptor set: %w", err)
+	}
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(fullName))
+	if err != nil {
+		return nil, fmt.Errorf("descriptor set has no message type named %q", fullName)
+	}
+	msgDesc, ok := desc.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("descriptor set element %q is not a message type", fullName)
+	}
+	return msgDesc, nil
+}
+
+// ToProtobufMessageByName is a variant of NewProtobufMessage which finds the
+// descriptor for the message type with the given full name in the given
+// registry, for applications such as plugin systems that learn which
+// message type to use only at runtime. If files is nil then
+// ToProtobufMessageByName uses protoregistry.GlobalFiles.
+//
+// As with NewProtobufMessage, the result is a dynamic message (from package
+// dynamicpb) even if there's a generated Go type for the message type.
+//
+// ToProtobufMessageByName pays attention to the same options as
+// ToProtobufMessage.
+func ToProtobufMessageByName(obj cty.Value, name protoreflect.FullName, files *protoregistry.Files, opts ...Option) (proto.Message, error) {
+	desc, err := findMessageDesc(name, files)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := NewProtobufMessage(obj, desc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return msg.Interface(), nil
+}
+
+// FromProtobufMessageByName decodes the given serialized message of the
+// message type with the given full name, which it finds in the given
+// registry, and then converts it in the same way as FromProtobufMessage. If
+// files is nil then FromProtobufMessageByName uses
+// protoregistry.GlobalFiles.
+//
+// This is the inverse of ToProtobufMessageByName followed by proto.Marshal,
+// and pays attention to the same options as FromProtobufMessage.
+func FromProtobufMessageByName(raw []byte, name protoreflect.FullName, files *protoregistry.Files, opts ...Option) (cty.Value, error) {
+	desc, err := findMessageDesc(name, files)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	msg := dynamicpb.NewMessage(desc)
+	err = proto.Unmarshal(raw, msg)
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("invalid %s message: %w", name, err)
+	}
+	return FromProtobufMessage(msg, opts...)
+}
+
+// findMessageDesc returns the descriptor for the message type with the given
+// full name from the given registry, or from protoregistry.GlobalFiles if
+// the registry is nil.
+func findMessageDesc(name protoreflect.FullName, files *protoregistry.Files) (protoreflect.MessageDescriptor, error) {
+	if files == nil {
+		files = protoregistry.GlobalFiles
+	}
+	desc, err := files.FindDescriptorByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("no message type named %q is registered", name)
+	}
+	msgDesc, ok := desc.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("registered element %q is not a message type", name)
+	}
+	return msgDesc, nil
+}
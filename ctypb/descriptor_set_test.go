@@ -0,0 +1,196 @@
+package ctypb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/zclconf/go-cty-protobuf/internal/testproto"
+)
+
+func TestLoadMessageDesc(t *testing.T) {
+	testFile := testproto.File_testproto_proto
+	makeSet := func(files ...protoreflect.FileDescriptor) []byte {
+		var set descriptorpb.FileDescriptorSet
+		for _, file := range files {
+			set.File = append(set.File, protodesc.ToFileDescriptorProto(file))
+		}
+		ret, err := proto.Marshal(&set)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ret
+	}
+	complete := makeSet(
+		anypb.File_google_protobuf_any_proto,
+		structpb.File_google_protobuf_struct_proto,
+		timestamppb.File_google_protobuf_timestamp_proto,
+		durationpb.File_google_protobuf_duration_proto,
+		wrapperspb.File_google_protobuf_wrappers_proto,
+		testFile,
+	)
+	incomplete := makeSet(testFile)
+
+	tests := map[string]struct {
+		FDSet    []byte
+		FullName string
+		WantErr  string
+	}{
+		"ok": {
+			FDSet:    complete,
+			FullName: "testproto.Assorted",
+		},
+		"nested message": {
+			FDSet:    complete,
+			FullName: "testproto.Assorted.Nested",
+		},
+		"unknown message": {
+			FDSet:    complete,
+			FullName: "testproto.Nonexistent",
+			WantErr:  `descriptor set has no message type named "testproto.Nonexistent"`,
+		},
+		"not a message": {
+			FDSet:    complete,
+			FullName: "testproto.WithEnum.Things",
+			WantErr:  `descriptor set element "testproto.WithEnum.Things" is not a message type`,
+		},
+		"missing dependencies": {
+			FDSet:    incomplete,
+			FullName: "testproto.Assorted",
+			WantErr:  `invalid descriptor set: `,
+		},
+		"invalid": {
+			FDSet:    []byte("not a descriptor set"),
+			FullName: "testproto.Assorted",
+			WantErr:  `invalid descriptor set: `,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := LoadMessageDesc(test.FDSet, test.FullName)
+
+			if test.WantErr != "" {
+				if err == nil {
+					t.Fatalf("succeeded; want error\nwant: %s", test.WantErr)
+				}
+				// The errors from the protobuf library are intentionally
+				// unstable, so we only check our own prefix of them.
+				if got, want := err.Error(), test.WantErr; !strings.HasPrefix(got, want) {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+
+			if got, want := got.FullName(), protoreflect.FullName(test.FullName); got != want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
+
+func TestProtobufMessageByName(t *testing.T) {
+	obj := cty.ObjectVal(map[string]cty.Value{
+		"outside": cty.StringVal("hello"),
+		"a":       cty.NullVal(cty.String),
+		"b":       cty.StringVal("b"),
+	})
+	want := &testproto.WithOneOf{
+		Outside: "hello",
+		TOneof:  &testproto.WithOneOf_B{B: "b"},
+	}
+
+	// A nil registry means the global registry, where the generated code
+	// registers the test types.
+	for name, files := range map[string]*protoregistry.Files{
+		"global":   nil,
+		"explicit": protoregistry.GlobalFiles,
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := ToProtobufMessageByName(obj, "testproto.WithOneOf", files)
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("wrong result\n%s", diff)
+			}
+
+			raw, err := proto.Marshal(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			back, err := FromProtobufMessageByName(raw, "testproto.WithOneOf", files)
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			if !obj.RawEquals(back) {
+				t.Errorf("wrong result after round-trip\ngot:  %#v\nwant: %#v", back, obj)
+			}
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		tests := map[string]struct {
+			Name    protoreflect.FullName
+			Files   *protoregistry.Files
+			WantErr string
+		}{
+			"unknown message": {
+				Name:    "testproto.Nonexistent",
+				WantErr: `no message type named "testproto.Nonexistent" is registered`,
+			},
+			"not a message": {
+				Name:    "testproto.WithEnum.Things",
+				WantErr: `registered element "testproto.WithEnum.Things" is not a message type`,
+			},
+			"empty registry": {
+				Name:    "testproto.WithOneOf",
+				Files:   &protoregistry.Files{},
+				WantErr: `no message type named "testproto.WithOneOf" is registered`,
+			},
+		}
+		for name, test := range tests {
+			t.Run(name, func(t *testing.T) {
+				_, err := ToProtobufMessageByName(obj, test.Name, test.Files)
+				if err == nil {
+					t.Fatalf("succeeded; want error\nwant: %s", test.WantErr)
+				}
+				if got, want := err.Error(), test.WantErr; got != want {
+					t.Errorf("wrong error from ToProtobufMessageByName\ngot:  %s\nwant: %s", got, want)
+				}
+				_, err = FromProtobufMessageByName(nil, test.Name, test.Files)
+				if err == nil {
+					t.Fatalf("succeeded; want error\nwant: %s", test.WantErr)
+				}
+				if got, want := err.Error(), test.WantErr; got != want {
+					t.Errorf("wrong error from FromProtobufMessageByName\ngot:  %s\nwant: %s", got, want)
+				}
+			})
+		}
+	})
+
+	t.Run("invalid message", func(t *testing.T) {
+		_, err := FromProtobufMessageByName([]byte{0xff}, "testproto.WithOneOf", nil)
+		if err == nil {
+			t.Fatalf("succeeded with invalid message; want error")
+		}
+		if got, want := err.Error(), "invalid testproto.WithOneOf message: "; !strings.HasPrefix(got, want) {
+			t.Errorf("wrong error\ngot:  %s\nwant prefix: %s", got, want)
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package ctypb
+
+import (
+	"math"
+
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// enumObjectType is the type that represents an enumeration value when the
+// WithEnumObjects option is in effect.
+var enumObjectType = cty.Object(map[string]cty.Type{
+	"name":   cty.String,
+	"number": cty.Number,
+})
+
+// enumValueName returns the string that represents the given enumeration
+// value, which is decided by the EnumNameFunc if there is one.
+func enumValueName(desc protoreflect.EnumValueDescriptor, opts *options) string {
+	if opts.enumNameFunc != nil {
+		return opts.enumNameFunc(desc)
+	}
+	return string(desc.Name())
+}
+
+// findEnumValue returns the value of the given enumeration that the given
+// string represents, or nil if there is none. This is the inverse of
+// enumValueName, using the EnumValueFunc if there is one.
+func findEnumValue(enum protoreflect.EnumDescriptor, name string, opts *options) protoreflect.EnumValueDescriptor {
+	if opts.enumValueFunc != nil {
+		return opts.enumValueFunc(enum, name)
+	}
+	return enum.Values().ByName(protoreflect.Name(name))
+}
+
+// fromEnumObject returns the object that represents the given enumeration
+// number, for use with the enum objects option.
+//
+// A number that isn't part of the enumeration has a null name, so that the
+// number still survives a round trip.
+func fromEnumObject(num protoreflect.EnumNumber, enum protoreflect.EnumDescriptor, opts *options) cty.Value {
+	name := cty.NullVal(cty.String)
+	if desc := enum.Values().ByNumber(num); desc != nil {
+		name = cty.StringVal(enumValueName(desc, opts))
+	}
+	return cty.ObjectVal(map[string]cty.Value{
+		"name":   name,
+		"number": cty.NumberIntVal(int64(num)),
+	})
+}
+
+// toEnumObject returns the enumeration number that the given object
+// represents, for use with the enum objects option.
+//
+// Either of the object's attributes may be null, but if both are set then
+// they must refer to the same value. A number that isn't part of the
+// enumeration is acceptable only if the name is null.
+//
+// toEnumObject can't deal with a null or unknown object. The caller should
+// deal with that first, before calling.
+func toEnumObject(v cty.Value, enum protoreflect.EnumDescriptor, opts *options, path cty.Path) (protoreflect.EnumNumber, error) {
+	if !v.Type().Equals(enumObjectType) {
+		return 0, path.NewErrorf("an object with attributes \"name\" and \"number\" is required")
+	}
+	nameV := v.GetAttr("name")
+	numV := v.GetAttr("number")
+	if !nameV.IsKnown() || !numV.IsKnown() {
+		return 0, path.NewErrorf("value must be known")
+	}
+
+	var byName protoreflect.EnumValueDescriptor
+	if !nameV.IsNull() {
+		byName = findEnumValue(enum, nameV.AsString(), opts)
+		if byName == nil {
+			return 0, path.GetAttr("name").NewErrorf("value isn't one of the expected keywords")
+		}
+	}
+	if numV.IsNull() {
+		if byName == nil {
+			return 0, path.NewErrorf("at least one of the attributes \"name\" and \"number\" must be set")
+		}
+		return byName.Number(), nil
+	}
+
+	bf := numV.AsBigFloat()
+	n, acc := bf.Int64()
+	if !bf.IsInt() || acc != 0 || n < math.MinInt32 || n > math.MaxInt32 {
+		return 0, path.GetAttr("number").NewErrorf("value must be a whole number between %d and %d", math.MinInt32, math.MaxInt32)
+	}
+	num := protoreflect.EnumNumber(n)
+	if byName != nil && byName.Number() != num {
+		return 0, path.NewErrorf("name %q refers to number %d, not %d", nameV.AsString(), byName.Number(), num)
+	}
+	return num, nil
+}
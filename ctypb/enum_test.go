@@ -0,0 +1,186 @@
+package ctypb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/zclconf/go-cty-protobuf/internal/testproto"
+)
+
+func TestEnumObjects(t *testing.T) {
+	lower := []Option{
+		WithEnumNameFunc(func(value protoreflect.EnumValueDescriptor) string {
+			return strings.ToLower(string(value.Name()))
+		}),
+		WithEnumValueFunc(func(enum protoreflect.EnumDescriptor, name string) protoreflect.EnumValueDescriptor {
+			values := enum.Values()
+			for i := 0; i < values.Len(); i++ {
+				if v := values.Get(i); strings.ToLower(string(v.Name())) == name {
+					return v
+				}
+			}
+			return nil
+		}),
+	}
+	enumObj := func(name cty.Value, num int64) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{
+			"name":   name,
+			"number": cty.NumberIntVal(num),
+		})
+	}
+
+	tests := map[string]struct {
+		Input   *testproto.WithEnum
+		Options []Option
+		Want    cty.Value
+	}{
+		"default": {
+			Input: &testproto.WithEnum{},
+			Want:  enumObj(cty.StringVal("A"), 0),
+		},
+		"defined": {
+			Input: &testproto.WithEnum{TEnum: testproto.WithEnum_C},
+			Want:  enumObj(cty.StringVal("C"), 2),
+		},
+		"undefined": {
+			Input: &testproto.WithEnum{TEnum: 7},
+			Want:  enumObj(cty.NullVal(cty.String), 7),
+		},
+		"name func": {
+			Input:   &testproto.WithEnum{TEnum: testproto.WithEnum_C},
+			Options: lower,
+			Want:    enumObj(cty.StringVal("c"), 2),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			opts := append([]Option{WithEnumObjects()}, test.Options...)
+			got, gotTy, err := DecodeMessage(test.Input.ProtoReflect(), opts...)
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			if got := got.GetAttr("t_enum"); !test.Want.RawEquals(got) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+			if got, want := gotTy.AttributeType("t_enum"), enumObjectType; !want.Equals(got) {
+				t.Errorf("wrong type\ngot:  %#v\nwant: %#v", got, want)
+			}
+
+			back := &testproto.WithEnum{}
+			err = ToProtobufMessage(got, back.ProtoReflect(), opts...)
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			if diff := cmp.Diff(test.Input, back, protocmp.Transform()); diff != "" {
+				t.Errorf("wrong result after round-trip\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestToProtobufMessageEnumObjects(t *testing.T) {
+	tests := map[string]struct {
+		Value    cty.Value
+		Want     testproto.WithEnum_Things
+		WantErr  string
+		WantPath cty.Path
+	}{
+		"name only": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"name":   cty.StringVal("d"),
+				"number": cty.NullVal(cty.Number),
+			}),
+			Want: testproto.WithEnum_d,
+		},
+		"number only": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"name":   cty.NullVal(cty.String),
+				"number": cty.NumberIntVal(1),
+			}),
+			Want: testproto.WithEnum_b,
+		},
+		"both consistent": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"name":   cty.StringVal("C"),
+				"number": cty.NumberIntVal(2),
+			}),
+			Want: testproto.WithEnum_C,
+		},
+		"both inconsistent": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"name":   cty.StringVal("C"),
+				"number": cty.NumberIntVal(3),
+			}),
+			WantErr:  `name "C" refers to number 2, not 3`,
+			WantPath: cty.GetAttrPath("t_enum"),
+		},
+		"neither": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"name":   cty.NullVal(cty.String),
+				"number": cty.NullVal(cty.Number),
+			}),
+			WantErr:  `at least one of the attributes "name" and "number" must be set`,
+			WantPath: cty.GetAttrPath("t_enum"),
+		},
+		"unknown name": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"name":   cty.StringVal("Z"),
+				"number": cty.NullVal(cty.Number),
+			}),
+			WantErr:  "value isn't one of the expected keywords",
+			WantPath: cty.GetAttrPath("t_enum").GetAttr("name"),
+		},
+		"fractional number": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"name":   cty.NullVal(cty.String),
+				"number": cty.NumberFloatVal(1.5),
+			}),
+			WantErr:  "value must be a whole number between -2147483648 and 2147483647",
+			WantPath: cty.GetAttrPath("t_enum").GetAttr("number"),
+		},
+		"string": {
+			Value:    cty.StringVal("C"),
+			WantErr:  `an object with attributes "name" and "number" is required`,
+			WantPath: cty.GetAttrPath("t_enum"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			obj := cty.ObjectVal(map[string]cty.Value{
+				"t_string": cty.StringVal(""),
+				"t_enum":   test.Value,
+			})
+			got := &testproto.WithEnum{}
+			err := ToProtobufMessage(obj, got.ProtoReflect(), WithEnumObjects())
+			if test.WantErr != "" {
+				if err == nil {
+					t.Fatalf("succeeded; want error\nwant: %s", test.WantErr)
+				}
+				if got, want := err.Error(), test.WantErr; got != want {
+					t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+				}
+				pathErr, ok := err.(cty.PathError)
+				if !ok {
+					t.Fatalf("error is %T, not cty.PathError: %s", err, err.Error())
+				}
+				if !pathErr.Path.Equals(test.WantPath) {
+					t.Errorf("wrong error path\ngot:  %#v\nwant: %#v", pathErr.Path, test.WantPath)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			if got, want := got.TEnum, test.Want; got != want {
+				t.Errorf("wrong result %s; want %s", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package ctypb
+
+import (
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// FieldConverter is the interface implemented by special-case conversions
+// for particular fields, which replace the usual conversion for the field's
+// kind regardless of the options in effect.
+//
+// A converter is associated with a specific field using the
+// WithFieldConverters option. It applies to each value of the field: to
+// the field's value if it's singular, or to each of its elements if it's
+// repeated. The values of a map field are instead the values of the "value"
+// field of the map's entry message, so a converter for them must be
+// registered under that field's full name, such as
+// "example.Message.LabelsEntry.value". Converters can be used only with
+// fields of scalar and enum kinds, because message types can be customized
+// using WellKnownHandler instead.
+type FieldConverter interface {
+	// Type returns the cty type that represents the values of the given
+	// field.
+	Type(field protoreflect.FieldDescriptor) cty.Type
+
+	// FromProto returns a value representing the given value of the given
+	// field, which must conform to the type returned by Type.
+	FromProto(v protoreflect.Value, field protoreflect.FieldDescriptor) (cty.Value, error)
+
+	// ToProto returns the value of the given field that the given value
+	// represents. The value is never null or unknown, but may be of any
+	// type that conforms to the type returned by Type.
+	ToProto(v cty.Value, field protoreflect.FieldDescriptor) (protoreflect.Value, error)
+}
+
+// fieldConverterFor returns the converter to use for the values of the given
+// field, or nil if they should be converted in the usual way.
+func fieldConverterFor(field protoreflect.FieldDescriptor, opts *options, path cty.Path) (FieldConverter, error) {
+	conv := opts.fieldConverters[field.FullName()]
+	if conv == nil {
+		return nil, nil
+	}
+	if field.Message() != nil {
+		return nil, path.NewErrorf("field %s is a message field, so it can't have a FieldConverter", field.FullName())
+	}
+	return conv, nil
+}
+
+// fromFieldConverter converts the given value of the given field using the
+// given converter, verifying that the result has the type the converter
+// declared.
+func fromFieldConverter(conv FieldConverter, rawV protoreflect.Value, field protoreflect.FieldDescriptor, path cty.Path) (cty.Value, error) {
+	v, err := conv.FromProto(rawV, field)
+	if err != nil {
+		return cty.NilVal, path.NewError(err)
+	}
+	if ty := conv.Type(field); !v.Type().Equals(ty) {
+		return cty.NilVal, path.NewErrorf("converter for %s returned %s, but should return %s", field.FullName(), v.Type().FriendlyName(), ty.FriendlyName())
+	}
+	return v, nil
+}
+
+// toFieldConverter returns the value of the given field that the given value
+// represents, using the given converter.
+//
+// toFieldConverter can't deal with null or unknown values. The caller should
+// deal with that first, before calling.
+func toFieldConverter(conv FieldConverter, v cty.Value, field protoreflect.FieldDescriptor, path cty.Path) (protoreflect.Value, error) {
+	ret, err := conv.ToProto(v, field)
+	if err != nil {
+		return protoreflect.Value{}, path.NewError(err)
+	}
+	return ret, nil
+}
@@ -0,0 +1,226 @@
+package ctypb
+
+import (
+	"fmt"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty-debug/ctydebug"
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/zclconf/go-cty-protobuf/internal/testproto"
+)
+
+func TestFieldConverters(t *testing.T) {
+	opts := []Option{
+		WithFieldConverters(map[protoreflect.FullName]FieldConverter{
+			"testproto.Assorted.t_bytes":                       utf8FieldConverter{},
+			"testproto.WithRepeated.t_strings":                 utf8FieldConverter{},
+			"testproto.WithRepeated.TMapStringBoolEntry.value": yesNoFieldConverter{},
+		}),
+		// The converters take priority over the options for the kind.
+		WithBytesAsNumberLists(),
+	}
+
+	tests := map[string]struct {
+		Msg      proto.Message
+		Attr     string
+		Want     cty.Value
+		WantType cty.Type
+	}{
+		"singular": {
+			&testproto.Assorted{
+				TBytes:  []byte("hello"),
+				TString: "world",
+			},
+			"t_bytes",
+			cty.StringVal("hello"),
+			cty.String,
+		},
+		"repeated": {
+			&testproto.WithRepeated{
+				TStrings: []string{"a", "b"},
+			},
+			"t_strings",
+			cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+			cty.List(cty.String),
+		},
+		"map values": {
+			&testproto.WithRepeated{
+				TMapStringBool: map[string]bool{"x": true, "y": false},
+			},
+			"t_map_string_bool",
+			cty.MapVal(map[string]cty.Value{
+				"x": cty.StringVal("yes"),
+				"y": cty.StringVal("no"),
+			}),
+			cty.Map(cty.String),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			desc := test.Msg.ProtoReflect().Descriptor()
+			ty, err := ImpliedTypeForMessageDesc(desc, opts...)
+			if err != nil {
+				t.Fatalf("unexpected error from ImpliedTypeForMessageDesc\ngot: %s", err.Error())
+			}
+			if got := ty.AttributeType(test.Attr); !test.WantType.Equals(got) {
+				t.Errorf("wrong implied type\ngot:  %s\nwant: %s", ctydebug.TypeString(got), ctydebug.TypeString(test.WantType))
+			}
+
+			got, err := FromProtobufMessage(test.Msg.ProtoReflect(), opts...)
+			if err != nil {
+				t.Fatalf("unexpected error from FromProtobufMessage\ngot: %s", err.Error())
+			}
+			if got := got.GetAttr(test.Attr); !test.Want.RawEquals(got) {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", ctydebug.ValueString(got), ctydebug.ValueString(test.Want))
+			}
+
+			into := test.Msg.ProtoReflect().New()
+			err = ToProtobufMessage(got, into, opts...)
+			if err != nil {
+				t.Fatalf("unexpected error from ToProtobufMessage\ngot: %s", err.Error())
+			}
+			if diff := cmp.Diff(test.Msg, into.Interface(), protocmp.Transform()); diff != "" {
+				t.Errorf("wrong ToProtobufMessage result\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFieldConvertersErrors(t *testing.T) {
+	checkErr := func(t *testing.T, err error, wantErr string, wantPath cty.Path) {
+		t.Helper()
+		if err == nil {
+			t.Fatalf("succeeded; want error\nwant: %s", wantErr)
+		}
+		if got := err.Error(); got != wantErr {
+			t.Errorf("wrong error\ngot:  %s\nwant: %s", got, wantErr)
+		}
+		pathErr, ok := err.(cty.PathError)
+		if !ok {
+			t.Fatalf("error is %T, not cty.PathError: %s", err, err.Error())
+		}
+		if !pathErr.Path.Equals(wantPath) {
+			t.Errorf("wrong error path\ngot:  %#v\nwant: %#v", pathErr.Path, wantPath)
+		}
+	}
+
+	t.Run("message field", func(t *testing.T) {
+		opts := WithFieldConverters(map[protoreflect.FullName]FieldConverter{
+			"testproto.Assorted.t_message": utf8FieldConverter{},
+		})
+		_, err := ImpliedTypeForMessageDesc((&testproto.Assorted{}).ProtoReflect().Descriptor(), opts)
+		checkErr(t, err, "field testproto.Assorted.t_message is a message field, so it can't have a FieldConverter", cty.GetAttrPath("t_message"))
+	})
+	t.Run("from error", func(t *testing.T) {
+		opts := WithFieldConverters(map[protoreflect.FullName]FieldConverter{
+			"testproto.WithRepeated.t_strings": utf8FieldConverter{},
+		})
+		_, err := FromProtobufMessage((&testproto.WithRepeated{
+			TStrings: []string{"ok", "\xff"},
+		}).ProtoReflect(), opts)
+		checkErr(t, err, "value is not valid UTF-8 text", cty.GetAttrPath("t_strings").Index(cty.NumberIntVal(1)))
+	})
+	t.Run("from wrong type", func(t *testing.T) {
+		opts := WithFieldConverters(map[protoreflect.FullName]FieldConverter{
+			"testproto.Assorted.t_string": wrongTypeFieldConverter{},
+		})
+		_, err := FromProtobufMessage((&testproto.Assorted{TString: "hi"}).ProtoReflect(), opts)
+		checkErr(t, err, "converter for testproto.Assorted.t_string returned number, but should return string", cty.GetAttrPath("t_string"))
+	})
+	t.Run("to error", func(t *testing.T) {
+		opts := WithFieldConverters(map[protoreflect.FullName]FieldConverter{
+			"testproto.WithRepeated.TMapStringBoolEntry.value": yesNoFieldConverter{},
+		})
+		obj, err := FromProtobufMessage((&testproto.WithRepeated{}).ProtoReflect(), opts)
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		attrs := obj.AsValueMap()
+		attrs["t_map_string_bool"] = cty.MapVal(map[string]cty.Value{
+			"x": cty.StringVal("maybe"),
+		})
+		err = ToProtobufMessage(cty.ObjectVal(attrs), (&testproto.WithRepeated{}).ProtoReflect(), opts)
+		checkErr(t, err, `must be "yes" or "no"`, cty.GetAttrPath("t_map_string_bool").Index(cty.StringVal("x")))
+	})
+}
+
+// utf8FieldConverter is a FieldConverter for string and bytes fields which
+// represents their values as strings, requiring them to be valid UTF-8.
+type utf8FieldConverter struct{}
+
+func (utf8FieldConverter) Type(field protoreflect.FieldDescriptor) cty.Type {
+	return cty.String
+}
+
+func (utf8FieldConverter) FromProto(v protoreflect.Value, field protoreflect.FieldDescriptor) (cty.Value, error) {
+	var s string
+	if field.Kind() == protoreflect.BytesKind {
+		s = string(v.Bytes())
+	} else {
+		s = v.String()
+	}
+	if !utf8.ValidString(s) {
+		return cty.NilVal, fmt.Errorf("value is not valid UTF-8 text")
+	}
+	return cty.StringVal(s), nil
+}
+
+func (utf8FieldConverter) ToProto(v cty.Value, field protoreflect.FieldDescriptor) (protoreflect.Value, error) {
+	if !cty.String.Equals(v.Type()) {
+		return protoreflect.Value{}, fmt.Errorf("a string is required")
+	}
+	if field.Kind() == protoreflect.BytesKind {
+		return protoreflect.ValueOfBytes([]byte(v.AsString())), nil
+	}
+	return protoreflect.ValueOfString(v.AsString()), nil
+}
+
+// yesNoFieldConverter is a FieldConverter for bool fields which represents
+// their values as the strings "yes" and "no".
+type yesNoFieldConverter struct{}
+
+func (yesNoFieldConverter) Type(field protoreflect.FieldDescriptor) cty.Type {
+	return cty.String
+}
+
+func (yesNoFieldConverter) FromProto(v protoreflect.Value, field protoreflect.FieldDescriptor) (cty.Value, error) {
+	if v.Bool() {
+		return cty.StringVal("yes"), nil
+	}
+	return cty.StringVal("no"), nil
+}
+
+func (yesNoFieldConverter) ToProto(v cty.Value, field protoreflect.FieldDescriptor) (protoreflect.Value, error) {
+	if cty.String.Equals(v.Type()) {
+		switch v.AsString() {
+		case "yes":
+			return protoreflect.ValueOfBool(true), nil
+		case "no":
+			return protoreflect.ValueOfBool(false), nil
+		}
+	}
+	return protoreflect.Value{}, fmt.Errorf("must be \"yes\" or \"no\"")
+}
+
+// wrongTypeFieldConverter is a FieldConverter which returns values that
+// don't conform to the type it declares.
+type wrongTypeFieldConverter struct{}
+
+func (wrongTypeFieldConverter) Type(field protoreflect.FieldDescriptor) cty.Type {
+	return cty.String
+}
+
+func (wrongTypeFieldConverter) FromProto(v protoreflect.Value, field protoreflect.FieldDescriptor) (cty.Value, error) {
+	return cty.Zero, nil
+}
+
+func (wrongTypeFieldConverter) ToProto(v cty.Value, field protoreflect.FieldDescriptor) (protoreflect.Value, error) {
+	return protoreflect.ValueOfString(""), nil
+}
@@ -0,0 +1,231 @@
+package ctypb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// FromProtobufMessageMasked is a variant of FromProtobufMessage which
+// converts only the fields named in the given field mask, for partial
+// updates where the mask says which parts of the message are relevant.
+//
+// Each path in the mask is a sequence of field names separated by periods,
+// as in the google.protobuf.FieldMask documentation, where all but the last
+// must name singular message fields. The result is an object with only the
+// attributes that lead to the fields named in the mask, and so it doesn't
+// conform to the type that ImpliedTypeForMessageDesc returns. Callers
+// should treat the result's type as cty.DynamicPseudoType. An empty mask
+// produces an empty object.
+//
+// A field named by the mask is converted in its entirety, even if the mask
+// also names some of its nested fields. An absent message on the way to a
+// field named by the mask is represented as null, rather than as an object.
+//
+// FromProtobufMessageMasked pays attention to the same options as
+// FromProtobufMessage, except for WithPreserveUnknownFields and
+// WithOneofDiscriminator, whose attributes a field mask can't name.
+// ToProtobufMessageMasked is the inverse of this function.
+func FromProtobufMessageMasked(msg protoreflect.Message, mask *fieldmaskpb.FieldMask, opts ...Option) (cty.Value, error) {
+	o := makeOptions(opts)
+	desc := msg.Descriptor()
+	if handler, _ := wellKnownHandlerFor(desc, o); handler != nil {
+		return cty.NilVal, fmt.Errorf("message type %s is not represented as an object", desc.FullName())
+	}
+	tree := &fieldMaskTree{}
+	for _, maskPath := range mask.GetPaths() {
+		tree.add(maskPath, strings.Split(maskPath, "."))
+	}
+	return fromProtobufMessageMasked(msg, tree, o, make(cty.Path, 0, 4))
+}
+
+// fieldMaskTree is a field mask arranged as a tree of field names, so that
+// FromProtobufMessageMasked can visit each message only once.
+type fieldMaskTree struct {
+	// maskPath is the first of the field mask paths that led to this node,
+	// for use in error messages.
+	maskPath string
+
+	// whole is true if the mask names this node's field itself, in which
+	// case children is irrelevant.
+	whole bool
+
+	// names are the field names of the children in the order that the
+	// mask first mentions them, so that errors are deterministic.
+	names    []string
+	children map[string]*fieldMaskTree
+}
+
+func (t *fieldMaskTree) add(maskPath string, names []string) {
+	if len(names) == 0 {
+		t.whole = true
+		return
+	}
+	child, ok := t.children[names[0]]
+	if !ok {
+		if t.children == nil {
+			t.children = make(map[string]*fieldMaskTree)
+		}
+		child = &fieldMaskTree{maskPath: maskPath}
+		t.children[names[0]] = child
+		t.names = append(t.names, names[0])
+	}
+	child.add(maskPath, names[1:])
+}
+
+func fromProtobufMessageMasked(msg protoreflect.Message, tree *fieldMaskTree, opts *options, path cty.Path) (cty.Value, error) {
+	if err := opts.contextErr(); err != nil {
+		return cty.NilVal, err
+	}
+	desc := msg.Descriptor()
+	attrs := make(map[string]cty.Value, len(tree.names))
+	for _, fieldName := range tree.names {
+		child := tree.children[fieldName]
+		field := desc.Fields().ByName(protoreflect.Name(fieldName))
+		if field == nil {
+			return cty.NilVal, path.NewErrorf("field mask path %q refers to %q, which is not a field of %s", child.maskPath, fieldName, desc.FullName())
+		}
+		name := opts.fieldAttrName(field)
+
+		// Temporarily extend path with new attribute name
+		path := append(path, cty.GetAttrStep{Name: name})
+
+		if child.whole {
+			v, err := fromProtobufMessageField(msg, field, opts, path)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			attrs[name] = v
+			continue
+		}
+
+		if field.Message() == nil || field.Cardinality() == protoreflect.Repeated {
+			return cty.NilVal, path.NewErrorf("field mask path %q traverses %s, which is not a singular message field", child.maskPath, field.FullName())
+		}
+		if handler, _ := wellKnownHandlerFor(field.Message(), opts); handler != nil || (opts.wellKnownStruct && isWellKnownStruct(field.Message())) {
+			return cty.NilVal, path.NewErrorf("field mask path %q traverses %s, which is not represented as an object", child.maskPath, field.FullName())
+		}
+		if !msg.Has(field) {
+			attrs[name] = cty.NullVal(cty.DynamicPseudoType)
+			continue
+		}
+		nested, tooDeep := opts.nestedMessage()
+		if tooDeep {
+			return cty.NilVal, path.NewErrorf("message is nested more than %d levels deep", opts.maxDepth)
+		}
+		v, err := fromProtobufMessageMasked(msg.Get(field).Message(), child, nested, reservePath(path))
+		if err != nil {
+			return cty.NilVal, err
+		}
+		attrs[name] = v
+	}
+	return cty.ObjectVal(attrs), nil
+}
+
+// ToProtobufMessageMasked is a variant of ToProtobufMessage which writes
+// only the fields named in the given field mask into the given message,
+// leaving all of its other fields unchanged. This is intended for partial
+// updates, where the given object represents a change to an existing
+// message rather than a replacement for it.
+//
+// Each path in the mask is a sequence of field names separated by periods,
+// as in the google.protobuf.FieldMask documentation, where all but the last
+// must name singular message fields. The given object needs only the
+// attributes that lead to the fields named in the mask, and any other
+// attributes are ignored.
+//
+// As is conventional for field masks, a repeated or map field named by the
+// mask is replaced in its entirety, and a null value for a field named by
+// the mask clears that field. A null value for a message attribute on the
+// way to a field named by the mask also clears that field, if the message
+// is present in the given message, and otherwise leaves it absent.
+//
+// ToProtobufMessageMasked pays attention to the same options as
+// ToProtobufMessage, except for WithPreserveUnknownFields, because a field
+// mask can't name the unknown fields. FromProtobufMessageMasked is the
+// inverse of this function.
+func ToProtobufMessageMasked(obj cty.Value, into protoreflect.Message, mask *fieldmaskpb.FieldMask, opts ...Option) error {
+	o := makeOptions(opts)
+	path := make(cty.Path, 0, 4)
+	if obj.IsNull() {
+		return path.NewErrorf("must not be null")
+	}
+	if !obj.IsKnown() {
+		return path.NewErrorf("value must be known")
+	}
+	for _, maskPath := range mask.GetPaths() {
+		names := strings.Split(maskPath, ".")
+		err := toProtobufMessageMaskPath(obj, into, maskPath, names, o, path)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// toProtobufMessageMaskPath writes the field at the end of the given field
+// names, which are the remainder of the given field mask path, from the
+// given object into the given message.
+func toProtobufMessageMaskPath(obj cty.Value, into protoreflect.Message, maskPath string, names []string, opts *options, path cty.Path) error {
+	desc := into.Descriptor()
+	field := desc.Fields().ByName(protoreflect.Name(names[0]))
+	if field == nil {
+		return path.NewErrorf("field mask path %q refers to %q, which is not a field of %s", maskPath, names[0], desc.FullName())
+	}
+	name := opts.fieldAttrName(field)
+	if !obj.IsNull() && obj.Type().IsObjectType() {
+		var err error
+		name, err = fieldAttrNameIn(obj.Type(), field, name, opts, path)
+		if err != nil {
+			return err
+		}
+	}
+
+	var v cty.Value
+	switch {
+	case obj.IsNull():
+		v = cty.NullVal(cty.DynamicPseudoType)
+	case !obj.Type().IsObjectType():
+		return path.NewErrorf("an object is required")
+	case !obj.Type().HasAttribute(name):
+		return path.NewErrorf("missing required attribute %q", name)
+	default:
+		v = obj.GetAttr(name)
+	}
+
+	// Temporarily extend path with new attribute name
+	path = append(path, cty.GetAttrStep{Name: name})
+
+	if len(names) == 1 {
+		if obj.IsNull() {
+			// The null message on the way here represents an absent
+			// message, which has the default value for every field.
+			into.Clear(field)
+			return nil
+		}
+		return toProtobufMessageField(into, field, v, opts, path)
+	}
+
+	if field.Message() == nil || field.Cardinality() == protoreflect.Repeated {
+		return path.NewErrorf("field mask path %q traverses %s, which is not a singular message field", maskPath, field.FullName())
+	}
+	if handler, _ := wellKnownHandlerFor(field.Message(), opts); handler != nil || (opts.wellKnownStruct && isWellKnownStruct(field.Message())) {
+		return path.NewErrorf("field mask path %q traverses %s, which is not represented as an object", maskPath, field.FullName())
+	}
+	if v.IsNull() && !into.Has(field) {
+		// There's nothing to clear, and we mustn't create the nested
+		// message just to leave it empty.
+		return nil
+	}
+	if !v.IsKnown() {
+		return path.NewErrorf("value must be known")
+	}
+	nested, tooDeep := opts.nestedMessage()
+	if tooDeep {
+		return path.NewErrorf("message is nested more than %d levels deep", opts.maxDepth)
+	}
+	return toProtobufMessageMaskPath(v, into.Mutable(field).Message(), maskPath, names[1:], nested, path)
+}
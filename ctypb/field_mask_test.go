@@ -0,0 +1,330 @@
+package ctypb
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty-debug/ctydebug"
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	"github.com/zclconf/go-cty-protobuf/internal/testproto"
+)
+
+func TestToProtobufMessageMasked(t *testing.T) {
+	base := &testproto.Assorted{
+		TString: "before",
+		TInt32:  1,
+		TBool:   true,
+		TMessage: &testproto.Assorted_Nested{
+			TNestedField: "nested before",
+		},
+	}
+	tests := map[string]struct {
+		Base     proto.Message
+		Value    cty.Value
+		Paths    []string
+		Want     proto.Message
+		WantErr  string
+		WantPath cty.Path
+	}{
+		"scalar fields": {
+			Base: base,
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_string": cty.StringVal("after"),
+				"t_int32":  cty.NumberIntVal(2),
+				"t_bool":   cty.False,
+			}),
+			Paths: []string{"t_string", "t_int32"},
+			Want: &testproto.Assorted{
+				TString: "after",
+				TInt32:  2,
+				TBool:   true,
+				TMessage: &testproto.Assorted_Nested{
+					TNestedField: "nested before",
+				},
+			},
+		},
+		"nested field": {
+			Base: base,
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_message": cty.ObjectVal(map[string]cty.Value{
+					"t_nested_field": cty.StringVal("nested after"),
+				}),
+			}),
+			Paths: []string{"t_message.t_nested_field"},
+			Want: &testproto.Assorted{
+				TString: "before",
+				TInt32:  1,
+				TBool:   true,
+				TMessage: &testproto.Assorted_Nested{
+					TNestedField: "nested after",
+				},
+			},
+		},
+		"nested field in absent message": {
+			Base: &testproto.Assorted{},
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_message": cty.ObjectVal(map[string]cty.Value{
+					"t_nested_field": cty.StringVal("new"),
+				}),
+			}),
+			Paths: []string{"t_message.t_nested_field"},
+			Want: &testproto.Assorted{
+				TMessage: &testproto.Assorted_Nested{
+					TNestedField: "new",
+				},
+			},
+		},
+		"nested field under null": {
+			Base: base,
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_message": cty.NullVal(cty.DynamicPseudoType),
+			}),
+			Paths: []string{"t_message.t_nested_field"},
+			Want: &testproto.Assorted{
+				TString:  "before",
+				TInt32:   1,
+				TBool:    true,
+				TMessage: &testproto.Assorted_Nested{},
+			},
+		},
+		"nested field under null in absent message": {
+			Base: &testproto.Assorted{},
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_message": cty.NullVal(cty.DynamicPseudoType),
+			}),
+			Paths: []string{"t_message.t_nested_field"},
+			Want:  &testproto.Assorted{},
+		},
+		"clear message": {
+			Base: base,
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_message": cty.NullVal(cty.DynamicPseudoType),
+			}),
+			Paths: []string{"t_message"},
+			Want: &testproto.Assorted{
+				TString: "before",
+				TInt32:  1,
+				TBool:   true,
+			},
+		},
+		"replace repeated": {
+			Base: &testproto.WithRepeated{
+				TStrings: []string{"a", "b"},
+			},
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_strings": cty.ListVal([]cty.Value{cty.StringVal("c")}),
+			}),
+			Paths: []string{"t_strings"},
+			Want: &testproto.WithRepeated{
+				TStrings: []string{"c"},
+			},
+		},
+		"empty mask": {
+			Base:  base,
+			Value: cty.EmptyObjectVal,
+			Want:  base,
+		},
+		"missing attribute": {
+			Base:     base,
+			Value:    cty.EmptyObjectVal,
+			Paths:    []string{"t_string"},
+			WantErr:  `missing required attribute "t_string"`,
+			WantPath: cty.Path{},
+		},
+		"no such field": {
+			Base: base,
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_message": cty.EmptyObjectVal,
+			}),
+			Paths:    []string{"t_message.nope"},
+			WantErr:  `field mask path "t_message.nope" refers to "nope", which is not a field of testproto.Assorted.Nested`,
+			WantPath: cty.GetAttrPath("t_message"),
+		},
+		"traverses scalar": {
+			Base: base,
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_string": cty.StringVal("after"),
+			}),
+			Paths:    []string{"t_string.length"},
+			WantErr:  `field mask path "t_string.length" traverses testproto.Assorted.t_string, which is not a singular message field`,
+			WantPath: cty.GetAttrPath("t_string"),
+		},
+		"invalid value": {
+			Base: base,
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_message": cty.ObjectVal(map[string]cty.Value{
+					"t_nested_field": cty.EmptyObjectVal,
+				}),
+			}),
+			Paths:    []string{"t_message.t_nested_field"},
+			WantErr:  "a string is required",
+			WantPath: cty.GetAttrPath("t_message").GetAttr("t_nested_field"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := proto.Clone(test.Base)
+			mask := &fieldmaskpb.FieldMask{Paths: test.Paths}
+			err := ToProtobufMessageMasked(test.Value, got.ProtoReflect(), mask)
+
+			if test.WantErr != "" {
+				if err == nil {
+					t.Fatalf("succeeded; want error\nwant: %s", test.WantErr)
+				}
+				if got, want := err.Error(), test.WantErr; got != want {
+					t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+				}
+				pathErr, ok := err.(cty.PathError)
+				if !ok {
+					t.Fatalf("error is %T, not cty.PathError: %s", err, err.Error())
+				}
+				if !pathErr.Path.Equals(test.WantPath) {
+					t.Errorf("wrong error path\ngot:  %#v\nwant: %#v", pathErr.Path, test.WantPath)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+
+			if diff := cmp.Diff(test.Want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("wrong result\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFromProtobufMessageMasked(t *testing.T) {
+	full := &testproto.Assorted{
+		TString: "hello",
+		TInt32:  5,
+		TBool:   true,
+		TMessage: &testproto.Assorted_Nested{
+			TNestedField: "nested",
+		},
+	}
+	tests := map[string]struct {
+		Input    *testproto.Assorted
+		Paths    []string
+		Want     cty.Value
+		WantErr  string
+		WantPath cty.Path
+	}{
+		"scalar fields": {
+			Input: full,
+			Paths: []string{"t_string", "t_int32"},
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"t_string": cty.StringVal("hello"),
+				"t_int32":  cty.NumberIntVal(5),
+			}),
+		},
+		"nested field": {
+			Input: full,
+			Paths: []string{"t_bool", "t_message.t_nested_field"},
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"t_bool": cty.True,
+				"t_message": cty.ObjectVal(map[string]cty.Value{
+					"t_nested_field": cty.StringVal("nested"),
+				}),
+			}),
+		},
+		"whole message overrides nested field": {
+			Input: full,
+			Paths: []string{"t_message.t_nested_field", "t_message"},
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"t_message": cty.ObjectVal(map[string]cty.Value{
+					"t_nested_field": cty.StringVal("nested"),
+				}),
+			}),
+		},
+		"absent whole message": {
+			Input: &testproto.Assorted{},
+			Paths: []string{"t_message"},
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"t_message": cty.NullVal(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+			}),
+		},
+		"nested field in absent message": {
+			Input: &testproto.Assorted{},
+			Paths: []string{"t_message.t_nested_field"},
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"t_message": cty.NullVal(cty.DynamicPseudoType),
+			}),
+		},
+		"empty mask": {
+			Input: full,
+			Want:  cty.EmptyObjectVal,
+		},
+		"no such field": {
+			Input:    full,
+			Paths:    []string{"t_message.nope"},
+			WantErr:  `field mask path "t_message.nope" refers to "nope", which is not a field of testproto.Assorted.Nested`,
+			WantPath: cty.GetAttrPath("t_message"),
+		},
+		"traverses scalar": {
+			Input:    full,
+			Paths:    []string{"t_string.length"},
+			WantErr:  `field mask path "t_string.length" traverses testproto.Assorted.t_string, which is not a singular message field`,
+			WantPath: cty.GetAttrPath("t_string"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mask := &fieldmaskpb.FieldMask{Paths: test.Paths}
+			got, err := FromProtobufMessageMasked(test.Input.ProtoReflect(), mask)
+
+			if test.WantErr != "" {
+				if err == nil {
+					t.Fatalf("succeeded; want error\nwant: %s", test.WantErr)
+				}
+				if got, want := err.Error(), test.WantErr; got != want {
+					t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+				}
+				pathErr, ok := err.(cty.PathError)
+				if !ok {
+					t.Fatalf("error is %T, not cty.PathError: %s", err, err.Error())
+				}
+				if !pathErr.Path.Equals(test.WantPath) {
+					t.Errorf("wrong error path\ngot:  %#v\nwant: %#v", pathErr.Path, test.WantPath)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+
+			if !test.Want.RawEquals(got) {
+				t.Errorf(
+					"wrong result\ngot: %s\nwant: %s",
+					ctydebug.ValueString(got),
+					ctydebug.ValueString(test.Want),
+				)
+			}
+
+			// Writing the result back with the same mask must reproduce
+			// the masked fields of the input.
+			back := &testproto.Assorted{}
+			err = ToProtobufMessageMasked(got, back.ProtoReflect(), mask)
+			if err != nil {
+				t.Fatalf("unexpected error from ToProtobufMessageMasked\ngot: %s", err.Error())
+			}
+			again, err := FromProtobufMessageMasked(back.ProtoReflect(), mask)
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			if !got.RawEquals(again) {
+				t.Errorf(
+					"wrong result after round-trip\ngot: %s\nwant: %s",
+					ctydebug.ValueString(again),
+					ctydebug.ValueString(got),
+				)
+			}
+		})
+	}
+}
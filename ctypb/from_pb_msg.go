@@ -1,14 +1,20 @@
 package ctypb
 
 import (
-	"encoding/base64"
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"unicode/utf8"
 
 	"github.com/zclconf/go-cty/cty"
 	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
 // FromProtobufMessage converts the given message to an equivalent cty.Value,
-// which will always be of an object type.
+// which will be of an object type unless the WithWellKnownHandlers option
+// includes a handler for the message's own type, in which case the result
+// is whatever that handler returns.
 //
 // Specifically, the result is guaranteed to conform to the type that
 // ImpliedTypeForMessageDesc would've returned if given the message descriptor
@@ -17,47 +23,377 @@ import (
 // Note that FromProtobufMessage takes a protoreflect.Message rather than
 // a proto.Message value directly. You can obtain a protoreflect.Message
 // value from a proto.Message value by calling its ProtoReflect method.
-func FromProtobufMessage(msg protoreflect.Message) (cty.Value, error) {
-	path := make(cty.Path, 0, 4) // some capacity to avoid further allocs for shallow structures
-	return fromProtobufMessage(msg, path)
+//
+// FromProtobufMessage only reads the given message, so it's safe to convert
+// the same message from several goroutines at once, but it's not safe to
+// modify the message concurrently with the conversion, just as with any
+// other use of a message. The result doesn't share any memory with the
+// message once FromProtobufMessage returns, and so is unaffected by later
+// modifications.
+//
+// FromProtobufMessage pays attention to the following options:
+//   - WithOmitDefaults
+//   - WithEmitUnpopulated
+//   - WithBytesCapsule
+//   - WithWellKnownStruct
+//   - WithExtensions
+//   - WithEnumNameFunc
+//   - WithEnumObjects
+//   - WithPreserveUnknownFields
+//   - WithBytesAsUTF8Strings
+//   - WithBytesAsNumberLists
+//   - WithInt64AsStrings
+//   - WithWellKnownHandlers
+//   - WithMessageCapsule
+//   - WithFieldNameFunc
+//   - WithFieldConverters
+//   - WithMaxDepth
+//   - WithDefaultMessages
+//   - WithBase64Encoding
+//   - WithOneofDiscriminator
+//   - WithTraceFunc
+//   - WithMaxStringLen
+//   - WithMaxBytesLen
+func FromProtobufMessage(msg protoreflect.Message, opts ...Option) (cty.Value, error) {
+	return Converter{opts: makeOptions(opts)}.FromMessage(msg)
+}
+
+// FromProtobufMessageContext is a variant of FromProtobufMessage which
+// checks whether the given context has been cancelled before converting
+// each message, and if so returns the context's error immediately.
+//
+// This is intended for converting large messages where the caller might
+// need to abandon the conversion, such as when handling a request that
+// has a deadline.
+func FromProtobufMessageContext(ctx context.Context, msg protoreflect.Message, opts ...Option) (cty.Value, error) {
+	return fromProtobufMessageRoot(msg, makeOptionsContext(ctx, opts))
+}
+
+// FromProtobufMessages converts each of the given messages to a cty value,
+// as with FromProtobufMessage, and returns a list of the results.
+//
+// All of the messages must be of the type described by the given message
+// descriptor, and each element of the result conforms to the type that
+// ImpliedTypeForMessageDesc would return for that descriptor. The
+// descriptor is needed to produce an empty list of the appropriate type
+// when there are no messages.
+//
+// If that type includes cty.DynamicPseudoType, such as for fields converted
+// by WithWellKnownStruct, the elements might not all have the same type, and
+// in that case the result is a tuple rather than a list.
+//
+// FromProtobufMessages pays attention to the same options as
+// FromProtobufMessage.
+func FromProtobufMessages(desc protoreflect.MessageDescriptor, msgs []protoreflect.Message, opts ...Option) (cty.Value, error) {
+	c := Converter{opts: makeOptions(opts)}
+	ety, err := c.ImpliedType(desc)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	if len(msgs) == 0 {
+		return cty.ListValEmpty(ety), nil
+	}
+
+	elems := make([]cty.Value, len(msgs))
+	same := true
+	for i, msg := range msgs {
+		path := cty.IndexIntPath(i)
+		if got := msg.Descriptor().FullName(); got != desc.FullName() {
+			return cty.NilVal, path.NewErrorf("message is of type %s, but all messages must be of type %s", got, desc.FullName())
+		}
+		v, err := c.FromMessage(msg)
+		if err != nil {
+			return cty.NilVal, path.NewError(err)
+		}
+		if errs := v.Type().TestConformance(ety); len(errs) != 0 {
+			// This can happen if the message has the same name as the
+			// descriptor but a different schema, such as when one of them
+			// was loaded from an older descriptor set.
+			return cty.NilVal, path.NewErrorf("message of type %s does not match the given descriptor", desc.FullName())
+		}
+		if i != 0 && !v.Type().Equals(elems[0].Type()) {
+			same = false
+		}
+		elems[i] = v
+	}
+	if !same {
+		// A list can't have elements of different types.
+		return cty.TupleVal(elems), nil
+	}
+	return cty.ListVal(elems), nil
+}
+
+// DecodeMessage converts the given message to a cty value as with
+// FromProtobufMessage, and also returns the type that
+// ImpliedTypeForMessageDesc returns for the message's descriptor with the
+// same options, for callers that need both.
+//
+// The value always conforms to the returned type. The value's own type is
+// exactly the returned type unless the type includes
+// cty.DynamicPseudoType, such as for fields beyond the limit set by
+// WithMaxDepth or for fields converted by WithWellKnownStruct, where the
+// value has whatever concrete type its content implies.
+//
+// The type comes from the same cache that ImpliedTypeForMessageDesc uses,
+// so after the first call for a particular message type and set of options
+// DecodeMessage walks only the message, and not its descriptor. Options
+// that disable that cache, such as WithFieldNameFunc, make DecodeMessage
+// walk the descriptor on every call, and so callers using them should
+// prefer a Converter, which has its own cache.
+//
+// DecodeMessage pays attention to the same options as FromProtobufMessage.
+func DecodeMessage(msg protoreflect.Message, opts ...Option) (cty.Value, cty.Type, error) {
+	c := Converter{opts: makeOptions(opts)}
+	ty, err := c.ImpliedType(msg.Descriptor())
+	if err != nil {
+		return cty.NilVal, cty.NilType, err
+	}
+	v, err := c.FromMessage(msg)
+	if err != nil {
+		return cty.NilVal, cty.NilType, err
+	}
+	return v, ty, nil
+}
+
+func fromProtobufMessageRoot(msg protoreflect.Message, opts *options) (cty.Value, error) {
+	path := make(cty.Path, 0, 8) // some capacity to avoid further allocs for shallow structures
+	if handler, ty := wellKnownHandlerFor(msg.Descriptor(), opts); handler != nil {
+		return fromWellKnownHandler(handler, ty, msg, opts, path)
+	}
+	return fromProtobufMessage(msg, opts, path)
+}
+
+// attrsMapPool is a pool of maps that fromProtobufMessage uses to collect
+// attribute values before constructing an object value, to avoid
+// allocating a new map for every message.
+var attrsMapPool = sync.Pool{
+	New: func() interface{} {
+		return make(map[string]cty.Value)
+	},
+}
+
+// attrPathStepsCache is a cache of the results of attrPathSteps, keyed by
+// message descriptor.
+var attrPathStepsCache sync.Map
+
+// attrPathSteps returns a slice of path steps with one element per field
+// of the given message descriptor, with each one being a cty.GetAttrStep
+// for the corresponding attribute.
+//
+// This exists to avoid allocating a new path step for every field of every
+// message we convert, which would otherwise be a significant proportion of
+// the allocations when converting messages with many scalar fields.
+func attrPathSteps(desc protoreflect.MessageDescriptor) []cty.PathStep {
+	if steps, ok := attrPathStepsCache.Load(desc); ok {
+		return steps.([]cty.PathStep)
+	}
+	fields := desc.Fields()
+	steps := make([]cty.PathStep, fields.Len())
+	for i := range steps {
+		steps[i] = cty.GetAttrStep{Name: string(fields.Get(i).Name())}
+	}
+	attrPathStepsCache.Store(desc, steps)
+	return steps
 }
 
-func fromProtobufMessage(msg protoreflect.Message, path cty.Path) (cty.Value, error) {
+// reservePath returns the given path, or a copy of it, with capacity for at
+// least one more step.
+//
+// We extend paths by appending to them for each nested attribute and
+// element, so that sibling steps share the backing array of their parent's
+// path. Once that array is full, though, each append would allocate a new
+// one, so callers about to extend the same path many times should call
+// this first so that all of the extended paths share one new array.
+//
+// Sharing the array is safe because cty copies the path into each
+// PathError, and recordSetField copies it too.
+func reservePath(path cty.Path) cty.Path {
+	if len(path) < cap(path) {
+		return path
+	}
+	ret := make(cty.Path, len(path), 2*len(path)+4)
+	copy(ret, path)
+	return ret
+}
+
+func fromProtobufMessage(msg protoreflect.Message, opts *options, path cty.Path) (cty.Value, error) {
+	if err := opts.contextErr(); err != nil {
+		return cty.NilVal, err
+	}
 	desc := msg.Descriptor()
 	fields := desc.Fields()
-	attrs := make(map[string]cty.Value, fields.Len())
+	steps := attrPathSteps(desc)
+
+	// cty.ObjectVal copies the attribute values into its own data
+	// structure, so we can safely reuse the map we build here for
+	// future calls once we're finished.
+	attrs := attrsMapPool.Get().(map[string]cty.Value)
+	defer func() {
+		for k := range attrs {
+			delete(attrs, k)
+		}
+		attrsMapPool.Put(attrs)
+	}()
 
 	for i := 0; i < fields.Len(); i++ {
 		field := fields.Get(i)
-		name := string(field.Name())
+		name := opts.fieldAttrName(field)
+		step := steps[i]
+		if opts.fieldNameFunc != nil {
+			if _, exists := attrs[name]; exists {
+				return cty.NilVal, path.NewErrorf("more than one field has the attribute name %q", name)
+			}
+			step = cty.GetAttrStep{Name: name}
+		}
 
 		// Temporarily extend path with new attribute name
-		path := append(path, cty.GetAttrStep{Name: name})
+		path := append(path, step)
 
-		if field.HasPresence() && !msg.Has(field) {
-			// For presence-tracking fields that are absent, the cty
-			// representation is a null value of the field's implied
-			// type.
-			aty, err := impliedTypeForFieldDesc(field, path)
-			if err != nil {
-				return cty.NilVal, err
-			}
-			attrs[name] = cty.NullVal(aty)
-			continue
+		v, err := fromProtobufMessageField(msg, field, opts, path)
+		if err != nil {
+			return cty.NilVal, err
 		}
+		if opts.traceFunc != nil {
+			opts.traceFunc(path.Copy(), field.Kind(), v)
+		}
+		attrs[name] = v
+	}
+
+	if opts.extensionTypes != nil {
+		// Extension fields are named by their full names, to avoid
+		// conflicts with the message's own fields and with extensions
+		// from other packages.
+		var err error
+		opts.extensionTypes.RangeExtensionsByMessage(desc.FullName(), func(xt protoreflect.ExtensionType) bool {
+			field := xt.TypeDescriptor()
+			name := string(field.FullName())
+			if _, exists := attrs[name]; exists {
+				// Only possible if a FieldNameFunc chose the same name.
+				err = path.NewErrorf("more than one field has the attribute name %q", name)
+				return false
+			}
+
+			// Temporarily extend path with new attribute name
+			path := append(path, cty.GetAttrStep{Name: name})
 
-		rawV := msg.Get(field)
-		v, err := fromProtobufFieldValue(rawV, field, path)
+			v, thisErr := fromProtobufMessageField(msg, field, opts, path)
+			if thisErr != nil {
+				err = thisErr
+				return false
+			}
+			if opts.traceFunc != nil {
+				opts.traceFunc(path.Copy(), field.Kind(), v)
+			}
+			attrs[name] = v
+			return true
+		})
 		if err != nil {
 			return cty.NilVal, err
 		}
-		attrs[name] = v
+	}
+
+	for _, oneof := range discriminatedOneofs(desc, opts) {
+		name := string(oneof.Name())
+		if _, exists := attrs[name]; exists {
+			return cty.NilVal, path.NewErrorf("attribute %q for oneof discriminator conflicts with a field of the same name", name)
+		}
+		attrs[name] = fromOneofDiscriminator(msg, oneof, opts)
+	}
+
+	if name := opts.unknownFieldsAttr; name != "" {
+		if _, exists := attrs[name]; exists {
+			return cty.NilVal, path.NewErrorf("attribute %q for unknown fields conflicts with a field of the same name", name)
+		}
+		if raw := msg.GetUnknown(); len(raw) != 0 {
+			attrs[name] = fromProtobufBytes(raw, opts)
+		} else {
+			attrs[name] = cty.NullVal(impliedTypeForBytes(opts))
+		}
 	}
 
 	return cty.ObjectVal(attrs), nil
 }
 
-func fromProtobufFieldValue(rawV protoreflect.Value, field protoreflect.FieldDescriptor, path cty.Path) (cty.Value, error) {
+func fromProtobufMessageField(msg protoreflect.Message, field protoreflect.FieldDescriptor, opts *options, path cty.Path) (cty.Value, error) {
+	if field.HasPresence() && !msg.Has(field) {
+		if opts.emitUnpopulated && emitUnpopulatedField(field) {
+			// The caller asked us to use the field's default value
+			// instead of null, which msg.Get returns for an absent
+			// field.
+			return fromProtobufFieldValue(msg.Get(field), field, opts, path)
+		}
+		if opts.defaultMessages && defaultMessageField(field, opts) {
+			// Similarly, msg.Get returns an empty message for an absent
+			// message field, but we must not expand it if it's beyond the
+			// maximum depth, where the implied type requires null.
+			if _, tooDeep := opts.nestedMessage(); !tooDeep {
+				return fromProtobufFieldValue(msg.Get(field), field, opts, path)
+			}
+		}
+
+		// For presence-tracking fields that are absent, the cty
+		// representation is a null value of the field's implied
+		// type.
+		aty, err := impliedTypeForFieldDesc(field, opts, path)
+		if err != nil {
+			return cty.NilVal, err
+		}
+		return cty.NullVal(aty), nil
+	}
+
+	rawV := msg.Get(field)
+	if opts.omitDefaults && !field.HasPresence() && field.Cardinality() != protoreflect.Repeated && isZeroScalar(rawV, field) {
+		// The caller asked us to treat zero values of fields without
+		// presence tracking as if they were absent.
+		aty, err := impliedTypeForFieldDesc(field, opts, path)
+		if err != nil {
+			return cty.NilVal, err
+		}
+		return cty.NullVal(aty), nil
+	}
+
+	return fromProtobufFieldValue(rawV, field, opts, path)
+}
+
+// emitUnpopulatedField returns true if the given presence-tracking field
+// should have its default value when absent and the WithEmitUnpopulated
+// option is in effect.
+//
+// This follows protojson's EmitUnpopulated option in excluding message
+// fields, which could otherwise expand infinitely for recursive message
+// types, and members of real oneofs, which would then no longer indicate
+// which of the choices is set.
+func emitUnpopulatedField(field protoreflect.FieldDescriptor) bool {
+	if field.Message() != nil {
+		return false
+	}
+	if oneof := field.ContainingOneof(); oneof != nil && !oneof.IsSynthetic() {
+		return false
+	}
+	return true
+}
+
+// defaultMessageField returns true if the given presence-tracking field
+// should be represented as an object of default values when absent and the
+// WithDefaultMessages option is in effect.
+func defaultMessageField(field protoreflect.FieldDescriptor, opts *options) bool {
+	desc := field.Message()
+	if desc == nil || field.Cardinality() == protoreflect.Repeated {
+		return false
+	}
+	if oneof := field.ContainingOneof(); oneof != nil && !oneof.IsSynthetic() {
+		return false
+	}
+	if handler, _ := wellKnownHandlerFor(desc, opts); handler != nil {
+		return false
+	}
+	if opts.wellKnownStruct && isWellKnownStruct(desc) {
+		return false
+	}
+	return true
+}
+
+func fromProtobufFieldValue(rawV protoreflect.Value, field protoreflect.FieldDescriptor, opts *options, path cty.Path) (cty.Value, error) {
 	// This should generally follow the same structure as in
 	// impliedTypeForFieldDesc, because we must always produce
 	// a value of the same type that impliedTypeForFieldDesc
@@ -65,43 +401,65 @@ func fromProtobufFieldValue(rawV protoreflect.Value, field protoreflect.FieldDes
 
 	switch {
 	case field.IsMap():
-		subDesc := field.Message()
-		subFields := subDesc.Fields()
-		keyField := subFields.ByNumber(1)
-		valField := subFields.ByNumber(2)
+		path := reservePath(path)
+		keyField, valField, err := mapEntryFields(field, path)
+		if err != nil {
+			return cty.NilVal, err
+		}
 		rawMap := rawV.Map()
 		switch {
 		case keyField.Kind() == protoreflect.StringKind:
 			elems := make(map[string]cty.Value, rawMap.Len())
+			same := !typeDependsOnValue(valField, opts)
+			var firstTy cty.Type
 			var err error
 			rawMap.Range(func(rawK protoreflect.MapKey, rawV protoreflect.Value) bool {
 				key := rawK.String()
+				if opts.maxStringLen > 0 && len(key) > opts.maxStringLen {
+					// The error refers to the map as a whole, because the
+					// path to the element would include the overlong key.
+					err = path.NewErrorf("map key is %d bytes long, which exceeds the maximum of %d", len(key), opts.maxStringLen)
+					return false
+				}
 
 				// Temporarily extend path with placeholder for indexing.
 				path := append(path, cty.IndexStep{Key: cty.StringVal(key)})
 
-				ev, thisErr := fromProtobufFieldValue(rawV, valField, path)
+				ev, thisErr := fromProtobufFieldValue(rawV, valField, opts, path)
 				if thisErr != nil {
 					err = thisErr
 					return false
 				}
+				if len(elems) == 0 {
+					firstTy = ev.Type()
+				} else if !ev.Type().Equals(firstTy) {
+					same = false
+				}
 				elems[key] = ev
 				return true
 			})
 			if err != nil {
 				return cty.NilVal, err
 			}
-			if len(elems) == 0 {
+			switch {
+			case !same:
+				// A map can't have elements of different types, so we
+				// use an object instead, which ToProtobufMessage also
+				// accepts.
+				return cty.ObjectVal(elems), nil
+			case len(elems) == 0:
 				path := append(path, cty.IndexStep{Key: cty.UnknownVal(cty.String)})
-				ety, err := impliedTypeForFieldDesc(valField, path)
+				ety, err := impliedTypeForFieldDesc(valField, opts, path)
 				if err != nil {
 					return cty.NilVal, err
 				}
 				return cty.MapValEmpty(ety), nil
+			default:
+				return cty.MapVal(elems), nil
 			}
-			return cty.MapVal(elems), nil
 		default:
 			elems := make([]cty.Value, 0, rawMap.Len())
+			same := !typeDependsOnValue(valField, opts)
 			var err error
 			rawMap.Range(func(rawK protoreflect.MapKey, rawV protoreflect.Value) bool {
 				// Temporarily extend path with placeholder for indexing.
@@ -111,34 +469,45 @@ func fromProtobufFieldValue(rawV protoreflect.Value, field protoreflect.FieldDes
 				path := append(path, cty.IndexStep{Key: cty.DynamicVal})
 
 				rawKV := rawK.Value()
-				ek, thisErr := fromProtobufFieldValue(rawKV, keyField, path)
+				ek, thisErr := fromProtobufFieldValue(rawKV, keyField, opts, append(path, cty.GetAttrStep{Name: "key"}))
 				if thisErr != nil {
 					err = thisErr
 					return false
 				}
 
-				ev, thisErr := fromProtobufFieldValue(rawV, valField, path)
+				ev, thisErr := fromProtobufFieldValue(rawV, valField, opts, append(path, cty.GetAttrStep{Name: "value"}))
 				if thisErr != nil {
 					err = thisErr
 					return false
 				}
 
-				elems = append(elems, cty.ObjectVal(map[string]cty.Value{
+				elem := cty.ObjectVal(map[string]cty.Value{
 					"key":   ek,
 					"value": ev,
-				}))
+				})
+				if len(elems) != 0 && !elem.Type().Equals(elems[0].Type()) {
+					same = false
+				}
+				elems = append(elems, elem)
 				return true
 			})
 			if err != nil {
 				return cty.NilVal, err
 			}
+			if !same {
+				// A set can't have elements of different types either,
+				// so we use a tuple of the elements in key order instead,
+				// which ToProtobufMessage also accepts.
+				sortMapEntries(elems, isInt64Kind(keyField.Kind()))
+				return cty.TupleVal(elems), nil
+			}
 			if len(elems) == 0 {
 				path := append(path, cty.IndexStep{Key: cty.DynamicVal})
-				keyTy, err := impliedTypeForFieldDesc(keyField, path)
+				keyTy, err := impliedTypeForFieldDesc(keyField, opts, path)
 				if err != nil {
 					return cty.NilVal, err
 				}
-				valTy, err := impliedTypeForFieldDesc(valField, path)
+				valTy, err := impliedTypeForFieldDesc(valField, opts, path)
 				if err != nil {
 					return cty.NilVal, err
 				}
@@ -147,37 +516,88 @@ func fromProtobufFieldValue(rawV protoreflect.Value, field protoreflect.FieldDes
 					"value": valTy,
 				})), nil
 			}
+			// Protobuf map iteration order is randomized, so we sort the
+			// elements to make sure we build the set in the same way
+			// every time, regardless of the order of the source entries.
+			sortMapEntries(elems, isInt64Kind(keyField.Kind()))
 			return cty.SetVal(elems), nil
 		}
 	case field.IsList():
+		path := reservePath(path)
 		rawList := rawV.List()
 		elems := make([]cty.Value, rawList.Len())
+		same := !typeDependsOnValue(field, opts)
 		for i := 0; i < rawList.Len(); i++ {
-			// Temporarily extend path with placeholder for indexing.
-			path := append(path, cty.IndexStep{Key: cty.NumberIntVal(int64(i))})
+			// Temporarily extend path with placeholder for indexing. Only
+			// errors and the trace function ever see the path, so unless
+			// there's a trace function we avoid allocating a number for
+			// every element and add the real index only if there's an
+			// error, because lists of messages can be very long.
+			step := listIndexPlaceholder
+			if opts.traceFunc != nil {
+				step = cty.IndexStep{Key: cty.NumberIntVal(int64(i))}
+			}
+			path := append(path, step)
 
 			rawEV := rawList.Get(i)
-			ev, err := fromProtobufFieldKindValue(rawEV, field, path)
+			ev, err := fromProtobufFieldKindValue(rawEV, field, opts, path)
 			if err != nil {
-				return cty.NilVal, err
+				return cty.NilVal, withListIndex(err, len(path)-1, i)
+			}
+			if i != 0 && !ev.Type().Equals(elems[0].Type()) {
+				same = false
 			}
 			elems[i] = ev
 		}
-		if len(elems) == 0 {
+		switch {
+		case !same:
+			// A list can't have elements of different types, so we use a
+			// tuple instead, which ToProtobufMessage also accepts.
+			return cty.TupleVal(elems), nil
+		case len(elems) == 0:
 			path := append(path, cty.IndexStep{Key: cty.UnknownVal(cty.Number)})
-			ety, err := impliedTypeForFieldKind(field, path)
+			ety, err := impliedTypeForFieldKind(field, opts, path)
 			if err != nil {
 				return cty.NilVal, err
 			}
 			return cty.ListValEmpty(ety), nil
+		default:
+			return cty.ListVal(elems), nil
 		}
-		return cty.ListVal(elems), nil
 	default:
-		return fromProtobufFieldKindValue(rawV, field, path)
+		return fromProtobufFieldKindValue(rawV, field, opts, path)
+	}
+}
+
+// listIndexPlaceholder is the path step that fromProtobufFieldValue uses
+// for list elements until it knows it needs the real index.
+var listIndexPlaceholder = cty.IndexStep{Key: cty.UnknownVal(cty.Number)}
+
+// withListIndex returns the given error with the placeholder step at the
+// given position of its path, if it's a cty.PathError that has one, replaced
+// with the given list index.
+func withListIndex(err error, pos int, idx int) error {
+	pathErr, ok := err.(cty.PathError)
+	if !ok || len(pathErr.Path) <= pos {
+		return err
+	}
+	if step, ok := pathErr.Path[pos].(cty.IndexStep); ok && step.Key.RawEquals(listIndexPlaceholder.Key) {
+		// The error has its own copy of the path, so we can modify it.
+		pathErr.Path[pos] = cty.IndexStep{Key: cty.NumberIntVal(int64(idx))}
 	}
+	return pathErr
 }
 
-func fromProtobufFieldKindValue(rawV protoreflect.Value, field protoreflect.FieldDescriptor, path cty.Path) (cty.Value, error) {
+func fromProtobufFieldKindValue(rawV protoreflect.Value, field protoreflect.FieldDescriptor, opts *options, path cty.Path) (cty.Value, error) {
+	if conv, err := fieldConverterFor(field, opts, path); conv != nil || err != nil {
+		if err != nil {
+			return cty.NilVal, err
+		}
+		return fromFieldConverter(conv, rawV, field, path)
+	}
+	if opts.int64AsStrings && isInt64Kind(field.Kind()) {
+		return fromProtobufInt64String(rawV, field.Kind()), nil
+	}
 	switch kind := field.Kind(); kind {
 	case protoreflect.BoolKind:
 		if rawV.Bool() {
@@ -191,27 +611,124 @@ func fromProtobufFieldKindValue(rawV protoreflect.Value, field protoreflect.Fiel
 	case protoreflect.FloatKind, protoreflect.DoubleKind:
 		return cty.NumberFloatVal(rawV.Float()), nil
 	case protoreflect.StringKind:
-		return cty.StringVal(rawV.String()), nil
+		s := rawV.String()
+		if opts.maxStringLen > 0 && len(s) > opts.maxStringLen {
+			return cty.NilVal, path.NewErrorf("string is %d bytes long, which exceeds the maximum of %d", len(s), opts.maxStringLen)
+		}
+		return cty.StringVal(s), nil
 	case protoreflect.BytesKind:
-		// cty strings are sequences of unicode characters rather than of
-		// bytes, so our convention is to Base64-encode the bytes to
-		// represent them in cty without loss.
-		return cty.StringVal(base64.StdEncoding.EncodeToString(rawV.Bytes())), nil
+		if b := rawV.Bytes(); opts.maxBytesLen > 0 && len(b) > opts.maxBytesLen {
+			return cty.NilVal, path.NewErrorf("value is %d bytes long, which exceeds the maximum of %d", len(b), opts.maxBytesLen)
+		}
+		if opts.bytesAsUTF8 {
+			b := rawV.Bytes()
+			if !utf8.Valid(b) {
+				return cty.NilVal, path.NewErrorf("value is not valid UTF-8 text")
+			}
+			return cty.StringVal(string(b)), nil
+		}
+		if opts.bytesAsNumbers {
+			return fromProtobufByteNumbers(rawV.Bytes()), nil
+		}
+		return fromProtobufBytes(rawV.Bytes(), opts), nil
 	case protoreflect.EnumKind:
 		// cty doesn't have a sense of enums, so for usability we translate
 		// these to strings based on the enum field names. That means we
 		// need to translate the stored number into a name to return.
+		if isWellKnownNullValue(field, opts) {
+			// ...except for this one, whose only member means null.
+			return cty.NullVal(cty.DynamicPseudoType), nil
+		}
 		num := rawV.Enum()
-		desc := field.Enum().Values().ByNumber(rawV.Enum())
+		if opts.enumObjects {
+			return fromEnumObject(num, field.Enum(), opts), nil
+		}
+		desc := field.Enum().Values().ByNumber(num)
 		if desc == nil {
 			// Invalid enum member, then
 			return cty.NilVal, path.NewErrorf("value %d is not part of the enumeration", num)
 		}
-		return cty.StringVal(string(desc.Name())), nil
+		return cty.StringVal(enumValueName(desc, opts)), nil
 	case protoreflect.MessageKind, protoreflect.GroupKind:
 		sub := rawV.Message()
-		return fromProtobufMessage(sub, path)
+		if handler, ty := wellKnownHandlerFor(sub.Descriptor(), opts); handler != nil {
+			return fromWellKnownHandler(handler, ty, sub, opts, path)
+		}
+		if opts.wellKnownStruct && isWellKnownStruct(sub.Descriptor()) {
+			return fromWellKnownStructMessage(sub, path)
+		}
+		nested, tooDeep := opts.nestedMessage()
+		if tooDeep {
+			return cty.NilVal, path.NewErrorf("message is nested more than %d levels deep", opts.maxDepth)
+		}
+		return fromProtobufMessage(sub, nested, reservePath(path))
+	default:
+		return cty.NilVal, path.NewErrorf("field %s has protobuf kind %s, which has no cty equivalent", field.FullName(), kind.String())
+	}
+}
+
+// fromProtobufBytes returns the cty representation of the given bytes,
+// which is a value of the type returned by impliedTypeForBytes.
+//
+// The given slice might belong to the source message, so the result never
+// refers to its underlying array.
+func fromProtobufBytes(b []byte, opts *options) cty.Value {
+	if opts.bytesCapsule {
+		return BytesCapsuleVal(append([]byte(nil), b...))
+	}
+	// cty strings are sequences of unicode characters rather than of
+	// bytes, so our convention is to Base64-encode the bytes to
+	// represent them in cty without loss.
+	return cty.StringVal(opts.base64().EncodeToString(b))
+}
+
+// fromProtobufInt64String returns the decimal string representation of the
+// given value of one of the 64-bit integer kinds, for use with the
+// int64-as-strings option.
+func fromProtobufInt64String(rawV protoreflect.Value, kind protoreflect.Kind) cty.Value {
+	switch kind {
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return cty.StringVal(strconv.FormatUint(rawV.Uint(), 10))
+	default:
+		return cty.StringVal(strconv.FormatInt(rawV.Int(), 10))
+	}
+}
+
+// fromProtobufByteNumbers returns a list of numbers with one element per
+// byte in the given slice, for use with the bytes-as-numbers option.
+func fromProtobufByteNumbers(b []byte) cty.Value {
+	if len(b) == 0 {
+		return cty.ListValEmpty(cty.Number)
+	}
+	elems := make([]cty.Value, len(b))
+	for i, c := range b {
+		elems[i] = cty.NumberIntVal(int64(c))
+	}
+	return cty.ListVal(elems)
+}
+
+// isZeroScalar returns true if the given value is the zero value for the
+// kind of the given field, which must be a scalar (non-message) field.
+func isZeroScalar(rawV protoreflect.Value, field protoreflect.FieldDescriptor) bool {
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		return !rawV.Bool()
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind, protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return rawV.Int() == 0
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return rawV.Uint() == 0
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		// We intentionally don't consider negative zero as zero here,
+		// because proto3 serializes it explicitly.
+		f := rawV.Float()
+		return f == 0 && !math.Signbit(f)
+	case protoreflect.StringKind:
+		return rawV.String() == ""
+	case protoreflect.BytesKind:
+		return len(rawV.Bytes()) == 0
+	case protoreflect.EnumKind:
+		return rawV.Enum() == 0
 	default:
-		return cty.NilVal, path.NewErrorf("no cty equivalent for protobuf kind %s", kind.String())
+		return false
 	}
 }
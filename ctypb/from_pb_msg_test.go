@@ -1,13 +1,28 @@
 package ctypb
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/zclconf/go-cty-debug/ctydebug"
 	"github.com/zclconf/go-cty-protobuf/internal/testproto"
 	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/dynamicpb"
 	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 func TestFromProtobufMessage(t *testing.T) {
@@ -24,9 +39,24 @@ func TestFromProtobufMessage(t *testing.T) {
 		}
 		return v
 	}
+	mustStruct := func(v map[string]interface{}) *structpb.Struct {
+		ret, err := structpb.NewStruct(v)
+		if err != nil {
+			panic(err)
+		}
+		return ret
+	}
+	mustList := func(v []interface{}) *structpb.ListValue {
+		ret, err := structpb.NewList(v)
+		if err != nil {
+			panic(err)
+		}
+		return ret
+	}
 
 	tests := map[string]struct {
 		Input   protoreflect.ProtoMessage
+		Options []Option
 		Want    cty.Value
 		WantErr string
 	}{
@@ -99,6 +129,217 @@ func TestFromProtobufMessage(t *testing.T) {
 				"t_uint64":   cty.NumberIntVal(10),
 			}),
 		},
+		"assorted all unset omitting defaults": {
+			Input:   &testproto.Assorted{},
+			Options: []Option{WithOmitDefaults()},
+			// With WithOmitDefaults, the fields that don't track presence
+			// become null when they have their zero value, just as the
+			// fields that do track presence would when absent.
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"t_bool":    cty.NullVal(cty.Bool),
+				"t_bytes":   cty.NullVal(cty.String),
+				"t_double":  cty.NullVal(cty.Number),
+				"t_fixed32": cty.NullVal(cty.Number),
+				"t_fixed64": cty.NullVal(cty.Number),
+				"t_float":   cty.NullVal(cty.Number),
+				"t_int32":   cty.NullVal(cty.Number),
+				"t_int64":   cty.NullVal(cty.Number),
+				"t_message": cty.NullVal(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_sfixed32": cty.NullVal(cty.Number),
+				"t_sfixed64": cty.NullVal(cty.Number),
+				"t_sint32":   cty.NullVal(cty.Number),
+				"t_sint64":   cty.NullVal(cty.Number),
+				"t_string":   cty.NullVal(cty.String),
+				"t_uint32":   cty.NullVal(cty.Number),
+				"t_uint64":   cty.NullVal(cty.Number),
+			}),
+		},
+		"assorted some set omitting defaults": {
+			Input: &testproto.Assorted{
+				TDouble: 1.5,
+				TInt32:  -7,
+				TUint64: 10,
+				TBool:   true,
+				TBytes:  []byte("HELLO COMPUTER"),
+				TMessage: &testproto.Assorted_Nested{
+					// The nested field has its zero value, so it
+					// should be null in the result.
+					TNestedField: "",
+				},
+			},
+			Options: []Option{WithOmitDefaults()},
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"t_bool":    cty.True,
+				"t_bytes":   cty.StringVal("SEVMTE8gQ09NUFVURVI="),
+				"t_double":  cty.NumberFloatVal(1.5),
+				"t_fixed32": cty.NullVal(cty.Number),
+				"t_fixed64": cty.NullVal(cty.Number),
+				"t_float":   cty.NullVal(cty.Number),
+				"t_int32":   cty.NumberIntVal(-7),
+				"t_int64":   cty.NullVal(cty.Number),
+				"t_message": cty.ObjectVal(map[string]cty.Value{
+					"t_nested_field": cty.NullVal(cty.String),
+				}),
+				"t_sfixed32": cty.NullVal(cty.Number),
+				"t_sfixed64": cty.NullVal(cty.Number),
+				"t_sint32":   cty.NullVal(cty.Number),
+				"t_sint64":   cty.NullVal(cty.Number),
+				"t_string":   cty.NullVal(cty.String),
+				"t_uint32":   cty.NullVal(cty.Number),
+				"t_uint64":   cty.NumberIntVal(10),
+			}),
+		},
+		"assorted bytes as capsule": {
+			Input: &testproto.Assorted{
+				TBytes: []byte("HELLO COMPUTER"),
+			},
+			Options: []Option{WithBytesCapsule()},
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"t_bool":    cty.False,
+				"t_bytes":   BytesCapsuleVal([]byte("HELLO COMPUTER")),
+				"t_double":  cty.NumberIntVal(0),
+				"t_fixed32": cty.NumberIntVal(0),
+				"t_fixed64": cty.NumberIntVal(0),
+				"t_float":   cty.NumberIntVal(0),
+				"t_int32":   cty.NumberIntVal(0),
+				"t_int64":   cty.NumberIntVal(0),
+				"t_message": cty.NullVal(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_sfixed32": cty.NumberIntVal(0),
+				"t_sfixed64": cty.NumberIntVal(0),
+				"t_sint32":   cty.NumberIntVal(0),
+				"t_sint64":   cty.NumberIntVal(0),
+				"t_string":   cty.StringVal(""),
+				"t_uint32":   cty.NumberIntVal(0),
+				"t_uint64":   cty.NumberIntVal(0),
+			}),
+		},
+		"assorted bytes as UTF-8": {
+			Input: &testproto.Assorted{
+				TBytes: []byte("HELLO \xf0\x9f\x96\xa5"),
+			},
+			Options: []Option{WithBytesAsUTF8Strings()},
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"t_bool":    cty.False,
+				"t_bytes":   cty.StringVal("HELLO 🖥"),
+				"t_double":  cty.NumberIntVal(0),
+				"t_fixed32": cty.NumberIntVal(0),
+				"t_fixed64": cty.NumberIntVal(0),
+				"t_float":   cty.NumberIntVal(0),
+				"t_int32":   cty.NumberIntVal(0),
+				"t_int64":   cty.NumberIntVal(0),
+				"t_message": cty.NullVal(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_sfixed32": cty.NumberIntVal(0),
+				"t_sfixed64": cty.NumberIntVal(0),
+				"t_sint32":   cty.NumberIntVal(0),
+				"t_sint64":   cty.NumberIntVal(0),
+				"t_string":   cty.StringVal(""),
+				"t_uint32":   cty.NumberIntVal(0),
+				"t_uint64":   cty.NumberIntVal(0),
+			}),
+		},
+		"assorted bytes as UTF-8 with invalid UTF-8": {
+			Input: &testproto.Assorted{
+				TBytes: []byte("HELLO \xff"),
+			},
+			Options: []Option{WithBytesAsUTF8Strings()},
+			WantErr: "value is not valid UTF-8 text",
+		},
+		"assorted bytes as number lists": {
+			Input: &testproto.Assorted{
+				TBytes: []byte{0, 1, 255},
+			},
+			Options: []Option{WithBytesAsNumberLists()},
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"t_bool": cty.False,
+				"t_bytes": cty.ListVal([]cty.Value{
+					cty.NumberIntVal(0),
+					cty.NumberIntVal(1),
+					cty.NumberIntVal(255),
+				}),
+				"t_double":  cty.NumberIntVal(0),
+				"t_fixed32": cty.NumberIntVal(0),
+				"t_fixed64": cty.NumberIntVal(0),
+				"t_float":   cty.NumberIntVal(0),
+				"t_int32":   cty.NumberIntVal(0),
+				"t_int64":   cty.NumberIntVal(0),
+				"t_message": cty.NullVal(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_sfixed32": cty.NumberIntVal(0),
+				"t_sfixed64": cty.NumberIntVal(0),
+				"t_sint32":   cty.NumberIntVal(0),
+				"t_sint64":   cty.NumberIntVal(0),
+				"t_string":   cty.StringVal(""),
+				"t_uint32":   cty.NumberIntVal(0),
+				"t_uint64":   cty.NumberIntVal(0),
+			}),
+		},
+		"assorted empty bytes as number lists": {
+			Input:   &testproto.Assorted{},
+			Options: []Option{WithBytesAsNumberLists()},
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"t_bool":    cty.False,
+				"t_bytes":   cty.ListValEmpty(cty.Number),
+				"t_double":  cty.NumberIntVal(0),
+				"t_fixed32": cty.NumberIntVal(0),
+				"t_fixed64": cty.NumberIntVal(0),
+				"t_float":   cty.NumberIntVal(0),
+				"t_int32":   cty.NumberIntVal(0),
+				"t_int64":   cty.NumberIntVal(0),
+				"t_message": cty.NullVal(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_sfixed32": cty.NumberIntVal(0),
+				"t_sfixed64": cty.NumberIntVal(0),
+				"t_sint32":   cty.NumberIntVal(0),
+				"t_sint64":   cty.NumberIntVal(0),
+				"t_string":   cty.StringVal(""),
+				"t_uint32":   cty.NumberIntVal(0),
+				"t_uint64":   cty.NumberIntVal(0),
+			}),
+		},
+		"assorted with field name func": {
+			Input: &testproto.Assorted{
+				TString: "hello",
+				TMessage: &testproto.Assorted_Nested{
+					TNestedField: "world",
+				},
+			},
+			Options: []Option{WithFieldNameFunc(stripFieldNamePrefix)},
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"BOOL":    cty.False,
+				"BYTES":   cty.StringVal(""),
+				"DOUBLE":  cty.NumberIntVal(0),
+				"FIXED32": cty.NumberIntVal(0),
+				"FIXED64": cty.NumberIntVal(0),
+				"FLOAT":   cty.NumberIntVal(0),
+				"INT32":   cty.NumberIntVal(0),
+				"INT64":   cty.NumberIntVal(0),
+				"MESSAGE": cty.ObjectVal(map[string]cty.Value{
+					"NESTED_FIELD": cty.StringVal("world"),
+				}),
+				"SFIXED32": cty.NumberIntVal(0),
+				"SFIXED64": cty.NumberIntVal(0),
+				"SINT32":   cty.NumberIntVal(0),
+				"SINT64":   cty.NumberIntVal(0),
+				"STRING":   cty.StringVal("hello"),
+				"UINT32":   cty.NumberIntVal(0),
+				"UINT64":   cty.NumberIntVal(0),
+			}),
+		},
+		"assorted with conflicting field names": {
+			Input: &testproto.Assorted{},
+			Options: []Option{WithFieldNameFunc(func(field protoreflect.FieldDescriptor) string {
+				return "same"
+			})},
+			WantErr: `more than one field has the attribute name "same"`,
+		},
 		"Optional all unset": {
 			Input: &testproto.WithOptional{},
 			// Only the fields with presence tracking appear as null.
@@ -112,6 +353,45 @@ func TestFromProtobufMessage(t *testing.T) {
 				"string_req":  cty.StringVal(""),
 			}),
 		},
+		"Optional all unset emitting unpopulated": {
+			Input:   &testproto.WithOptional{},
+			Options: []Option{WithEmitUnpopulated()},
+			// Absent scalar fields take on their default values, but
+			// absent message fields are still null.
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"int32_opt":   cty.NumberIntVal(0),
+				"int32_req":   cty.NumberIntVal(0),
+				"message_opt": cty.NullVal(cty.EmptyObject),
+				"message_req": cty.NullVal(cty.EmptyObject),
+				"string_opt":  cty.StringVal(""),
+				"string_req":  cty.StringVal(""),
+			}),
+		},
+		"Optional all unset emitting unpopulated then omitting defaults": {
+			Input:   &testproto.WithOptional{},
+			Options: []Option{WithEmitUnpopulated(), WithOmitDefaults()},
+			// The last of the two mutually-exclusive options wins.
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"int32_opt":   cty.NullVal(cty.Number),
+				"int32_req":   cty.NullVal(cty.Number),
+				"message_opt": cty.NullVal(cty.EmptyObject),
+				"message_req": cty.NullVal(cty.EmptyObject),
+				"string_opt":  cty.NullVal(cty.String),
+				"string_req":  cty.NullVal(cty.String),
+			}),
+		},
+		"Optional all unset omitting defaults then emitting unpopulated": {
+			Input:   &testproto.WithOptional{},
+			Options: []Option{WithOmitDefaults(), WithEmitUnpopulated()},
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"int32_opt":   cty.NumberIntVal(0),
+				"int32_req":   cty.NumberIntVal(0),
+				"message_opt": cty.NullVal(cty.EmptyObject),
+				"message_req": cty.NullVal(cty.EmptyObject),
+				"string_opt":  cty.StringVal(""),
+				"string_req":  cty.StringVal(""),
+			}),
+		},
 		"Optional all set": {
 			Input: &testproto.WithOptional{
 				StringReq:  "hi required",
@@ -140,6 +420,17 @@ func TestFromProtobufMessage(t *testing.T) {
 				"outside": cty.StringVal(""),
 			}),
 		},
+		"OneOf all unset emitting unpopulated": {
+			Input:   &testproto.WithOneOf{},
+			Options: []Option{WithEmitUnpopulated()},
+			// The choices of a OneOf are still null, so that the
+			// result doesn't appear to have all of them set.
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"a":       cty.NullVal(cty.String),
+				"b":       cty.NullVal(cty.String),
+				"outside": cty.StringVal(""),
+			}),
+		},
 		"OneOf all set": {
 			Input: &testproto.WithOneOf{
 				Outside: "hello",
@@ -176,6 +467,77 @@ func TestFromProtobufMessage(t *testing.T) {
 				"t_strings": cty.ListValEmpty(cty.String),
 			}),
 		},
+		"Repeated with zero elements omitting defaults": {
+			Input: &testproto.WithRepeated{
+				TStrings:       []string{""},
+				TMapStringBool: map[string]bool{"a": false},
+			},
+			Options: []Option{WithOmitDefaults()},
+			// WithOmitDefaults applies only to singular fields, so
+			// repeated and map fields are still empty collections when
+			// unset and their zero-valued elements are preserved.
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"t_map_number_bool": cty.SetValEmpty(cty.Object(map[string]cty.Type{
+					"key":   cty.Number,
+					"value": cty.Bool,
+				})),
+				"t_map_number_message": cty.SetValEmpty(cty.Object(map[string]cty.Type{
+					"key": cty.Number,
+					"value": cty.Object(map[string]cty.Type{
+						"t_nested_field": cty.String,
+					}),
+				})),
+				"t_map_string_bool": cty.MapVal(map[string]cty.Value{
+					"a": cty.False,
+				}),
+				"t_map_string_message": cty.MapValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_message": cty.ListValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_strings": cty.ListVal([]cty.Value{cty.StringVal("")}),
+			}),
+		},
+		"Repeated with extreme int64 map keys": {
+			Input: &testproto.WithRepeated{
+				TMapNumberBool: map[int64]bool{
+					math.MinInt64: true,
+					-1:            false,
+					math.MaxInt64: true,
+				},
+			},
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"t_map_number_bool": cty.SetVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"key":   cty.NumberIntVal(math.MinInt64),
+						"value": cty.True,
+					}),
+					cty.ObjectVal(map[string]cty.Value{
+						"key":   cty.NumberIntVal(-1),
+						"value": cty.False,
+					}),
+					cty.ObjectVal(map[string]cty.Value{
+						"key":   cty.NumberIntVal(math.MaxInt64),
+						"value": cty.True,
+					}),
+				}),
+				"t_map_number_message": cty.SetValEmpty(cty.Object(map[string]cty.Type{
+					"key": cty.Number,
+					"value": cty.Object(map[string]cty.Type{
+						"t_nested_field": cty.String,
+					}),
+				})),
+				"t_map_string_bool": cty.MapValEmpty(cty.Bool),
+				"t_map_string_message": cty.MapValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_message": cty.ListValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_strings": cty.ListValEmpty(cty.String),
+			}),
+		},
 		"Repeated all set": {
 			Input: &testproto.WithRepeated{
 				TStrings:       []string{"hello", "world"},
@@ -305,6 +667,70 @@ func TestFromProtobufMessage(t *testing.T) {
 				"t_string": cty.StringVal("not an any"),
 			}),
 		},
+		"Struct none set": {
+			Input:   &testproto.WithStruct{},
+			Options: []Option{WithWellKnownStruct()},
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"t_struct":     cty.NullVal(cty.DynamicPseudoType),
+				"t_value":      cty.NullVal(cty.DynamicPseudoType),
+				"t_list_value": cty.NullVal(cty.DynamicPseudoType),
+			}),
+		},
+		"Struct empty": {
+			Input: &testproto.WithStruct{
+				TStruct:    &structpb.Struct{},
+				TValue:     structpb.NewNullValue(),
+				TListValue: &structpb.ListValue{},
+			},
+			Options: []Option{WithWellKnownStruct()},
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"t_struct":     cty.EmptyObjectVal,
+				"t_value":      cty.NullVal(cty.DynamicPseudoType),
+				"t_list_value": cty.EmptyTupleVal,
+			}),
+		},
+		"Struct all set": {
+			Input: &testproto.WithStruct{
+				TStruct: mustStruct(map[string]interface{}{
+					"string": "hello",
+					"bool":   true,
+					"null":   nil,
+					"list":   []interface{}{"a"},
+				}),
+				TValue: structpb.NewNumberValue(1.5),
+				TListValue: mustList([]interface{}{
+					2,
+					"two",
+					map[string]interface{}{
+						"nested": "beep",
+					},
+				}),
+			},
+			Options: []Option{WithWellKnownStruct()},
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"t_struct": cty.ObjectVal(map[string]cty.Value{
+					"string": cty.StringVal("hello"),
+					"bool":   cty.True,
+					"null":   cty.NullVal(cty.DynamicPseudoType),
+					"list":   cty.TupleVal([]cty.Value{cty.StringVal("a")}),
+				}),
+				"t_value": cty.NumberFloatVal(1.5),
+				"t_list_value": cty.TupleVal([]cty.Value{
+					cty.NumberFloatVal(2),
+					cty.StringVal("two"),
+					cty.ObjectVal(map[string]cty.Value{
+						"nested": cty.StringVal("beep"),
+					}),
+				}),
+			}),
+		},
+		"Struct with NaN": {
+			Input: &testproto.WithStruct{
+				TListValue: mustList([]interface{}{math.NaN()}),
+			},
+			Options: []Option{WithWellKnownStruct()},
+			WantErr: "cannot represent NaN as a number",
+		},
 		"Enum all unset": {
 			Input: &testproto.WithEnum{},
 			Want: cty.ObjectVal(map[string]cty.Value{
@@ -312,6 +738,46 @@ func TestFromProtobufMessage(t *testing.T) {
 				"t_string": cty.StringVal(""),
 			}),
 		},
+		"Enum all unset omitting defaults": {
+			Input:   &testproto.WithEnum{},
+			Options: []Option{WithOmitDefaults()},
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"t_enum":   cty.NullVal(cty.String),
+				"t_string": cty.NullVal(cty.String),
+			}),
+		},
+		"Optional set to zero omitting defaults": {
+			Input: &testproto.WithOptional{
+				StringOpt: ptrString(""),
+				Int32Opt:  ptrInt32(0),
+			},
+			Options: []Option{WithOmitDefaults()},
+			// Fields that track presence are not affected by
+			// WithOmitDefaults, because they can already represent
+			// the difference between absent and zero.
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"int32_opt":   cty.NumberIntVal(0),
+				"int32_req":   cty.NullVal(cty.Number),
+				"message_opt": cty.NullVal(cty.EmptyObject),
+				"message_req": cty.NullVal(cty.EmptyObject),
+				"string_opt":  cty.StringVal(""),
+				"string_req":  cty.NullVal(cty.String),
+			}),
+		},
+		"Enum with custom names": {
+			Input: &testproto.WithEnum{
+				TEnum: testproto.WithEnum_C,
+			},
+			Options: []Option{
+				WithEnumNameFunc(func(value protoreflect.EnumValueDescriptor) string {
+					return "THING_" + strings.ToUpper(string(value.Name()))
+				}),
+			},
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"t_enum":   cty.StringVal("THING_C"),
+				"t_string": cty.StringVal(""),
+			}),
+		},
 		"Enum all set": {
 			Input: &testproto.WithEnum{
 				TString: "hello",
@@ -322,24 +788,1561 @@ func TestFromProtobufMessage(t *testing.T) {
 				"t_string": cty.StringVal("hello"),
 			}),
 		},
-	}
-
-	for name, test := range tests {
-		t.Run(name, func(t *testing.T) {
-			got, err := FromProtobufMessage(test.Input.ProtoReflect())
-
-			if test.WantErr != "" {
-				if err == nil {
-					t.Fatalf("succeeded; want error\nwant: %s", test.WantErr)
-				}
-				if got, want := err.Error(), test.WantErr; got != want {
-					t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
-				}
-				return
-			} else if err != nil {
+		"Group unset": {
+			Input: &testproto.WithGroup{},
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"thing": cty.NullVal(cty.Object(map[string]cty.Type{
+					"name": cty.String,
+				})),
+			}),
+		},
+		"Group set": {
+			Input: &testproto.WithGroup{
+				Thing: &testproto.WithGroup_Thing{Name: ptrString("hello")},
+			},
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"thing": cty.ObjectVal(map[string]cty.Value{
+					"name": cty.StringVal("hello"),
+				}),
+			}),
+		},
+		"Extendable without extensions option": {
+			Input: func() protoreflect.ProtoMessage {
+				msg := &testproto.Extendable{Name: ptrString("hello")}
+				proto.SetExtension(msg, testproto.E_ExtString, "ignored")
+				return msg
+			}(),
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal("hello"),
+			}),
+		},
+		"Extendable with extensions unset": {
+			Input:   &testproto.Extendable{},
+			Options: []Option{WithExtensions(testExtensionTypes())},
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"name":                  cty.NullVal(cty.String),
+				"testproto.ext_string":  cty.NullVal(cty.String),
+				"testproto.ext_numbers": cty.ListValEmpty(cty.Number),
+			}),
+		},
+		"Extendable with extensions set": {
+			Input: func() protoreflect.ProtoMessage {
+				msg := &testproto.Extendable{Name: ptrString("hello")}
+				proto.SetExtension(msg, testproto.E_ExtString, "world")
+				proto.SetExtension(msg, testproto.E_ExtNumbers, []int32{1, 2})
+				return msg
+			}(),
+			Options: []Option{WithExtensions(testExtensionTypes())},
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"name":                 cty.StringVal("hello"),
+				"testproto.ext_string": cty.StringVal("world"),
+				"testproto.ext_numbers": cty.ListVal([]cty.Value{
+					cty.NumberIntVal(1),
+					cty.NumberIntVal(2),
+				}),
+			}),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := FromProtobufMessage(test.Input.ProtoReflect(), test.Options...)
+
+			if test.WantErr != "" {
+				if err == nil {
+					t.Fatalf("succeeded; want error\nwant: %s", test.WantErr)
+				}
+				if got, want := err.Error(), test.WantErr; got != want {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+
+			if !test.Want.RawEquals(got) {
+				t.Errorf(
+					"wrong result\ngot: %s\nwant: %s",
+					ctydebug.ValueString(got),
+					ctydebug.ValueString(test.Want),
+				)
+			}
+		})
+	}
+
+}
+
+func TestFromProtobufMessageWellKnownStructCollections(t *testing.T) {
+	// The implied type of google.protobuf.Value is cty.DynamicPseudoType
+	// with WithWellKnownStruct, so the elements of maps and repeated fields
+	// of that type can each have a different type, and therefore become
+	// objects and tuples rather than maps, lists, and sets. The same is true
+	// of the elements of a repeated field of a message type containing a
+	// Value, but those become a tuple only if their types actually differ.
+	// The implied type of all of these fields is cty.DynamicPseudoType.
+	mustValue := func(v interface{}) *structpb.Value {
+		ret, err := structpb.NewValue(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ret
+	}
+	mustStruct := func(v map[string]interface{}) *structpb.Struct {
+		ret, err := structpb.NewStruct(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ret
+	}
+	mustList := func(v []interface{}) *structpb.ListValue {
+		ret, err := structpb.NewList(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ret
+	}
+	withStruct := func(v cty.Value) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{
+			"t_struct":     cty.NullVal(cty.DynamicPseudoType),
+			"t_value":      v,
+			"t_list_value": cty.NullVal(cty.DynamicPseudoType),
+		})
+	}
+	entry := func(k int64, v cty.Value) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{
+			"key":   cty.NumberIntVal(k),
+			"value": v,
+		})
+	}
+
+	tests := map[string]struct {
+		Input protoreflect.ProtoMessage
+		Want  cty.Value
+	}{
+		"Struct mixed": {
+			mustStruct(map[string]interface{}{"a": 1, "b": "x"}),
+			cty.ObjectVal(map[string]cty.Value{
+				"fields": cty.ObjectVal(map[string]cty.Value{
+					"a": cty.NumberIntVal(1),
+					"b": cty.StringVal("x"),
+				}),
+			}),
+		},
+		"ListValue mixed": {
+			mustList([]interface{}{1, "x"}),
+			cty.ObjectVal(map[string]cty.Value{
+				"values": cty.TupleVal([]cty.Value{
+					cty.NumberIntVal(1),
+					cty.StringVal("x"),
+				}),
+			}),
+		},
+		"collections mixed": {
+			&testproto.WithStructCollections{
+				TValueMap: map[string]*structpb.Value{
+					"a": mustValue(1),
+					"b": mustValue("x"),
+					"c": mustValue(nil),
+					"d": mustValue([]interface{}{true}),
+				},
+				TValueNumberMap: map[int64]*structpb.Value{
+					2: mustValue("x"),
+					1: mustValue(1),
+				},
+				TValues: []*structpb.Value{
+					mustValue(1),
+					mustValue("x"),
+					mustValue(map[string]interface{}{"y": false}),
+				},
+				TStructs: []*testproto.WithStruct{
+					{TValue: mustValue(1)},
+					{TValue: mustValue("x")},
+				},
+			},
+			cty.ObjectVal(map[string]cty.Value{
+				"t_value_map": cty.ObjectVal(map[string]cty.Value{
+					"a": cty.NumberIntVal(1),
+					"b": cty.StringVal("x"),
+					"c": cty.NullVal(cty.DynamicPseudoType),
+					"d": cty.TupleVal([]cty.Value{cty.True}),
+				}),
+				"t_value_number_map": cty.TupleVal([]cty.Value{
+					entry(1, cty.NumberIntVal(1)),
+					entry(2, cty.StringVal("x")),
+				}),
+				"t_values": cty.TupleVal([]cty.Value{
+					cty.NumberIntVal(1),
+					cty.StringVal("x"),
+					cty.ObjectVal(map[string]cty.Value{"y": cty.False}),
+				}),
+				"t_structs": cty.TupleVal([]cty.Value{
+					withStruct(cty.NumberIntVal(1)),
+					withStruct(cty.StringVal("x")),
+				}),
+			}),
+		},
+		"collections of the same type": {
+			// These are objects and tuples too, so that the type of a
+			// field doesn't change depending on whether the elements
+			// happen to have the same type.
+			&testproto.WithStructCollections{
+				TValueMap: map[string]*structpb.Value{
+					"a": mustValue(1),
+				},
+				TValueNumberMap: map[int64]*structpb.Value{
+					1: mustValue(1),
+				},
+				TValues: []*structpb.Value{
+					mustValue(1),
+					mustValue(2),
+				},
+				TStructs: []*testproto.WithStruct{
+					{TValue: mustValue(1)},
+					{TValue: mustValue(2)},
+				},
+			},
+			cty.ObjectVal(map[string]cty.Value{
+				"t_value_map": cty.ObjectVal(map[string]cty.Value{
+					"a": cty.NumberIntVal(1),
+				}),
+				"t_value_number_map": cty.TupleVal([]cty.Value{
+					entry(1, cty.NumberIntVal(1)),
+				}),
+				"t_values": cty.TupleVal([]cty.Value{
+					cty.NumberIntVal(1),
+					cty.NumberIntVal(2),
+				}),
+				"t_structs": cty.ListVal([]cty.Value{
+					withStruct(cty.NumberIntVal(1)),
+					withStruct(cty.NumberIntVal(2)),
+				}),
+			}),
+		},
+		"collections empty": {
+			&testproto.WithStructCollections{},
+			cty.ObjectVal(map[string]cty.Value{
+				"t_value_map":        cty.EmptyObjectVal,
+				"t_value_number_map": cty.EmptyTupleVal,
+				"t_values":           cty.EmptyTupleVal,
+				"t_structs": cty.ListValEmpty(cty.Object(map[string]cty.Type{
+					"t_struct":     cty.DynamicPseudoType,
+					"t_value":      cty.DynamicPseudoType,
+					"t_list_value": cty.DynamicPseudoType,
+				})),
+			}),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := FromProtobufMessage(test.Input.ProtoReflect(), WithWellKnownStruct())
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			if !test.Want.RawEquals(got) {
+				t.Fatalf("wrong result\ngot:  %s\nwant: %s", ctydebug.ValueString(got), ctydebug.ValueString(test.Want))
+			}
+			ty, err := ImpliedTypeForMessageDesc(test.Input.ProtoReflect().Descriptor(), WithWellKnownStruct())
+			if err != nil {
+				t.Fatalf("unexpected error from ImpliedTypeForMessageDesc\ngot: %s", err.Error())
+			}
+			if errs := got.Type().TestConformance(ty); len(errs) != 0 {
+				t.Errorf("result does not conform to the implied type %s: %s", ctydebug.TypeString(ty), errs[0])
+			}
+
+			into := test.Input.ProtoReflect().New()
+			err = ToProtobufMessage(got, into, WithWellKnownStruct())
+			if err != nil {
+				t.Fatalf("unexpected error from ToProtobufMessage\ngot: %s", err.Error())
+			}
+			if diff := cmp.Diff(test.Input, into.Interface(), protocmp.Transform()); diff != "" {
+				t.Errorf("wrong round-trip result\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFromProtobufMessageInt64StringMapKeyOrder(t *testing.T) {
+	// With WithInt64AsStrings, the entries of a map whose keys are 64-bit
+	// integers and whose values have different types become a tuple in
+	// numeric order of their keys, even though the keys are strings.
+	mustValue := func(v interface{}) *structpb.Value {
+		ret, err := structpb.NewValue(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ret
+	}
+	entry := func(k string, v cty.Value) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{
+			"key":   cty.StringVal(k),
+			"value": v,
+		})
+	}
+	msg := &testproto.WithStructCollections{
+		TValueNumberMap: map[int64]*structpb.Value{
+			10: mustValue("x"),
+			9:  mustValue(1),
+			-2: mustValue(true),
+		},
+	}
+
+	got, err := FromProtobufMessage(msg.ProtoReflect(), WithWellKnownStruct(), WithInt64AsStrings())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	want := cty.TupleVal([]cty.Value{
+		entry("-2", cty.True),
+		entry("9", cty.NumberIntVal(1)),
+		entry("10", cty.StringVal("x")),
+	})
+	if got := got.GetAttr("t_value_number_map"); !want.RawEquals(got) {
+		t.Errorf("wrong result\ngot:  %s\nwant: %s", ctydebug.ValueString(got), ctydebug.ValueString(want))
+	}
+}
+
+func TestFromProtobufMessageUnknownFieldsRoundTrip(t *testing.T) {
+	// This is a field number that isn't declared in the schema for Simple.
+	var raw []byte
+	raw = protowire.AppendTag(raw, 99, protowire.VarintType)
+	raw = protowire.AppendVarint(raw, 12)
+
+	input := &testproto.Simple{Foo: &testproto.Empty{}}
+	input.ProtoReflect().SetUnknown(raw)
+	input.Foo.ProtoReflect().SetUnknown(raw)
+
+	got, err := FromProtobufMessage(input.ProtoReflect(), WithPreserveUnknownFields())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	want := cty.ObjectVal(map[string]cty.Value{
+		"foo": cty.ObjectVal(map[string]cty.Value{
+			"__unknown": cty.StringVal("mAYM"),
+		}),
+		"__unknown": cty.StringVal("mAYM"),
+	})
+	if !want.RawEquals(got) {
+		t.Fatalf(
+			"wrong result\ngot: %s\nwant: %s",
+			ctydebug.ValueString(got),
+			ctydebug.ValueString(want),
+		)
+	}
+
+	output := &testproto.Simple{}
+	err = ToProtobufMessage(got, output.ProtoReflect(), WithPreserveUnknownFields())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if !proto.Equal(input, output) {
+		t.Errorf("round-trip result does not match input\ngot:  %s\nwant: %s", output, input)
+	}
+	if got, want := output.ProtoReflect().GetUnknown(), protoreflect.RawFields(raw); !bytes.Equal(got, want) {
+		t.Errorf("wrong unknown fields\ngot:  %x\nwant: %x", got, want)
+	}
+}
+
+func BenchmarkFromProtobufMessage(b *testing.B) {
+	msg := (&testproto.Assorted{
+		TDouble:   1.5,
+		TFloat:    2.5,
+		TInt32:    -7,
+		TInt64:    -8,
+		TUint32:   9,
+		TUint64:   10,
+		TSint32:   -11,
+		TSint64:   -12,
+		TFixed32:  64,
+		TFixed64:  65,
+		TSfixed32: -64,
+		TSfixed64: -65,
+		TBool:     true,
+		TString:   "hello",
+		TBytes:    []byte("HELLO COMPUTER"),
+	}).ProtoReflect()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, err := FromProtobufMessage(msg)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFromProtobufMessageDeep(b *testing.B) {
+	const depth = 50
+	msg := deepRecursiveMessage(depth)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, err := FromProtobufMessage(msg.ProtoReflect(), WithMaxDepth(depth))
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFromProtobufMessageLargeRepeated(b *testing.B) {
+	const count = 10000
+	msg := &testproto.WithRepeated{
+		TMessage: make([]*testproto.WithRepeated_Nested, count),
+	}
+	for i := range msg.TMessage {
+		msg.TMessage[i] = &testproto.WithRepeated_Nested{
+			TNestedField: "element " + strconv.Itoa(i),
+		}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := FromProtobufMessage(msg.ProtoReflect())
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// deepRecursiveMessage returns a chain of the given number of nested
+// Recursive messages.
+func deepRecursiveMessage(depth int) *testproto.Recursive {
+	var msg *testproto.Recursive
+	for i := 0; i < depth; i++ {
+		msg = &testproto.Recursive{Name: "link", Next: msg}
+	}
+	return msg
+}
+
+func TestFromProtobufMessageContext(t *testing.T) {
+	msg := &testproto.Simple{
+		Foo: &testproto.Empty{},
+	}
+
+	got, err := FromProtobufMessageContext(context.Background(), msg.ProtoReflect())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	want := cty.ObjectVal(map[string]cty.Value{
+		"foo": cty.EmptyObjectVal,
+	})
+	if !want.RawEquals(got) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = FromProtobufMessageContext(ctx, msg.ProtoReflect())
+	if err != context.Canceled {
+		t.Errorf("wrong error\ngot:  %#v\nwant: %#v", err, context.Canceled)
+	}
+}
+
+func TestFromProtobufMessageNestedMessagePresence(t *testing.T) {
+	// Message fields always track presence, so an absent nested message must
+	// be distinguishable from a present one whose fields all have their
+	// default values, even though the nested message type has fields.
+	tests := map[string]struct {
+		Opts       []Option
+		WantNested cty.Value
+	}{
+		"default": {
+			nil,
+			cty.ObjectVal(map[string]cty.Value{
+				"t_nested_field": cty.StringVal(""),
+			}),
+		},
+		"omit defaults": {
+			[]Option{WithOmitDefaults()},
+			cty.ObjectVal(map[string]cty.Value{
+				"t_nested_field": cty.NullVal(cty.String),
+			}),
+		},
+		"emit unpopulated": {
+			[]Option{WithEmitUnpopulated()},
+			cty.ObjectVal(map[string]cty.Value{
+				"t_nested_field": cty.StringVal(""),
+			}),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			absent := &testproto.Assorted{}
+			present := &testproto.Assorted{
+				TMessage: &testproto.Assorted_Nested{},
+			}
+
+			gotAbsent, err := FromProtobufMessage(absent.ProtoReflect(), test.Opts...)
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			wantAbsent := cty.NullVal(test.WantNested.Type())
+			if got := gotAbsent.GetAttr("t_message"); !wantAbsent.RawEquals(got) {
+				t.Errorf("wrong value for absent message\ngot:  %#v\nwant: %#v", got, wantAbsent)
+			}
+
+			gotPresent, err := FromProtobufMessage(present.ProtoReflect(), test.Opts...)
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			if got := gotPresent.GetAttr("t_message"); !test.WantNested.RawEquals(got) {
+				t.Errorf("wrong value for present message\ngot:  %#v\nwant: %#v", got, test.WantNested)
+			}
+
+			// Converting back must preserve the distinction.
+			intoAbsent := &testproto.Assorted{}
+			if err := ToProtobufMessage(gotAbsent, intoAbsent.ProtoReflect(), test.Opts...); err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			if intoAbsent.TMessage != nil {
+				t.Errorf("absent message became present after round-trip")
+			}
+			intoPresent := &testproto.Assorted{}
+			if err := ToProtobufMessage(gotPresent, intoPresent.ProtoReflect(), test.Opts...); err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			if intoPresent.TMessage == nil {
+				t.Errorf("present message became absent after round-trip")
+			}
+		})
+	}
+}
+
+func TestFromProtobufMessageComplexMapPath(t *testing.T) {
+	// With WithBytesAsUTF8Strings, invalid UTF-8 deep inside a map value
+	// is an error, whose path must lead all the way to the bytes field.
+	complexMsg := &testproto.WithComplexMap_Complex{
+		Inners: []*testproto.WithComplexMap_Complex_Inner{
+			{Name: "ok"},
+			{Name: "bad", Data: []byte{0xff}},
+		},
+	}
+	tests := map[string]struct {
+		Input    *testproto.WithComplexMap
+		WantPath cty.Path
+	}{
+		"string keys": {
+			&testproto.WithComplexMap{
+				TMapStringComplex: map[string]*testproto.WithComplexMap_Complex{
+					"k": complexMsg,
+				},
+			},
+			cty.GetAttrPath("t_map_string_complex").Index(cty.StringVal("k")).GetAttr("inners").IndexInt(1).GetAttr("data"),
+		},
+		"number keys": {
+			&testproto.WithComplexMap{
+				TMapNumberComplex: map[int64]*testproto.WithComplexMap_Complex{
+					1: complexMsg,
+				},
+			},
+			// The set element isn't known until it's been built, so the
+			// element step uses a placeholder.
+			cty.GetAttrPath("t_map_number_complex").Index(cty.DynamicVal).GetAttr("value").GetAttr("inners").IndexInt(1).GetAttr("data"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := FromProtobufMessage(test.Input.ProtoReflect(), WithBytesAsUTF8Strings())
+			if err == nil {
+				t.Fatalf("succeeded with invalid UTF-8; want error")
+			}
+			pathErr, ok := err.(cty.PathError)
+			if !ok {
+				t.Fatalf("error is %T, not cty.PathError: %s", err, err.Error())
+			}
+			if got, want := len(pathErr.Path), len(test.WantPath); got != want {
+				t.Fatalf("wrong error path length %d; want %d\ngot: %#v", got, want, pathErr.Path)
+			}
+			for i := range test.WantPath {
+				// cty.DynamicVal doesn't equal itself, so we compare the
+				// index steps by their key types instead.
+				gotStep, wantStep := pathErr.Path[i], test.WantPath[i]
+				if wantIdx, ok := wantStep.(cty.IndexStep); ok {
+					gotIdx, ok := gotStep.(cty.IndexStep)
+					if !ok || !gotIdx.Key.Type().Equals(wantIdx.Key.Type()) || gotIdx.Key.IsKnown() != wantIdx.Key.IsKnown() || (wantIdx.Key.IsKnown() && !gotIdx.Key.RawEquals(wantIdx.Key)) {
+						t.Errorf("wrong path step %d\ngot:  %#v\nwant: %#v", i, gotStep, wantStep)
+					}
+					continue
+				}
+				if gotStep != wantStep {
+					t.Errorf("wrong path step %d\ngot:  %#v\nwant: %#v", i, gotStep, wantStep)
+				}
+			}
+		})
+	}
+}
+
+func TestFromProtobufMessageListMap(t *testing.T) {
+	// Protocol buffers don't allow repeated map values, but a map value can
+	// be a message that has repeated fields of its own.
+	hasListTy := cty.Object(map[string]cty.Type{
+		"items": cty.List(cty.String),
+	})
+	wantTy := cty.Object(map[string]cty.Type{
+		"t_map": cty.Map(hasListTy),
+	})
+	msg := &testproto.WithListMap{
+		TMap: map[string]*testproto.WithListMap_HasList{
+			"a": {Items: []string{"x", "y"}},
+			"b": {},
+		},
+	}
+	want := cty.ObjectVal(map[string]cty.Value{
+		"t_map": cty.MapVal(map[string]cty.Value{
+			"a": cty.ObjectVal(map[string]cty.Value{
+				"items": cty.ListVal([]cty.Value{cty.StringVal("x"), cty.StringVal("y")}),
+			}),
+			"b": cty.ObjectVal(map[string]cty.Value{
+				"items": cty.ListValEmpty(cty.String),
+			}),
+		}),
+	})
+
+	gotTy, err := ImpliedTypeForMessageDesc(msg.ProtoReflect().Descriptor())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if !wantTy.Equals(gotTy) {
+		t.Errorf("wrong implied type\ngot:  %#v\nwant: %#v", gotTy, wantTy)
+	}
+
+	got, err := FromProtobufMessage(msg.ProtoReflect())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if !want.RawEquals(got) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	back := &testproto.WithListMap{}
+	err = ToProtobufMessage(got, back.ProtoReflect())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if diff := cmp.Diff(msg, back, protocmp.Transform()); diff != "" {
+		t.Errorf("wrong result after round-trip\n%s", diff)
+	}
+
+	// Errors in the list elements must report the full path.
+	bad := cty.ObjectVal(map[string]cty.Value{
+		"t_map": cty.MapVal(map[string]cty.Value{
+			"a": cty.ObjectVal(map[string]cty.Value{
+				"items": cty.ListVal([]cty.Value{cty.StringVal("x"), cty.NullVal(cty.String)}),
+			}),
+		}),
+	})
+	err = ToProtobufMessage(bad, (&testproto.WithListMap{}).ProtoReflect())
+	if err == nil {
+		t.Fatalf("succeeded with null list element; want error")
+	}
+	pathErr, ok := err.(cty.PathError)
+	if !ok {
+		t.Fatalf("error is %T, not cty.PathError: %s", err, err.Error())
+	}
+	wantPath := cty.GetAttrPath("t_map").Index(cty.StringVal("a")).GetAttr("items").IndexInt(1)
+	if !pathErr.Path.Equals(wantPath) {
+		t.Errorf("wrong error path\ngot:  %#v\nwant: %#v", pathErr.Path, wantPath)
+	}
+}
+
+func TestFromProtobufMessages(t *testing.T) {
+	desc := (*testproto.WithOneOf)(nil).ProtoReflect().Descriptor()
+	ety := cty.Object(map[string]cty.Type{
+		"outside": cty.String,
+		"a":       cty.String,
+		"b":       cty.String,
+	})
+
+	tests := map[string]struct {
+		Msgs    []protoreflect.Message
+		Want    cty.Value
+		WantErr string
+	}{
+		"empty": {
+			Msgs: nil,
+			Want: cty.ListValEmpty(ety),
+		},
+		"several": {
+			Msgs: []protoreflect.Message{
+				(&testproto.WithOneOf{Outside: "first"}).ProtoReflect(),
+				(&testproto.WithOneOf{TOneof: &testproto.WithOneOf_B{B: "second"}}).ProtoReflect(),
+			},
+			Want: cty.ListVal([]cty.Value{
+				cty.ObjectVal(map[string]cty.Value{
+					"outside": cty.StringVal("first"),
+					"a":       cty.NullVal(cty.String),
+					"b":       cty.NullVal(cty.String),
+				}),
+				cty.ObjectVal(map[string]cty.Value{
+					"outside": cty.StringVal(""),
+					"a":       cty.NullVal(cty.String),
+					"b":       cty.StringVal("second"),
+				}),
+			}),
+		},
+		"mixed types": {
+			Msgs: []protoreflect.Message{
+				(&testproto.WithOneOf{Outside: "first"}).ProtoReflect(),
+				(&testproto.Empty{}).ProtoReflect(),
+			},
+			WantErr: "message is of type testproto.Empty, but all messages must be of type testproto.WithOneOf",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := FromProtobufMessages(desc, test.Msgs)
+
+			if test.WantErr != "" {
+				if err == nil {
+					t.Fatalf("succeeded; want error\nwant: %s", test.WantErr)
+				}
+				if got, want := err.Error(), test.WantErr; got != want {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+				}
+				if pathErr, ok := err.(cty.PathError); !ok || !pathErr.Path.Equals(cty.IndexIntPath(1)) {
+					t.Errorf("error does not refer to the second message")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+
+			if !test.Want.RawEquals(got) {
+				t.Errorf(
+					"wrong result\ngot: %s\nwant: %s",
+					ctydebug.ValueString(got),
+					ctydebug.ValueString(test.Want),
+				)
+			}
+		})
+	}
+}
+
+func TestDecodeMessage(t *testing.T) {
+	tests := map[string]struct {
+		Msg     protoreflect.Message
+		Options []Option
+	}{
+		"assorted": {
+			Msg: (&testproto.Assorted{TString: "hello", TInt32: 5}).ProtoReflect(),
+		},
+		"repeated": {
+			Msg: (&testproto.WithRepeated{TStrings: []string{"a"}}).ProtoReflect(),
+		},
+		"type-affecting options": {
+			Msg:     (&testproto.WithOneOf{TOneof: &testproto.WithOneOf_A{A: "a"}}).ProtoReflect(),
+			Options: []Option{WithOneofDiscriminator(), WithFieldNameFunc(stripFieldNamePrefix)},
+		},
+		"well-known struct collections": {
+			// The type includes cty.DynamicPseudoType here, so the value
+			// only conforms to it rather than having exactly that type.
+			Msg: (&testproto.WithStructCollections{
+				TValueMap: map[string]*structpb.Value{
+					"a": structpb.NewNumberValue(1),
+					"b": structpb.NewStringValue("x"),
+				},
+				TValueNumberMap: map[int64]*structpb.Value{
+					1: structpb.NewBoolValue(true),
+				},
+				TValues: []*structpb.Value{
+					structpb.NewNumberValue(1),
+					structpb.NewStringValue("x"),
+				},
+				TStructs: []*testproto.WithStruct{
+					{TValue: structpb.NewNumberValue(1)},
+					{TValue: structpb.NewStringValue("x")},
+				},
+			}).ProtoReflect(),
+			Options: []Option{WithWellKnownStruct()},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			gotV, gotTy, err := DecodeMessage(test.Msg, test.Options...)
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			if gotTy.HasDynamicTypes() {
+				if errs := gotV.Type().TestConformance(gotTy); len(errs) != 0 {
+					t.Errorf("value does not conform to type %s: %s", ctydebug.TypeString(gotTy), errs[0])
+				}
+			} else if !gotTy.Equals(gotV.Type()) {
+				t.Errorf(
+					"type does not match value\ngot:  %s\nwant: %s",
+					ctydebug.TypeString(gotTy),
+					ctydebug.TypeString(gotV.Type()),
+				)
+			}
+			wantTy, err := ImpliedTypeForMessageDesc(test.Msg.Descriptor(), test.Options...)
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			if !wantTy.Equals(gotTy) {
+				t.Errorf(
+					"wrong type\ngot:  %s\nwant: %s",
+					ctydebug.TypeString(gotTy),
+					ctydebug.TypeString(wantTy),
+				)
+			}
+
+			wantV, err := FromProtobufMessage(test.Msg, test.Options...)
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			if !wantV.RawEquals(gotV) {
+				t.Errorf(
+					"wrong value\ngot: %s\nwant: %s",
+					ctydebug.ValueString(gotV),
+					ctydebug.ValueString(wantV),
+				)
+			}
+		})
+	}
+}
+
+func TestFromProtobufMessagesDynamic(t *testing.T) {
+	// With WithWellKnownStruct the elements can have different types,
+	// which can't belong to a list.
+	desc := (*testproto.WithStruct)(nil).ProtoReflect().Descriptor()
+	withValue := func(v cty.Value) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{
+			"t_struct":     cty.NullVal(cty.DynamicPseudoType),
+			"t_value":      v,
+			"t_list_value": cty.NullVal(cty.DynamicPseudoType),
+		})
+	}
+
+	tests := map[string]struct {
+		Msgs []protoreflect.Message
+		Want cty.Value
+	}{
+		"same types": {
+			Msgs: []protoreflect.Message{
+				(&testproto.WithStruct{TValue: structpb.NewNumberValue(1)}).ProtoReflect(),
+				(&testproto.WithStruct{TValue: structpb.NewNumberValue(2)}).ProtoReflect(),
+			},
+			Want: cty.ListVal([]cty.Value{
+				withValue(cty.NumberIntVal(1)),
+				withValue(cty.NumberIntVal(2)),
+			}),
+		},
+		"different types": {
+			Msgs: []protoreflect.Message{
+				(&testproto.WithStruct{TValue: structpb.NewNumberValue(1)}).ProtoReflect(),
+				(&testproto.WithStruct{TValue: structpb.NewStringValue("x")}).ProtoReflect(),
+			},
+			Want: cty.TupleVal([]cty.Value{
+				withValue(cty.NumberIntVal(1)),
+				withValue(cty.StringVal("x")),
+			}),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := FromProtobufMessages(desc, test.Msgs, WithWellKnownStruct())
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			if !test.Want.RawEquals(got) {
+				t.Errorf(
+					"wrong result\ngot: %s\nwant: %s",
+					ctydebug.ValueString(got),
+					ctydebug.ValueString(test.Want),
+				)
+			}
+		})
+	}
+}
+
+func TestFromProtobufMessagePackedRepeated(t *testing.T) {
+	// The decoder accepts repeated scalar fields in either the packed or
+	// unpacked encoding, regardless of which one the schema prefers, and
+	// the result must be the same either way.
+	numbers := []int64{1, -2, 300}
+	var packed, unpacked []byte
+
+	var payload []byte
+	for _, n := range numbers {
+		payload = protowire.AppendVarint(payload, uint64(n))
+	}
+	packed = protowire.AppendTag(packed, 1, protowire.BytesType)
+	packed = protowire.AppendBytes(packed, payload)
+
+	for _, n := range numbers {
+		unpacked = protowire.AppendTag(unpacked, 1, protowire.VarintType)
+		unpacked = protowire.AppendVarint(unpacked, uint64(n))
+	}
+
+	want := cty.ObjectVal(map[string]cty.Value{
+		"t_numbers": cty.ListVal([]cty.Value{
+			cty.NumberIntVal(1),
+			cty.NumberIntVal(-2),
+			cty.NumberIntVal(300),
+		}),
+	})
+	for name, raw := range map[string][]byte{"packed": packed, "unpacked": unpacked} {
+		t.Run(name, func(t *testing.T) {
+			msg := &testproto.WithRepeatedNumbers{}
+			if err := proto.Unmarshal(raw, msg); err != nil {
+				t.Fatalf("failed to decode: %s", err)
+			}
+			got, err := FromProtobufMessage(msg.ProtoReflect())
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			if !want.RawEquals(got) {
+				t.Errorf(
+					"wrong result\ngot: %s\nwant: %s",
+					ctydebug.ValueString(got),
+					ctydebug.ValueString(want),
+				)
+			}
+			if msg.ProtoReflect().GetUnknown() != nil {
+				t.Errorf("some of the input was treated as unknown fields")
+			}
+
+			// A dynamic message must behave the same way.
+			dyn := dynamicpb.NewMessage(msg.ProtoReflect().Descriptor())
+			if err := proto.Unmarshal(raw, dyn); err != nil {
+				t.Fatalf("failed to decode dynamic message: %s", err)
+			}
+			got, err = FromProtobufMessage(dyn)
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			if !want.RawEquals(got) {
+				t.Errorf(
+					"wrong result for dynamic message\ngot: %s\nwant: %s",
+					ctydebug.ValueString(got),
+					ctydebug.ValueString(want),
+				)
+			}
+		})
+	}
+}
+
+func TestFromProtobufMessageMaxDepth(t *testing.T) {
+	// A linked list far deeper than any reasonable stack would allow us
+	// to recurse, which must be rejected at the limit rather than
+	// recursing all the way down.
+	const length = 100000
+	const maxDepth = 100
+	head := &testproto.Recursive{Name: "0"}
+	tail := head
+	for i := 1; i < length; i++ {
+		tail.Next = &testproto.Recursive{}
+		tail = tail.Next
+	}
+
+	_, err := FromProtobufMessage(head.ProtoReflect(), WithMaxDepth(maxDepth))
+	if err == nil {
+		t.Fatalf("succeeded; want error")
+	}
+	if got, want := err.Error(), "message is nested more than 100 levels deep"; !strings.HasSuffix(got, want) {
+		t.Errorf("wrong error\ngot:  %s\nwant suffix: %s", got, want)
+	}
+	pathErr, ok := err.(cty.PathError)
+	if !ok {
+		t.Fatalf("error is %T, not cty.PathError: %s", err, err.Error())
+	}
+	if got, want := len(pathErr.Path), maxDepth+1; got != want {
+		t.Errorf("error path has %d steps; want %d", got, want)
+	}
+
+	// A list that fits within the limit converts successfully, with
+	// the field that would exceed the limit represented as a null.
+	short := &testproto.Recursive{
+		Name: "a",
+		Next: &testproto.Recursive{Name: "b"},
+	}
+	got, err := FromProtobufMessage(short.ProtoReflect(), WithMaxDepth(1))
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	want := cty.ObjectVal(map[string]cty.Value{
+		"name": cty.StringVal("a"),
+		"next": cty.ObjectVal(map[string]cty.Value{
+			"name": cty.StringVal("b"),
+			"next": cty.NullVal(cty.DynamicPseudoType),
+		}),
+	})
+	if !want.RawEquals(got) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestFromProtobufMessageDefaultMessages(t *testing.T) {
+	defaultNested := cty.ObjectVal(map[string]cty.Value{
+		"t_nested_field": cty.StringVal(""),
+	})
+
+	absent := &testproto.Assorted{}
+	got, err := FromProtobufMessage(absent.ProtoReflect(), WithDefaultMessages())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if got := got.GetAttr("t_message"); !defaultNested.RawEquals(got) {
+		t.Errorf("wrong value for absent message\ngot:  %#v\nwant: %#v", got, defaultNested)
+	}
+
+	// Converting back leaves the field unset again.
+	into := &testproto.Assorted{}
+	if err := ToProtobufMessage(got, into.ProtoReflect(), WithDefaultMessages()); err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if into.TMessage != nil {
+		t.Errorf("absent message became present after round-trip")
+	}
+
+	// A message with a populated field is still set, of course.
+	attrs := got.AsValueMap()
+	attrs["t_message"] = cty.ObjectVal(map[string]cty.Value{
+		"t_nested_field": cty.StringVal("hello"),
+	})
+	got = cty.ObjectVal(attrs)
+	into = &testproto.Assorted{}
+	if err := ToProtobufMessage(got, into.ProtoReflect(), WithDefaultMessages()); err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if into.TMessage == nil || into.TMessage.TNestedField != "hello" {
+		t.Errorf("wrong nested message after round-trip: %#v", into.TMessage)
+	}
+
+	// Oneof members remain null, so that it's clear which one is set.
+	got, err = FromProtobufMessage((&testproto.WithOneOf{}).ProtoReflect(), WithDefaultMessages())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if got := got.GetAttr("a"); !got.IsNull() {
+		t.Errorf("absent oneof member is not null: %#v", got)
+	}
+
+	// A recursive message expands only as far as the maximum depth.
+	got, err = FromProtobufMessage((&testproto.Recursive{}).ProtoReflect(), WithDefaultMessages(), WithMaxDepth(1))
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	want := cty.ObjectVal(map[string]cty.Value{
+		"name": cty.StringVal(""),
+		"next": cty.ObjectVal(map[string]cty.Value{
+			"name": cty.StringVal(""),
+			"next": cty.NullVal(cty.DynamicPseudoType),
+		}),
+	})
+	if !want.RawEquals(got) {
+		t.Errorf("wrong result for recursive message\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestFromProtobufMessageNullValue(t *testing.T) {
+	msg := &testproto.WithNullValue{
+		TNull:  structpb.NullValue_NULL_VALUE,
+		TNulls: []structpb.NullValue{structpb.NullValue_NULL_VALUE},
+	}
+
+	t.Run("with WithWellKnownStruct", func(t *testing.T) {
+		opts := []Option{WithWellKnownStruct()}
+		ty, err := ImpliedTypeForMessageDesc(msg.ProtoReflect().Descriptor(), opts...)
+		if err != nil {
+			t.Fatalf("unexpected error from ImpliedTypeForMessageDesc\ngot: %s", err.Error())
+		}
+		got, err := FromProtobufMessage(msg.ProtoReflect(), opts...)
+		if err != nil {
+			t.Fatalf("unexpected error from FromProtobufMessage\ngot: %s", err.Error())
+		}
+		want := cty.ObjectVal(map[string]cty.Value{
+			"t_null": cty.NullVal(cty.DynamicPseudoType),
+			"t_nulls": cty.ListVal([]cty.Value{
+				cty.NullVal(cty.DynamicPseudoType),
+			}),
+		})
+		if !want.RawEquals(got) {
+			t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+		}
+		if errs := got.Type().TestConformance(ty); len(errs) != 0 {
+			t.Errorf("result does not conform to implied type: %s", errs[0])
+		}
+
+		into := &testproto.WithNullValue{}
+		if err := ToProtobufMessage(got, into.ProtoReflect(), opts...); err != nil {
+			t.Fatalf("unexpected error from ToProtobufMessage\ngot: %s", err.Error())
+		}
+		if diff := cmp.Diff(msg, into, protocmp.Transform()); diff != "" {
+			t.Errorf("wrong result after round-trip\n%s", diff)
+		}
+
+		err = ToProtobufMessage(cty.ObjectVal(map[string]cty.Value{
+			"t_null":  cty.StringVal("NULL_VALUE"),
+			"t_nulls": cty.ListValEmpty(cty.DynamicPseudoType),
+		}), into.ProtoReflect(), opts...)
+		if err == nil {
+			t.Fatalf("ToProtobufMessage succeeded with non-null value; want error")
+		}
+		if got, want := err.Error(), "must be null"; got != want {
+			t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+		}
+		if pathErr, ok := err.(cty.PathError); !ok || !pathErr.Path.Equals(cty.GetAttrPath("t_null")) {
+			t.Errorf("wrong error path for %#v", err)
+		}
+	})
+	t.Run("without WithWellKnownStruct", func(t *testing.T) {
+		// Without the option, NullValue is an enum like any other.
+		got, err := FromProtobufMessage(msg.ProtoReflect())
+		if err != nil {
+			t.Fatalf("unexpected error from FromProtobufMessage\ngot: %s", err.Error())
+		}
+		want := cty.ObjectVal(map[string]cty.Value{
+			"t_null": cty.StringVal("NULL_VALUE"),
+			"t_nulls": cty.ListVal([]cty.Value{
+				cty.StringVal("NULL_VALUE"),
+			}),
+		})
+		if !want.RawEquals(got) {
+			t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+		}
+	})
+}
+
+func TestInt64AsStrings(t *testing.T) {
+	opts := []Option{WithInt64AsStrings()}
+
+	t.Run("assorted", func(t *testing.T) {
+		msg := &testproto.Assorted{
+			TInt32:    -5,
+			TInt64:    math.MinInt64,
+			TUint64:   math.MaxUint64,
+			TSint64:   -9007199254740993, // not representable as float64
+			TFixed64:  9007199254740993,
+			TSfixed64: math.MaxInt64,
+		}
+		got, gotTy, err := DecodeMessage(msg.ProtoReflect(), opts...)
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		for name, want := range map[string]cty.Value{
+			"t_int32":    cty.NumberIntVal(-5),
+			"t_uint32":   cty.NumberIntVal(0),
+			"t_int64":    cty.StringVal("-9223372036854775808"),
+			"t_uint64":   cty.StringVal("18446744073709551615"),
+			"t_sint64":   cty.StringVal("-9007199254740993"),
+			"t_fixed64":  cty.StringVal("9007199254740993"),
+			"t_sfixed64": cty.StringVal("9223372036854775807"),
+		} {
+			if got := got.GetAttr(name); !want.RawEquals(got) {
+				t.Errorf("wrong value for %s\ngot:  %#v\nwant: %#v", name, got, want)
+			}
+		}
+		if !gotTy.Equals(got.Type()) {
+			t.Errorf(
+				"result does not conform to implied type\ngot:  %s\nwant: %s",
+				ctydebug.TypeString(got.Type()),
+				ctydebug.TypeString(gotTy),
+			)
+		}
+
+		back := &testproto.Assorted{}
+		err = ToProtobufMessage(got, back.ProtoReflect(), opts...)
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		if diff := cmp.Diff(msg, back, protocmp.Transform()); diff != "" {
+			t.Errorf("wrong result after round-trip\n%s", diff)
+		}
+	})
+	t.Run("map keys", func(t *testing.T) {
+		msg := &testproto.WithRepeated{
+			TMapNumberBool: map[int64]bool{-1: true, 10: false},
+		}
+		got, err := FromProtobufMessage(msg.ProtoReflect(), opts...)
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		want := cty.SetVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{
+				"key":   cty.StringVal("-1"),
+				"value": cty.True,
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"key":   cty.StringVal("10"),
+				"value": cty.False,
+			}),
+		})
+		if got := got.GetAttr("t_map_number_bool"); !want.RawEquals(got) {
+			t.Errorf(
+				"wrong result\ngot: %s\nwant: %s",
+				ctydebug.ValueString(got),
+				ctydebug.ValueString(want),
+			)
+		}
+
+		back := &testproto.WithRepeated{}
+		err = ToProtobufMessage(got, back.ProtoReflect(), opts...)
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		if diff := cmp.Diff(msg, back, protocmp.Transform()); diff != "" {
+			t.Errorf("wrong result after round-trip\n%s", diff)
+		}
+	})
+	t.Run("wrapper", func(t *testing.T) {
+		opts := append([]Option{WithWellKnownHandlers(NewWellKnownHandlers())}, opts...)
+		msg := &testproto.WithWellKnown{TInt64Value: wrapperspb.Int64(-7)}
+		got, err := FromProtobufMessage(msg.ProtoReflect(), opts...)
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		if got, want := got.GetAttr("t_int64_value"), cty.StringVal("-7"); !want.RawEquals(got) {
+			t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+		}
+	})
+	t.Run("number accepted", func(t *testing.T) {
+		v, err := FromProtobufMessage((&testproto.Assorted{}).ProtoReflect(), opts...)
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		attrs := v.AsValueMap()
+		attrs["t_int64"] = cty.NumberIntVal(12)
+		got := &testproto.Assorted{}
+		err = ToProtobufMessage(cty.ObjectVal(attrs), got.ProtoReflect(), opts...)
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		if got, want := got.TInt64, int64(12); got != want {
+			t.Errorf("wrong result %d; want %d", got, want)
+		}
+	})
+	t.Run("invalid string", func(t *testing.T) {
+		v, err := FromProtobufMessage((&testproto.Assorted{}).ProtoReflect(), opts...)
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		attrs := v.AsValueMap()
+		attrs["t_uint64"] = cty.StringVal("lots")
+		err = ToProtobufMessage(cty.ObjectVal(attrs), (&testproto.Assorted{}).ProtoReflect(), opts...)
+		if err == nil {
+			t.Fatalf("succeeded with invalid string; want error")
+		}
+		if got, want := err.Error(), `a number is required, or a string containing a number, but "lots" is not a number`; got != want {
+			t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+		}
+		pathErr, ok := err.(cty.PathError)
+		if !ok {
+			t.Fatalf("error is %T, not cty.PathError: %s", err, err.Error())
+		}
+		if wantPath := cty.GetAttrPath("t_uint64"); !pathErr.Path.Equals(wantPath) {
+			t.Errorf("wrong error path\ngot:  %#v\nwant: %#v", pathErr.Path, wantPath)
+		}
+	})
+}
+
+func TestFromProtobufMessageTraceFunc(t *testing.T) {
+	type traced struct {
+		Path cty.Path
+		Kind protoreflect.Kind
+		V    cty.Value
+	}
+	var got []traced
+	trace := func(path cty.Path, kind protoreflect.Kind, v cty.Value) {
+		got = append(got, traced{path, kind, v})
+	}
+
+	msg := &testproto.Recursive{
+		Name: "outer",
+		Next: &testproto.Recursive{Name: "inner"},
+	}
+	_, err := FromProtobufMessage(msg.ProtoReflect(), WithMaxDepth(2), WithTraceFunc(trace))
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+
+	// The fields of the nested message come before the field containing
+	// that message, because each one is reported once it's complete.
+	nestedNext := cty.NullVal(cty.Object(map[string]cty.Type{
+		"name": cty.String,
+		"next": cty.DynamicPseudoType,
+	}))
+	want := []traced{
+		{cty.GetAttrPath("name"), protoreflect.StringKind, cty.StringVal("outer")},
+		{cty.GetAttrPath("next").GetAttr("name"), protoreflect.StringKind, cty.StringVal("inner")},
+		{cty.GetAttrPath("next").GetAttr("next"), protoreflect.MessageKind, nestedNext},
+		{cty.GetAttrPath("next"), protoreflect.MessageKind, cty.ObjectVal(map[string]cty.Value{
+			"name": cty.StringVal("inner"),
+			"next": nestedNext,
+		})},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("wrong number of calls %d; want %d\ngot: %#v", len(got), len(want), got)
+	}
+	for i := range want {
+		if !got[i].Path.Equals(want[i].Path) || got[i].Kind != want[i].Kind || !got[i].V.RawEquals(want[i].V) {
+			t.Errorf("wrong call %d\ngot:  %#v\nwant: %#v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestFromProtobufMessageMaxLen(t *testing.T) {
+	tests := map[string]struct {
+		Input    protoreflect.ProtoMessage
+		Options  []Option
+		WantErr  string
+		WantPath cty.Path
+	}{
+		"string at limit": {
+			Input:   &testproto.Assorted{TString: "hello"},
+			Options: []Option{WithMaxStringLen(5)},
+		},
+		"string over limit": {
+			Input:    &testproto.Assorted{TString: "hello!"},
+			Options:  []Option{WithMaxStringLen(5)},
+			WantErr:  "string is 6 bytes long, which exceeds the maximum of 5",
+			WantPath: cty.GetAttrPath("t_string"),
+		},
+		"string limit counts bytes": {
+			Input:    &testproto.Assorted{TString: "héllo"},
+			Options:  []Option{WithMaxStringLen(5)},
+			WantErr:  "string is 6 bytes long, which exceeds the maximum of 5",
+			WantPath: cty.GetAttrPath("t_string"),
+		},
+		"repeated string over limit": {
+			Input:    &testproto.WithRepeated{TStrings: []string{"ok", "too long"}},
+			Options:  []Option{WithMaxStringLen(2)},
+			WantErr:  "string is 8 bytes long, which exceeds the maximum of 2",
+			WantPath: cty.GetAttrPath("t_strings").IndexInt(1),
+		},
+		"string in repeated message over limit": {
+			Input: &testproto.WithRepeated{TMessage: []*testproto.WithRepeated_Nested{
+				{TNestedField: "ok"},
+				{TNestedField: "ok"},
+				{TNestedField: "too long"},
+			}},
+			Options:  []Option{WithMaxStringLen(2)},
+			WantErr:  "string is 8 bytes long, which exceeds the maximum of 2",
+			WantPath: cty.GetAttrPath("t_message").IndexInt(2).GetAttr("t_nested_field"),
+		},
+		"map key over limit": {
+			Input:    &testproto.WithRepeated{TMapStringBool: map[string]bool{"ok": true, "too long": false}},
+			Options:  []Option{WithMaxStringLen(3)},
+			WantErr:  "map key is 8 bytes long, which exceeds the maximum of 3",
+			WantPath: cty.GetAttrPath("t_map_string_bool"),
+		},
+		"map key at limit": {
+			Input:   &testproto.WithRepeated{TMapStringBool: map[string]bool{"abc": true}},
+			Options: []Option{WithMaxStringLen(3)},
+		},
+		"bytes at limit": {
+			Input:   &testproto.Assorted{TBytes: []byte{1, 2, 3}},
+			Options: []Option{WithMaxBytesLen(3)},
+		},
+		"bytes over limit": {
+			Input:    &testproto.Assorted{TBytes: []byte{1, 2, 3, 4}},
+			Options:  []Option{WithMaxBytesLen(3)},
+			WantErr:  "value is 4 bytes long, which exceeds the maximum of 3",
+			WantPath: cty.GetAttrPath("t_bytes"),
+		},
+		"bytes over limit as UTF-8": {
+			Input:    &testproto.Assorted{TBytes: []byte("abcd")},
+			Options:  []Option{WithMaxBytesLen(3), WithBytesAsUTF8Strings()},
+			WantErr:  "value is 4 bytes long, which exceeds the maximum of 3",
+			WantPath: cty.GetAttrPath("t_bytes"),
+		},
+		"string limit ignores bytes": {
+			Input:   &testproto.Assorted{TBytes: []byte("abcd")},
+			Options: []Option{WithMaxStringLen(3)},
+		},
+		"wrapped string over limit": {
+			Input:    &testproto.WithWellKnown{TStringValue: wrapperspb.String("hello!")},
+			Options:  []Option{WithMaxStringLen(5), WithWellKnownHandlers(NewWellKnownHandlers())},
+			WantErr:  "string is 6 bytes long, which exceeds the maximum of 5",
+			WantPath: cty.GetAttrPath("t_string_value"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := FromProtobufMessage(test.Input.ProtoReflect(), test.Options...)
+			if test.WantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error\ngot: %s", err.Error())
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("succeeded; want error\nwant: %s", test.WantErr)
+			}
+			if got, want := err.Error(), test.WantErr; got != want {
+				t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+			}
+			pathErr, ok := err.(cty.PathError)
+			if !ok {
+				t.Fatalf("error is %T, not cty.PathError: %s", err, err.Error())
+			}
+			if !pathErr.Path.Equals(test.WantPath) {
+				t.Errorf("wrong error path\ngot:  %#v\nwant: %#v", pathErr.Path, test.WantPath)
+			}
+		})
+	}
+}
+
+func TestFromProtobufMessageSourceModified(t *testing.T) {
+	// The result must not share memory with the source message, so that
+	// modifying the message afterwards can't change the result.
+	tests := map[string][]Option{
+		"base64":         nil,
+		"UTF-8":          {WithBytesAsUTF8Strings()},
+		"number lists":   {WithBytesAsNumberLists()},
+		"unknown fields": {WithPreserveUnknownFields()},
+		"capsule":        {WithBytesCapsule(), WithPreserveUnknownFields()},
+	}
+	for name, opts := range tests {
+		t.Run(name, func(t *testing.T) {
+			msg := &testproto.Assorted{TBytes: []byte("abc")}
+			raw := protowire.AppendTag(nil, 99, protowire.VarintType)
+			raw = protowire.AppendVarint(raw, 0)
+			msg.ProtoReflect().SetUnknown(raw)
+			got, err := FromProtobufMessage(msg.ProtoReflect(), opts...)
+			if err != nil {
 				t.Fatalf("unexpected error\ngot: %s", err.Error())
 			}
+			want, err := FromProtobufMessage(proto.Clone(msg).ProtoReflect(), opts...)
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+
+			copy(msg.TBytes, "xyz")
+			raw[len(raw)-1] = 1
+
+			if !want.RawEquals(got) {
+				t.Errorf(
+					"result changed after modifying the message\ngot: %s\nwant: %s",
+					ctydebug.ValueString(got),
+					ctydebug.ValueString(want),
+				)
+			}
+		})
+	}
+}
+
+func TestFromProtobufMessageConcurrent(t *testing.T) {
+	// Converting the same message from several goroutines at once must be
+	// safe, including the package-level caches and pools that the
+	// conversions share. This is most useful with the race detector
+	// enabled, as in "go test -race".
+	msg := &testproto.WithComplexMap{
+		TMapStringComplex: map[string]*testproto.WithComplexMap_Complex{
+			"a": {Inner: &testproto.WithComplexMap_Complex_Inner{Name: "a", Data: []byte("data")}},
+		},
+		TMapNumberComplex: map[int64]*testproto.WithComplexMap_Complex{
+			1: {Inner: &testproto.WithComplexMap_Complex_Inner{Name: "b"}},
+		},
+	}
+	want, err := FromProtobufMessage(msg.ProtoReflect())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+
+	const n = 8
+	results := make(chan cty.Value, n)
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			v, err := FromProtobufMessage(msg.ProtoReflect())
+			if err != nil {
+				errs <- err
+				return
+			}
+			results <- v
+		}()
+	}
+	for i := 0; i < n; i++ {
+		select {
+		case err := <-errs:
+			t.Errorf("unexpected error\ngot: %s", err.Error())
+		case got := <-results:
+			if !want.RawEquals(got) {
+				t.Errorf(
+					"wrong result\ngot: %s\nwant: %s",
+					ctydebug.ValueString(got),
+					ctydebug.ValueString(want),
+				)
+			}
+		}
+	}
+}
+
+func TestFromProtobufMessageEmptyMessages(t *testing.T) {
+	// A message type with no fields is represented as cty.EmptyObjectVal
+	// in every position, and must survive a round trip in each of them.
+	wantTy := cty.Object(map[string]cty.Type{
+		"t_empty":        cty.EmptyObject,
+		"t_empty_map":    cty.Map(cty.EmptyObject),
+		"t_empty_list":   cty.List(cty.EmptyObject),
+		"t_empty_choice": cty.EmptyObject,
+		"t_other_choice": cty.String,
+	})
+	tests := map[string]struct {
+		Input *testproto.WithEmpty
+		Want  cty.Value
+	}{
+		"all absent": {
+			Input: &testproto.WithEmpty{},
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"t_empty":        cty.NullVal(cty.EmptyObject),
+				"t_empty_map":    cty.MapValEmpty(cty.EmptyObject),
+				"t_empty_list":   cty.ListValEmpty(cty.EmptyObject),
+				"t_empty_choice": cty.NullVal(cty.EmptyObject),
+				"t_other_choice": cty.NullVal(cty.String),
+			}),
+		},
+		"all present": {
+			Input: &testproto.WithEmpty{
+				TEmpty:     &testproto.Empty{},
+				TEmptyMap:  map[string]*testproto.Empty{"a": {}, "b": {}},
+				TEmptyList: []*testproto.Empty{{}, {}},
+				TChoice:    &testproto.WithEmpty_TEmptyChoice{TEmptyChoice: &testproto.Empty{}},
+			},
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"t_empty": cty.EmptyObjectVal,
+				"t_empty_map": cty.MapVal(map[string]cty.Value{
+					"a": cty.EmptyObjectVal,
+					"b": cty.EmptyObjectVal,
+				}),
+				"t_empty_list":   cty.ListVal([]cty.Value{cty.EmptyObjectVal, cty.EmptyObjectVal}),
+				"t_empty_choice": cty.EmptyObjectVal,
+				"t_other_choice": cty.NullVal(cty.String),
+			}),
+		},
+		"other oneof member": {
+			Input: &testproto.WithEmpty{
+				TChoice: &testproto.WithEmpty_TOtherChoice{TOtherChoice: "other"},
+			},
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"t_empty":        cty.NullVal(cty.EmptyObject),
+				"t_empty_map":    cty.MapValEmpty(cty.EmptyObject),
+				"t_empty_list":   cty.ListValEmpty(cty.EmptyObject),
+				"t_empty_choice": cty.NullVal(cty.EmptyObject),
+				"t_other_choice": cty.StringVal("other"),
+			}),
+		},
+	}
 
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, gotTy, err := DecodeMessage(test.Input.ProtoReflect())
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			if !wantTy.Equals(gotTy) {
+				t.Errorf(
+					"wrong type\ngot: %s\nwant: %s",
+					ctydebug.TypeString(gotTy),
+					ctydebug.TypeString(wantTy),
+				)
+			}
 			if !test.Want.RawEquals(got) {
 				t.Errorf(
 					"wrong result\ngot: %s\nwant: %s",
@@ -347,7 +2350,228 @@ func TestFromProtobufMessage(t *testing.T) {
 					ctydebug.ValueString(test.Want),
 				)
 			}
+
+			back := &testproto.WithEmpty{}
+			err = ToProtobufMessage(got, back.ProtoReflect())
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			if diff := cmp.Diff(test.Input, back, protocmp.Transform()); diff != "" {
+				t.Errorf("wrong result after round-trip\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("top level", func(t *testing.T) {
+		got, err := FromProtobufMessage((&testproto.Empty{}).ProtoReflect())
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		if !cty.EmptyObjectVal.RawEquals(got) {
+			t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, cty.EmptyObjectVal)
+		}
+		err = ToProtobufMessage(cty.EmptyObjectVal, (&testproto.Empty{}).ProtoReflect())
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+	})
+}
+
+func TestFromProtobufMessageEnumMap(t *testing.T) {
+	desc := (*testproto.WithEnumMap)(nil).ProtoReflect().Descriptor()
+	wantTy := cty.Object(map[string]cty.Type{
+		"t_colors": cty.Map(cty.String),
+		"t_colors_by_number": cty.Set(cty.Object(map[string]cty.Type{
+			"key":   cty.Number,
+			"value": cty.String,
+		})),
+	})
+	gotTy, err := ImpliedTypeForMessageDesc(desc)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if !wantTy.Equals(gotTy) {
+		t.Fatalf(
+			"wrong type\ngot: %s\nwant: %s",
+			ctydebug.TypeString(gotTy),
+			ctydebug.TypeString(wantTy),
+		)
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		msg := &testproto.WithEnumMap{
+			TColors: map[string]testproto.Color{
+				"sky":   testproto.Color_COLOR_UNSPECIFIED,
+				"grass": testproto.Color_GREEN,
+			},
+			TColorsByNumber: map[int64]testproto.Color{
+				1: testproto.Color_RED,
+			},
+		}
+		got, err := FromProtobufMessage(msg.ProtoReflect())
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		want := cty.ObjectVal(map[string]cty.Value{
+			"t_colors": cty.MapVal(map[string]cty.Value{
+				"sky":   cty.StringVal("COLOR_UNSPECIFIED"),
+				"grass": cty.StringVal("GREEN"),
+			}),
+			"t_colors_by_number": cty.SetVal([]cty.Value{
+				cty.ObjectVal(map[string]cty.Value{
+					"key":   cty.NumberIntVal(1),
+					"value": cty.StringVal("RED"),
+				}),
+			}),
+		})
+		if !want.RawEquals(got) {
+			t.Errorf(
+				"wrong result\ngot: %s\nwant: %s",
+				ctydebug.ValueString(got),
+				ctydebug.ValueString(want),
+			)
+		}
+
+		back := &testproto.WithEnumMap{}
+		err = ToProtobufMessage(got, back.ProtoReflect())
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		if diff := cmp.Diff(msg, back, protocmp.Transform()); diff != "" {
+			t.Errorf("wrong result after round-trip\n%s", diff)
+		}
+	})
+	t.Run("undefined number with string key", func(t *testing.T) {
+		msg := &testproto.WithEnumMap{
+			TColors: map[string]testproto.Color{"sky": 9},
+		}
+		_, err := FromProtobufMessage(msg.ProtoReflect())
+		if err == nil {
+			t.Fatalf("succeeded with undefined enum number; want error")
+		}
+		if got, want := err.Error(), "value 9 is not part of the enumeration"; got != want {
+			t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+		}
+		pathErr, ok := err.(cty.PathError)
+		if !ok {
+			t.Fatalf("error is %T, not cty.PathError: %s", err, err.Error())
+		}
+		wantPath := cty.GetAttrPath("t_colors").Index(cty.StringVal("sky"))
+		if !pathErr.Path.Equals(wantPath) {
+			t.Errorf("wrong error path\ngot:  %#v\nwant: %#v", pathErr.Path, wantPath)
+		}
+	})
+	t.Run("undefined number with number key", func(t *testing.T) {
+		msg := &testproto.WithEnumMap{
+			TColorsByNumber: map[int64]testproto.Color{1: 9},
+		}
+		_, err := FromProtobufMessage(msg.ProtoReflect())
+		if err == nil {
+			t.Fatalf("succeeded with undefined enum number; want error")
+		}
+		pathErr, ok := err.(cty.PathError)
+		if !ok {
+			t.Fatalf("error is %T, not cty.PathError: %s", err, err.Error())
+		}
+		// The set element isn't known until it's been built, so the
+		// element step uses cty.DynamicVal as a placeholder, which doesn't
+		// equal itself.
+		if got, want := len(pathErr.Path), 3; got != want {
+			t.Fatalf("wrong error path length %d; want %d\ngot: %#v", got, want, pathErr.Path)
+		}
+		if step, ok := pathErr.Path[1].(cty.IndexStep); !ok || step.Key.IsKnown() {
+			t.Errorf("wrong element step %#v; want placeholder", pathErr.Path[1])
+		}
+		if step, ok := pathErr.Path[2].(cty.GetAttrStep); !ok || step.Name != "value" {
+			t.Errorf("wrong final step %#v; want value attribute", pathErr.Path[2])
+		}
+	})
+	t.Run("unknown keyword", func(t *testing.T) {
+		obj := cty.ObjectVal(map[string]cty.Value{
+			"t_colors": cty.MapVal(map[string]cty.Value{
+				"sky": cty.StringVal("BLUE"),
+			}),
+			"t_colors_by_number": cty.SetValEmpty(wantTy.AttributeType("t_colors_by_number").ElementType()),
 		})
+		err := ToProtobufMessage(obj, (&testproto.WithEnumMap{}).ProtoReflect())
+		if err == nil {
+			t.Fatalf("succeeded with unknown keyword; want error")
+		}
+		if got, want := err.Error(), "value isn't one of the expected keywords"; got != want {
+			t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+		}
+		pathErr, ok := err.(cty.PathError)
+		if !ok {
+			t.Fatalf("error is %T, not cty.PathError: %s", err, err.Error())
+		}
+		wantPath := cty.GetAttrPath("t_colors").Index(cty.StringVal("sky"))
+		if !pathErr.Path.Equals(wantPath) {
+			t.Errorf("wrong error path\ngot:  %#v\nwant: %#v", pathErr.Path, wantPath)
+		}
+	})
+}
+
+func TestInt64AsStringsJSON(t *testing.T) {
+	// With WithInt64AsStrings, the JSON encoding of a cty value uses the
+	// same quoted representation of the 64-bit integers as the protocol
+	// buffers JSON mapping, and so survives a round trip through JSON
+	// without any loss of precision, even for software that decodes JSON
+	// numbers as float64.
+	opts := []Option{WithInt64AsStrings()}
+	msg := &testproto.Assorted{
+		TInt64:   math.MinInt64 + 1,
+		TUint64:  math.MaxUint64 - 1,
+		TFixed64: 9007199254740993, // not representable as float64
+	}
+	v, ty, err := DecodeMessage(msg.ProtoReflect(), opts...)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	js, err := ctyjson.Marshal(v, ty)
+	if err != nil {
+		t.Fatalf("unexpected error from ctyjson.Marshal\ngot: %s", err.Error())
+	}
+
+	pbJS, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+	if err != nil {
+		t.Fatalf("unexpected error from protojson.Marshal\ngot: %s", err.Error())
+	}
+	var gotAttrs, wantAttrs map[string]interface{}
+	if err := json.Unmarshal(js, &gotAttrs); err != nil {
+		t.Fatalf("invalid JSON from ctyjson.Marshal: %s", err)
+	}
+	if err := json.Unmarshal(pbJS, &wantAttrs); err != nil {
+		t.Fatalf("invalid JSON from protojson.Marshal: %s", err)
+	}
+	for _, name := range []string{"t_int64", "t_uint64", "t_fixed64"} {
+		if got, want := gotAttrs[name], wantAttrs[name]; got != want {
+			t.Errorf("wrong JSON for %s\ngot:  %#v\nwant: %#v", name, got, want)
+		}
 	}
 
+	t.Run("through cty", func(t *testing.T) {
+		got, err := ctyjson.Unmarshal(js, ty)
+		if err != nil {
+			t.Fatalf("unexpected error from ctyjson.Unmarshal\ngot: %s", err.Error())
+		}
+		back := &testproto.Assorted{}
+		err = ToProtobufMessage(got, back.ProtoReflect(), opts...)
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		if diff := cmp.Diff(msg, back, protocmp.Transform()); diff != "" {
+			t.Errorf("wrong result after round-trip\n%s", diff)
+		}
+	})
+	t.Run("through protojson", func(t *testing.T) {
+		// The JSON from the cty value is also valid input for protojson.
+		back := &testproto.Assorted{}
+		err := protojson.Unmarshal(js, back)
+		if err != nil {
+			t.Fatalf("unexpected error from protojson.Unmarshal\ngot: %s", err.Error())
+		}
+		if diff := cmp.Diff(msg, back, protocmp.Transform()); diff != "" {
+			t.Errorf("wrong result after round-trip\n%s", diff)
+		}
+	})
 }
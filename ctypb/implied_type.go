@@ -8,9 +8,11 @@ import (
 // ImpliedTypeForMessageDesc returns a cty.Type which corresponds to the given
 // protocol buffers message descriptor.
 //
-// The result will always be an object type, whose attributes each correspond
-// to fields of the message descriptor. The types of those attributes will
-// depend on the definitions of each field.
+// The result is an object type, whose attributes each correspond to fields
+// of the message descriptor. The types of those attributes will depend on
+// the definitions of each field. The only exception is when the
+// WithWellKnownHandlers option includes a handler for the given message
+// type itself, in which case the result is the handler's type.
 //
 // The conversion from protobuf schema to cty is lossy, because cty and
 // protobuf do not have all concepts in common. In particular, the conversion
@@ -26,31 +28,164 @@ import (
 //
 // If ImpliedTypeForMessageDesc returns an error then it might be a
 // cty.PathError referring to a specific sub-path within the generated type.
-func ImpliedTypeForMessageDesc(desc protoreflect.MessageDescriptor) (cty.Type, error) {
-	path := make(cty.Path, 0, 4) // four levels deep without further allocation
-	ty, err := impliedTypeForMessageDesc(desc, path)
-	return ty, err
+//
+// ImpliedTypeForMessageDesc pays attention to the following options:
+//   - WithBytesCapsule
+//   - WithWellKnownStruct
+//   - WithExtensions
+//   - WithEnumObjects
+//   - WithPreserveUnknownFields
+//   - WithBytesAsUTF8Strings
+//   - WithBytesAsNumberLists
+//   - WithInt64AsStrings
+//   - WithWellKnownHandlers
+//   - WithMessageCapsule
+//   - WithFieldNameFunc
+//   - WithFieldConverters
+//   - WithMaxDepth
+//   - WithOneofDiscriminator
+func ImpliedTypeForMessageDesc(desc protoreflect.MessageDescriptor, opts ...Option) (cty.Type, error) {
+	return Converter{opts: makeOptions(opts)}.ImpliedType(desc)
+}
+
+// NullValueForMessageDesc returns a null value of the type that
+// ImpliedTypeForMessageDesc would return for the same descriptor and
+// options, which can be useful as a placeholder or as a conversion target
+// when preparing to decode a message.
+//
+// Pass the same options that will be used for the eventual conversion, so
+// that the type of the null value matches the conversion result. To
+// instead get a non-null object whose attributes are null or empty, use
+// TemplateValueForMessageDesc.
+func NullValueForMessageDesc(desc protoreflect.MessageDescriptor, opts ...Option) (cty.Value, error) {
+	ty, err := ImpliedTypeForMessageDesc(desc, opts...)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	return cty.NullVal(ty), nil
+}
+
+// TemplateValueForMessageDesc returns a non-null object of the type that
+// ImpliedTypeForMessageDesc would return for the same descriptor and
+// options, which can serve as a starting point for building a value to
+// pass to ToProtobufMessage, such as in a user interface that presents a
+// form to fill in.
+//
+// Each attribute of the result corresponding to a repeated or map field is
+// an empty collection, and every other attribute is null, including those
+// for nested messages.
+//
+// If the implied type is not an object type, which is possible only when
+// the WithWellKnownHandlers option includes a handler for the message type
+// itself, then the result is a null value of that type, as with
+// NullValueForMessageDesc.
+func TemplateValueForMessageDesc(desc protoreflect.MessageDescriptor, opts ...Option) (cty.Value, error) {
+	ty, err := ImpliedTypeForMessageDesc(desc, opts...)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	if !ty.IsObjectType() {
+		return cty.NullVal(ty), nil
+	}
+	atys := ty.AttributeTypes()
+	attrs := make(map[string]cty.Value, len(atys))
+	for name, aty := range atys {
+		switch {
+		case aty.IsListType():
+			attrs[name] = cty.ListValEmpty(aty.ElementType())
+		case aty.IsMapType():
+			attrs[name] = cty.MapValEmpty(aty.ElementType())
+		case aty.IsSetType():
+			attrs[name] = cty.SetValEmpty(aty.ElementType())
+		default:
+			attrs[name] = cty.NullVal(aty)
+		}
+	}
+	return cty.ObjectVal(attrs), nil
+}
+
+func impliedTypeForMessageDesc(desc protoreflect.MessageDescriptor, opts *options, path cty.Path) (ty cty.Type, err error) {
+	cache := opts.impliedTypeCache()
+	if cache == nil {
+		return impliedTypeForMessageDescUncached(desc, opts, path)
+	}
+	if ty, ok := cache.get(desc, opts); ok {
+		return ty, nil
+	}
+	ty, err = impliedTypeForMessageDescUncached(desc, opts, path)
+	if err != nil {
+		return cty.NilType, err
+	}
+	cache.put(desc, opts, ty)
+	return ty, nil
 }
 
-func impliedTypeForMessageDesc(desc protoreflect.MessageDescriptor, path cty.Path) (ty cty.Type, err error) {
+func impliedTypeForMessageDescUncached(desc protoreflect.MessageDescriptor, opts *options, path cty.Path) (ty cty.Type, err error) {
 	fields := desc.Fields()
 	atys := make(map[string]cty.Type, fields.Len())
 	for i := 0; i < fields.Len(); i++ {
 		field := fields.Get(i)
-		name := string(field.Name())
+		name := opts.fieldAttrName(field)
+		if _, exists := atys[name]; exists {
+			return cty.NilType, path.NewErrorf("more than one field has the attribute name %q", name)
+		}
 
 		// Temporarily extend path with new attribute name
 		path := append(path, cty.GetAttrStep{Name: name})
-		aty, err := impliedTypeForFieldDesc(field, path)
+		aty, err := impliedTypeForFieldDesc(field, opts, path)
 		if err != nil {
 			return cty.NilType, err
 		}
 		atys[name] = aty
 	}
+
+	if opts.extensionTypes != nil {
+		// Extension fields are named by their full names, to avoid
+		// conflicts with the message's own fields and with extensions
+		// from other packages.
+		opts.extensionTypes.RangeExtensionsByMessage(desc.FullName(), func(xt protoreflect.ExtensionType) bool {
+			field := xt.TypeDescriptor()
+			name := string(field.FullName())
+			if _, exists := atys[name]; exists {
+				// Only possible if a FieldNameFunc chose the same name.
+				err = path.NewErrorf("more than one field has the attribute name %q", name)
+				return false
+			}
+
+			// Temporarily extend path with new attribute name
+			path := append(path, cty.GetAttrStep{Name: name})
+			var aty cty.Type
+			aty, err = impliedTypeForFieldDesc(field, opts, path)
+			if err != nil {
+				return false
+			}
+			atys[name] = aty
+			return true
+		})
+		if err != nil {
+			return cty.NilType, err
+		}
+	}
+
+	for _, oneof := range discriminatedOneofs(desc, opts) {
+		name := string(oneof.Name())
+		if _, exists := atys[name]; exists {
+			return cty.NilType, path.NewErrorf("attribute %q for oneof discriminator conflicts with a field of the same name", name)
+		}
+		atys[name] = cty.String
+	}
+
+	if name := opts.unknownFieldsAttr; name != "" {
+		if _, exists := atys[name]; exists {
+			return cty.NilType, path.NewErrorf("attribute %q for unknown fields conflicts with a field of the same name", name)
+		}
+		atys[name] = impliedTypeForBytes(opts)
+	}
+
 	return cty.Object(atys), nil
 }
 
-func impliedTypeForFieldDesc(field protoreflect.FieldDescriptor, path cty.Path) (ty cty.Type, err error) {
+func impliedTypeForFieldDesc(field protoreflect.FieldDescriptor, opts *options, path cty.Path) (ty cty.Type, err error) {
 	isRepeated := field.Cardinality() == protoreflect.Repeated
 
 	if isRepeated {
@@ -62,27 +197,33 @@ func impliedTypeForFieldDesc(field protoreflect.FieldDescriptor, path cty.Path)
 		// that's the closest approximation of that intent which we
 		// can achieve in cty.
 		if field.IsMap() {
-			sub := field.Message()
-			subFields := sub.Fields()
-			keyField := subFields.ByNumber(1)
-			valField := subFields.ByNumber(2)
+			keyField, valField, err := mapEntryFields(field, path)
+			if err != nil {
+				return cty.NilType, err
+			}
+			if valueTypeVaries(valField, opts, nil) {
+				// The elements can each have a different type, so
+				// FromProtobufMessage may produce an object or tuple whose
+				// type we can't predict until we have a value.
+				return cty.DynamicPseudoType, nil
+			}
 			switch {
 			case keyField.Kind() == protoreflect.StringKind:
 				// Temporarily extend path with placeholder for indexing.
 				path := append(path, cty.IndexStep{Key: cty.UnknownVal(cty.String)})
-				valTy, err := impliedTypeForFieldDesc(valField, path)
+				valTy, err := impliedTypeForFieldDesc(valField, opts, path)
 				if err != nil {
 					return cty.NilType, err
 				}
 				return cty.Map(valTy), nil
 			default:
-				keyTy, err := impliedTypeForFieldDesc(keyField, path)
+				keyTy, err := impliedTypeForFieldDesc(keyField, opts, path)
 				if err != nil {
 					return cty.NilType, err
 				}
 				// Temporarily extend path with placeholder for indexing.
 				path := append(path, cty.IndexStep{Key: cty.UnknownVal(keyTy)})
-				valTy, err := impliedTypeForFieldDesc(valField, path)
+				valTy, err := impliedTypeForFieldDesc(valField, opts, path)
 				if err != nil {
 					return cty.NilType, err
 				}
@@ -95,35 +236,198 @@ func impliedTypeForFieldDesc(field protoreflect.FieldDescriptor, path cty.Path)
 	}
 
 	// Determine the base type, ignoring cardinality for now.
-	aty, err := impliedTypeForFieldKind(field, path)
+	aty, err := impliedTypeForFieldKind(field, opts, path)
 	if err != nil {
 		return cty.NilType, err
 	}
 
 	// If the field is "repeated" and it didn't match our special case for
 	// maps above then the result is a list of the base type we already
-	// determined.
+	// determined, unless the elements can each have a different type, in
+	// which case FromProtobufMessage may produce a tuple whose type we
+	// can't predict until we have a value.
 	if isRepeated {
+		if valueTypeVaries(field, opts, nil) {
+			return cty.DynamicPseudoType, nil
+		}
 		return cty.List(aty), nil
 	}
 	return aty, nil
 }
 
+// typeDependsOnValue returns true if the implied type of the values of the
+// given field's kind is cty.DynamicPseudoType because the type of each value
+// depends on its content, as for the message types that WithWellKnownStruct
+// applies to. The elements of a map or repeated field of such a kind can
+// have different types, so they can't become a cty map, list, or set, and
+// so such a field becomes an object or tuple instead, whose implied type is
+// also cty.DynamicPseudoType.
+func typeDependsOnValue(field protoreflect.FieldDescriptor, opts *options) bool {
+	if field.Kind() != protoreflect.MessageKind && field.Kind() != protoreflect.GroupKind {
+		return false
+	}
+	if handler, ty := wellKnownHandlerFor(field.Message(), opts); handler != nil {
+		return ty == cty.DynamicPseudoType
+	}
+	return opts.wellKnownStruct && isWellKnownStruct(field.Message())
+}
+
+// valueTypeVaries returns true if the values of the given field's kind can
+// have different types depending on their content, either because
+// typeDependsOnValue returns true for it or because it's a message field
+// whose message type contains such a field, at any depth. The elements of a
+// map or repeated field of such a kind might not all have the same type, so
+// the field's implied type is cty.DynamicPseudoType.
+//
+// The visiting argument records the message types whose fields are already
+// being checked, to avoid endless recursion for recursive message types, and
+// may be nil on the initial call.
+func valueTypeVaries(field protoreflect.FieldDescriptor, opts *options, visiting map[protoreflect.FullName]bool) bool {
+	if typeDependsOnValue(field, opts) {
+		return true
+	}
+	if field.Kind() != protoreflect.MessageKind && field.Kind() != protoreflect.GroupKind {
+		return false
+	}
+	desc := field.Message()
+	if handler, _ := wellKnownHandlerFor(desc, opts); handler != nil {
+		return false
+	}
+	if opts.wellKnownStruct && isWellKnownStruct(desc) {
+		return false
+	}
+	if visiting[desc.FullName()] {
+		return false
+	}
+	if visiting == nil {
+		visiting = make(map[protoreflect.FullName]bool)
+	}
+	visiting[desc.FullName()] = true
+	fields := desc.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		if valueTypeVaries(fields.Get(i), opts, visiting) {
+			return true
+		}
+	}
+	varies := false
+	if opts.extensionTypes != nil {
+		opts.extensionTypes.RangeExtensionsByMessage(desc.FullName(), func(xt protoreflect.ExtensionType) bool {
+			varies = valueTypeVaries(xt.TypeDescriptor(), opts, visiting)
+			return !varies
+		})
+	}
+	return varies
+}
+
+// mapEntryFields returns the key and value fields of the map entry message
+// for the given map field, or an error if the map entry message doesn't
+// have the shape that protocol buffers requires.
+//
+// Descriptors produced by the protocol buffers compiler or by package
+// protodesc are always valid, but other implementations of the descriptor
+// interfaces might not be, and so we check rather than risk panicking.
+func mapEntryFields(field protoreflect.FieldDescriptor, path cty.Path) (keyField, valField protoreflect.FieldDescriptor, err error) {
+	entry := field.Message()
+	if entry == nil {
+		return nil, nil, path.NewErrorf("map field %s has no map entry message", field.FullName())
+	}
+	fields := entry.Fields()
+	keyField = fields.ByNumber(1)
+	valField = fields.ByNumber(2)
+	switch {
+	case fields.Len() != 2 || keyField == nil || valField == nil:
+		return nil, nil, path.NewErrorf("map field %s has malformed map entry message %s: must have exactly two fields, numbered 1 and 2", field.FullName(), entry.FullName())
+	case keyField.Cardinality() == protoreflect.Repeated || valField.Cardinality() == protoreflect.Repeated:
+		return nil, nil, path.NewErrorf("map field %s has malformed map entry message %s: key and value fields must not be repeated", field.FullName(), entry.FullName())
+	}
+	switch keyField.Kind() {
+	case protoreflect.BoolKind, protoreflect.StringKind,
+		protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return keyField, valField, nil
+	default:
+		return nil, nil, path.NewErrorf("map field %s has malformed map entry message %s: keys of kind %s are not allowed", field.FullName(), entry.FullName(), keyField.Kind())
+	}
+}
+
 // impliedTypeForFieldKind determines a corresponding type for the given
 // field's kind (and optionally, nested message type) while disregarding
 // the cardinality.
-func impliedTypeForFieldKind(field protoreflect.FieldDescriptor, path cty.Path) (ty cty.Type, err error) {
+func impliedTypeForFieldKind(field protoreflect.FieldDescriptor, opts *options, path cty.Path) (ty cty.Type, err error) {
+	if conv, err := fieldConverterFor(field, opts, path); conv != nil || err != nil {
+		if err != nil {
+			return cty.NilType, err
+		}
+		return conv.Type(field), nil
+	}
+	if opts.int64AsStrings && isInt64Kind(field.Kind()) {
+		return cty.String, nil
+	}
 	switch kind := field.Kind(); kind {
 	case protoreflect.BoolKind:
 		return cty.Bool, nil
 	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Uint32Kind, protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Uint64Kind, protoreflect.Sfixed32Kind, protoreflect.Fixed32Kind, protoreflect.FloatKind, protoreflect.Sfixed64Kind, protoreflect.Fixed64Kind, protoreflect.DoubleKind:
 		return cty.Number, nil
-	case protoreflect.StringKind, protoreflect.BytesKind, protoreflect.EnumKind:
+	case protoreflect.StringKind:
 		return cty.String, nil
+	case protoreflect.EnumKind:
+		if isWellKnownNullValue(field, opts) {
+			return cty.DynamicPseudoType, nil
+		}
+		if opts.enumObjects {
+			return enumObjectType, nil
+		}
+		return cty.String, nil
+	case protoreflect.BytesKind:
+		if opts.bytesAsUTF8 {
+			return cty.String, nil
+		}
+		if opts.bytesAsNumbers {
+			return cty.List(cty.Number), nil
+		}
+		return impliedTypeForBytes(opts), nil
 	case protoreflect.MessageKind, protoreflect.GroupKind:
-		// The type is that of the nested message descriptor.
-		return impliedTypeForMessageDesc(field.Message(), path)
+		// The type is that of the nested message descriptor, unless
+		// there's a well-known type handler for it.
+		if handler, ty := wellKnownHandlerFor(field.Message(), opts); handler != nil {
+			return ty, nil
+		}
+		if opts.wellKnownStruct && isWellKnownStruct(field.Message()) {
+			// These can contain arbitrary JSON-like data, so we can't
+			// predict the type until we have a value.
+			return cty.DynamicPseudoType, nil
+		}
+		nested, tooDeep := opts.nestedMessage()
+		if tooDeep {
+			// Values of this field must always be null, so the type
+			// doesn't matter and we must not recurse any further.
+			return cty.DynamicPseudoType, nil
+		}
+		return impliedTypeForMessageDesc(field.Message(), nested, reservePath(path))
+	default:
+		return cty.NilType, path.NewErrorf("field %s has protobuf kind %s, which has no cty equivalent", field.FullName(), kind.String())
+	}
+}
+
+// impliedTypeForBytes returns the type used to represent a sequence of
+// bytes, which depends on whether the bytes capsule option is enabled.
+func impliedTypeForBytes(opts *options) cty.Type {
+	if opts.bytesCapsule {
+		return BytesCapsuleType
+	}
+	return cty.String
+}
+
+// isInt64Kind returns true if the given kind is one of the 64-bit integer
+// kinds that WithInt64AsStrings represents as strings.
+func isInt64Kind(kind protoreflect.Kind) bool {
+	switch kind {
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return true
 	default:
-		return cty.NilType, path.NewErrorf("no cty equivalent for protobuf kind %s", kind.String())
+		return false
 	}
 }
@@ -0,0 +1,53 @@
+package ctypb
+
+import (
+	"sync"
+
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// impliedTypeCache is a global cache of the results of
+// impliedTypeForMessageDesc, because applications tend to convert messages
+// of the same few types many times over and the implied type of a message
+// descriptor never changes.
+var impliedTypeCache typeCache
+
+// cacheImpliedTypes can be set to false to disable impliedTypeCache, so
+// that tests can exercise the uncached codepath.
+var cacheImpliedTypes = true
+
+// typeCache is a concurrency-safe cache of implied types for message
+// descriptors. The zero value is an empty cache ready to use.
+type typeCache struct {
+	types sync.Map // of typeCacheKey to cty.Type
+}
+
+// typeCacheKey is the key type for typeCache.
+//
+// We key by the descriptor itself rather than by its full name because
+// applications which load descriptors at runtime might have several
+// different versions of a message type with the same name, such as when
+// comparing an old and a new version of a schema.
+type typeCacheKey struct {
+	desc protoreflect.MessageDescriptor
+	opts typeOptions
+}
+
+func (c *typeCache) get(desc protoreflect.MessageDescriptor, opts *options) (cty.Type, bool) {
+	if !cacheImpliedTypes {
+		return cty.NilType, false
+	}
+	ty, ok := c.types.Load(typeCacheKey{desc, opts.typeOptions()})
+	if !ok {
+		return cty.NilType, false
+	}
+	return ty.(cty.Type), true
+}
+
+func (c *typeCache) put(desc protoreflect.MessageDescriptor, opts *options, ty cty.Type) {
+	if !cacheImpliedTypes {
+		return
+	}
+	c.types.Store(typeCacheKey{desc, opts.typeOptions()}, ty)
+}
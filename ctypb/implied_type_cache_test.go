@@ -0,0 +1,87 @@
+package ctypb
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/zclconf/go-cty-protobuf/internal/testproto"
+)
+
+func TestImpliedTypeCache(t *testing.T) {
+	var cache typeCache
+	desc := (*testproto.Assorted)(nil).ProtoReflect().Descriptor()
+	plain := makeOptions(nil)
+	capsule := makeOptions([]Option{WithBytesCapsule()})
+
+	if _, ok := cache.get(desc, plain); ok {
+		t.Fatalf("empty cache returned a result")
+	}
+	cache.put(desc, plain, cty.EmptyObject)
+	if got, ok := cache.get(desc, plain); !ok || !got.Equals(cty.EmptyObject) {
+		t.Fatalf("wrong result from cache: %#v, %t", got, ok)
+	}
+	if _, ok := cache.get(desc, capsule); ok {
+		t.Fatalf("cache returned a result for different type options")
+	}
+	if _, ok := cache.get((*testproto.Simple)(nil).ProtoReflect().Descriptor(), plain); ok {
+		t.Fatalf("cache returned a result for different descriptor")
+	}
+}
+
+func TestImpliedTypeCacheExtensions(t *testing.T) {
+	// An extension registry may gain more extensions between calls, so
+	// the package-level functions must not return a type cached from
+	// before.
+	types := &protoregistry.Types{}
+	if err := types.RegisterExtension(testproto.E_ExtString); err != nil {
+		t.Fatal(err)
+	}
+	desc := (*testproto.Extendable)(nil).ProtoReflect().Descriptor()
+	ty, err := ImpliedTypeForMessageDesc(desc, WithExtensions(types))
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if ty.HasAttribute("testproto.ext_numbers") {
+		t.Fatalf("type has attribute for unregistered extension")
+	}
+
+	if err := types.RegisterExtension(testproto.E_ExtNumbers); err != nil {
+		t.Fatal(err)
+	}
+	ty, err = ImpliedTypeForMessageDesc(desc, WithExtensions(types))
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if !ty.HasAttribute("testproto.ext_numbers") {
+		t.Errorf("type has no attribute for newly-registered extension")
+	}
+}
+
+func BenchmarkImpliedTypeForMessageDesc(b *testing.B) {
+	desc := (*testproto.WithRepeated)(nil).ProtoReflect().Descriptor()
+
+	b.Run("cached", func(b *testing.B) {
+		benchmarkImpliedTypeForMessageDesc(b, desc, true)
+	})
+	b.Run("uncached", func(b *testing.B) {
+		benchmarkImpliedTypeForMessageDesc(b, desc, false)
+	})
+}
+
+func benchmarkImpliedTypeForMessageDesc(b *testing.B, desc protoreflect.MessageDescriptor, cached bool) {
+	defer func(prev bool) {
+		cacheImpliedTypes = prev
+	}(cacheImpliedTypes)
+	cacheImpliedTypes = cached
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, err := ImpliedTypeForMessageDesc(desc)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
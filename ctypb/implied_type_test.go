@@ -1,11 +1,14 @@
 package ctypb
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/zclconf/go-cty-debug/ctydebug"
 	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
 
 	"github.com/zclconf/go-cty-protobuf/internal/testproto"
 )
@@ -13,6 +16,7 @@ import (
 func TestImpliedTypeForMessageDesc(t *testing.T) {
 	tests := []struct {
 		Input   protoreflect.MessageDescriptor
+		Options []Option
 		Want    cty.Type
 		WantErr string
 	}{
@@ -39,6 +43,30 @@ func TestImpliedTypeForMessageDesc(t *testing.T) {
 				"t_uint64":   cty.Number,
 			}),
 		},
+		{
+			Input:   (*testproto.Assorted)(nil).ProtoReflect().Descriptor(),
+			Options: []Option{WithBytesCapsule()},
+			Want: cty.Object(map[string]cty.Type{
+				"t_bool":    cty.Bool,
+				"t_bytes":   BytesCapsuleType,
+				"t_double":  cty.Number,
+				"t_fixed32": cty.Number,
+				"t_fixed64": cty.Number,
+				"t_float":   cty.Number,
+				"t_int32":   cty.Number,
+				"t_int64":   cty.Number,
+				"t_message": cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				}),
+				"t_sfixed32": cty.Number,
+				"t_sfixed64": cty.Number,
+				"t_sint32":   cty.Number,
+				"t_sint64":   cty.Number,
+				"t_string":   cty.String,
+				"t_uint32":   cty.Number,
+				"t_uint64":   cty.Number,
+			}),
+		},
 		{
 			Input: (*testproto.WithOptional)(nil).ProtoReflect().Descriptor(),
 			// "optional" has no effect on the implied type, because
@@ -135,11 +163,78 @@ func TestImpliedTypeForMessageDesc(t *testing.T) {
 				"t_string": cty.String,
 			}),
 		},
+		{
+			Input: (*testproto.Extendable)(nil).ProtoReflect().Descriptor(),
+			Want: cty.Object(map[string]cty.Type{
+				"name": cty.String,
+			}),
+		},
+		{
+			Input:   (*testproto.Extendable)(nil).ProtoReflect().Descriptor(),
+			Options: []Option{WithExtensions(testExtensionTypes())},
+			Want: cty.Object(map[string]cty.Type{
+				"name":                  cty.String,
+				"testproto.ext_string":  cty.String,
+				"testproto.ext_numbers": cty.List(cty.Number),
+			}),
+		},
+		{
+			Input:   (*testproto.Simple)(nil).ProtoReflect().Descriptor(),
+			Options: []Option{WithPreserveUnknownFieldsAttr("extra")},
+			Want: cty.Object(map[string]cty.Type{
+				"foo": cty.Object(map[string]cty.Type{
+					"extra": cty.String,
+				}),
+				"extra": cty.String,
+			}),
+		},
+		{
+			Input:   (*testproto.Simple)(nil).ProtoReflect().Descriptor(),
+			Options: []Option{WithPreserveUnknownFieldsAttr("foo")},
+			WantErr: `attribute "foo" for unknown fields conflicts with a field of the same name`,
+		},
+		{
+			Input:   (*testproto.WithStruct)(nil).ProtoReflect().Descriptor(),
+			Options: []Option{WithWellKnownStruct()},
+			Want: cty.Object(map[string]cty.Type{
+				"t_struct":     cty.DynamicPseudoType,
+				"t_value":      cty.DynamicPseudoType,
+				"t_list_value": cty.DynamicPseudoType,
+			}),
+		},
+		{
+			// The elements of these collections can each have a different
+			// type, so the collections may become objects or tuples.
+			Input:   (*testproto.WithStructCollections)(nil).ProtoReflect().Descriptor(),
+			Options: []Option{WithWellKnownStruct()},
+			Want: cty.Object(map[string]cty.Type{
+				"t_value_map":        cty.DynamicPseudoType,
+				"t_value_number_map": cty.DynamicPseudoType,
+				"t_values":           cty.DynamicPseudoType,
+				"t_structs":          cty.DynamicPseudoType,
+			}),
+		},
+		{
+			Input:   (*testproto.WithOneOf)(nil).ProtoReflect().Descriptor(),
+			Options: []Option{WithFieldNameFunc(stripFieldNamePrefix)},
+			Want: cty.Object(map[string]cty.Type{
+				"A":       cty.String,
+				"B":       cty.String,
+				"OUTSIDE": cty.String,
+			}),
+		},
+		{
+			Input: (*testproto.WithOneOf)(nil).ProtoReflect().Descriptor(),
+			Options: []Option{WithFieldNameFunc(func(field protoreflect.FieldDescriptor) string {
+				return "same"
+			})},
+			WantErr: `more than one field has the attribute name "same"`,
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(string(test.Input.FullName()), func(t *testing.T) {
-			got, err := ImpliedTypeForMessageDesc(test.Input)
+			got, err := ImpliedTypeForMessageDesc(test.Input, test.Options...)
 
 			if test.WantErr != "" {
 				if err == nil {
@@ -163,3 +258,409 @@ func TestImpliedTypeForMessageDesc(t *testing.T) {
 		})
 	}
 }
+
+func TestNullValueForMessageDesc(t *testing.T) {
+	desc := (*testproto.Assorted)(nil).ProtoReflect().Descriptor()
+	for _, opts := range [][]Option{nil, {WithBytesCapsule()}} {
+		got, err := NullValueForMessageDesc(desc, opts...)
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		wantTy, err := ImpliedTypeForMessageDesc(desc, opts...)
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		if !got.IsNull() {
+			t.Errorf("result is not null")
+		}
+		if !got.Type().Equals(wantTy) {
+			t.Errorf(
+				"wrong type\ngot: %s\nwant: %s",
+				ctydebug.TypeString(got.Type()),
+				ctydebug.TypeString(wantTy),
+			)
+		}
+	}
+}
+
+func TestTemplateValueForMessageDesc(t *testing.T) {
+	tests := map[string]struct {
+		Desc protoreflect.MessageDescriptor
+		Opts []Option
+		Want cty.Value
+	}{
+		"repeated": {
+			Desc: (*testproto.WithRepeated)(nil).ProtoReflect().Descriptor(),
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"t_map_number_bool": cty.SetValEmpty(cty.Object(map[string]cty.Type{
+					"key":   cty.Number,
+					"value": cty.Bool,
+				})),
+				"t_map_number_message": cty.SetValEmpty(cty.Object(map[string]cty.Type{
+					"key": cty.Number,
+					"value": cty.Object(map[string]cty.Type{
+						"t_nested_field": cty.String,
+					}),
+				})),
+				"t_map_string_bool": cty.MapValEmpty(cty.Bool),
+				"t_map_string_message": cty.MapValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_message": cty.ListValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_strings": cty.ListValEmpty(cty.String),
+			}),
+		},
+		"optional": {
+			Desc: (*testproto.WithOptional)(nil).ProtoReflect().Descriptor(),
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"int32_opt":   cty.NullVal(cty.Number),
+				"int32_req":   cty.NullVal(cty.Number),
+				"message_opt": cty.NullVal(cty.EmptyObject),
+				"message_req": cty.NullVal(cty.EmptyObject),
+				"string_opt":  cty.NullVal(cty.String),
+				"string_req":  cty.NullVal(cty.String),
+			}),
+		},
+		"bytes capsule": {
+			Desc: (*testproto.Assorted)(nil).ProtoReflect().Descriptor(),
+			Opts: []Option{WithBytesCapsule()},
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"t_bool":    cty.NullVal(cty.Bool),
+				"t_bytes":   cty.NullVal(BytesCapsuleType),
+				"t_double":  cty.NullVal(cty.Number),
+				"t_fixed32": cty.NullVal(cty.Number),
+				"t_fixed64": cty.NullVal(cty.Number),
+				"t_float":   cty.NullVal(cty.Number),
+				"t_int32":   cty.NullVal(cty.Number),
+				"t_int64":   cty.NullVal(cty.Number),
+				"t_message": cty.NullVal(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_sfixed32": cty.NullVal(cty.Number),
+				"t_sfixed64": cty.NullVal(cty.Number),
+				"t_sint32":   cty.NullVal(cty.Number),
+				"t_sint64":   cty.NullVal(cty.Number),
+				"t_string":   cty.NullVal(cty.String),
+				"t_uint32":   cty.NullVal(cty.Number),
+				"t_uint64":   cty.NullVal(cty.Number),
+			}),
+		},
+		"empty": {
+			Desc: (*testproto.Empty)(nil).ProtoReflect().Descriptor(),
+			Want: cty.EmptyObjectVal,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := TemplateValueForMessageDesc(test.Desc, test.Opts...)
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			if !test.Want.RawEquals(got) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+			wantTy, err := ImpliedTypeForMessageDesc(test.Desc, test.Opts...)
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			if !got.Type().Equals(wantTy) {
+				t.Errorf(
+					"wrong type\ngot: %s\nwant: %s",
+					ctydebug.TypeString(got.Type()),
+					ctydebug.TypeString(wantTy),
+				)
+			}
+		})
+	}
+}
+
+// testExtensionTypes returns a registry containing only the extensions
+// defined in the testproto package, for use with WithExtensions.
+func testExtensionTypes() *protoregistry.Types {
+	ret := &protoregistry.Types{}
+	for _, xt := range []protoreflect.ExtensionType{testproto.E_ExtString, testproto.E_ExtNumbers} {
+		err := ret.RegisterExtension(xt)
+		if err != nil {
+			panic(err)
+		}
+	}
+	return ret
+}
+
+// stripFieldNamePrefix is a FieldNameFunc which removes any "t_" prefix from
+// the field name and converts the remainder to uppercase.
+func stripFieldNamePrefix(field protoreflect.FieldDescriptor) string {
+	return strings.ToUpper(strings.TrimPrefix(string(field.Name()), "t_"))
+}
+
+func TestUnsupportedFieldKind(t *testing.T) {
+	// All of the kinds that protoc can generate have a cty equivalent, so
+	// we simulate an unsupported kind by overriding the kind of a real
+	// field descriptor.
+	realField := (*testproto.Assorted)(nil).ProtoReflect().Descriptor().Fields().ByName("t_string")
+	field := fakeKindField{realField, protoreflect.Kind(0)}
+	path := cty.GetAttrPath("t_string")
+	want := "field testproto.Assorted.t_string has protobuf kind <unknown:0>, which has no cty equivalent"
+
+	_, err := impliedTypeForFieldKind(field, defaultOptions, path)
+	if err == nil {
+		t.Fatalf("impliedTypeForFieldKind succeeded; want error")
+	}
+	if got := err.Error(); got != want {
+		t.Errorf("wrong error from impliedTypeForFieldKind\ngot:  %s\nwant: %s", got, want)
+	}
+
+	_, err = fromProtobufFieldKindValue(protoreflect.ValueOfString("hello"), field, defaultOptions, path)
+	if err == nil {
+		t.Fatalf("fromProtobufFieldKindValue succeeded; want error")
+	}
+	if got := err.Error(); got != want {
+		t.Errorf("wrong error from fromProtobufFieldKindValue\ngot:  %s\nwant: %s", got, want)
+	}
+
+	_, err = toProtobufValue(cty.StringVal("hello"), field, nil, defaultOptions, path)
+	if err == nil {
+		t.Fatalf("toProtobufValue succeeded; want error")
+	}
+	if got := err.Error(); got != want {
+		t.Errorf("wrong error from toProtobufValue\ngot:  %s\nwant: %s", got, want)
+	}
+	if pathErr, ok := err.(cty.PathError); !ok || !pathErr.Path.Equals(path) {
+		t.Errorf("error does not refer to the field's path")
+	}
+}
+
+// fakeKindField is a protoreflect.FieldDescriptor that reports a different
+// kind than the descriptor it wraps.
+type fakeKindField struct {
+	protoreflect.FieldDescriptor
+	kind protoreflect.Kind
+}
+
+func (f fakeKindField) Kind() protoreflect.Kind {
+	return f.kind
+}
+
+func TestMalformedMapEntry(t *testing.T) {
+	// The protocol buffers compiler and package protodesc both refuse to
+	// produce malformed map entry messages, so we simulate one by
+	// overriding the fields of a real map entry message.
+	realField := (*testproto.WithRepeated)(nil).ProtoReflect().Descriptor().Fields().ByName("t_map_string_bool")
+	realEntry := realField.Message()
+	keyField := realEntry.Fields().ByNumber(1)
+	valField := realEntry.Fields().ByNumber(2)
+	path := cty.GetAttrPath("t_map_string_bool")
+
+	tests := map[string]struct {
+		Fields  []protoreflect.FieldDescriptor
+		WantErr string
+	}{
+		"missing value": {
+			[]protoreflect.FieldDescriptor{keyField},
+			"map field testproto.WithRepeated.t_map_string_bool has malformed map entry message testproto.WithRepeated.TMapStringBoolEntry: must have exactly two fields, numbered 1 and 2",
+		},
+		"extra field": {
+			[]protoreflect.FieldDescriptor{keyField, valField, fakeNumberField{valField, 3}},
+			"map field testproto.WithRepeated.t_map_string_bool has malformed map entry message testproto.WithRepeated.TMapStringBoolEntry: must have exactly two fields, numbered 1 and 2",
+		},
+		"repeated value": {
+			[]protoreflect.FieldDescriptor{keyField, fakeRepeatedField{valField}},
+			"map field testproto.WithRepeated.t_map_string_bool has malformed map entry message testproto.WithRepeated.TMapStringBoolEntry: key and value fields must not be repeated",
+		},
+		"bytes key": {
+			[]protoreflect.FieldDescriptor{fakeKindField{keyField, protoreflect.BytesKind}, valField},
+			"map field testproto.WithRepeated.t_map_string_bool has malformed map entry message testproto.WithRepeated.TMapStringBoolEntry: keys of kind bytes are not allowed",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			field := fakeMapField{realField, fakeFieldsMessage{realEntry, test.Fields}}
+
+			_, err := impliedTypeForFieldDesc(field, defaultOptions, path)
+			if err == nil {
+				t.Fatalf("impliedTypeForFieldDesc succeeded; want error")
+			}
+			if got := err.Error(); got != test.WantErr {
+				t.Errorf("wrong error from impliedTypeForFieldDesc\ngot:  %s\nwant: %s", got, test.WantErr)
+			}
+
+			msg := (&testproto.WithRepeated{TMapStringBool: map[string]bool{"a": true}}).ProtoReflect()
+			_, err = fromProtobufFieldValue(msg.Get(realField), field, defaultOptions, path)
+			if err == nil {
+				t.Fatalf("fromProtobufFieldValue succeeded; want error")
+			}
+			if got := err.Error(); got != test.WantErr {
+				t.Errorf("wrong error from fromProtobufFieldValue\ngot:  %s\nwant: %s", got, test.WantErr)
+			}
+
+			v := cty.MapVal(map[string]cty.Value{"a": cty.True})
+			err = toProtobufMessageField(msg, field, v, defaultOptions, path)
+			if err == nil {
+				t.Fatalf("toProtobufMessageField succeeded; want error")
+			}
+			if got := err.Error(); got != test.WantErr {
+				t.Errorf("wrong error from toProtobufMessageField\ngot:  %s\nwant: %s", got, test.WantErr)
+			}
+			if pathErr, ok := err.(cty.PathError); !ok || !pathErr.Path.Equals(path) {
+				t.Errorf("error does not refer to the field's path")
+			}
+		})
+	}
+}
+
+// fakeMapField is a protoreflect.FieldDescriptor that reports a different
+// map entry message than the descriptor it wraps.
+type fakeMapField struct {
+	protoreflect.FieldDescriptor
+	entry protoreflect.MessageDescriptor
+}
+
+func (f fakeMapField) Message() protoreflect.MessageDescriptor {
+	return f.entry
+}
+
+// fakeFieldsMessage is a protoreflect.MessageDescriptor that reports a
+// different set of fields than the descriptor it wraps.
+type fakeFieldsMessage struct {
+	protoreflect.MessageDescriptor
+	fields []protoreflect.FieldDescriptor
+}
+
+func (m fakeFieldsMessage) Fields() protoreflect.FieldDescriptors {
+	return fakeFieldList{m.MessageDescriptor.Fields(), m.fields}
+}
+
+// fakeFieldList is a protoreflect.FieldDescriptors containing an arbitrary
+// list of fields.
+type fakeFieldList struct {
+	protoreflect.FieldDescriptors
+	fields []protoreflect.FieldDescriptor
+}
+
+func (l fakeFieldList) Len() int {
+	return len(l.fields)
+}
+
+func (l fakeFieldList) Get(i int) protoreflect.FieldDescriptor {
+	return l.fields[i]
+}
+
+func (l fakeFieldList) ByNumber(n protoreflect.FieldNumber) protoreflect.FieldDescriptor {
+	for _, f := range l.fields {
+		if f.Number() == n {
+			return f
+		}
+	}
+	return nil
+}
+
+// fakeNumberField is a protoreflect.FieldDescriptor that reports a
+// different field number than the descriptor it wraps.
+type fakeNumberField struct {
+	protoreflect.FieldDescriptor
+	number protoreflect.FieldNumber
+}
+
+func (f fakeNumberField) Number() protoreflect.FieldNumber {
+	return f.number
+}
+
+// fakeRepeatedField is a protoreflect.FieldDescriptor that reports repeated
+// cardinality regardless of the cardinality of the descriptor it wraps.
+type fakeRepeatedField struct {
+	protoreflect.FieldDescriptor
+}
+
+func (f fakeRepeatedField) Cardinality() protoreflect.Cardinality {
+	return protoreflect.Repeated
+}
+
+func TestExtensionAttributeNameCollision(t *testing.T) {
+	// Extension attributes are named by their full names, which can only
+	// collide with another field's attribute when a FieldNameFunc chooses
+	// the same name, but we must still detect that rather than letting one
+	// of the two silently replace the other.
+	opts := []Option{
+		WithExtensions(testExtensionTypes()),
+		WithFieldNameFunc(func(field protoreflect.FieldDescriptor) string {
+			if field.Name() == "name" {
+				return "testproto.ext_string"
+			}
+			return string(field.Name())
+		}),
+	}
+	want := `more than one field has the attribute name "testproto.ext_string"`
+	desc := (*testproto.Extendable)(nil).ProtoReflect().Descriptor()
+
+	_, err := ImpliedTypeForMessageDesc(desc, opts...)
+	if err == nil {
+		t.Fatalf("ImpliedTypeForMessageDesc succeeded; want error")
+	}
+	if got := err.Error(); got != want {
+		t.Errorf("wrong error from ImpliedTypeForMessageDesc\ngot:  %s\nwant: %s", got, want)
+	}
+
+	msg := &testproto.Extendable{Name: proto.String("hello")}
+	_, err = FromProtobufMessage(msg.ProtoReflect(), opts...)
+	if err == nil {
+		t.Fatalf("FromProtobufMessage succeeded; want error")
+	}
+	if got := err.Error(); got != want {
+		t.Errorf("wrong error from FromProtobufMessage\ngot:  %s\nwant: %s", got, want)
+	}
+
+	v := cty.ObjectVal(map[string]cty.Value{
+		"testproto.ext_string":  cty.StringVal("hello"),
+		"testproto.ext_numbers": cty.ListValEmpty(cty.Number),
+	})
+	err = ToProtobufMessage(v, (&testproto.Extendable{}).ProtoReflect(), opts...)
+	if err == nil {
+		t.Fatalf("ToProtobufMessage succeeded; want error")
+	}
+	if got := err.Error(); got != want {
+		t.Errorf("wrong error from ToProtobufMessage\ngot:  %s\nwant: %s", got, want)
+	}
+
+	_, err = PresenceTrackingAttributes(desc, opts...)
+	if err == nil {
+		t.Fatalf("PresenceTrackingAttributes succeeded; want error")
+	}
+	if got := err.Error(); got != want {
+		t.Errorf("wrong error from PresenceTrackingAttributes\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestImpliedTypeForMessageDescMaxDepth(t *testing.T) {
+	desc := (*testproto.Recursive)(nil).ProtoReflect().Descriptor()
+	got, err := ImpliedTypeForMessageDesc(desc, WithMaxDepth(2))
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	want := cty.Object(map[string]cty.Type{
+		"name": cty.String,
+		"next": cty.Object(map[string]cty.Type{
+			"name": cty.String,
+			"next": cty.Object(map[string]cty.Type{
+				"name": cty.String,
+				"next": cty.DynamicPseudoType,
+			}),
+		}),
+	})
+	if !want.Equals(got) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	// The nested types must not be cached, because they would be wrong for
+	// the same message type at a different depth.
+	got, err = ImpliedTypeForMessageDesc(desc, WithMaxDepth(1))
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if want := want.AttributeType("next"); !want.Equals(got) {
+		t.Errorf("wrong result for smaller limit\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
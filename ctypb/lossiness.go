@@ -0,0 +1,244 @@
+package ctypb
+
+import (
+	"fmt"
+
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// LossinessNote describes one way in which converting messages of a
+// particular type to cty values loses information, as returned by
+// DescribeLossiness.
+type LossinessNote struct {
+	// Path is the path to the affected part of the implied type. Paths
+	// into collections use an unknown value as the index key, as a
+	// placeholder for any element.
+	Path cty.Path
+
+	// Message is a human-readable description of what's lost.
+	Message string
+}
+
+// DescribeLossiness returns notes about the information that's lost when
+// converting messages described by the given descriptor into cty values of
+// the type that ImpliedTypeForMessageDesc returns, given the same options.
+//
+// This is intended to help with deciding whether this package is suitable
+// for a particular schema. The notes cover the numeric kinds all becoming
+// cty.Number, oneofs becoming separate attributes, the representations of
+// bytes and enumeration values, maps with non-string keys becoming sets,
+// and fields that the conversion discards.
+//
+// The notes for each message type appear only for its first occurrence in
+// each branch of the schema, so that recursive message types don't produce
+// an infinite number of notes.
+//
+// DescribeLossiness pays attention to the following options:
+//   - WithBytesCapsule
+//   - WithWellKnownStruct
+//   - WithExtensions
+//   - WithEnumObjects
+//   - WithPreserveUnknownFields
+//   - WithBytesAsUTF8Strings
+//   - WithBytesAsNumberLists
+//   - WithInt64AsStrings
+//   - WithWellKnownHandlers
+//   - WithMessageCapsule
+//   - WithFieldNameFunc
+//   - WithFieldConverters
+//   - WithOneofDiscriminator
+func DescribeLossiness(desc protoreflect.MessageDescriptor, opts ...Option) []LossinessNote {
+	d := lossinessDescriber{
+		opts:   makeOptions(opts),
+		active: make(map[protoreflect.FullName]bool),
+	}
+	if d.opts.unknownFieldsAttr == "" {
+		d.note(nil, "unknown fields in this message and any nested messages are discarded, unless using WithPreserveUnknownFields")
+	}
+	d.message(desc, make(cty.Path, 0, 4))
+	return d.notes
+}
+
+// lossinessDescriber is the state of a single call to DescribeLossiness.
+type lossinessDescriber struct {
+	opts  *options
+	notes []LossinessNote
+
+	// active tracks the message types we're currently describing, so that
+	// we can avoid infinite recursion for recursive message types.
+	active map[protoreflect.FullName]bool
+}
+
+func (d *lossinessDescriber) note(path cty.Path, format string, args ...interface{}) {
+	d.notes = append(d.notes, LossinessNote{
+		Path:    path.Copy(),
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (d *lossinessDescriber) message(desc protoreflect.MessageDescriptor, path cty.Path) {
+	if d.active[desc.FullName()] {
+		d.note(path, "message type %s is recursive, so its notes appear only at its outer occurrence", desc.FullName())
+		return
+	}
+	d.active[desc.FullName()] = true
+	defer delete(d.active, desc.FullName())
+
+	if desc.ExtensionRanges().Len() != 0 && d.opts.extensionTypes == nil {
+		d.note(path, "extension fields of %s are discarded, unless using WithExtensions", desc.FullName())
+	}
+
+	fields := desc.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		path := append(path, cty.GetAttrStep{Name: d.opts.fieldAttrName(field)})
+		d.field(field, path)
+	}
+	if d.opts.extensionTypes != nil {
+		d.opts.extensionTypes.RangeExtensionsByMessage(desc.FullName(), func(xt protoreflect.ExtensionType) bool {
+			field := xt.TypeDescriptor()
+			path := append(path, cty.GetAttrStep{Name: string(field.FullName())})
+			d.field(field, path)
+			return true
+		})
+	}
+}
+
+func (d *lossinessDescriber) field(field protoreflect.FieldDescriptor, path cty.Path) {
+	if oneof := field.ContainingOneof(); oneof != nil && !oneof.IsSynthetic() {
+		if d.opts.oneofDiscriminator {
+			d.note(path, "field belongs to oneof %s, but cty has no equivalent of a oneof, so the type allows more than one of its attributes to be non-null, although the %q attribute records which one is set", oneof.Name(), oneof.Name())
+		} else {
+			d.note(path, "field belongs to oneof %s, but cty has no equivalent of a oneof, so the type allows more than one of its attributes to be non-null", oneof.Name())
+		}
+	}
+
+	switch {
+	case field.IsMap():
+		keyField, valField, err := mapEntryFields(field, path)
+		if err != nil {
+			// The conversion functions would fail for this field.
+			d.note(path, "%s", err)
+			return
+		}
+		if keyField.Kind() == protoreflect.StringKind {
+			path := append(path, cty.IndexStep{Key: cty.UnknownVal(cty.String)})
+			d.fieldKind(valField, path)
+			return
+		}
+		d.note(path, "map with %s keys becomes a set of objects with \"key\" and \"value\" attributes, because cty maps only support string keys", keyField.Kind())
+		path = append(path, cty.IndexStep{Key: cty.DynamicVal})
+		d.fieldKind(keyField, append(path, cty.GetAttrStep{Name: "key"}))
+		d.fieldKind(valField, append(path, cty.GetAttrStep{Name: "value"}))
+	case field.IsList():
+		d.fieldKind(field, append(path, cty.IndexStep{Key: cty.UnknownVal(cty.Number)}))
+	default:
+		d.fieldKind(field, path)
+	}
+}
+
+func (d *lossinessDescriber) fieldKind(field protoreflect.FieldDescriptor, path cty.Path) {
+	if conv, _ := fieldConverterFor(field, d.opts, path); conv != nil {
+		// We can't know what a custom conversion loses, just as for
+		// well-known handlers below.
+		return
+	}
+	if d.opts.int64AsStrings && isInt64Kind(field.Kind()) {
+		d.note(path, "protobuf kind %s becomes a string containing a decimal integer, which the type doesn't distinguish from other strings", field.Kind())
+		return
+	}
+	switch kind := field.Kind(); kind {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		d.note(path, "protobuf kind %s becomes cty.Number, which doesn't distinguish between the numeric kinds or limit values to the range of this one", kind)
+	case protoreflect.FloatKind:
+		d.note(path, "protobuf kind float becomes cty.Number, which can't represent NaN and is more precise than the field, so values are rounded when converting back")
+	case protoreflect.DoubleKind:
+		d.note(path, "protobuf kind double becomes cty.Number, which can't represent NaN")
+	case protoreflect.BytesKind:
+		switch {
+		case d.opts.bytesAsUTF8:
+			d.note(path, "bytes become a string of UTF-8 text, so bytes that aren't valid UTF-8 can't be converted, and text not in Unicode Normal Form C changes when converting back")
+		case d.opts.bytesAsNumbers:
+			d.note(path, "bytes become a list of numbers, which the type doesn't limit to the range of a byte")
+		case !d.opts.bytesCapsule:
+			d.note(path, "bytes become a base64-encoded string, which the type doesn't distinguish from other strings")
+		}
+	case protoreflect.EnumKind:
+		if isWellKnownNullValue(field, d.opts) {
+			d.note(path, "google.protobuf.NullValue becomes null, so a field that tracks presence loses it when converting back")
+			return
+		}
+		if d.opts.enumObjects {
+			// Both the name and the number survive, even for numbers that
+			// the schema doesn't define.
+			return
+		}
+		d.note(path, "enumeration values become strings containing their names, so values that aren't defined in the schema can't be converted")
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if handler, _ := wellKnownHandlerFor(field.Message(), d.opts); handler != nil {
+			return
+		}
+		if d.opts.wellKnownStruct && isWellKnownStruct(field.Message()) {
+			d.note(path, "%s becomes a value of any type, so the type doesn't describe the data's structure", field.Message().FullName())
+			return
+		}
+		d.message(field.Message(), path)
+	}
+}
+
+// ReservedNote describes one reserved field number range or reserved field
+// name of a message type, as returned by ReservedInfo.
+type ReservedNote struct {
+	// Start and End are the first and last field numbers of a reserved
+	// range, inclusive, or both zero if the note is about a reserved name.
+	Start, End protoreflect.FieldNumber
+
+	// Name is the reserved field name, or empty if the note is about a
+	// reserved range.
+	Name protoreflect.Name
+
+	// Message is a human-readable description of the reservation.
+	Message string
+}
+
+// ReservedInfo returns notes about the field numbers and names that the
+// given message type reserves, with the ranges of numbers first and then
+// the names, each in the order that the schema declares them.
+//
+// Reserved fields have no attributes in the implied type, because they
+// aren't fields at all, and so the schema can't carry any data for them.
+// This is purely informational, to complement DescribeLossiness for schema
+// tooling. It covers only the given message type and not any nested message
+// types, which callers can pass to ReservedInfo separately.
+func ReservedInfo(desc protoreflect.MessageDescriptor) []ReservedNote {
+	ranges := desc.ReservedRanges()
+	names := desc.ReservedNames()
+	if ranges.Len() == 0 && names.Len() == 0 {
+		return nil
+	}
+	ret := make([]ReservedNote, 0, ranges.Len()+names.Len())
+	for i := 0; i < ranges.Len(); i++ {
+		r := ranges.Get(i)
+		// The descriptor's ranges have an exclusive end.
+		start, end := r[0], r[1]-1
+		note := ReservedNote{Start: start, End: end}
+		if start == end {
+			note.Message = fmt.Sprintf("field number %d of %s is reserved, so no attribute can represent it", start, desc.FullName())
+		} else {
+			note.Message = fmt.Sprintf("field numbers %d through %d of %s are reserved, so no attributes can represent them", start, end, desc.FullName())
+		}
+		ret = append(ret, note)
+	}
+	for i := 0; i < names.Len(); i++ {
+		name := names.Get(i)
+		ret = append(ret, ReservedNote{
+			Name:    name,
+			Message: fmt.Sprintf("field name %q of %s is reserved, so no attribute can represent it", name, desc.FullName()),
+		})
+	}
+	return ret
+}
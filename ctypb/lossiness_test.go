@@ -0,0 +1,150 @@
+package ctypb
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/zclconf/go-cty-protobuf/internal/testproto"
+)
+
+func TestDescribeLossiness(t *testing.T) {
+	const (
+		unknownFields = "unknown fields in this message and any nested messages are discarded, unless using WithPreserveUnknownFields"
+		base64Bytes   = "bytes become a base64-encoded string, which the type doesn't distinguish from other strings"
+		oneofMember   = "field belongs to oneof t_oneof, but cty has no equivalent of a oneof, so the type allows more than one of its attributes to be non-null"
+	)
+	integer := func(kind string) string {
+		return "protobuf kind " + kind + " becomes cty.Number, which doesn't distinguish between the numeric kinds or limit values to the range of this one"
+	}
+
+	tests := map[string]struct {
+		Desc    protoreflect.MessageDescriptor
+		Options []Option
+		Want    []LossinessNote
+	}{
+		"assorted": {
+			Desc: (*testproto.Assorted)(nil).ProtoReflect().Descriptor(),
+			Want: []LossinessNote{
+				{nil, unknownFields},
+				{cty.GetAttrPath("t_double"), "protobuf kind double becomes cty.Number, which can't represent NaN"},
+				{cty.GetAttrPath("t_float"), "protobuf kind float becomes cty.Number, which can't represent NaN and is more precise than the field, so values are rounded when converting back"},
+				{cty.GetAttrPath("t_int32"), integer("int32")},
+				{cty.GetAttrPath("t_int64"), integer("int64")},
+				{cty.GetAttrPath("t_uint32"), integer("uint32")},
+				{cty.GetAttrPath("t_uint64"), integer("uint64")},
+				{cty.GetAttrPath("t_sint32"), integer("sint32")},
+				{cty.GetAttrPath("t_sint64"), integer("sint64")},
+				{cty.GetAttrPath("t_fixed32"), integer("fixed32")},
+				{cty.GetAttrPath("t_fixed64"), integer("fixed64")},
+				{cty.GetAttrPath("t_sfixed32"), integer("sfixed32")},
+				{cty.GetAttrPath("t_sfixed64"), integer("sfixed64")},
+				{cty.GetAttrPath("t_bytes"), base64Bytes},
+			},
+		},
+		"assorted with options": {
+			Desc:    (*testproto.Assorted)(nil).ProtoReflect().Descriptor(),
+			Options: []Option{WithPreserveUnknownFields(), WithBytesCapsule(), WithFieldNameFunc(JSONFieldName)},
+			Want: []LossinessNote{
+				{cty.GetAttrPath("tDouble"), "protobuf kind double becomes cty.Number, which can't represent NaN"},
+				{cty.GetAttrPath("tFloat"), "protobuf kind float becomes cty.Number, which can't represent NaN and is more precise than the field, so values are rounded when converting back"},
+				{cty.GetAttrPath("tInt32"), integer("int32")},
+				{cty.GetAttrPath("tInt64"), integer("int64")},
+				{cty.GetAttrPath("tUint32"), integer("uint32")},
+				{cty.GetAttrPath("tUint64"), integer("uint64")},
+				{cty.GetAttrPath("tSint32"), integer("sint32")},
+				{cty.GetAttrPath("tSint64"), integer("sint64")},
+				{cty.GetAttrPath("tFixed32"), integer("fixed32")},
+				{cty.GetAttrPath("tFixed64"), integer("fixed64")},
+				{cty.GetAttrPath("tSfixed32"), integer("sfixed32")},
+				{cty.GetAttrPath("tSfixed64"), integer("sfixed64")},
+			},
+		},
+		"oneof": {
+			Desc: (*testproto.WithOneOf)(nil).ProtoReflect().Descriptor(),
+			Want: []LossinessNote{
+				{nil, unknownFields},
+				{cty.GetAttrPath("a"), oneofMember},
+				{cty.GetAttrPath("b"), oneofMember},
+			},
+		},
+		"oneof with discriminator": {
+			Desc:    (*testproto.WithOneOf)(nil).ProtoReflect().Descriptor(),
+			Options: []Option{WithOneofDiscriminator()},
+			Want: []LossinessNote{
+				{nil, unknownFields},
+				{cty.GetAttrPath("a"), oneofMember + `, although the "t_oneof" attribute records which one is set`},
+				{cty.GetAttrPath("b"), oneofMember + `, although the "t_oneof" attribute records which one is set`},
+			},
+		},
+		"maps": {
+			Desc: (*testproto.WithRepeated)(nil).ProtoReflect().Descriptor(),
+			Want: []LossinessNote{
+				{nil, unknownFields},
+				{cty.GetAttrPath("t_map_number_bool"), "map with int64 keys becomes a set of objects with \"key\" and \"value\" attributes, because cty maps only support string keys"},
+				{cty.GetAttrPath("t_map_number_bool").Index(cty.DynamicVal).GetAttr("key"), integer("int64")},
+				{cty.GetAttrPath("t_map_number_message"), "map with int64 keys becomes a set of objects with \"key\" and \"value\" attributes, because cty maps only support string keys"},
+				{cty.GetAttrPath("t_map_number_message").Index(cty.DynamicVal).GetAttr("key"), integer("int64")},
+			},
+		},
+		"recursive": {
+			Desc: (*testproto.Recursive)(nil).ProtoReflect().Descriptor(),
+			Want: []LossinessNote{
+				{nil, unknownFields},
+				{cty.GetAttrPath("next"), "message type testproto.Recursive is recursive, so its notes appear only at its outer occurrence"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := DescribeLossiness(test.Desc, test.Options...)
+			if len(got) != len(test.Want) {
+				t.Errorf("wrong number of notes %d; want %d", len(got), len(test.Want))
+			}
+			for i := 0; i < len(got) || i < len(test.Want); i++ {
+				switch {
+				case i >= len(got):
+					t.Errorf("missing note %d: %#v", i, test.Want[i])
+				case i >= len(test.Want):
+					t.Errorf("extra note %d: %#v", i, got[i])
+				case !got[i].Path.Equals(test.Want[i].Path) || got[i].Message != test.Want[i].Message:
+					t.Errorf("wrong note %d\ngot:  %#v\nwant: %#v", i, got[i], test.Want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReservedInfo(t *testing.T) {
+	got := ReservedInfo((*testproto.WithReserved)(nil).ProtoReflect().Descriptor())
+	want := []ReservedNote{
+		{
+			Start:   2,
+			End:     2,
+			Message: "field number 2 of testproto.WithReserved is reserved, so no attribute can represent it",
+		},
+		{
+			Start:   9,
+			End:     11,
+			Message: "field numbers 9 through 11 of testproto.WithReserved are reserved, so no attributes can represent them",
+		},
+		{
+			Name:    "old_name",
+			Message: `field name "old_name" of testproto.WithReserved is reserved, so no attribute can represent it`,
+		},
+		{
+			Name:    "older_name",
+			Message: `field name "older_name" of testproto.WithReserved is reserved, so no attribute can represent it`,
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+
+	if got := ReservedInfo((*testproto.Assorted)(nil).ProtoReflect().Descriptor()); got != nil {
+		t.Errorf("wrong result for message without reservations\ngot: %#v", got)
+	}
+}
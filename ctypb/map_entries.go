@@ -0,0 +1,79 @@
+package ctypb
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// SortedMapEntries returns the elements of the given set of map entry
+// objects in order of their "key" attributes.
+//
+// FromProtobufMessage represents a protocol buffers map field whose keys are
+// not strings as a set of objects with "key" and "value" attributes, and the
+// iteration order of a cty set is not meaningful. SortedMapEntries is for
+// callers that need a predictable order, such as when rendering the entries
+// for display. Numeric keys sort in numeric order, string keys in
+// lexicographical order of their bytes, and false sorts before true. String
+// keys that both contain integers, such as those that represent 64-bit
+// integer keys under WithInt64AsStrings, instead sort in numeric order.
+//
+// SortedMapEntries panics if the given value is not a known, non-null set
+// of objects with a "key" attribute.
+func SortedMapEntries(v cty.Value) []cty.Value {
+	ty := v.Type()
+	if !ty.IsSetType() || !ty.ElementType().IsObjectType() || !ty.ElementType().HasAttribute("key") {
+		panic("SortedMapEntries requires a set of map entry objects")
+	}
+	if v.IsNull() || !v.IsKnown() {
+		panic("SortedMapEntries requires a known, non-null set")
+	}
+	elems := v.AsValueSlice()
+	sortMapEntries(elems, true)
+	return elems
+}
+
+// sortMapEntries sorts the given map entry objects in place, in the order
+// described for SortedMapEntries, except that string keys are compared as
+// integers only if intStrings is true.
+func sortMapEntries(elems []cty.Value, intStrings bool) {
+	sort.SliceStable(elems, func(i, j int) bool {
+		return mapKeyLess(elems[i].GetAttr("key"), elems[j].GetAttr("key"), intStrings)
+	})
+}
+
+// mapKeyLess returns true if map key a should sort before map key b. If
+// intStrings is true then string keys that both contain integers are
+// compared numerically.
+//
+// Null and unknown keys, which FromProtobufMessage never produces, sort
+// after all others.
+func mapKeyLess(a, b cty.Value, intStrings bool) bool {
+	aOK := a.IsKnown() && !a.IsNull()
+	bOK := b.IsKnown() && !b.IsNull()
+	if !aOK || !bOK {
+		return aOK && !bOK
+	}
+	ty := a.Type()
+	if !ty.Equals(b.Type()) {
+		return false
+	}
+	switch ty {
+	case cty.Number:
+		return a.LessThan(b).True()
+	case cty.String:
+		if intStrings {
+			aInt, aOK := new(big.Int).SetString(a.AsString(), 10)
+			bInt, bOK := new(big.Int).SetString(b.AsString(), 10)
+			if aOK && bOK {
+				return aInt.Cmp(bInt) < 0
+			}
+		}
+		return a.AsString() < b.AsString()
+	case cty.Bool:
+		return a.False() && b.True()
+	default:
+		return false
+	}
+}
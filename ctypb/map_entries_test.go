@@ -0,0 +1,88 @@
+package ctypb
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/zclconf/go-cty-protobuf/internal/testproto"
+)
+
+func TestSortedMapEntries(t *testing.T) {
+	msg := &testproto.WithRepeated{
+		TMapNumberBool: map[int64]bool{
+			10: true,
+			-3: false,
+			2:  true,
+			0:  false,
+			7:  true,
+		},
+	}
+
+	var first cty.Value
+	for i := 0; i < 10; i++ {
+		obj, err := FromProtobufMessage(msg.ProtoReflect())
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		got := obj.GetAttr("t_map_number_bool")
+		if i == 0 {
+			first = got
+		} else if !first.RawEquals(got) {
+			t.Fatalf("inconsistent result\nfirst: %#v\nlater: %#v", first, got)
+		}
+	}
+
+	entries := SortedMapEntries(first)
+	want := []int64{-3, 0, 2, 7, 10}
+	if len(entries) != len(want) {
+		t.Fatalf("wrong number of entries %d; want %d", len(entries), len(want))
+	}
+	for i, entry := range entries {
+		if got, want := entry.GetAttr("key"), cty.NumberIntVal(want[i]); !want.RawEquals(got) {
+			t.Errorf("wrong key for entry %d\ngot:  %#v\nwant: %#v", i, got, want)
+		}
+	}
+}
+
+func TestSortedMapEntriesKeyTypes(t *testing.T) {
+	entry := func(key cty.Value) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{
+			"key":   key,
+			"value": cty.True,
+		})
+	}
+	tests := map[string]struct {
+		Keys []cty.Value
+		Want []cty.Value
+	}{
+		"strings": {
+			[]cty.Value{cty.StringVal("b"), cty.StringVal("a"), cty.StringVal("B")},
+			[]cty.Value{cty.StringVal("B"), cty.StringVal("a"), cty.StringVal("b")},
+		},
+		"integer strings": {
+			// These represent 64-bit integer keys under WithInt64AsStrings.
+			[]cty.Value{cty.StringVal("10"), cty.StringVal("9"), cty.StringVal("-2")},
+			[]cty.Value{cty.StringVal("-2"), cty.StringVal("9"), cty.StringVal("10")},
+		},
+		"bools": {
+			[]cty.Value{cty.True, cty.False},
+			[]cty.Value{cty.False, cty.True},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			elems := make([]cty.Value, len(test.Keys))
+			for i, key := range test.Keys {
+				elems[i] = entry(key)
+			}
+			got := SortedMapEntries(cty.SetVal(elems))
+			for i, want := range test.Want {
+				if got := got[i].GetAttr("key"); !want.RawEquals(got) {
+					t.Errorf("wrong key for entry %d\ngot:  %#v\nwant: %#v", i, got, want)
+				}
+			}
+		})
+	}
+}
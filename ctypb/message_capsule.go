@@ -0,0 +1,52 @@
+package ctypb
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// messageCapsuleHandler is the WellKnownHandler that represents messages of
+// a particular type as values of a capsule type wrapping the Go
+// representation of the message, for WithMessageCapsule.
+type messageCapsuleHandler struct {
+	ty cty.Type
+}
+
+func (h messageCapsuleHandler) Type(desc protoreflect.MessageDescriptor) (cty.Type, bool) {
+	return h.ty, true
+}
+
+func (h messageCapsuleHandler) FromProto(msg protoreflect.Message) (cty.Value, error) {
+	m := msg.Interface()
+	if err := h.checkGoType(m); err != nil {
+		return cty.NilVal, err
+	}
+	// The capsule must not share memory with the source message, so that
+	// later changes to the message can't change the value.
+	return cty.CapsuleVal(h.ty, proto.Clone(m)), nil
+}
+
+func (h messageCapsuleHandler) ToProto(v cty.Value, msg protoreflect.Message) error {
+	if !v.Type().Equals(h.ty) {
+		return fmt.Errorf("a value of type %s is required", h.ty.FriendlyName())
+	}
+	if err := h.checkGoType(msg.Interface()); err != nil {
+		return err
+	}
+	proto.Merge(msg.Interface(), v.EncapsulatedValue().(proto.Message))
+	return nil
+}
+
+// checkGoType returns an error if the given message doesn't have the Go type
+// that the handler's capsule type wraps.
+func (h messageCapsuleHandler) checkGoType(m proto.Message) error {
+	want := reflect.PtrTo(h.ty.EncapsulatedType())
+	if got := reflect.TypeOf(m); got != want {
+		return fmt.Errorf("capsule type %s requires messages of Go type %s, but this message has Go type %s", h.ty.FriendlyName(), want, got)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package ctypb
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty-debug/ctydebug"
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/zclconf/go-cty-protobuf/internal/testproto"
+)
+
+func TestWithMessageCapsule(t *testing.T) {
+	emptyCapsule := cty.Capsule("empty", reflect.TypeOf((*testproto.Empty)(nil)).Elem())
+	opts := []Option{WithMessageCapsule("testproto.Empty", emptyCapsule)}
+
+	desc := (*testproto.WithEmpty)(nil).ProtoReflect().Descriptor()
+	wantTy := cty.Object(map[string]cty.Type{
+		"t_empty":        emptyCapsule,
+		"t_empty_map":    cty.Map(emptyCapsule),
+		"t_empty_list":   cty.List(emptyCapsule),
+		"t_empty_choice": emptyCapsule,
+		"t_other_choice": cty.String,
+	})
+	gotTy, err := ImpliedTypeForMessageDesc(desc, opts...)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if !wantTy.Equals(gotTy) {
+		t.Fatalf(
+			"wrong type\ngot: %s\nwant: %s",
+			ctydebug.TypeString(gotTy),
+			ctydebug.TypeString(wantTy),
+		)
+	}
+
+	inner := &testproto.Empty{}
+	msg := &testproto.WithEmpty{
+		TEmpty:     inner,
+		TEmptyMap:  map[string]*testproto.Empty{"a": {}},
+		TEmptyList: []*testproto.Empty{{}, {}},
+	}
+	got, err := FromProtobufMessage(msg.ProtoReflect(), opts...)
+	if err != nil {
+		t.Fatalf("unexpected error from FromProtobufMessage\ngot: %s", err.Error())
+	}
+	if !got.Type().Equals(wantTy) {
+		t.Fatalf("wrong result type\ngot: %s\nwant: %s", ctydebug.TypeString(got.Type()), ctydebug.TypeString(wantTy))
+	}
+	wrapped, ok := got.GetAttr("t_empty").EncapsulatedValue().(*testproto.Empty)
+	if !ok {
+		t.Fatalf("capsule wraps %T, not *testproto.Empty", got.GetAttr("t_empty").EncapsulatedValue())
+	}
+	if wrapped == inner {
+		t.Errorf("capsule wraps the source message rather than a copy")
+	}
+	if got := got.GetAttr("t_empty_choice"); !got.IsNull() {
+		t.Errorf("wrong t_empty_choice %#v; want null", got)
+	}
+
+	into := &testproto.WithEmpty{}
+	err = ToProtobufMessage(got, into.ProtoReflect(), opts...)
+	if err != nil {
+		t.Fatalf("unexpected error from ToProtobufMessage\ngot: %s", err.Error())
+	}
+	if diff := cmp.Diff(msg, into, protocmp.Transform()); diff != "" {
+		t.Errorf("wrong ToProtobufMessage result\n%s", diff)
+	}
+	if into.TEmpty == wrapped {
+		t.Errorf("message refers to the capsule's message rather than a copy")
+	}
+
+	t.Run("wrong Go type", func(t *testing.T) {
+		dyn := dynamicpb.NewMessage(desc)
+		dyn.Set(desc.Fields().ByName("t_empty"), protoreflect.ValueOfMessage(dynamicpb.NewMessage(inner.ProtoReflect().Descriptor())))
+		_, err := FromProtobufMessage(dyn, opts...)
+		if err == nil {
+			t.Fatalf("succeeded; want error")
+		}
+		if got, want := err.Error(), "capsule type empty requires messages of Go type *testproto.Empty, but this message has Go type *dynamicpb.Message"; got != want {
+			t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+		}
+		pathErr, ok := err.(cty.PathError)
+		if !ok {
+			t.Fatalf("error is %T, not cty.PathError: %s", err, err.Error())
+		}
+		if wantPath := cty.GetAttrPath("t_empty"); !pathErr.Path.Equals(wantPath) {
+			t.Errorf("wrong error path\ngot:  %#v\nwant: %#v", pathErr.Path, wantPath)
+		}
+	})
+	t.Run("wrong value type", func(t *testing.T) {
+		attrs := got.AsValueMap()
+		attrs["t_empty"] = cty.EmptyObjectVal
+		err := ToProtobufMessage(cty.ObjectVal(attrs), (&testproto.WithEmpty{}).ProtoReflect(), opts...)
+		if err == nil {
+			t.Fatalf("succeeded; want error")
+		}
+		if got, want := err.Error(), "a value of type empty is required"; got != want {
+			t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+		}
+		pathErr, ok := err.(cty.PathError)
+		if !ok {
+			t.Fatalf("error is %T, not cty.PathError: %s", err, err.Error())
+		}
+		if wantPath := cty.GetAttrPath("t_empty"); !pathErr.Path.Equals(wantPath) {
+			t.Errorf("wrong error path\ngot:  %#v\nwant: %#v", pathErr.Path, wantPath)
+		}
+	})
+}
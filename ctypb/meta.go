@@ -0,0 +1,144 @@
+package ctypb
+
+import (
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// MessageMeta describes the message type behind an object returned by
+// FromProtobufMessageWithMeta, for tools such as editors and validators that
+// need details of the schema alongside the values.
+type MessageMeta struct {
+	// Desc is the descriptor of the message type.
+	Desc protoreflect.MessageDescriptor
+
+	// Attrs describes the field behind each attribute of the object, keyed
+	// by attribute name. Attributes that don't represent fields, such as
+	// those added by WithPreserveUnknownFields or WithOneofDiscriminator,
+	// don't appear here.
+	Attrs map[string]*FieldMeta
+}
+
+// FieldMeta describes the field behind an attribute of an object returned
+// by FromProtobufMessageWithMeta.
+type FieldMeta struct {
+	// Field is the field's descriptor, for any details not covered by the
+	// other fields of FieldMeta.
+	Field protoreflect.FieldDescriptor
+
+	// Number is the field number.
+	Number protoreflect.FieldNumber
+
+	// Kind is the protocol buffers kind of the field, or of its values if
+	// it's a map field.
+	Kind protoreflect.Kind
+
+	// Oneof is the name of the oneof that the field belongs to, or empty if
+	// it doesn't belong to one. Synthetic oneofs, which the protocol
+	// buffers compiler generates for proto3 "optional" fields, are not
+	// included.
+	Oneof protoreflect.Name
+
+	// HasPresence is true if the field tracks presence, as described for
+	// PresenceTrackingAttributes.
+	HasPresence bool
+
+	// Message describes the nested message type if the attribute, each of
+	// its elements if it's a repeated field, or each of its values if it's
+	// a map field, is an object representing a message. It's nil otherwise,
+	// including for message fields converted by a WellKnownHandler or by
+	// WithWellKnownStruct.
+	Message *MessageMeta
+}
+
+// FromProtobufMessageWithMeta is a variant of FromProtobufMessage which also
+// returns metadata about the fields behind the attributes of the result,
+// in a structure that mirrors the nesting of the result's objects.
+//
+// The metadata describes the message types rather than the message values,
+// and so it's the same for all messages of the same type and it describes
+// nested message fields even when they are null. There is exactly one
+// MessageMeta for each message type, which means that the metadata for a
+// recursive message type contains cycles.
+//
+// If WithWellKnownHandlers includes a handler for the message type of the
+// given message itself, then the result is not an object and the returned
+// metadata is nil.
+//
+// FromProtobufMessageWithMeta pays attention to the same options as
+// FromProtobufMessage.
+func FromProtobufMessageWithMeta(msg protoreflect.Message, opts ...Option) (cty.Value, *MessageMeta, error) {
+	v, err := FromProtobufMessage(msg, opts...)
+	if err != nil {
+		return cty.NilVal, nil, err
+	}
+	o := makeOptions(opts)
+	desc := msg.Descriptor()
+	if handler, _ := wellKnownHandlerFor(desc, o); handler != nil {
+		return v, nil, nil
+	}
+	b := metaBuilder{
+		opts:  o,
+		metas: make(map[protoreflect.FullName]*MessageMeta),
+	}
+	return v, b.message(desc), nil
+}
+
+// metaBuilder is the state of a single call to FromProtobufMessageWithMeta.
+type metaBuilder struct {
+	opts *options
+
+	// metas tracks the metadata we've already built for each message type,
+	// so that recursive message types produce cycles rather than infinite
+	// recursion.
+	metas map[protoreflect.FullName]*MessageMeta
+}
+
+func (b *metaBuilder) message(desc protoreflect.MessageDescriptor) *MessageMeta {
+	if meta, ok := b.metas[desc.FullName()]; ok {
+		return meta
+	}
+	fields := desc.Fields()
+	meta := &MessageMeta{
+		Desc:  desc,
+		Attrs: make(map[string]*FieldMeta, fields.Len()),
+	}
+	b.metas[desc.FullName()] = meta
+
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		meta.Attrs[b.opts.fieldAttrName(field)] = b.field(field)
+	}
+	if b.opts.extensionTypes != nil {
+		b.opts.extensionTypes.RangeExtensionsByMessage(desc.FullName(), func(xt protoreflect.ExtensionType) bool {
+			field := xt.TypeDescriptor()
+			meta.Attrs[string(field.FullName())] = b.field(field)
+			return true
+		})
+	}
+	return meta
+}
+
+func (b *metaBuilder) field(field protoreflect.FieldDescriptor) *FieldMeta {
+	ret := &FieldMeta{
+		Field:       field,
+		Number:      field.Number(),
+		HasPresence: field.HasPresence(),
+	}
+	if oneof := field.ContainingOneof(); oneof != nil && !oneof.IsSynthetic() {
+		ret.Oneof = oneof.Name()
+	}
+
+	elemField := field
+	if field.IsMap() {
+		elemField = field.MapValue()
+	}
+	ret.Kind = elemField.Kind()
+	if desc := elemField.Message(); desc != nil {
+		handler, _ := wellKnownHandlerFor(desc, b.opts)
+		if handler == nil && !(b.opts.wellKnownStruct && isWellKnownStruct(desc)) {
+			ret.Message = b.message(desc)
+		}
+	}
+	return ret
+}
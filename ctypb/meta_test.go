@@ -0,0 +1,95 @@
+package ctypb
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/zclconf/go-cty-protobuf/internal/testproto"
+)
+
+func TestFromProtobufMessageWithMeta(t *testing.T) {
+	t.Run("oneof and presence", func(t *testing.T) {
+		msg := &testproto.WithOneOf{TOneof: &testproto.WithOneOf_A{A: "a"}}
+		v, meta, err := FromProtobufMessageWithMeta(msg.ProtoReflect())
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		if got, want := v.GetAttr("a"), cty.StringVal("a"); !want.RawEquals(got) {
+			t.Errorf("wrong value for a\ngot:  %#v\nwant: %#v", got, want)
+		}
+		if got, want := meta.Desc.FullName(), protoreflect.FullName("testproto.WithOneOf"); got != want {
+			t.Errorf("wrong message type %s; want %s", got, want)
+		}
+		if got, want := len(meta.Attrs), len(v.Type().AttributeTypes()); got != want {
+			t.Errorf("metadata has %d attributes, but value has %d", got, want)
+		}
+
+		outside := meta.Attrs["outside"]
+		if outside.Number != 1 || outside.Kind != protoreflect.StringKind || outside.Oneof != "" || outside.HasPresence {
+			t.Errorf("wrong metadata for outside: %#v", outside)
+		}
+		b := meta.Attrs["b"]
+		if b.Number != 3 || b.Kind != protoreflect.StringKind || b.Oneof != "t_oneof" || !b.HasPresence {
+			t.Errorf("wrong metadata for b: %#v", b)
+		}
+	})
+	t.Run("synthetic oneof", func(t *testing.T) {
+		_, meta, err := FromProtobufMessageWithMeta((&testproto.WithOptional{}).ProtoReflect())
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		opt := meta.Attrs["string_opt"]
+		if opt.Oneof != "" || !opt.HasPresence {
+			t.Errorf("wrong metadata for string_opt: %#v", opt)
+		}
+		if got := meta.Attrs["message_req"].Message; got == nil || got.Desc.FullName() != "testproto.WithOptional.Nested" {
+			t.Errorf("wrong metadata for message_req's message: %#v", got)
+		}
+	})
+	t.Run("nested maps and lists", func(t *testing.T) {
+		_, meta, err := FromProtobufMessageWithMeta((&testproto.WithComplexMap{}).ProtoReflect(), WithFieldNameFunc(stripFieldNamePrefix))
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		for _, name := range []string{"MAP_STRING_COMPLEX", "MAP_NUMBER_COMPLEX"} {
+			field := meta.Attrs[name]
+			if field == nil {
+				t.Fatalf("no metadata for %s", name)
+			}
+			if field.Kind != protoreflect.MessageKind || field.Message == nil {
+				t.Fatalf("wrong metadata for %s: %#v", name, field)
+			}
+			inners := field.Message.Attrs["INNERS"]
+			if inners.Number != 3 || inners.Message == nil || inners.Message.Attrs["DATA"].Kind != protoreflect.BytesKind {
+				t.Errorf("wrong metadata for %s.INNERS: %#v", name, inners)
+			}
+		}
+		// Both maps have the same value type, which has only one
+		// MessageMeta.
+		if meta.Attrs["MAP_STRING_COMPLEX"].Message != meta.Attrs["MAP_NUMBER_COMPLEX"].Message {
+			t.Errorf("same message type has different metadata")
+		}
+	})
+	t.Run("recursive", func(t *testing.T) {
+		msg := &testproto.Recursive{Name: "a", Next: &testproto.Recursive{Name: "b"}}
+		_, meta, err := FromProtobufMessageWithMeta(msg.ProtoReflect(), WithMaxDepth(3))
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		if got := meta.Attrs["next"].Message; got != meta {
+			t.Errorf("recursive field doesn't refer back to its own message type")
+		}
+	})
+	t.Run("handled message types", func(t *testing.T) {
+		_, meta, err := FromProtobufMessageWithMeta((&testproto.WithWellKnown{}).ProtoReflect(), WithWellKnownHandlers(NewWellKnownHandlers()))
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		ts := meta.Attrs["t_timestamp"]
+		if ts.Kind != protoreflect.MessageKind || ts.Message != nil {
+			t.Errorf("wrong metadata for t_timestamp: %#v", ts)
+		}
+	})
+}
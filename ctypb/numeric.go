@@ -0,0 +1,238 @@
+package ctypb
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// NumericNote describes a number that a field can't represent exactly, as
+// returned by CheckNumericCompatibility.
+type NumericNote struct {
+	// Path is the path to the number within the checked value.
+	Path cty.Path
+
+	// Kind is the protocol buffers kind of the field that the number would
+	// be written to.
+	Kind protoreflect.Kind
+
+	// Message is a human-readable description of the problem.
+	Message string
+}
+
+// CheckNumericCompatibility returns notes about the numbers in the given
+// object that the fields of the given message type can't represent exactly,
+// because they are out of range for the field's kind, have a fractional part
+// when the field's kind is an integer kind, or are more precise than a
+// floating point field can represent.
+//
+// All of the numeric kinds of protocol buffers become cty.Number, which
+// doesn't record the kind a number came from, and so converting a message
+// to cty and then back into a message of a different type can't detect a
+// number being written to a field of a narrower kind. ToProtobufMessage
+// rejects numbers that are out of range or have a fractional part, but
+// silently rounds numbers for float and double fields, so this is a way to
+// check for those problems before writing, or to find all of them at once
+// rather than only the first.
+//
+// The given object should conform to the type that ImpliedTypeForMessageDesc
+// returns for the given descriptor, but CheckNumericCompatibility skips any
+// parts of it that don't, along with null and unknown values, because
+// ToProtobufMessage reports those problems itself. Fields converted by a
+// WellKnownHandler, WithWellKnownStruct, or a FieldConverter are also
+// skipped.
+//
+// CheckNumericCompatibility pays attention to the following options:
+//   - WithWellKnownStruct
+//   - WithExtensions
+//   - WithNumberCoercion
+//   - WithInt64AsStrings
+//   - WithWellKnownHandlers
+//   - WithMessageCapsule
+//   - WithFieldNameFunc
+//   - WithFieldConverters
+func CheckNumericCompatibility(obj cty.Value, desc protoreflect.MessageDescriptor, opts ...Option) []NumericNote {
+	c := numericChecker{opts: makeOptions(opts)}
+	c.message(obj, desc, make(cty.Path, 0, 4))
+	return c.notes
+}
+
+// numericChecker is the state of a single call to CheckNumericCompatibility.
+type numericChecker struct {
+	opts  *options
+	notes []NumericNote
+}
+
+func (c *numericChecker) message(obj cty.Value, desc protoreflect.MessageDescriptor, path cty.Path) {
+	if !obj.IsKnown() || obj.IsNull() || !obj.Type().IsObjectType() {
+		return
+	}
+	ty := obj.Type()
+	fields := desc.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		name := c.opts.fieldAttrName(field)
+		if !ty.HasAttribute(name) {
+			continue
+		}
+		path := append(path, cty.GetAttrStep{Name: name})
+		c.field(obj.GetAttr(name), field, path)
+	}
+	if c.opts.extensionTypes != nil {
+		c.opts.extensionTypes.RangeExtensionsByMessage(desc.FullName(), func(xt protoreflect.ExtensionType) bool {
+			field := xt.TypeDescriptor()
+			name := string(field.FullName())
+			if ty.HasAttribute(name) {
+				path := append(path, cty.GetAttrStep{Name: name})
+				c.field(obj.GetAttr(name), field, path)
+			}
+			return true
+		})
+	}
+}
+
+func (c *numericChecker) field(v cty.Value, field protoreflect.FieldDescriptor, path cty.Path) {
+	if !v.IsKnown() || v.IsNull() {
+		return
+	}
+	ty := v.Type()
+	switch {
+	case field.IsMap():
+		keyField, valField, err := mapEntryFields(field, path)
+		if err != nil {
+			return
+		}
+		path := reservePath(path)
+		if keyField.Kind() == protoreflect.StringKind {
+			if !(ty.IsMapType() || ty.IsObjectType()) {
+				return
+			}
+			for it := v.ElementIterator(); it.Next(); {
+				k, ev := it.Element()
+				c.fieldKind(ev, valField, append(path, cty.IndexStep{Key: k}))
+			}
+			return
+		}
+		if !(ty.IsSetType() || ty.IsTupleType()) {
+			return
+		}
+		for it := v.ElementIterator(); it.Next(); {
+			k, ev := it.Element()
+			if !ev.IsKnown() || ev.IsNull() || !ev.Type().IsObjectType() {
+				continue
+			}
+			if ty.IsSetType() {
+				k = ev
+			}
+			path := append(path, cty.IndexStep{Key: k})
+			if ev.Type().HasAttribute("key") {
+				c.fieldKind(ev.GetAttr("key"), keyField, append(path, cty.GetAttrStep{Name: "key"}))
+			}
+			if ev.Type().HasAttribute("value") {
+				c.fieldKind(ev.GetAttr("value"), valField, append(path, cty.GetAttrStep{Name: "value"}))
+			}
+		}
+	case field.IsList():
+		// ToProtobufMessage also accepts a set for a repeated field, whose
+		// elements are their own keys.
+		if !(ty.IsListType() || ty.IsTupleType() || ty.IsSetType()) {
+			return
+		}
+		path := reservePath(path)
+		for it := v.ElementIterator(); it.Next(); {
+			k, ev := it.Element()
+			if ty.IsSetType() {
+				k = ev
+			}
+			c.fieldKind(ev, field, append(path, cty.IndexStep{Key: k}))
+		}
+	default:
+		c.fieldKind(v, field, path)
+	}
+}
+
+func (c *numericChecker) fieldKind(v cty.Value, field protoreflect.FieldDescriptor, path cty.Path) {
+	if !v.IsKnown() || v.IsNull() {
+		return
+	}
+	if conv, _ := fieldConverterFor(field, c.opts, path); conv != nil {
+		return
+	}
+	kind := field.Kind()
+	switch kind {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if handler, _ := wellKnownHandlerFor(field.Message(), c.opts); handler != nil {
+			return
+		}
+		if c.opts.wellKnownStruct && isWellKnownStruct(field.Message()) {
+			return
+		}
+		c.message(v, field.Message(), reservePath(path))
+		return
+	case protoreflect.BoolKind, protoreflect.StringKind, protoreflect.BytesKind, protoreflect.EnumKind:
+		return
+	}
+
+	v, err := toProtobufNumber(v, kind, c.opts, path)
+	if err != nil || !cty.Number.Equals(v.Type()) {
+		return
+	}
+	if msg := numericProblem(v.AsBigFloat(), kind); msg != "" {
+		c.notes = append(c.notes, NumericNote{
+			Path:    path.Copy(),
+			Kind:    kind,
+			Message: msg,
+		})
+	}
+}
+
+// numericProblem returns a description of why a field of the given kind
+// can't represent the given number exactly, or an empty string if it can.
+func numericProblem(bf *big.Float, kind protoreflect.Kind) string {
+	s := bf.Text('f', -1)
+	switch kind {
+	case protoreflect.FloatKind:
+		f, acc := bf.Float32()
+		if !bf.IsInf() && math.IsInf(float64(f), 0) {
+			return fmt.Sprintf("value %s is out of range for a float field", s)
+		}
+		if acc != big.Exact {
+			return fmt.Sprintf("value %s can't be represented exactly by a float field, so it would be rounded", s)
+		}
+		return ""
+	case protoreflect.DoubleKind:
+		f, acc := bf.Float64()
+		if !bf.IsInf() && math.IsInf(f, 0) {
+			return fmt.Sprintf("value %s is out of range for a double field", s)
+		}
+		if acc != big.Exact {
+			return fmt.Sprintf("value %s can't be represented exactly by a double field, so it would be rounded", s)
+		}
+		return ""
+	}
+
+	if !bf.IsInt() {
+		return fmt.Sprintf("value %s is not a whole number, so a field of kind %s can't represent it", s, kind)
+	}
+	var min, max *big.Int
+	switch kind {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		min, max = big.NewInt(math.MinInt32), big.NewInt(math.MaxInt32)
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		min, max = big.NewInt(math.MinInt64), big.NewInt(math.MaxInt64)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		min, max = new(big.Int), new(big.Int).SetUint64(math.MaxUint32)
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		min, max = new(big.Int), new(big.Int).SetUint64(math.MaxUint64)
+	default:
+		return ""
+	}
+	bi, _ := bf.Int(nil)
+	if bi.Cmp(min) < 0 || bi.Cmp(max) > 0 {
+		return fmt.Sprintf("value %s is out of range for a field of kind %s, which allows %s to %s", s, kind, min, max)
+	}
+	return ""
+}
@@ -0,0 +1,188 @@
+package ctypb
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/zclconf/go-cty-protobuf/internal/testproto"
+)
+
+func TestCheckNumericCompatibility(t *testing.T) {
+	assorted := func(attr string, v cty.Value) cty.Value {
+		obj, err := FromProtobufMessage((&testproto.Assorted{}).ProtoReflect())
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		attrs := obj.AsValueMap()
+		attrs[attr] = v
+		return cty.ObjectVal(attrs)
+	}
+	assortedDesc := (&testproto.Assorted{}).ProtoReflect().Descriptor()
+
+	tests := map[string]struct {
+		Value   cty.Value
+		Desc    protoreflect.MessageDescriptor
+		Options []Option
+		Want    []NumericNote
+	}{
+		"all compatible": {
+			Value: assorted("t_int32", cty.NumberIntVal(-5)),
+			Desc:  assortedDesc,
+		},
+		"int32 out of range": {
+			Value: assorted("t_int32", cty.NumberIntVal(1<<31)),
+			Desc:  assortedDesc,
+			Want: []NumericNote{
+				{
+					Path:    cty.GetAttrPath("t_int32"),
+					Kind:    protoreflect.Int32Kind,
+					Message: "value 2147483648 is out of range for a field of kind int32, which allows -2147483648 to 2147483647",
+				},
+			},
+		},
+		"negative uint64": {
+			Value: assorted("t_uint64", cty.NumberIntVal(-1)),
+			Desc:  assortedDesc,
+			Want: []NumericNote{
+				{
+					Path:    cty.GetAttrPath("t_uint64"),
+					Kind:    protoreflect.Uint64Kind,
+					Message: "value -1 is out of range for a field of kind uint64, which allows 0 to 18446744073709551615",
+				},
+			},
+		},
+		"fraction for sint64": {
+			Value: assorted("t_sint64", cty.NumberFloatVal(1.5)),
+			Desc:  assortedDesc,
+			Want: []NumericNote{
+				{
+					Path:    cty.GetAttrPath("t_sint64"),
+					Kind:    protoreflect.Sint64Kind,
+					Message: "value 1.5 is not a whole number, so a field of kind sint64 can't represent it",
+				},
+			},
+		},
+		"float precision": {
+			Value: assorted("t_float", cty.MustParseNumberVal("0.1")),
+			Desc:  assortedDesc,
+			Want: []NumericNote{
+				{
+					Path:    cty.GetAttrPath("t_float"),
+					Kind:    protoreflect.FloatKind,
+					Message: "value 0.1 can't be represented exactly by a float field, so it would be rounded",
+				},
+			},
+		},
+		"float out of range": {
+			Value: assorted("t_float", cty.MustParseNumberVal("1e39")),
+			Desc:  assortedDesc,
+			Want: []NumericNote{
+				{
+					Path:    cty.GetAttrPath("t_float"),
+					Kind:    protoreflect.FloatKind,
+					Message: "value 1000000000000000000000000000000000000000 is out of range for a float field",
+				},
+			},
+		},
+		"double precision": {
+			Value: assorted("t_double", cty.MustParseNumberVal("0.1")),
+			Desc:  assortedDesc,
+			Want: []NumericNote{
+				{
+					Path:    cty.GetAttrPath("t_double"),
+					Kind:    protoreflect.DoubleKind,
+					Message: "value 0.1 can't be represented exactly by a double field, so it would be rounded",
+				},
+			},
+		},
+		"double exact": {
+			Value: assorted("t_double", cty.NumberFloatVal(0.1)),
+			Desc:  assortedDesc,
+		},
+		"infinity for float": {
+			Value: assorted("t_float", cty.PositiveInfinity),
+			Desc:  assortedDesc,
+		},
+		"repeated": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_numbers": cty.ListVal([]cty.Value{
+					cty.NumberIntVal(1),
+					cty.NumberFloatVal(2.5),
+					cty.UnknownVal(cty.Number),
+				}),
+			}),
+			Desc: (&testproto.WithRepeatedNumbers{}).ProtoReflect().Descriptor(),
+			Want: []NumericNote{
+				{
+					Path:    cty.GetAttrPath("t_numbers").IndexInt(1),
+					Kind:    protoreflect.Int64Kind,
+					Message: "value 2.5 is not a whole number, so a field of kind int64 can't represent it",
+				},
+			},
+		},
+		"repeated as set": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_numbers": cty.SetVal([]cty.Value{
+					cty.NumberIntVal(1),
+					cty.NumberFloatVal(2.5),
+				}),
+			}),
+			Desc: (&testproto.WithRepeatedNumbers{}).ProtoReflect().Descriptor(),
+			Want: []NumericNote{
+				{
+					Path:    cty.GetAttrPath("t_numbers").Index(cty.NumberFloatVal(2.5)),
+					Kind:    protoreflect.Int64Kind,
+					Message: "value 2.5 is not a whole number, so a field of kind int64 can't represent it",
+				},
+			},
+		},
+		"map keys in tuple": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_map_number_bool": cty.TupleVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"key":   cty.NumberFloatVal(1.5),
+						"value": cty.True,
+					}),
+				}),
+			}),
+			Desc: (&testproto.WithRepeated{}).ProtoReflect().Descriptor(),
+			Want: []NumericNote{
+				{
+					Path:    cty.GetAttrPath("t_map_number_bool").IndexInt(0).GetAttr("key"),
+					Kind:    protoreflect.Int64Kind,
+					Message: "value 1.5 is not a whole number, so a field of kind int64 can't represent it",
+				},
+			},
+		},
+		"int64 as string": {
+			Value:   assorted("t_int64", cty.StringVal("9223372036854775808")),
+			Desc:    assortedDesc,
+			Options: []Option{WithInt64AsStrings()},
+			Want: []NumericNote{
+				{
+					Path:    cty.GetAttrPath("t_int64"),
+					Kind:    protoreflect.Int64Kind,
+					Message: "value 9223372036854775808 is out of range for a field of kind int64, which allows -9223372036854775808 to 9223372036854775807",
+				},
+			},
+		},
+		"wrong type is skipped": {
+			Value: assorted("t_int32", cty.StringVal("not a number")),
+			Desc:  assortedDesc,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := CheckNumericCompatibility(test.Value, test.Desc, test.Options...)
+			if diff := cmp.Diff(test.Want, got, cmp.Comparer(func(a, b cty.Path) bool {
+				return a.Equals(b)
+			})); diff != "" {
+				t.Errorf("wrong result\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package ctypb
+
+import (
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// discriminatedOneofs returns the oneofs of the given message type that
+// have a discriminator attribute, which is all of its real oneofs if the
+// WithOneofDiscriminator option is in effect and none otherwise.
+//
+// Synthetic oneofs, which the protocol buffers compiler generates for
+// proto3 "optional" fields, have only one member and so don't need one.
+func discriminatedOneofs(desc protoreflect.MessageDescriptor, opts *options) []protoreflect.OneofDescriptor {
+	if !opts.oneofDiscriminator {
+		return nil
+	}
+	oneofs := desc.Oneofs()
+	var ret []protoreflect.OneofDescriptor
+	for i := 0; i < oneofs.Len(); i++ {
+		oneof := oneofs.Get(i)
+		if oneof.IsSynthetic() {
+			continue
+		}
+		ret = append(ret, oneof)
+	}
+	return ret
+}
+
+// fromOneofDiscriminator returns the value of the discriminator attribute
+// for the given oneof of the given message, which is the attribute name of
+// its member that is set, or null if none of them are.
+func fromOneofDiscriminator(msg protoreflect.Message, oneof protoreflect.OneofDescriptor, opts *options) cty.Value {
+	field := msg.WhichOneof(oneof)
+	if field == nil {
+		return cty.NullVal(cty.String)
+	}
+	return cty.StringVal(opts.fieldAttrName(field))
+}
+
+// checkOneofDiscriminator returns an error if the given value of the
+// discriminator attribute for a oneof doesn't agree with the name of the
+// oneof's non-null attribute, which is the empty string if all of them are
+// null.
+func checkOneofDiscriminator(v cty.Value, oneof protoreflect.OneofDescriptor, setName string, path cty.Path) error {
+	if !v.IsKnown() {
+		return path.NewErrorf("value must be known")
+	}
+	if !v.IsNull() && !v.Type().Equals(cty.String) {
+		return path.NewErrorf("a string is required")
+	}
+	var got string
+	if !v.IsNull() {
+		got = v.AsString()
+	}
+	switch {
+	case got == setName:
+		return nil
+	case setName == "":
+		return path.NewErrorf("must be null, because all of the attributes for oneof %s are null", oneof.Name())
+	default:
+		return path.NewErrorf("must be %q, to match the non-null attribute for oneof %s", setName, oneof.Name())
+	}
+}
@@ -0,0 +1,167 @@
+package ctypb
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/zclconf/go-cty-protobuf/internal/testproto"
+)
+
+func TestOneofDiscriminator(t *testing.T) {
+	tests := map[string]struct {
+		Input   *testproto.WithOneOf
+		Options []Option
+		Want    cty.Value
+	}{
+		"none set": {
+			Input: &testproto.WithOneOf{Outside: "hello"},
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"outside": cty.StringVal("hello"),
+				"a":       cty.NullVal(cty.String),
+				"b":       cty.NullVal(cty.String),
+				"t_oneof": cty.NullVal(cty.String),
+			}),
+		},
+		"a set": {
+			Input: &testproto.WithOneOf{TOneof: &testproto.WithOneOf_A{A: "a"}},
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"outside": cty.StringVal(""),
+				"a":       cty.StringVal("a"),
+				"b":       cty.NullVal(cty.String),
+				"t_oneof": cty.StringVal("a"),
+			}),
+		},
+		"b set to its default": {
+			Input: &testproto.WithOneOf{TOneof: &testproto.WithOneOf_B{}},
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"outside": cty.StringVal(""),
+				"a":       cty.NullVal(cty.String),
+				"b":       cty.StringVal(""),
+				"t_oneof": cty.StringVal("b"),
+			}),
+		},
+		"field name func": {
+			Input:   &testproto.WithOneOf{TOneof: &testproto.WithOneOf_B{B: "b"}},
+			Options: []Option{WithFieldNameFunc(stripFieldNamePrefix)},
+			Want: cty.ObjectVal(map[string]cty.Value{
+				"OUTSIDE": cty.StringVal(""),
+				"A":       cty.NullVal(cty.String),
+				"B":       cty.StringVal("b"),
+				"t_oneof": cty.StringVal("B"),
+			}),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			opts := append([]Option{WithOneofDiscriminator()}, test.Options...)
+			got, err := FromProtobufMessage(test.Input.ProtoReflect(), opts...)
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			if !test.Want.RawEquals(got) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+
+			wantTy, err := ImpliedTypeForMessageDesc(test.Input.ProtoReflect().Descriptor(), opts...)
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			if !wantTy.Equals(got.Type()) {
+				t.Errorf("result does not conform to implied type\ngot:  %#v\nwant: %#v", got.Type(), wantTy)
+			}
+
+			back := &testproto.WithOneOf{}
+			err = ToProtobufMessage(got, back.ProtoReflect(), opts...)
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			if diff := cmp.Diff(test.Input, back, protocmp.Transform()); diff != "" {
+				t.Errorf("wrong result after round-trip\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestOneofDiscriminatorSynthetic(t *testing.T) {
+	// The synthetic oneofs for proto3 "optional" fields don't get a
+	// discriminator attribute.
+	desc := (*testproto.WithOptional)(nil).ProtoReflect().Descriptor()
+	want, err := ImpliedTypeForMessageDesc(desc)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	got, err := ImpliedTypeForMessageDesc(desc, WithOneofDiscriminator())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if !want.Equals(got) {
+		t.Errorf("wrong type\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestToProtobufMessageOneofDiscriminatorMismatch(t *testing.T) {
+	tests := map[string]struct {
+		A, B, Discriminator cty.Value
+		WantErr             string
+	}{
+		"names the null member": {
+			A:             cty.StringVal("a"),
+			B:             cty.NullVal(cty.String),
+			Discriminator: cty.StringVal("b"),
+			WantErr:       `must be "a", to match the non-null attribute for oneof t_oneof`,
+		},
+		"null with a member set": {
+			A:             cty.NullVal(cty.String),
+			B:             cty.StringVal("b"),
+			Discriminator: cty.NullVal(cty.String),
+			WantErr:       `must be "b", to match the non-null attribute for oneof t_oneof`,
+		},
+		"non-null with no member set": {
+			A:             cty.NullVal(cty.String),
+			B:             cty.NullVal(cty.String),
+			Discriminator: cty.StringVal("a"),
+			WantErr:       "must be null, because all of the attributes for oneof t_oneof are null",
+		},
+		"wrong type": {
+			A:             cty.StringVal("a"),
+			B:             cty.NullVal(cty.String),
+			Discriminator: cty.True,
+			WantErr:       "a string is required",
+		},
+		"unknown": {
+			A:             cty.StringVal("a"),
+			B:             cty.NullVal(cty.String),
+			Discriminator: cty.UnknownVal(cty.String),
+			WantErr:       "value must be known",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			obj := cty.ObjectVal(map[string]cty.Value{
+				"outside": cty.StringVal(""),
+				"a":       test.A,
+				"b":       test.B,
+				"t_oneof": test.Discriminator,
+			})
+			err := ToProtobufMessage(obj, (&testproto.WithOneOf{}).ProtoReflect(), WithOneofDiscriminator())
+			if err == nil {
+				t.Fatalf("succeeded; want error\nwant: %s", test.WantErr)
+			}
+			if got, want := err.Error(), test.WantErr; got != want {
+				t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+			}
+			pathErr, ok := err.(cty.PathError)
+			if !ok {
+				t.Fatalf("error is %T, not cty.PathError: %s", err, err.Error())
+			}
+			if wantPath := cty.GetAttrPath("t_oneof"); !pathErr.Path.Equals(wantPath) {
+				t.Errorf("wrong error path\ngot:  %#v\nwant: %#v", pathErr.Path, wantPath)
+			}
+		})
+	}
+}
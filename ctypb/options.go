@@ -0,0 +1,847 @@
+package ctypb
+
+import (
+	"context"
+	"encoding/base64"
+
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// Option is the type of optional arguments to the conversion functions in
+// this package, which customize how values are converted.
+//
+// Each function documents which options it pays attention to. Options that
+// are not relevant to a particular function are silently ignored, so that
+// callers can use the same set of options in both directions.
+type Option func(*options)
+
+// options is the internal representation of a set of Option values, after
+// they have all been applied.
+type options struct {
+	omitDefaults       bool
+	emitUnpopulated    bool
+	integerTruncation  bool
+	numberCoercion     bool
+	bytesCapsule       bool
+	wellKnownStruct    bool
+	extensionTypes     *protoregistry.Types
+	enumNameFunc       EnumNameFunc
+	enumValueFunc      EnumValueFunc
+	enumObjects        bool
+	unknownFieldsAttr  string
+	bytesAsUTF8        bool
+	bytesAsNumbers     bool
+	int64AsStrings     bool
+	wellKnownHandlers  *WellKnownHandlers
+	fieldNameFunc      FieldNameFunc
+	fieldConverters    map[protoreflect.FullName]FieldConverter
+	jsonNameFallback   bool
+	messageCapsules    map[protoreflect.FullName]cty.Type
+	maxDepth           int
+	maxStringLen       int
+	maxBytesLen        int
+	defaultMessages    bool
+	base64Encoding     *base64.Encoding
+	indexedObjectLists bool
+	oneofDiscriminator bool
+	traceFunc          TraceFunc
+	textMarshal        *prototext.MarshalOptions
+
+	// depth is the message nesting depth of the conversion in progress,
+	// which is tracked only when maxDepth is set. See nestedMessage.
+	depth int
+
+	// ctx is the context given to one of the context-aware variants of
+	// the conversion functions, or nil for the others.
+	ctx context.Context
+
+	// setFields, if not nil, collects the paths of the attributes that
+	// ToProtobufMessageSetFields wrote into the message.
+	setFields *[]cty.Path
+
+	// typeCache is the cache of implied types belonging to a Converter,
+	// or nil to use the package-level cache.
+	typeCache *typeCache
+}
+
+// typeOptions is a subset of options containing only the settings that
+// can affect the result of ImpliedTypeForMessageDesc, used as part of the
+// key for caching implied types.
+//
+// Any new option that affects implied types must be reflected here, or
+// else the cache will return incorrect results.
+type typeOptions struct {
+	bytesCapsule       bool
+	wellKnownStruct    bool
+	extensionTypes     *protoregistry.Types
+	enumObjects        bool
+	unknownFieldsAttr  string
+	bytesAsUTF8        bool
+	bytesAsNumbers     bool
+	int64AsStrings     bool
+	wellKnownHandlers  *WellKnownHandlers
+	maxDepth           int
+	oneofDiscriminator bool
+}
+
+func (o *options) typeOptions() typeOptions {
+	return typeOptions{
+		bytesCapsule:       o.bytesCapsule,
+		wellKnownStruct:    o.wellKnownStruct,
+		extensionTypes:     o.extensionTypes,
+		enumObjects:        o.enumObjects,
+		unknownFieldsAttr:  o.unknownFieldsAttr,
+		bytesAsUTF8:        o.bytesAsUTF8,
+		bytesAsNumbers:     o.bytesAsNumbers,
+		int64AsStrings:     o.int64AsStrings,
+		wellKnownHandlers:  o.wellKnownHandlers,
+		maxDepth:           o.maxDepth,
+		oneofDiscriminator: o.oneofDiscriminator,
+	}
+}
+
+// defaultOptions is the result of makeOptions when there are no options,
+// which we share to avoid allocating a new object for every call.
+// This object must never be modified.
+var defaultOptions = &options{}
+
+func makeOptions(opts []Option) *options {
+	if len(opts) == 0 {
+		return defaultOptions
+	}
+	ret := &options{}
+	for _, opt := range opts {
+		opt(ret)
+	}
+	return ret
+}
+
+// makeOptionsContext is like makeOptions but also associates the given
+// context with the result, for use by the context-aware variants of the
+// conversion functions.
+func makeOptionsContext(ctx context.Context, opts []Option) *options {
+	ret := &options{}
+	for _, opt := range opts {
+		opt(ret)
+	}
+	ret.ctx = ctx
+	return ret
+}
+
+// impliedTypeCache returns the cache to use for implied types, or nil if
+// implied types must not be cached.
+func (o *options) impliedTypeCache() *typeCache {
+	if o.depth != 0 {
+		// The implied type of a nested message depends on how deeply
+		// it's nested when there's a maximum depth, so we can cache
+		// only the types of top-level messages.
+		return nil
+	}
+	if o.typeCache != nil {
+		return o.typeCache
+	}
+	if o.fieldNameFunc != nil || o.fieldConverters != nil || o.messageCapsules != nil {
+		// Functions and maps are not comparable, so we can't include
+		// these in the cache key. A Converter has its own cache and so
+		// doesn't have this problem.
+		return nil
+	}
+	if o.extensionTypes != nil {
+		// Callers commonly register more extensions in a registry over
+		// time, which changes the implied types, so the registry's
+		// identity isn't a safe cache key beyond a single Converter.
+		return nil
+	}
+	return &impliedTypeCache
+}
+
+// fieldAttrName returns the name of the object attribute that represents
+// the given field, which must not be an extension field.
+func (o *options) fieldAttrName(field protoreflect.FieldDescriptor) string {
+	if o.fieldNameFunc != nil {
+		return o.fieldNameFunc(field)
+	}
+	return string(field.Name())
+}
+
+// nestedMessage returns the options to use for converting a message nested
+// inside the message currently being converted, and whether that message
+// would exceed the maximum depth set by WithMaxDepth.
+func (o *options) nestedMessage() (*options, bool) {
+	if o.maxDepth <= 0 {
+		return o, false
+	}
+	if o.depth >= o.maxDepth {
+		return nil, true
+	}
+	ret := *o
+	ret.depth++
+	return &ret, false
+}
+
+// recordSetField records that the attribute at the given path was written
+// into a message, if the caller asked for that information.
+func (o *options) recordSetField(path cty.Path) {
+	if o.setFields == nil {
+		return
+	}
+	// The caller may reuse the path's backing array, so we must copy it.
+	*o.setFields = append(*o.setFields, path.Copy())
+}
+
+// contextErr returns the error from the context associated with the
+// options, if any, which is non-nil if the conversion should stop early.
+func (o *options) contextErr() error {
+	if o.ctx == nil {
+		return nil
+	}
+	return o.ctx.Err()
+}
+
+// WithOmitDefaults is an Option for FromProtobufMessage which causes it to
+// represent scalar fields that don't track presence as null whenever they
+// have the zero value for their kind, rather than producing that zero value
+// explicitly.
+//
+// This produces values which more closely mirror what would actually appear
+// in the protocol buffers wire format for proto3 messages, where such fields
+// are not serialized at all when they have their zero value, and is
+// similar to the default behavior of protojson when EmitUnpopulated is not
+// set. The resulting sparse values can be easier to compare, because only
+// the populated fields have non-null values.
+//
+// This introduces an ambiguity: a field that was explicitly set to its zero
+// value is indistinguishable from one that was never set, and both become
+// null. That matches the wire format, which makes the same two cases
+// indistinguishable. When this option is passed to ToProtobufMessage, it
+// accepts null for those fields and leaves them unset, so that the zero
+// value is restored when converting back. Use a field with
+// presence tracking, such as a proto3 "optional" field, if the distinction
+// is important.
+//
+// Repeated and map fields are not affected by this option: they are always
+// represented as collections, which are empty when the field is unset.
+//
+// The result still conforms to the type returned by
+// ImpliedTypeForMessageDesc, because the omitted attributes are null rather
+// than absent.
+//
+// WithOmitDefaults and WithEmitUnpopulated are mutually exclusive, so
+// whichever of the two appears last in the options overrides the other.
+func WithOmitDefaults() Option {
+	return func(o *options) {
+		o.omitDefaults = true
+		o.emitUnpopulated = false
+	}
+}
+
+// WithEmitUnpopulated is an Option for FromProtobufMessage which causes it
+// to represent absent scalar fields that track presence using their default
+// values, rather than as null. This is similar to protojson's
+// EmitUnpopulated option, and is useful when the result will be used with
+// a schema that expects attributes to have concrete values.
+//
+// As with protojson, absent message fields and the unset members of a
+// oneof are still represented as null.
+//
+// WithEmitUnpopulated and WithOmitDefaults are mutually exclusive, so
+// whichever of the two appears last in the options overrides the other.
+func WithEmitUnpopulated() Option {
+	return func(o *options) {
+		o.emitUnpopulated = true
+		o.omitDefaults = false
+	}
+}
+
+// WithDefaultMessages is an Option which causes FromProtobufMessage to
+// represent an absent singular message field as an object whose attributes
+// all have the default values for the corresponding fields of the nested
+// message, rather than as null, so that callers don't need to check for
+// null at every level of nesting.
+//
+// The members of a real oneof are still represented as null when absent,
+// because otherwise there would be no way to tell which of the choices is
+// set. Fields converted by a WellKnownHandler or by WithWellKnownStruct are
+// also still represented as null, as are fields beyond the limit set by
+// WithMaxDepth, which this option would otherwise expand infinitely for
+// recursive message types.
+//
+// When this option is passed to ToProtobufMessage, it leaves unset any of
+// the same fields whose value converts to a message that has no populated
+// fields, so that an absent field is restored when converting back. As with
+// WithOmitDefaults, this means that a message field explicitly set to an
+// empty message is indistinguishable from one that was never set. A null
+// value is still accepted for these fields, and also leaves them unset.
+func WithDefaultMessages() Option {
+	return func(o *options) {
+		o.defaultMessages = true
+	}
+}
+
+// WithIndexedObjectLists is an Option for ToProtobufMessage which causes it
+// to also accept an object or a map for a repeated field whose elements are
+// messages, as long as its attribute names or keys are decimal indices
+// ("0", "1", and so on). The elements are written in index order.
+//
+// This is intended for tools that accumulate the elements of a list as a
+// sparse mapping, such as during templating. The indices must still cover
+// every position from zero up to the highest index, so a missing index is
+// an error. This option has no effect on values that are already lists.
+func WithIndexedObjectLists() Option {
+	return func(o *options) {
+		o.indexedObjectLists = true
+	}
+}
+
+// WithIntegerTruncation is an Option for ToProtobufMessage which causes it
+// to silently truncate numbers with a fractional part towards zero when
+// assigning them to fields of the integer kinds.
+//
+// By default, ToProtobufMessage returns an error for a number that isn't
+// a whole number when the target field has an integer kind, because such
+// a number is likely to be a mistake in the input. Infinite values are
+// still rejected with this option, because they have no whole number part.
+func WithIntegerTruncation() Option {
+	return func(o *options) {
+		o.integerTruncation = true
+	}
+}
+
+// WithNumberCoercion is an Option for ToProtobufMessage which causes it to
+// also accept a string containing a decimal number for fields of the
+// numeric kinds, converting it to a number using the usual cty conversion
+// rules. This is useful for values decoded from JSON where large integers
+// were written as strings to avoid losing precision, in the same way that
+// protojson accepts quoted 64-bit integers.
+//
+// By default, ToProtobufMessage requires a number for these fields. A
+// string that doesn't contain a valid number is an error either way.
+func WithNumberCoercion() Option {
+	return func(o *options) {
+		o.numberCoercion = true
+	}
+}
+
+// WithInt64AsStrings is an Option which causes fields of the 64-bit integer
+// kinds (int64, uint64, sint64, fixed64, and sfixed64) to be represented as
+// strings containing their decimal values, rather than as numbers. This
+// follows the protocol buffers JSON mapping, and preserves the exact values
+// when the cty values are later encoded as JSON and decoded by software
+// that uses floating point numbers. The same applies to the keys of maps
+// with keys of those kinds and to the google.protobuf.Int64Value and
+// google.protobuf.UInt64Value wrappers with WithWellKnownHandlers.
+//
+// ToProtobufMessage accepts either a string or a number for those fields
+// when this option is in effect. This option must be used consistently
+// across ImpliedTypeForMessageDesc, FromProtobufMessage, and
+// ToProtobufMessage.
+func WithInt64AsStrings() Option {
+	return func(o *options) {
+		o.int64AsStrings = true
+	}
+}
+
+// WithBytesCapsule is an Option which causes fields of the bytes kind to be
+// represented by values of BytesCapsuleType, rather than by the default
+// representation as base64-encoded strings.
+//
+// The capsule values contain the bytes directly, avoiding the cost of
+// encoding and decoding base64, but those values can only be used with
+// applications that are aware of this package's capsule type. The
+// conversion functions copy the bytes in both directions, so that a capsule
+// value never shares memory with a message.
+//
+// This option must be used consistently across ImpliedTypeForMessageDesc,
+// FromProtobufMessage, and ToProtobufMessage, because it changes the type
+// of the affected attributes.
+func WithBytesCapsule() Option {
+	return func(o *options) {
+		o.bytesCapsule = true
+	}
+}
+
+// WithWellKnownStruct is an Option which causes fields whose types are the
+// well-known types google.protobuf.Struct, google.protobuf.Value, and
+// google.protobuf.ListValue to be treated as containers for arbitrary
+// JSON-like data, rather than as normal messages.
+//
+// With this option enabled, the implied type of such fields is
+// cty.DynamicPseudoType. FromProtobufMessage represents a Struct as an
+// object, a ListValue as a tuple, and a Value as whatever type corresponds
+// with the kind of value it contains, with a null_value or absent field
+// represented as cty.NullVal(cty.DynamicPseudoType).
+//
+// Because the elements of a map or repeated field of one of these types can
+// each have a different type, FromProtobufMessage represents such a field as
+// an object or tuple rather than as a map or list. A map whose keys aren't
+// strings becomes a tuple of its entries in key order, rather than a set.
+// The implied type of such a field is therefore also cty.DynamicPseudoType,
+// as is the implied type of a map or repeated field of a message type that
+// contains one of these types, whose elements become an object or tuple only
+// if they don't all have the same type.
+//
+// ToProtobufMessage accepts for a Value field a value of any type that has a
+// JSON equivalent: strings, numbers, bools, objects and maps, lists and
+// tuples, and nulls. A Struct field accepts only objects and maps, and
+// a ListValue field accepts only lists and tuples. Values of other types,
+// such as sets or capsule types, are rejected with an error.
+//
+// This option also applies to fields whose type is the well-known enum type
+// google.protobuf.NullValue, whose only member represents null. The implied
+// type of such fields is also cty.DynamicPseudoType, and FromProtobufMessage
+// represents them as cty.NullVal(cty.DynamicPseudoType). ToProtobufMessage
+// accepts only null for those fields, and writes it as the enum's only
+// member, except that it leaves a field that tracks presence unset, so the
+// presence of such a field does not survive a round-trip.
+func WithWellKnownStruct() Option {
+	return func(o *options) {
+		o.wellKnownStruct = true
+	}
+}
+
+// WithExtensions is an Option which causes the conversion functions to
+// include extension fields (a proto2 feature) in the object representation
+// of each message, for any extensions registered in the given registry
+// which extend the message type in question.
+//
+// Each extension field is represented by an attribute named after the full
+// name of the extension, such as "example.ext_field", to avoid conflicts
+// with the message's own fields and with other extensions. As with normal
+// fields, an absent extension field is represented by a null value.
+//
+// The set of extensions in the registry affects the implied type of a
+// message, so the registry must not change between calls that are
+// expected to produce consistent types. The package-level functions don't
+// cache implied types when this option is used, so that they reflect any
+// extensions registered since the previous call, but a Converter caches
+// them as usual.
+func WithExtensions(types *protoregistry.Types) Option {
+	return func(o *options) {
+		o.extensionTypes = types
+	}
+}
+
+// EnumNameFunc is the signature of a function that decides which string
+// represents a particular enumeration value in cty, for use with
+// WithEnumNameFunc.
+type EnumNameFunc func(value protoreflect.EnumValueDescriptor) string
+
+// EnumValueFunc is the signature of a function that finds the enumeration
+// value represented by a particular string in cty, for use with
+// WithEnumValueFunc. It should be the inverse of an EnumNameFunc.
+//
+// If the given string doesn't correspond with any value of the given
+// enumeration, the function must return nil.
+type EnumValueFunc func(enum protoreflect.EnumDescriptor, name string) protoreflect.EnumValueDescriptor
+
+// WithEnumNameFunc is an Option for FromProtobufMessage which overrides the
+// default behavior of representing an enumeration value as a string
+// containing the name of the value as written in the schema.
+//
+// This can be useful for presenting enumeration values in a friendlier
+// form, such as by removing a common prefix from all of the value names.
+// If the result will also be converted back into a message, use
+// WithEnumValueFunc with an inverse function.
+func WithEnumNameFunc(f EnumNameFunc) Option {
+	return func(o *options) {
+		o.enumNameFunc = f
+	}
+}
+
+// WithEnumValueFunc is an Option for ToProtobufMessage which overrides the
+// default behavior of finding an enumeration value by matching the given
+// string with the names of the values as written in the schema.
+//
+// This is the inverse of WithEnumNameFunc.
+func WithEnumValueFunc(f EnumValueFunc) Option {
+	return func(o *options) {
+		o.enumValueFunc = f
+	}
+}
+
+// WithEnumObjects is an Option which causes enumeration values to be
+// represented as objects with a "name" attribute containing the name of
+// the value and a "number" attribute containing its number, rather than as
+// strings containing only the name, so that consumers can use whichever
+// they prefer. WithEnumNameFunc and WithEnumValueFunc still decide the
+// names.
+//
+// FromProtobufMessage sets the name to null for a number that isn't part
+// of the enumeration, rather than returning an error. ToProtobufMessage
+// accepts an object with either attribute set to null, but if both are set
+// then they must refer to the same value.
+//
+// This option must be used consistently across ImpliedTypeForMessageDesc,
+// FromProtobufMessage, and ToProtobufMessage.
+func WithEnumObjects() Option {
+	return func(o *options) {
+		o.enumObjects = true
+	}
+}
+
+// DefaultUnknownFieldsAttr is the name of the attribute that
+// WithPreserveUnknownFields uses to represent unknown fields.
+const DefaultUnknownFieldsAttr = "__unknown"
+
+// WithPreserveUnknownFields is an Option which causes the conversion
+// functions to preserve any unknown fields in messages, such as fields
+// that were added in a newer version of a schema than the one in use.
+//
+// The unknown fields of each message are represented in the wire format
+// as bytes, in an additional attribute named by DefaultUnknownFieldsAttr,
+// which is null if a message has no unknown fields. The bytes are
+// represented in the same way as for fields of the bytes kind, and so this
+// attribute is a base64-encoded string unless WithBytesCapsule is also in
+// effect.
+//
+// Use WithPreserveUnknownFieldsAttr to choose a different attribute name.
+func WithPreserveUnknownFields() Option {
+	return WithPreserveUnknownFieldsAttr(DefaultUnknownFieldsAttr)
+}
+
+// WithPreserveUnknownFieldsAttr is like WithPreserveUnknownFields except
+// that it uses the given attribute name instead of DefaultUnknownFieldsAttr.
+//
+// The conversion functions return an error if any message has a field of
+// the same name as the given attribute.
+func WithPreserveUnknownFieldsAttr(name string) Option {
+	return func(o *options) {
+		o.unknownFieldsAttr = name
+	}
+}
+
+// WithOneofDiscriminator is an Option which causes the conversion functions
+// to add an attribute for each oneof in a message, named after the oneof,
+// whose value is the attribute name of the oneof's member that is set, or
+// null if none of them are. This is in addition to the attributes for the
+// members themselves, and saves callers from checking each of them to find
+// which one is non-null.
+//
+// ToProtobufMessage requires the discriminator attribute to agree with the
+// members, returning an error if it isn't the name of the non-null member,
+// or isn't null when all of the members are null.
+//
+// The conversion functions return an error if any message has a field of
+// the same name as one of its oneofs.
+func WithOneofDiscriminator() Option {
+	return func(o *options) {
+		o.oneofDiscriminator = true
+	}
+}
+
+// WithBase64Encoding is an Option which causes the base64-encoded strings
+// that represent fields of the bytes kind to use the given encoding, rather
+// than the default of base64.StdEncoding.
+//
+// This is useful for interoperating with systems that expect a particular
+// alphabet or padding behavior, such as base64.URLEncoding or the unpadded
+// variants base64.RawStdEncoding and base64.RawURLEncoding. The same
+// encoding must be used in both directions: ToProtobufMessage returns an
+// error for a string that isn't valid in the given encoding, including a
+// padded string when the encoding has no padding.
+//
+// The encoding also applies to the representation of unknown fields when
+// WithPreserveUnknownFields is in effect, and to the "value" strings that
+// DecodeAny and EncodeAny work with, but has no effect on fields whose bytes
+// are represented in some other way, such as with WithBytesCapsule.
+func WithBase64Encoding(enc *base64.Encoding) Option {
+	return func(o *options) {
+		o.base64Encoding = enc
+	}
+}
+
+// base64 returns the encoding to use for base64-encoded bytes.
+func (o *options) base64() *base64.Encoding {
+	if o.base64Encoding == nil {
+		return base64.StdEncoding
+	}
+	return o.base64Encoding
+}
+
+// WithBytesAsUTF8Strings is an Option which causes fields of the bytes kind
+// to be represented as strings containing the bytes interpreted as UTF-8
+// text, rather than the default representation as base64-encoded strings.
+//
+// This is useful for schemas that use bytes fields to carry text. With this
+// option enabled, FromProtobufMessage returns an error if any bytes field
+// contains bytes that are not valid UTF-8, and ToProtobufMessage writes the
+// UTF-8 encoding of the given string into the field.
+//
+// Note that cty normalizes all strings to Unicode Normal Form C, so a bytes
+// field containing text in a different normal form will not round-trip
+// exactly.
+//
+// This option takes precedence over WithBytesCapsule for fields of the
+// bytes kind, but WithBytesCapsule still applies to the representation of
+// unknown fields when WithPreserveUnknownFields is also in effect.
+//
+// WithBytesAsUTF8Strings and WithBytesAsNumberLists are mutually exclusive,
+// so whichever of the two appears last in the options overrides the other.
+func WithBytesAsUTF8Strings() Option {
+	return func(o *options) {
+		o.bytesAsUTF8 = true
+		o.bytesAsNumbers = false
+	}
+}
+
+// WithBytesAsNumberLists is an Option which causes fields of the bytes kind
+// to be represented as lists of numbers, with one element per byte, rather
+// than the default representation as base64-encoded strings.
+//
+// This is useful for applications that need to work with individual bytes
+// using cty operations. ToProtobufMessage also accepts a tuple of numbers,
+// and returns an error if any element is not a whole number between 0
+// and 255.
+//
+// As with WithBytesAsUTF8Strings, this option takes precedence over
+// WithBytesCapsule for fields of the bytes kind, but not for the
+// representation of unknown fields.
+//
+// WithBytesAsNumberLists and WithBytesAsUTF8Strings are mutually exclusive,
+// so whichever of the two appears last in the options overrides the other.
+func WithBytesAsNumberLists() Option {
+	return func(o *options) {
+		o.bytesAsNumbers = true
+		o.bytesAsUTF8 = false
+	}
+}
+
+// WithWellKnownHandlers is an Option which causes message-typed fields to
+// be converted using the handlers in the given registry, for any message
+// types that have a handler registered.
+//
+// Use NewWellKnownHandlers to obtain a registry that includes the built-in
+// handlers for some of the well-known types, and then optionally register
+// additional handlers for application-specific message types.
+//
+// Without this option, all message types are represented as objects.
+func WithWellKnownHandlers(handlers *WellKnownHandlers) Option {
+	return func(o *options) {
+		o.wellKnownHandlers = handlers
+	}
+}
+
+// WithMessageCapsule is an Option which causes messages of the type with the
+// given full name to be represented as values of the given capsule type,
+// which wraps the Go representation of the message, rather than as objects.
+// This is for applications that handle certain message types specially and
+// so would rather work with the messages directly.
+//
+// The capsule type must encapsulate the Go type that messages of this type
+// have, which for generated code is the generated struct type. For
+// example, given a capsule type created using
+// reflect.TypeOf((*examplepb.Thing)(nil)).Elem(), each capsule value wraps
+// a *examplepb.Thing. FromProtobufMessage wraps a copy of each message, and
+// ToProtobufMessage copies the wrapped message into the destination. Both
+// return an error if a message doesn't have the Go type that the capsule
+// type encapsulates, such as a dynamicpb message when the capsule type
+// encapsulates a generated struct type.
+//
+// This option can be used more than once to represent several message
+// types as capsules, and takes priority over any handler for the same
+// message type registered using WithWellKnownHandlers. WithMessageCapsule
+// panics if the given type is not a capsule type.
+//
+// Implied types can't be cached in the package-level cache when this option
+// is in effect, so it's best to use this option only with a Converter,
+// which has its own cache.
+func WithMessageCapsule(fullName protoreflect.FullName, ty cty.Type) Option {
+	if !ty.IsCapsuleType() {
+		panic("WithMessageCapsule requires a capsule type")
+	}
+	return func(o *options) {
+		if o.messageCapsules == nil {
+			o.messageCapsules = make(map[protoreflect.FullName]cty.Type)
+		}
+		o.messageCapsules[fullName] = ty
+	}
+}
+
+// WithMaxDepth is an Option which limits how deeply messages may be nested
+// inside one another, where a top-level message is at depth zero and each
+// message in a field of another message is one level deeper than the
+// message containing it. A limit of zero or less means no limit, which is
+// the default.
+//
+// FromProtobufMessage and ToProtobufMessage return an error for a message
+// nested more deeply than the limit, rather than recursing without bound
+// into deeply-nested data.
+//
+// The limit also applies to implied types: the attribute for a message
+// field that would exceed the limit has type cty.DynamicPseudoType, and its
+// value is always null. A recursive message type, such as one representing
+// a linked list, has no finite implied type without this option, and so
+// this option is required to convert messages of such types.
+func WithMaxDepth(n int) Option {
+	return func(o *options) {
+		o.maxDepth = n
+	}
+}
+
+// TraceFunc is the signature of a function that WithTraceFunc calls for
+// each field that FromProtobufMessage converts.
+//
+// The path is the path of the field's attribute within the overall result,
+// the kind is the protocol buffers kind of the field, which is MessageKind
+// for map fields, and v is the value that the conversion produced for the
+// attribute.
+type TraceFunc func(path cty.Path, kind protoreflect.Kind, v cty.Value)
+
+// WithTraceFunc is an Option for FromProtobufMessage which causes it to call
+// the given function for each field it converts, including those in nested
+// messages, for diagnosing unexpected results in large conversions. The
+// function is called after the conversion of each field is complete, and so
+// the fields of a nested message are reported before the field containing
+// that message.
+//
+// Fields are not reported if the conversion fails, and neither are the
+// attributes that don't represent fields, such as those added by
+// WithPreserveUnknownFields or WithOneofDiscriminator.
+func WithTraceFunc(fn TraceFunc) Option {
+	return func(o *options) {
+		o.traceFunc = fn
+	}
+}
+
+// WithMaxStringLen is an Option for FromProtobufMessage which causes it to
+// return an error for any string field value longer than n bytes, as a
+// defense against untrusted messages that would otherwise use excessive
+// memory once converted. The limit applies separately to each value, so
+// each element of a repeated field and each key and value of a map field
+// can be up to n bytes long. A limit of zero or less means no limit, which
+// is the default.
+func WithMaxStringLen(n int) Option {
+	return func(o *options) {
+		o.maxStringLen = n
+	}
+}
+
+// WithMaxBytesLen is an Option for FromProtobufMessage which causes it to
+// return an error for any bytes field value longer than n bytes, in the
+// same way as WithMaxStringLen does for strings. The limit applies to the
+// raw bytes, and FromProtobufMessage checks it before producing the
+// representation of the bytes, which for the default base64 representation
+// is a third larger still. A limit of zero or less means no limit, which
+// is the default.
+func WithMaxBytesLen(n int) Option {
+	return func(o *options) {
+		o.maxBytesLen = n
+	}
+}
+
+// WithTextMarshalOptions is an Option for ToProtobufText which overrides how
+// it formats the text, such as to produce a more compact result on a single
+// line. The given options are used instead of ToProtobufText's defaults. If
+// they have no resolver then ToProtobufText uses the registry from
+// WithExtensions, if any.
+func WithTextMarshalOptions(mo prototext.MarshalOptions) Option {
+	return func(o *options) {
+		o.textMarshal = &mo
+	}
+}
+
+// FieldNameFunc is the signature of a function that decides the name of
+// the object attribute that represents a particular field.
+type FieldNameFunc func(field protoreflect.FieldDescriptor) string
+
+// JSONFieldName is a FieldNameFunc which names each attribute using the
+// JSON name of the corresponding field, which is the name used by the
+// protocol buffers JSON mapping. Unless the schema specifies otherwise,
+// that's the field name converted to lower camel case, so that a field
+// named "t_string" becomes an attribute named "tString".
+func JSONFieldName(field protoreflect.FieldDescriptor) string {
+	return field.JSONName()
+}
+
+// FieldNumberAliases returns a FieldNameFunc which names the attributes for
+// the fields of the given message type that have the given field numbers
+// using the corresponding names from the given map, and names all other
+// attributes using the given fallback function, or by the field names in
+// the schema if the fallback is nil.
+//
+// This is intended for keeping the old attribute name of a field that was
+// renamed in the schema, so that existing consumers of the cty values are
+// not affected by the change. Field numbers are unique only within a single
+// message type, so the aliases apply only to the fields of the message type
+// with the same full name as the given descriptor, and not to the fields of
+// any nested messages. Combine several of these functions by passing one as
+// the fallback of another to set aliases for more than one message type.
+//
+// As with any other FieldNameFunc, ToProtobufMessage uses the same names in
+// reverse when used with WithFieldNameFunc.
+func FieldNumberAliases(desc protoreflect.MessageDescriptor, aliases map[protoreflect.FieldNumber]string, fallback FieldNameFunc) FieldNameFunc {
+	msgName := desc.FullName()
+	return func(field protoreflect.FieldDescriptor) string {
+		if field.ContainingMessage().FullName() == msgName {
+			if name, ok := aliases[field.Number()]; ok {
+				return name
+			}
+		}
+		if fallback != nil {
+			return fallback(field)
+		}
+		return string(field.Name())
+	}
+}
+
+// WithFieldNameFunc is an Option which overrides the names of the object
+// attributes that represent the fields of each message, which are by
+// default the same as the field names in the protocol buffers schema.
+//
+// The given function is called for each field of each message type, and
+// must return a name that is unique among the fields of the message. The
+// conversion functions return an error if two fields of the same message
+// have the same attribute name. Extension fields are still named by their
+// full names, as described for WithExtensions. Pass JSONFieldName to use
+// the same names as the protocol buffers JSON mapping, or use
+// FieldNumberAliases to keep the old names of renamed fields.
+//
+// Implied types can't be cached in the package-level cache when this option
+// is in effect, so it's best to use this option only with a Converter,
+// which has its own cache.
+func WithFieldNameFunc(fn FieldNameFunc) Option {
+	return func(o *options) {
+		o.fieldNameFunc = fn
+	}
+}
+
+// WithJSONNameFallback is an Option for ToProtobufMessage which allows each
+// field to be represented by an attribute named after the field's JSON name
+// when the object has no attribute of the usual name, so that objects
+// produced by tools that use the protocol buffers JSON mapping can be
+// converted directly. ToProtobufMessage returns an error if an object has
+// attributes of both names for the same field.
+//
+// The JSON name of a field is the name used by the protocol buffers JSON
+// mapping, as described for JSONFieldName. The fallback doesn't apply to
+// extension fields. FromProtobufMessage and ImpliedTypeForMessageDesc still
+// use the usual names, so use WithFieldNameFunc with JSONFieldName instead
+// to use JSON names in both directions.
+func WithJSONNameFallback() Option {
+	return func(o *options) {
+		o.jsonNameFallback = true
+	}
+}
+
+// WithFieldConverters is an Option which replaces the usual conversion of
+// particular fields with the given converters, keyed by the full names of
+// the fields they apply to, such as "example.Message.payload". This allows
+// treating a single field differently from the others of the same kind,
+// such as a bytes field that always contains UTF-8 text. The conversion
+// functions return an error if a converter is given for a message field.
+//
+// The converters take priority over all of the other options that affect
+// the representation of a field's values, such as WithInt64AsStrings, and
+// DescribeLossiness doesn't describe the fields that they apply to.
+//
+// Implied types can't be cached in the package-level cache when this option
+// is in effect, so it's best to use this option only with a Converter,
+// which has its own cache.
+func WithFieldConverters(convs map[protoreflect.FullName]FieldConverter) Option {
+	return func(o *options) {
+		o.fieldConverters = convs
+	}
+}
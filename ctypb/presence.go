@@ -0,0 +1,83 @@
+package ctypb
+
+import (
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// PresenceTrackingAttributes returns a map from each attribute name of the
+// object type that ImpliedTypeForMessageDesc would return for the given
+// descriptor to a boolean indicating whether the corresponding field tracks
+// presence, and so whether FromProtobufMessage might represent it as null.
+//
+// Fields that track presence include message fields, members of a oneof,
+// and scalar fields declared as "optional". Repeated and map fields never
+// track presence, and are always represented as possibly-empty collections.
+// The attribute for unknown fields enabled by WithPreserveUnknownFields is
+// reported as tracking presence, because it's null when there are no
+// unknown fields. Likewise for the discriminator attributes enabled by
+// WithOneofDiscriminator, which are null when none of a oneof's members
+// are set.
+//
+// Note that with WithOmitDefaults, FromProtobufMessage also returns null for
+// scalar fields that don't track presence whenever they have their default
+// values, and so callers using that option should consider all non-repeated
+// attributes as possibly null. Conversely, with WithDefaultMessages,
+// FromProtobufMessage returns null for a message field only if it's a
+// member of a oneof or is converted by a WellKnownHandler or by
+// WithWellKnownStruct, or if it's beyond the limit set by WithMaxDepth.
+//
+// PresenceTrackingAttributes pays attention to the following options:
+//   - WithExtensions
+//   - WithPreserveUnknownFields
+//   - WithFieldNameFunc
+//   - WithOneofDiscriminator
+func PresenceTrackingAttributes(desc protoreflect.MessageDescriptor, opts ...Option) (map[string]bool, error) {
+	o := makeOptions(opts)
+	var path cty.Path
+
+	fields := desc.Fields()
+	ret := make(map[string]bool, fields.Len())
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		name := o.fieldAttrName(field)
+		if _, exists := ret[name]; exists {
+			return nil, path.NewErrorf("more than one field has the attribute name %q", name)
+		}
+		ret[name] = field.HasPresence()
+	}
+
+	if o.extensionTypes != nil {
+		var err error
+		o.extensionTypes.RangeExtensionsByMessage(desc.FullName(), func(xt protoreflect.ExtensionType) bool {
+			field := xt.TypeDescriptor()
+			name := string(field.FullName())
+			if _, exists := ret[name]; exists {
+				err = path.NewErrorf("more than one field has the attribute name %q", name)
+				return false
+			}
+			ret[name] = field.HasPresence()
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, oneof := range discriminatedOneofs(desc, o) {
+		name := string(oneof.Name())
+		if _, exists := ret[name]; exists {
+			return nil, path.NewErrorf("attribute %q for oneof discriminator conflicts with a field of the same name", name)
+		}
+		ret[name] = true
+	}
+
+	if name := o.unknownFieldsAttr; name != "" {
+		if _, exists := ret[name]; exists {
+			return nil, path.NewErrorf("attribute %q for unknown fields conflicts with a field of the same name", name)
+		}
+		ret[name] = true
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,103 @@
+package ctypb
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/zclconf/go-cty-protobuf/internal/testproto"
+)
+
+func TestPresenceTrackingAttributes(t *testing.T) {
+	tests := map[string]struct {
+		Desc    protoreflect.MessageDescriptor
+		Options []Option
+		Want    map[string]bool
+		WantErr string
+	}{
+		"optional": {
+			Desc: (*testproto.WithOptional)(nil).ProtoReflect().Descriptor(),
+			Want: map[string]bool{
+				"string_req":  false,
+				"string_opt":  true,
+				"int32_req":   false,
+				"int32_opt":   true,
+				"message_req": true,
+				"message_opt": true,
+			},
+		},
+		"oneof": {
+			Desc: (*testproto.WithOneOf)(nil).ProtoReflect().Descriptor(),
+			Want: map[string]bool{
+				"outside": false,
+				"a":       true,
+				"b":       true,
+			},
+		},
+		"repeated": {
+			Desc: (*testproto.WithRepeated)(nil).ProtoReflect().Descriptor(),
+			Want: map[string]bool{
+				"t_strings":            false,
+				"t_message":            false,
+				"t_map_string_bool":    false,
+				"t_map_number_bool":    false,
+				"t_map_string_message": false,
+				"t_map_number_message": false,
+			},
+		},
+		"field name func and unknown fields": {
+			Desc:    (*testproto.WithOneOf)(nil).ProtoReflect().Descriptor(),
+			Options: []Option{WithFieldNameFunc(JSONFieldName), WithPreserveUnknownFields()},
+			Want: map[string]bool{
+				"outside":                false,
+				"a":                      true,
+				"b":                      true,
+				DefaultUnknownFieldsAttr: true,
+			},
+		},
+		"field name collision": {
+			Desc: (*testproto.WithOneOf)(nil).ProtoReflect().Descriptor(),
+			Options: []Option{WithFieldNameFunc(func(field protoreflect.FieldDescriptor) string {
+				return "same"
+			})},
+			WantErr: `more than one field has the attribute name "same"`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := PresenceTrackingAttributes(test.Desc, test.Options...)
+
+			if test.WantErr != "" {
+				if err == nil {
+					t.Fatalf("succeeded; want error\nwant: %s", test.WantErr)
+				}
+				if got, want := err.Error(), test.WantErr; got != want {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+
+			if diff := cmp.Diff(test.Want, got); diff != "" {
+				t.Errorf("wrong result\n%s", diff)
+			}
+
+			// The result must line up with the implied type.
+			ty, err := ImpliedTypeForMessageDesc(test.Desc, test.Options...)
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			if got, want := len(got), len(ty.AttributeTypes()); got != want {
+				t.Errorf("result has %d attributes, but implied type has %d", got, want)
+			}
+			for name := range got {
+				if !ty.HasAttribute(name) {
+					t.Errorf("implied type has no attribute %q", name)
+				}
+			}
+		})
+	}
+}
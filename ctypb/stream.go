@@ -0,0 +1,125 @@
+package ctypb
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// MessageWriter writes a stream of messages converted from cty values, with
+// each message preceded by its length encoded as a varint.
+//
+// This is the same length-delimited framing used by the Java protocol
+// buffers library's writeDelimitedTo method, and so the result can be read
+// by other implementations that understand that framing, as well as by
+// MessageReader.
+//
+// A MessageWriter is not safe for concurrent use.
+type MessageWriter struct {
+	w    io.Writer
+	desc protoreflect.MessageDescriptor
+	conv *Converter
+	buf  []byte
+}
+
+// NewMessageWriter returns a MessageWriter that writes messages of the type
+// described by the given descriptor to the given writer, using the given
+// options for converting each value as with ToProtobufMessage.
+func NewMessageWriter(w io.Writer, desc protoreflect.MessageDescriptor, opts ...Option) *MessageWriter {
+	return &MessageWriter{
+		w:    w,
+		desc: desc,
+		conv: NewConverter(opts...),
+	}
+}
+
+// Write converts the given value to a message and then writes that message
+// to the underlying writer, preceded by its length.
+//
+// If the conversion fails then Write returns the error from the conversion
+// without writing anything.
+func (w *MessageWriter) Write(v cty.Value) error {
+	msg := dynamicpb.NewMessage(w.desc)
+	err := w.conv.ToMessage(v, msg)
+	if err != nil {
+		return err
+	}
+
+	// We reuse the same buffer for each message, to avoid allocating a new
+	// one each time.
+	buf := protowire.AppendVarint(w.buf[:0], uint64(proto.Size(msg.Interface())))
+	buf, err = proto.MarshalOptions{UseCachedSize: true}.MarshalAppend(buf, msg.Interface())
+	if err != nil {
+		return fmt.Errorf("failed to encode %s message: %w", w.desc.FullName(), err)
+	}
+	w.buf = buf
+	_, err = w.w.Write(buf)
+	return err
+}
+
+// MessageReader reads a stream of messages in the format written by
+// MessageWriter, converting each one to a cty value.
+//
+// A MessageReader buffers its input, and so it may read beyond the end of
+// the last message it returns. A MessageReader is not safe for concurrent
+// use.
+type MessageReader struct {
+	r    *bufio.Reader
+	desc protoreflect.MessageDescriptor
+	conv *Converter
+	buf  []byte
+}
+
+// NewMessageReader returns a MessageReader that reads messages of the type
+// described by the given descriptor from the given reader, using the given
+// options for converting each message as with FromProtobufMessage.
+func NewMessageReader(r io.Reader, desc protoreflect.MessageDescriptor, opts ...Option) *MessageReader {
+	return &MessageReader{
+		r:    bufio.NewReader(r),
+		desc: desc,
+		conv: NewConverter(opts...),
+	}
+}
+
+// Read reads the next message from the underlying reader and returns its
+// cty representation.
+//
+// At the end of the stream Read returns io.EOF. If the stream ends partway
+// through a message then Read returns io.ErrUnexpectedEOF.
+func (r *MessageReader) Read() (cty.Value, error) {
+	size, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		// ReadUvarint returns io.EOF only if it read no bytes at all.
+		return cty.NilVal, err
+	}
+	if size > math.MaxInt32 {
+		return cty.NilVal, fmt.Errorf("message length %d exceeds the protocol buffers limit", size)
+	}
+
+	if uint64(cap(r.buf)) < size {
+		r.buf = make([]byte, size)
+	}
+	buf := r.buf[:size]
+	_, err = io.ReadFull(r.r, buf)
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return cty.NilVal, err
+	}
+
+	msg := dynamicpb.NewMessage(r.desc)
+	err = proto.Unmarshal(buf, msg.Interface())
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("invalid %s message: %w", r.desc.FullName(), err)
+	}
+	return r.conv.FromMessage(msg)
+}
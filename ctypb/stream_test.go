@@ -0,0 +1,101 @@
+package ctypb
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/zclconf/go-cty-protobuf/internal/testproto"
+)
+
+func TestMessageWriterReader(t *testing.T) {
+	desc := (*testproto.WithOneOf)(nil).ProtoReflect().Descriptor()
+	values := []cty.Value{
+		cty.ObjectVal(map[string]cty.Value{
+			"outside": cty.StringVal("first"),
+			"a":       cty.StringVal("a"),
+			"b":       cty.NullVal(cty.String),
+		}),
+		// An all-defaults message has zero length, which must still be
+		// framed so that the reader sees it.
+		cty.ObjectVal(map[string]cty.Value{
+			"outside": cty.StringVal(""),
+			"a":       cty.NullVal(cty.String),
+			"b":       cty.NullVal(cty.String),
+		}),
+		cty.ObjectVal(map[string]cty.Value{
+			"outside": cty.StringVal("third"),
+			"a":       cty.NullVal(cty.String),
+			"b":       cty.StringVal("b"),
+		}),
+	}
+
+	var buf bytes.Buffer
+	w := NewMessageWriter(&buf, desc)
+	for _, v := range values {
+		if err := w.Write(v); err != nil {
+			t.Fatalf("unexpected error from Write\ngot: %s", err.Error())
+		}
+	}
+
+	// The framing must be compatible with other implementations, so we'll
+	// check that the first message can be decoded by hand too.
+	raw := buf.Bytes()
+	size, n := protowire.ConsumeVarint(raw)
+	if n < 0 {
+		t.Fatalf("invalid length prefix")
+	}
+	first := &testproto.WithOneOf{}
+	if err := proto.Unmarshal(raw[n:n+int(size)], first); err != nil {
+		t.Fatalf("failed to decode first message: %s", err)
+	}
+	if got, want := first.Outside, "first"; got != want {
+		t.Errorf("wrong first message outside field %q; want %q", got, want)
+	}
+
+	r := NewMessageReader(&buf, desc)
+	for i, want := range values {
+		got, err := r.Read()
+		if err != nil {
+			t.Fatalf("unexpected error from Read %d\ngot: %s", i, err.Error())
+		}
+		if !want.RawEquals(got) {
+			t.Errorf("wrong value %d\ngot:  %#v\nwant: %#v", i, got, want)
+		}
+	}
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("wrong error at end of stream %#v; want io.EOF", err)
+	}
+}
+
+func TestMessageWriterConversionError(t *testing.T) {
+	desc := (*testproto.WithOneOf)(nil).ProtoReflect().Descriptor()
+	var buf bytes.Buffer
+	w := NewMessageWriter(&buf, desc)
+	err := w.Write(cty.EmptyObjectVal)
+	if err == nil {
+		t.Fatalf("succeeded with invalid value; want error")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("wrote %d bytes despite the error", buf.Len())
+	}
+}
+
+func TestMessageReaderTruncated(t *testing.T) {
+	desc := (*testproto.WithOneOf)(nil).ProtoReflect().Descriptor()
+	raw, err := proto.Marshal(&testproto.WithOneOf{Outside: "hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	framed := protowire.AppendVarint(nil, uint64(len(raw)))
+	framed = append(framed, raw[:len(raw)-1]...)
+
+	r := NewMessageReader(bytes.NewReader(framed), desc)
+	if _, err := r.Read(); err != io.ErrUnexpectedEOF {
+		t.Errorf("wrong error %#v; want io.ErrUnexpectedEOF", err)
+	}
+}
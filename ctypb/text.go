@@ -0,0 +1,69 @@
+package ctypb
+
+import (
+	"fmt"
+
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// FromProtobufText parses the given message in the protocol buffers text
+// format, as a message of the type that the given descriptor describes, and
+// then converts it in the same way as FromProtobufMessage.
+//
+// The text format is common for test fixtures and for configuration files,
+// so this allows loading those directly as cty values. When WithExtensions
+// is in effect, the parser uses the same registry to resolve extension
+// fields and the message types of google.protobuf.Any values written in
+// the expanded form. Otherwise, it uses protoregistry.GlobalTypes.
+//
+// FromProtobufText pays attention to the same options as
+// FromProtobufMessage.
+func FromProtobufText(text []byte, desc protoreflect.MessageDescriptor, opts ...Option) (cty.Value, error) {
+	o := makeOptions(opts)
+	msg := dynamicpb.NewMessage(desc)
+	unmarshal := prototext.UnmarshalOptions{}
+	if o.extensionTypes != nil {
+		unmarshal.Resolver = o.extensionTypes
+	}
+	err := unmarshal.Unmarshal(text, msg)
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("invalid %s message: %w", desc.FullName(), err)
+	}
+	return FromProtobufMessage(msg, opts...)
+}
+
+// ToProtobufText converts the given value to a message of the type that the
+// given descriptor describes, in the same way as NewProtobufMessage, and
+// then returns that message in the protocol buffers text format.
+//
+// This is intended for producing human-readable messages, such as for
+// golden files in tests. By default the result is spread over multiple
+// lines with each level of nesting indented by two spaces, but
+// WithTextMarshalOptions can override that. As with the prototext package
+// itself, the exact formatting is not stable, so callers should not compare
+// the results byte-for-byte.
+//
+// ToProtobufText pays attention to the same options as ToProtobufMessage,
+// and also to WithTextMarshalOptions.
+func ToProtobufText(v cty.Value, desc protoreflect.MessageDescriptor, opts ...Option) ([]byte, error) {
+	msg, err := NewProtobufMessage(v, desc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	o := makeOptions(opts)
+	marshal := prototext.MarshalOptions{Multiline: true}
+	if o.textMarshal != nil {
+		marshal = *o.textMarshal
+	}
+	if marshal.Resolver == nil && o.extensionTypes != nil {
+		marshal.Resolver = o.extensionTypes
+	}
+	ret, err := marshal.Marshal(msg.Interface())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s message as text: %w", desc.FullName(), err)
+	}
+	return ret, nil
+}
@@ -0,0 +1,212 @@
+package ctypb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty-debug/ctydebug"
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/zclconf/go-cty-protobuf/internal/testproto"
+)
+
+func TestFromProtobufText(t *testing.T) {
+	mustAny := func(msg proto.Message) *anypb.Any {
+		ret, err := anypb.New(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ret
+	}
+
+	tests := map[string]struct {
+		Text    string
+		Options []Option
+		Want    proto.Message
+		WantErr string
+	}{
+		"scalars and nested message": {
+			Text: `
+				t_string: "hello"
+				t_int32: -5
+				t_bytes: "\x00\xff"
+				t_message { t_nested_field: "nested" }
+			`,
+			Want: &testproto.Assorted{
+				TString:  "hello",
+				TInt32:   -5,
+				TBytes:   []byte{0, 255},
+				TMessage: &testproto.Assorted_Nested{TNestedField: "nested"},
+			},
+		},
+		"empty": {
+			Text: ``,
+			Want: &testproto.Assorted{},
+		},
+		"repeated and maps": {
+			Text: `
+				t_strings: ["a", "b"]
+				t_map_string_bool { key: "x" value: true }
+				t_map_number_bool { key: 2 value: false }
+			`,
+			Want: &testproto.WithRepeated{
+				TStrings:       []string{"a", "b"},
+				TMapStringBool: map[string]bool{"x": true},
+				TMapNumberBool: map[int64]bool{2: false},
+			},
+		},
+		"expanded any": {
+			Text: `
+				t_any {
+					[type.googleapis.com/testproto.Simple] { foo {} }
+				}
+			`,
+			Want: &testproto.WithAny{
+				TAny: mustAny(&testproto.Simple{Foo: &testproto.Empty{}}),
+			},
+		},
+		"extensions": {
+			Text: `
+				name: "base"
+				[testproto.ext_string]: "extended"
+			`,
+			Options: []Option{WithExtensions(testExtensionTypes())},
+			Want: func() proto.Message {
+				msg := &testproto.Extendable{Name: proto.String("base")}
+				proto.SetExtension(msg, testproto.E_ExtString, "extended")
+				return msg
+			}(),
+		},
+		"no such field": {
+			Text:    `t_nope: 1`,
+			Want:    &testproto.Assorted{},
+			WantErr: "invalid testproto.Assorted message: ",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			desc := test.Want.ProtoReflect().Descriptor()
+			got, err := FromProtobufText([]byte(test.Text), desc, test.Options...)
+			if test.WantErr != "" {
+				if err == nil {
+					t.Fatalf("succeeded; want error\nwant: %s", test.WantErr)
+				}
+				// The rest of the message comes from the text parser, whose
+				// error messages are deliberately unstable.
+				if got, want := err.Error(), test.WantErr; !strings.HasPrefix(got, want) {
+					t.Errorf("wrong error\ngot:  %s\nwant: %s...", got, want)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+
+			want, err := FromProtobufMessage(test.Want.ProtoReflect(), test.Options...)
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			if !want.RawEquals(got) {
+				t.Errorf(
+					"wrong result\ngot: %s\nwant: %s",
+					ctydebug.ValueString(got),
+					ctydebug.ValueString(want),
+				)
+			}
+		})
+	}
+}
+
+func TestToProtobufText(t *testing.T) {
+	input := &testproto.Assorted{
+		TString:  "hello",
+		TInt32:   -5,
+		TMessage: &testproto.Assorted_Nested{TNestedField: "nested"},
+	}
+	v, err := FromProtobufMessage(input.ProtoReflect())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	desc := input.ProtoReflect().Descriptor()
+
+	tests := map[string]struct {
+		Options       []Option
+		WantMultiline bool
+	}{
+		"default": {
+			WantMultiline: true,
+		},
+		"single line": {
+			Options:       []Option{WithTextMarshalOptions(prototext.MarshalOptions{})},
+			WantMultiline: false,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			text, err := ToProtobufText(v, desc, test.Options...)
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			// The prototext package deliberately varies its formatting,
+			// so we can only check the result's general shape and that it
+			// parses back to the same message.
+			if got := strings.Contains(strings.TrimSpace(string(text)), "\n"); got != test.WantMultiline {
+				t.Errorf("wrong formatting\ngot:\n%s", text)
+			}
+			got := &testproto.Assorted{}
+			err = prototext.Unmarshal(text, got)
+			if err != nil {
+				t.Fatalf("result is not valid text format: %s\n%s", err, text)
+			}
+			if diff := cmp.Diff(input, got, protocmp.Transform()); diff != "" {
+				t.Errorf("wrong result\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("extensions", func(t *testing.T) {
+		opts := []Option{WithExtensions(testExtensionTypes())}
+		input := &testproto.Extendable{Name: proto.String("base")}
+		proto.SetExtension(input, testproto.E_ExtString, "extended")
+		v, err := FromProtobufMessage(input.ProtoReflect(), opts...)
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		text, err := ToProtobufText(v, input.ProtoReflect().Descriptor(), opts...)
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		got, err := FromProtobufText(text, input.ProtoReflect().Descriptor(), opts...)
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		if !v.RawEquals(got) {
+			t.Errorf(
+				"wrong result after round-trip\ngot: %s\nwant: %s",
+				ctydebug.ValueString(got),
+				ctydebug.ValueString(v),
+			)
+		}
+	})
+	t.Run("invalid value", func(t *testing.T) {
+		attrs := v.AsValueMap()
+		attrs["t_int32"] = cty.StringVal("nope")
+		_, err := ToProtobufText(cty.ObjectVal(attrs), desc)
+		if err == nil {
+			t.Fatalf("succeeded with invalid value; want error")
+		}
+		pathErr, ok := err.(cty.PathError)
+		if !ok {
+			t.Fatalf("error is %T, not cty.PathError: %s", err, err.Error())
+		}
+		if wantPath := cty.GetAttrPath("t_int32"); !pathErr.Path.Equals(wantPath) {
+			t.Errorf("wrong error path\ngot:  %#v\nwant: %#v", pathErr.Path, wantPath)
+		}
+	})
+}
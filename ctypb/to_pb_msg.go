@@ -1,11 +1,17 @@
 package ctypb
 
 import (
+	"context"
 	"encoding/base64"
+	"math/big"
+	"strconv"
+	"strings"
 
 	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
 	"github.com/zclconf/go-cty/cty/gocty"
 	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
 )
 
 // ToProtobufMessage writes the values from the given value into the
@@ -14,14 +20,33 @@ import (
 // The given value must have an object type matching what
 // ImpliedTypeForMessageDesc would return for the message descriptor
 // associated with the message given in "into", or else decoding will
-// fail.
+// fail. If the WithWellKnownHandlers option includes a handler for the
+// type of "into" then the value must instead be of the handler's type, such
+// as a bare string for a google.protobuf.StringValue message.
 //
 // The types in the protocol buffers type system can have a smaller range
 // than the corresponding cty types we convert from, so this function might
 // return an error if the given values are out of range. In those cases,
 // the returned error will be a cty.PathError with a message written to
 // be understood by an end-user who provided whatever data was converted
-// to cty.Value, without mentioning protobuf implementation details.
+// to cty.Value. The message names the field's kind, such as int32, since
+// that's what determines the range, and for the zigzag-encoded kinds sint32
+// and sint64 it also notes that the range is the same as int32 or int64.
+//
+// As a convenience, the value for a repeated field that is not a map field
+// may be a set or a tuple instead of a list, and the value for a map field
+// with string keys may be an object instead of a map. In both cases the
+// elements are converted individually to suit the field. Strings in a
+// tuple for a repeated field of a numeric kind are first converted to
+// numbers, if possible, so that a tuple containing both numbers and
+// strings containing numbers is acceptable.
+//
+// The value for a repeated or map field may also be null, which has the
+// same effect as an empty collection, leaving the field with no elements.
+// FromProtobufMessage never returns null for such fields.
+//
+// At most one of the attributes corresponding to the fields of a oneof may
+// be non-null. If they are all null then the oneof is left unset.
 //
 // In case of any error, the given message may be partially updated.
 //
@@ -29,18 +54,110 @@ import (
 // will return an error if there are any unknown values in the given object.
 // Don't pass marked values to ToProtobufMessage; it will panic if it
 // encounters any values that are marked.
-func ToProtobufMessage(obj cty.Value, into protoreflect.Message) error {
+//
+// ToProtobufMessage pays attention to the following options:
+//   - WithOmitDefaults
+//   - WithIntegerTruncation
+//   - WithNumberCoercion
+//   - WithBytesCapsule
+//   - WithWellKnownStruct
+//   - WithExtensions
+//   - WithEnumValueFunc
+//   - WithEnumObjects
+//   - WithPreserveUnknownFields
+//   - WithBytesAsUTF8Strings
+//   - WithBytesAsNumberLists
+//   - WithInt64AsStrings
+//   - WithWellKnownHandlers
+//   - WithMessageCapsule
+//   - WithFieldNameFunc
+//   - WithFieldConverters
+//   - WithJSONNameFallback
+//   - WithMaxDepth
+//   - WithDefaultMessages
+//   - WithBase64Encoding
+//   - WithIndexedObjectLists
+//   - WithOneofDiscriminator
+func ToProtobufMessage(obj cty.Value, into protoreflect.Message, opts ...Option) error {
+	return Converter{opts: makeOptions(opts)}.ToMessage(obj, into)
+}
+
+// ToProtobufMessageContext is a variant of ToProtobufMessage which checks
+// whether the given context has been cancelled before converting each
+// message, and if so returns the context's error immediately.
+//
+// This is intended for converting large values where the caller might
+// need to abandon the conversion, such as when handling a request that
+// has a deadline. The given message may be partially updated in that case.
+func ToProtobufMessageContext(ctx context.Context, obj cty.Value, into protoreflect.Message, opts ...Option) error {
+	return toProtobufMessageRoot(obj, into, makeOptionsContext(ctx, opts))
+}
+
+// ToProtobufMessageSetFields is a variant of ToProtobufMessage which also
+// returns the paths of all of the attributes whose values it wrote into
+// the message, including those of nested messages.
+//
+// The result includes each attribute that was non-null, and so excludes
+// attributes for absent fields, including those omitted under
+// WithOmitDefaults. The path of an attribute representing a message field
+// appears before the paths of the attributes inside that message, which
+// include an index step for each element of a repeated or map field.
+//
+// This is intended for auditing exactly what was written into a message,
+// such as before merging it into another message. If conversion fails then
+// the result is nil, even though the message may be partially updated.
+func ToProtobufMessageSetFields(obj cty.Value, into protoreflect.Message, opts ...Option) ([]cty.Path, error) {
+	// makeOptions may return the shared default options, so we must
+	// modify only a copy.
+	o := *makeOptions(opts)
+	var paths []cty.Path
+	o.setFields = &paths
+	err := toProtobufMessageRoot(obj, into, &o)
+	if err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+func toProtobufMessageRoot(obj cty.Value, into protoreflect.Message, opts *options) error {
 	path := make(cty.Path, 0, 4)
+	if handler, _ := wellKnownHandlerFor(into.Descriptor(), opts); handler != nil {
+		return toWellKnownHandler(handler, obj, into, opts, path)
+	}
 	if obj.IsNull() {
 		return path.NewErrorf("must not be null")
 	}
 	if !obj.IsKnown() {
 		return path.NewErrorf("value must be known")
 	}
-	return toProtobufMessage(obj, into, path)
+	return toProtobufMessage(obj, into, opts, path)
 }
 
-func toProtobufMessage(obj cty.Value, into protoreflect.Message, path cty.Path) error {
+// NewProtobufMessage is a variant of ToProtobufMessage which allocates a
+// new message of the type described by the given message descriptor, rather
+// than writing into an existing message.
+//
+// The new message is a dynamic message (from package dynamicpb) rather than
+// a value of any Go type generated by the protocol buffers compiler, so this
+// is useful primarily when working with message descriptors that were
+// loaded or constructed at runtime, such as those returned by LoadMessageDesc
+// or built directly using package protodesc.
+//
+// NewProtobufMessage pays attention to the same options as
+// ToProtobufMessage.
+func NewProtobufMessage(obj cty.Value, desc protoreflect.MessageDescriptor, opts ...Option) (protoreflect.Message, error) {
+	msg := dynamicpb.NewMessage(desc)
+	err := ToProtobufMessage(obj, msg, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func toProtobufMessage(obj cty.Value, into protoreflect.Message, opts *options, path cty.Path) error {
+	if err := opts.contextErr(); err != nil {
+		return err
+	}
 
 	desc := into.Descriptor()
 	fields := desc.Fields()
@@ -50,13 +167,27 @@ func toProtobufMessage(obj cty.Value, into protoreflect.Message, path cty.Path)
 		return path.NewErrorf("an object is required")
 	}
 
-	// TODO: Verify that any "oneofs" are well-formed, such
-	// that each one has only one of its fields non-null.
-
+	// Only one field of each oneof may be set, so we track which attribute
+	// was non-null for each one, allocating only if there are any oneofs.
+	var oneofSet map[protoreflect.FullName]string
+	var seen map[string]struct{}
+	if opts.fieldNameFunc != nil {
+		seen = make(map[string]struct{}, fields.Len())
+	}
 	for i := 0; i < fields.Len(); i++ {
 		field := fields.Get(i)
-		name := string(field.Name())
+		name := opts.fieldAttrName(field)
+		if seen != nil {
+			if _, exists := seen[name]; exists {
+				return path.NewErrorf("more than one field has the attribute name %q", name)
+			}
+			seen[name] = struct{}{}
+		}
 
+		name, err := fieldAttrNameIn(ty, field, name, opts, path)
+		if err != nil {
+			return err
+		}
 		if !ty.HasAttribute(name) {
 			return path.NewErrorf("missing required attribute %q", name)
 		}
@@ -65,48 +196,189 @@ func toProtobufMessage(obj cty.Value, into protoreflect.Message, path cty.Path)
 		path := append(path, cty.GetAttrStep{Name: name})
 
 		av := obj.GetAttr(name)
-		err := toProtobufMessageField(into, field, av, path)
+		if oneof := field.ContainingOneof(); oneof != nil && !oneof.IsSynthetic() && !av.IsNull() {
+			if other, exists := oneofSet[oneof.FullName()]; exists {
+				return path.NewErrorf("attributes %q and %q belong to the same oneof %s, so at most one of them may be non-null", other, name, oneof.Name())
+			}
+			if oneofSet == nil {
+				oneofSet = make(map[protoreflect.FullName]string)
+			}
+			oneofSet[oneof.FullName()] = name
+		}
+		err = toProtobufMessageField(into, field, av, opts, path)
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.extensionTypes != nil {
+		var err error
+		opts.extensionTypes.RangeExtensionsByMessage(desc.FullName(), func(xt protoreflect.ExtensionType) bool {
+			field := xt.TypeDescriptor()
+			name := string(field.FullName())
+			if seen != nil {
+				// A FieldNameFunc might have chosen the same name.
+				if _, exists := seen[name]; exists {
+					err = path.NewErrorf("more than one field has the attribute name %q", name)
+					return false
+				}
+			}
+
+			if !ty.HasAttribute(name) {
+				err = path.NewErrorf("missing required attribute %q", name)
+				return false
+			}
+
+			// Temporarily extend path with new attribute name
+			path := append(path, cty.GetAttrStep{Name: name})
+
+			av := obj.GetAttr(name)
+			err = toProtobufMessageField(into, field, av, opts, path)
+			return err == nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, oneof := range discriminatedOneofs(desc, opts) {
+		name := string(oneof.Name())
+		if seen != nil {
+			// A FieldNameFunc might have chosen the same name.
+			if _, exists := seen[name]; exists {
+				return path.NewErrorf("attribute %q for oneof discriminator conflicts with a field of the same name", name)
+			}
+		} else if desc.Fields().ByName(oneof.Name()) != nil {
+			return path.NewErrorf("attribute %q for oneof discriminator conflicts with a field of the same name", name)
+		}
+		if !ty.HasAttribute(name) {
+			return path.NewErrorf("missing required attribute %q", name)
+		}
+
+		// Temporarily extend path with new attribute name
+		path := append(path, cty.GetAttrStep{Name: name})
+
+		err := checkOneofDiscriminator(obj.GetAttr(name), oneof, oneofSet[oneof.FullName()], path)
 		if err != nil {
 			return err
 		}
 	}
 
+	if name := opts.unknownFieldsAttr; name != "" {
+		if !ty.HasAttribute(name) {
+			return path.NewErrorf("missing required attribute %q", name)
+		}
+
+		// Temporarily extend path with new attribute name
+		path := append(path, cty.GetAttrStep{Name: name})
+
+		av := obj.GetAttr(name)
+		if !av.IsKnown() {
+			return path.NewErrorf("value must be known")
+		}
+		var raw []byte
+		if !av.IsNull() {
+			opts.recordSetField(path)
+			var err error
+			raw, err = toProtobufBytes(av, opts, path)
+			if err != nil {
+				return err
+			}
+		}
+		into.SetUnknown(protoreflect.RawFields(raw))
+	}
+
 	return nil
 }
 
-func toProtobufMessageField(msg protoreflect.Message, field protoreflect.FieldDescriptor, v cty.Value, path cty.Path) error {
+// fieldAttrNameIn returns the name of the attribute of the given object type
+// that represents the given field, whose attribute name is usually the given
+// name. When WithJSONNameFallback is in effect and the object type has no
+// attribute of the usual name, that's the field's JSON name instead.
+//
+// The result is the usual name if there's no attribute of either name, so
+// that the caller reports the usual name as missing.
+func fieldAttrNameIn(ty cty.Type, field protoreflect.FieldDescriptor, name string, opts *options, path cty.Path) (string, error) {
+	if !opts.jsonNameFallback {
+		return name, nil
+	}
+	jsonName := field.JSONName()
+	if jsonName == name || !ty.HasAttribute(jsonName) {
+		return name, nil
+	}
+	if ty.HasAttribute(name) {
+		return "", path.NewErrorf("attributes %q and %q both represent field %s, so only one of them may be present", name, jsonName, field.Name())
+	}
+	return jsonName, nil
+}
+
+func toProtobufMessageField(msg protoreflect.Message, field protoreflect.FieldDescriptor, v cty.Value, opts *options, path cty.Path) error {
+	// A null value always represents an absent field, regardless of its
+	// type. In particular, a null for a message field doesn't need to have
+	// exactly the nested message's object type, which allows callers to
+	// use e.g. cty.NullVal(cty.DynamicPseudoType) for any unset field.
 	if v.IsNull() {
 		msg.Clear(field)
-		if !field.HasPresence() {
+		switch {
+		case field.HasPresence():
+			return nil
+		case field.Cardinality() == protoreflect.Repeated:
+			// Repeated fields never track presence, but we accept null
+			// as a shorthand for an empty collection.
+			return nil
+		case opts.omitDefaults:
+			// WithOmitDefaults makes FromProtobufMessage return null for
+			// zero values of these fields, so we accept the same here.
+			return nil
+		case isWellKnownNullValue(field, opts):
+			// Clearing a field that doesn't track presence sets it to
+			// zero, which is the only member of NullValue.
+			return nil
+		default:
 			return path.NewErrorf("must not be null")
 		}
-		return nil
 	}
 	if !v.IsKnown() {
 		return path.NewErrorf("value must be known")
 	}
 	ty := v.Type()
+	opts.recordSetField(path)
 
 	switch {
 	case field.IsMap():
-		keyField := field.MapKey()
-		valField := field.MapValue()
+		path := reservePath(path)
+		keyField, valField, err := mapEntryFields(field, path)
+		if err != nil {
+			return err
+		}
 		// We have a different representation for maps with string keys vs.
 		// maps with other key types.
 		switch {
 		case keyField.Kind() == protoreflect.StringKind:
-			// Should be a cty.Map whose element type corresponds with valField.
-			if !ty.IsMapType() {
+			// Should be a cty.Map whose element type corresponds with
+			// valField, but we also accept an object type whose attribute
+			// names are the keys, because the two are often used
+			// interchangeably for string-keyed collections.
+			if !(ty.IsMapType() || ty.IsObjectType()) {
 				return path.NewErrorf("a map is required")
 			}
 			protoMap := msg.NewField(field).Map()
 			for it := v.ElementIterator(); it.Next(); {
 				ek, ev := it.Element()
-				path := append(path, cty.IndexStep{Key: ek})
+
+				// Temporarily extend path with new attribute name or index
+				var step cty.PathStep = cty.IndexStep{Key: ek}
+				if ty.IsObjectType() {
+					step = cty.GetAttrStep{Name: ek.AsString()}
+				}
+				path := append(path, step)
+				if err := requireKnownElem(ev, valField, opts, path); err != nil {
+					return err
+				}
 				ekProto := protoreflect.MapKey(protoreflect.ValueOfString(ek.AsString()))
 				evProto, err := toProtobufValue(ev, valField, func() protoreflect.Value {
 					return protoMap.Mutable(ekProto)
-				}, path)
+				}, opts, path)
 				if err != nil {
 					return err
 				}
@@ -115,43 +387,60 @@ func toProtobufMessageField(msg protoreflect.Message, field protoreflect.FieldDe
 			msg.Set(field, protoreflect.ValueOfMap(protoMap))
 		default:
 			// Should be a cty.Set whose element type is an object with
-			// key and value attributes.
-			if !ty.IsSetType() {
-				return path.NewErrorf("a set of objects is required")
-			}
-			ety := ty.ElementType()
-			if !ety.IsObjectType() {
+			// key and value attributes, but we also accept a tuple of
+			// such objects, which FromProtobufMessage produces when the
+			// values have different types.
+			switch {
+			case ty.IsSetType():
+				if err := requireMapEntryType(ty.ElementType(), "set", path); err != nil {
+					return err
+				}
+			case ty.IsTupleType():
+				for _, ety := range ty.TupleElementTypes() {
+					if err := requireMapEntryType(ety, "tuple", path); err != nil {
+						return err
+					}
+				}
+			default:
 				return path.NewErrorf("a set of objects is required")
 			}
-			atys := ety.AttributeTypes()
-			if _, exists := atys["key"]; !exists {
-				return path.NewErrorf("set element type must have attribute \"key\"")
-			}
-			if _, exists := atys["value"]; !exists {
-				return path.NewErrorf("set element type must have attribute \"value\"")
-			}
-			if len(atys) != 2 {
-				return path.NewErrorf("set element type must only have attributes \"key\" and \"value\"")
-			}
 			protoMap := msg.NewField(field).Map()
 			// In this case we'll decode into the message type that the
 			// proto compiler generated to represent the map elements,
 			// since our element type ought to be compatible with it.
 			msg.Clear(field)
 			for it := v.ElementIterator(); it.Next(); {
-				_, ev := it.Element()
-				path := append(path, cty.IndexStep{Key: ev})
+				ek, ev := it.Element()
+				step := cty.IndexStep{Key: ev}
+				if ty.IsTupleType() {
+					step = cty.IndexStep{Key: ek}
+				}
+				path := append(path, step)
+				if err := requireKnownNonNull(ev, path); err != nil {
+					return err
+				}
 
 				keyVal := ev.GetAttr("key")
 				valVal := ev.GetAttr("value")
+				if err := requireKnownNonNull(keyVal, append(path, cty.GetAttrStep{Name: "key"})); err != nil {
+					return err
+				}
+				if err := requireKnownElem(valVal, valField, opts, append(path, cty.GetAttrStep{Name: "value"})); err != nil {
+					return err
+				}
 
-				keyProto, err := toProtobufValue(keyVal, keyField, nil, path)
+				keyProto, err := toProtobufValue(keyVal, keyField, nil, opts, append(path, cty.GetAttrStep{Name: "key"}))
 				if err != nil {
 					return err
 				}
+				// A cty set only guarantees that its whole elements are
+				// distinct, so two elements may still share the same key.
+				if protoMap.Has(protoreflect.MapKey(keyProto)) {
+					return path.NewErrorf("duplicate map key %s", protoreflect.MapKey(keyProto).String())
+				}
 				valProto, err := toProtobufValue(valVal, valField, func() protoreflect.Value {
 					return protoMap.Mutable(protoreflect.MapKey(keyProto))
-				}, path)
+				}, opts, append(path, cty.GetAttrStep{Name: "value"}))
 				if err != nil {
 					return err
 				}
@@ -161,20 +450,61 @@ func toProtobufMessageField(msg protoreflect.Message, field protoreflect.FieldDe
 			msg.Set(field, protoreflect.ValueOfMap(protoMap))
 		}
 	case field.IsList():
-		if !ty.IsListType() {
+		path := reservePath(path)
+		// We also accept sets and tuples here, because they can be
+		// produced by expressions that were intended to be lists, such
+		// as tuple constructors in HCL. The elements of a set are
+		// written in cty's own set iteration order.
+		var steps []cty.PathStep
+		if opts.indexedObjectLists && (ty.IsObjectType() || ty.IsMapType()) && isMessageField(field) {
+			var err error
+			v, steps, err = indexedObjectList(v, path)
+			if err != nil {
+				return err
+			}
+			ty = v.Type()
+		}
+		if !(ty.IsListType() || ty.IsSetType() || ty.IsTupleType()) {
 			return path.NewErrorf("a list is required")
 		}
+		var ety cty.Type
+		if ty.IsTupleType() {
+			var err error
+			ety, err = impliedTypeForFieldKind(field, opts, path)
+			if err != nil {
+				return err
+			}
+		}
 		msg.Clear(field)
 		protoList := msg.NewField(field).List()
-		for it := v.ElementIterator(); it.Next(); {
-			_, ev := it.Element()
-			path := append(path, cty.IndexStep{Key: ev})
+		for i, it := 0, v.ElementIterator(); it.Next(); i++ {
+			ek, ev := it.Element()
+			var step cty.PathStep = cty.IndexStep{Key: ek}
+			if steps != nil {
+				step = steps[i]
+			}
+			path := append(path, step)
+			if err := requireKnownElem(ev, field, opts, path); err != nil {
+				return err
+			}
+			if ty.IsTupleType() && ety.Equals(cty.Number) && ev.Type().Equals(cty.String) {
+				// The elements of a tuple can each have a different type,
+				// such as when a tuple constructor mixes numbers with
+				// strings containing numbers, so we'll convert strings to
+				// numbers where possible. We don't do any other conversions
+				// here so that a tuple is checked as strictly as a list
+				// would be, and if this one fails then toProtobufValue will
+				// report a suitable error below.
+				if converted, err := convert.Convert(ev, ety); err == nil {
+					ev = converted
+				}
+			}
 
 			alreadyAppended := false
 			evProto, err := toProtobufValue(ev, field, func() protoreflect.Value {
 				alreadyAppended = true
 				return protoList.AppendMutable()
-			}, path)
+			}, opts, path)
 			if err != nil {
 				return err
 			}
@@ -186,16 +516,131 @@ func toProtobufMessageField(msg protoreflect.Message, field protoreflect.FieldDe
 	default:
 		vProto, err := toProtobufValue(v, field, func() protoreflect.Value {
 			return msg.Mutable(field)
-		}, path)
+		}, opts, path)
 		if err != nil {
 			return err
 		}
 		msg.Set(field, vProto)
+		if opts.defaultMessages && defaultMessageField(field, opts) && isEmptyMessage(vProto.Message()) {
+			// FromProtobufMessage would've produced this value for an
+			// absent field, so we'll make it absent again.
+			msg.Clear(field)
+		}
+	}
+
+	return nil
+}
+
+// isEmptyMessage returns true if the given message has no populated fields
+// and no unknown fields.
+func isEmptyMessage(msg protoreflect.Message) bool {
+	empty := true
+	msg.Range(func(protoreflect.FieldDescriptor, protoreflect.Value) bool {
+		empty = false
+		return false
+	})
+	return empty && len(msg.GetUnknown()) == 0
+}
+
+// requireMapEntryType returns an error if the given type, which is the type
+// of an element of the given kind of collection representing a map field
+// whose keys aren't strings, isn't an object type with exactly the
+// attributes "key" and "value".
+func requireMapEntryType(ety cty.Type, collection string, path cty.Path) error {
+	if !ety.IsObjectType() {
+		return path.NewErrorf("a set of objects is required")
+	}
+	atys := ety.AttributeTypes()
+	if _, exists := atys["key"]; !exists {
+		return path.NewErrorf("%s element type must have attribute \"key\"", collection)
+	}
+	if _, exists := atys["value"]; !exists {
+		return path.NewErrorf("%s element type must have attribute \"value\"", collection)
 	}
+	if len(atys) != 2 {
+		return path.NewErrorf("%s element type must only have attributes \"key\" and \"value\"", collection)
+	}
+	return nil
+}
 
+// requireKnownNonNull returns an error if the given value, which is an
+// element of a collection, is null or unknown. Protocol buffers has no way
+// to represent null elements in repeated fields or maps.
+func requireKnownNonNull(v cty.Value, path cty.Path) error {
+	if v.IsNull() {
+		return path.NewErrorf("must not be null")
+	}
+	if !v.IsKnown() {
+		return path.NewErrorf("value must be known")
+	}
 	return nil
 }
 
+// isMessageField returns true if the elements of the given field are
+// messages.
+func isMessageField(field protoreflect.FieldDescriptor) bool {
+	kind := field.Kind()
+	return kind == protoreflect.MessageKind || kind == protoreflect.GroupKind
+}
+
+// indexedObjectList converts an object or map whose attribute names or keys
+// are decimal indices into a tuple of its elements in index order, for
+// WithIndexedObjectLists. It also returns the path step that leads to each
+// element of the given value, for use in error messages.
+func indexedObjectList(v cty.Value, path cty.Path) (cty.Value, []cty.PathStep, error) {
+	if !v.IsKnown() || v.IsNull() {
+		// The caller deals with these before asking for a list.
+		return v, nil, nil
+	}
+	n := v.LengthInt()
+	elems := make([]cty.Value, n)
+	steps := make([]cty.PathStep, n)
+	for it := v.ElementIterator(); it.Next(); {
+		ek, ev := it.Element()
+		key := ek.AsString()
+		var step cty.PathStep = cty.GetAttrStep{Name: key}
+		if v.Type().IsMapType() {
+			step = cty.IndexStep{Key: ek}
+		}
+		idx, err := strconv.Atoi(key)
+		if err != nil || idx < 0 || strconv.Itoa(idx) != key {
+			return v, nil, append(path, step).NewErrorf("element key must be a decimal index")
+		}
+		if idx >= n {
+			// There must be a missing index below this one, which
+			// we'll find below.
+			continue
+		}
+		elems[idx] = ev
+		steps[idx] = step
+	}
+	for i, step := range steps {
+		if step == nil {
+			return v, nil, path.NewErrorf("missing element %d", i)
+		}
+	}
+	if n == 0 {
+		return cty.EmptyTupleVal, steps, nil
+	}
+	return cty.TupleVal(elems), steps, nil
+}
+
+// requireKnownElem is like requireKnownNonNull except that it also accepts
+// null for a field of the well-known NullValue type, which represents all
+// of its values as null, and for a field of the well-known Value type,
+// which can contain null.
+func requireKnownElem(v cty.Value, field protoreflect.FieldDescriptor, opts *options, path cty.Path) error {
+	if v.IsNull() && isWellKnownNullValue(field, opts) {
+		return nil
+	}
+	if v.IsNull() && opts.wellKnownStruct && field.Kind() == protoreflect.MessageKind && field.Message().FullName() == valueFullName {
+		// A google.protobuf.Value element can contain null, which is how
+		// FromProtobufMessage represents one.
+		return nil
+	}
+	return requireKnownNonNull(v, path)
+}
+
 // toProtobufValue is a pretty awkward function that deals with decoding
 // individual cty values into arbitrary protocol buffers values. This is
 // made particularly awkward because protoreflect handles differently
@@ -212,10 +657,17 @@ func toProtobufMessageField(msg protoreflect.Message, field protoreflect.FieldDe
 // same value, so the caller might choose not to reassign it in that case,
 // but it also doesn't hurt to assign it again for simplicity's sake.
 //
-// toProtobufValue can't deal with null or unknown values. The caller
-// should deal with that first, before calling.
-func toProtobufValue(v cty.Value, field protoreflect.FieldDescriptor, mut func() protoreflect.Value, path cty.Path) (protoreflect.Value, error) {
+// toProtobufValue can't deal with null or unknown values, except for the
+// null values accepted by requireKnownElem. The caller should deal with
+// that first, before calling.
+func toProtobufValue(v cty.Value, field protoreflect.FieldDescriptor, mut func() protoreflect.Value, opts *options, path cty.Path) (protoreflect.Value, error) {
 	var nothing protoreflect.Value
+	if conv, err := fieldConverterFor(field, opts, path); conv != nil || err != nil {
+		if err != nil {
+			return nothing, err
+		}
+		return toFieldConverter(conv, v, field, path)
+	}
 	kind := field.Kind()
 	ty := v.Type()
 	switch kind {
@@ -230,76 +682,281 @@ func toProtobufValue(v cty.Value, field protoreflect.FieldDescriptor, mut func()
 		}
 		return protoreflect.ValueOfString(v.AsString()), nil
 	case protoreflect.BytesKind:
-		if !cty.String.Equals(ty) {
-			return nothing, path.NewErrorf("a string containing base64 bytes is required")
+		if opts.bytesAsUTF8 {
+			if !cty.String.Equals(ty) {
+				return nothing, path.NewErrorf("a string is required")
+			}
+			return protoreflect.ValueOfBytes([]byte(v.AsString())), nil
+		}
+		if opts.bytesAsNumbers {
+			bytes, err := toProtobufByteNumbers(v, path)
+			if err != nil {
+				return nothing, err
+			}
+			return protoreflect.ValueOfBytes(bytes), nil
 		}
-		b64s := v.AsString()
-		bytes, err := base64.StdEncoding.DecodeString(b64s)
+		bytes, err := toProtobufBytes(v, opts, path)
 		if err != nil {
-			return nothing, path.NewErrorf("string must contain base64-encoded bytes")
+			return nothing, err
 		}
 		return protoreflect.ValueOfBytes(bytes), nil
 	case protoreflect.EnumKind:
+		if isWellKnownNullValue(field, opts) {
+			if !v.IsNull() {
+				return nothing, path.NewErrorf("must be null")
+			}
+			return protoreflect.ValueOfEnum(0), nil
+		}
+		if opts.enumObjects {
+			num, err := toEnumObject(v, field.Enum(), opts, path)
+			if err != nil {
+				return nothing, err
+			}
+			return protoreflect.ValueOfEnum(num), nil
+		}
 		if !cty.String.Equals(ty) {
 			return nothing, path.NewErrorf("a string containing a keyword is required")
 		}
-		name := protoreflect.Name(v.AsString())
-		enumDesc := field.Enum()
-		optionDesc := enumDesc.Values().ByName(name)
+		optionDesc := findEnumValue(field.Enum(), v.AsString(), opts)
 		if optionDesc == nil {
 			return nothing, path.NewErrorf("value isn't one of the expected keywords")
 		}
 		return protoreflect.ValueOfEnum(optionDesc.Number()), nil
-	case protoreflect.MessageKind:
+	case protoreflect.MessageKind, protoreflect.GroupKind:
 		msg := mut().Message()
-		err := toProtobufMessage(v, msg, path)
+		if handler, _ := wellKnownHandlerFor(field.Message(), opts); handler != nil {
+			err := toWellKnownHandler(handler, v, msg, opts, path)
+			if err != nil {
+				return nothing, err
+			}
+			return protoreflect.ValueOfMessage(msg), nil
+		}
+		if opts.wellKnownStruct && isWellKnownStruct(field.Message()) {
+			err := toWellKnownStructMessage(v, msg, path)
+			if err != nil {
+				return nothing, err
+			}
+			return protoreflect.ValueOfMessage(msg), nil
+		}
+		nested, tooDeep := opts.nestedMessage()
+		if tooDeep {
+			return nothing, path.NewErrorf("message is nested more than %d levels deep", opts.maxDepth)
+		}
+		err := toProtobufMessage(v, msg, nested, reservePath(path))
 		if err != nil {
 			return nothing, err
 		}
 		return protoreflect.ValueOfMessage(msg), nil
 	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		v, err := toProtobufIntegerNumber(v, kind, opts, path)
+		if err != nil {
+			return nothing, err
+		}
 		var n int32
-		err := gocty.FromCtyValue(v, &n)
+		err = gocty.FromCtyValue(v, &n)
 		if err != nil {
-			return nothing, path.NewError(err)
+			return nothing, numberConversionError(v, kind, err, path)
 		}
 		return protoreflect.ValueOfInt32(n), nil
 	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		v, err := toProtobufIntegerNumber(v, kind, opts, path)
+		if err != nil {
+			return nothing, err
+		}
 		var n uint32
-		err := gocty.FromCtyValue(v, &n)
+		err = gocty.FromCtyValue(v, &n)
 		if err != nil {
-			return nothing, path.NewError(err)
+			return nothing, numberConversionError(v, kind, err, path)
 		}
 		return protoreflect.ValueOfUint32(n), nil
 	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		v, err := toProtobufIntegerNumber(v, kind, opts, path)
+		if err != nil {
+			return nothing, err
+		}
 		var n int64
-		err := gocty.FromCtyValue(v, &n)
+		err = gocty.FromCtyValue(v, &n)
 		if err != nil {
-			return nothing, path.NewError(err)
+			return nothing, numberConversionError(v, kind, err, path)
 		}
 		return protoreflect.ValueOfInt64(n), nil
 	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		v, err := toProtobufIntegerNumber(v, kind, opts, path)
+		if err != nil {
+			return nothing, err
+		}
 		var n uint64
-		err := gocty.FromCtyValue(v, &n)
+		err = gocty.FromCtyValue(v, &n)
 		if err != nil {
-			return nothing, path.NewError(err)
+			return nothing, numberConversionError(v, kind, err, path)
 		}
 		return protoreflect.ValueOfUint64(n), nil
 	case protoreflect.FloatKind:
+		v, err := toProtobufNumber(v, kind, opts, path)
+		if err != nil {
+			return nothing, err
+		}
 		var n float32
-		err := gocty.FromCtyValue(v, &n)
+		err = gocty.FromCtyValue(v, &n)
 		if err != nil {
-			return nothing, path.NewError(err)
+			return nothing, numberConversionError(v, kind, err, path)
 		}
 		return protoreflect.ValueOfFloat32(n), nil
 	case protoreflect.DoubleKind:
+		v, err := toProtobufNumber(v, kind, opts, path)
+		if err != nil {
+			return nothing, err
+		}
 		var n float64
-		err := gocty.FromCtyValue(v, &n)
+		err = gocty.FromCtyValue(v, &n)
 		if err != nil {
-			return nothing, path.NewError(err)
+			return nothing, numberConversionError(v, kind, err, path)
 		}
 		return protoreflect.ValueOfFloat64(n), nil
 	default:
-		return nothing, path.NewErrorf("no cty equivalent for protobuf kind %s", kind.String())
+		return nothing, path.NewErrorf("field %s has protobuf kind %s, which has no cty equivalent", field.FullName(), kind.String())
+	}
+}
+
+// toProtobufBytes returns the bytes represented by the given value, which
+// should be of the type returned by impliedTypeForBytes.
+//
+// toProtobufBytes can't deal with null or unknown values. The caller
+// should deal with that first, before calling.
+func toProtobufBytes(v cty.Value, opts *options, path cty.Path) ([]byte, error) {
+	ty := v.Type()
+	if opts.bytesCapsule {
+		if !BytesCapsuleType.Equals(ty) {
+			return nil, path.NewErrorf("a bytes value is required")
+		}
+		// The message must not share the capsule's underlying array,
+		// because cty values are immutable.
+		return append([]byte(nil), *v.EncapsulatedValue().(*[]byte)...), nil
+	}
+	if !cty.String.Equals(ty) {
+		return nil, path.NewErrorf("a string containing base64 bytes is required")
+	}
+	b64s := v.AsString()
+	enc := opts.base64()
+	bytes, err := enc.DecodeString(b64s)
+	if err != nil {
+		if strings.HasSuffix(b64s, "=") && !hasBase64Padding(enc) {
+			return nil, path.NewErrorf("string must contain base64-encoded bytes without padding")
+		}
+		return nil, path.NewErrorf("string must contain base64-encoded bytes")
+	}
+	return bytes, nil
+}
+
+// hasBase64Padding returns true if the given encoding uses padding
+// characters, which the base64 package doesn't otherwise expose.
+func hasBase64Padding(enc *base64.Encoding) bool {
+	return len(enc.EncodeToString([]byte{0})) == 4
+}
+
+// toProtobufByteNumbers returns the bytes represented by the given list or
+// tuple of numbers, for use with the bytes-as-numbers option.
+//
+// toProtobufByteNumbers can't deal with a null or unknown collection. The
+// caller should deal with that first, before calling.
+func toProtobufByteNumbers(v cty.Value, path cty.Path) ([]byte, error) {
+	ty := v.Type()
+	if !(ty.IsListType() || ty.IsTupleType()) {
+		return nil, path.NewErrorf("a list of numbers is required")
+	}
+	ret := make([]byte, 0, v.LengthInt())
+	for it := v.ElementIterator(); it.Next(); {
+		ek, ev := it.Element()
+		// Temporarily extend path with the element index
+		path := append(path, cty.IndexStep{Key: ek})
+		if ev.IsNull() {
+			return nil, path.NewErrorf("must not be null")
+		}
+		if !ev.IsKnown() {
+			return nil, path.NewErrorf("value must be known")
+		}
+		if !cty.Number.Equals(ev.Type()) {
+			return nil, path.NewErrorf("a number is required")
+		}
+		bf := ev.AsBigFloat()
+		n, acc := bf.Int64()
+		if acc != big.Exact || n < 0 || n > 255 {
+			return nil, path.NewErrorf("value %s is not a byte; must be a whole number between 0 and 255", formatNumber(ev))
+		}
+		ret = append(ret, byte(n))
+	}
+	return ret, nil
+}
+
+// toProtobufIntegerNumber checks whether the given value is suitable for
+// assignment to a field of one of the integer kinds, returning an error if
+// not.
+//
+// If the given value is a number with a fractional part then by default
+// that's an error, but if the integer truncation option is enabled then
+// the result is instead the given number truncated towards zero.
+//
+// If the given value isn't a number at all then toProtobufIntegerNumber
+// returns it verbatim, leaving the caller to report a type error.
+func toProtobufIntegerNumber(v cty.Value, kind protoreflect.Kind, opts *options, path cty.Path) (cty.Value, error) {
+	v, err := toProtobufNumber(v, kind, opts, path)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	if !cty.Number.Equals(v.Type()) {
+		return v, nil
+	}
+	bf := v.AsBigFloat()
+	if bf.IsInt() {
+		return v, nil
+	}
+	// Infinity isn't a whole number either, but there's no whole number
+	// to truncate it to.
+	if !opts.integerTruncation || bf.IsInf() {
+		return cty.NilVal, path.NewErrorf("value %s is not a whole number", formatNumber(v))
+	}
+	bi, _ := bf.Int(nil) // truncates towards zero
+	return cty.NumberVal(new(big.Float).SetInt(bi)), nil
+}
+
+// toProtobufNumber returns the given value converted to a number if it's
+// a string and either number coercion is enabled or the int64-as-strings
+// option applies to the given kind, or otherwise returns the given value
+// unchanged so that the caller can report a value of the wrong type.
+func toProtobufNumber(v cty.Value, kind protoreflect.Kind, opts *options, path cty.Path) (cty.Value, error) {
+	if !cty.String.Equals(v.Type()) {
+		return v, nil
+	}
+	if !opts.numberCoercion && !(opts.int64AsStrings && isInt64Kind(kind)) {
+		return v, nil
+	}
+	nv, err := convert.Convert(v, cty.Number)
+	if err != nil {
+		return cty.NilVal, path.NewErrorf("a number is required, or a string containing a number, but %q is not a number", v.AsString())
+	}
+	return nv, nil
+}
+
+// numberConversionError wraps an error from converting the given value to
+// a Go numeric type for a field of the given kind, adding the value itself
+// to the message if it's a number so that it's easier to find in a large
+// input.
+func numberConversionError(v cty.Value, kind protoreflect.Kind, err error, path cty.Path) error {
+	if !cty.Number.Equals(v.Type()) {
+		return path.NewError(err)
+	}
+	switch kind {
+	case protoreflect.Sint32Kind, protoreflect.Sint64Kind:
+		// The "sint" kinds differ from the "int" kinds only in their
+		// zigzag wire encoding, which users might not realize.
+		return path.NewErrorf("invalid value %s for %s field, which has the same range as %s: %s", formatNumber(v), kind, strings.TrimPrefix(kind.String(), "s"), err)
+	default:
+		return path.NewErrorf("invalid value %s for %s field: %s", formatNumber(v), kind, err)
 	}
 }
+
+// formatNumber returns a decimal representation of the given known,
+// non-null number value, for use in error messages.
+func formatNumber(v cty.Value) string {
+	return v.AsBigFloat().Text('f', -1)
+}
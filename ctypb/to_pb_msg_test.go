@@ -1,14 +1,25 @@
 package ctypb
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"math"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/zclconf/go-cty-protobuf/internal/testproto"
 	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
 	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 func TestToProtobufMessage(t *testing.T) {
@@ -25,10 +36,25 @@ func TestToProtobufMessage(t *testing.T) {
 		}
 		return v
 	}
+	mustStruct := func(v map[string]interface{}) *structpb.Struct {
+		ret, err := structpb.NewStruct(v)
+		if err != nil {
+			panic(err)
+		}
+		return ret
+	}
+	mustList := func(v []interface{}) *structpb.ListValue {
+		ret, err := structpb.NewList(v)
+		if err != nil {
+			panic(err)
+		}
+		return ret
+	}
 
 	tests := map[string]struct {
 		Value   cty.Value
 		Into    protoreflect.ProtoMessage
+		Options []Option
 		Want    protoreflect.ProtoMessage
 		WantErr string
 	}{
@@ -56,6 +82,63 @@ func TestToProtobufMessage(t *testing.T) {
 			Into: &testproto.Assorted{},
 			Want: &testproto.Assorted{},
 		},
+		"assorted all null": {
+			// This is the representation that FromProtobufMessage
+			// produces for an empty message when using WithOmitDefaults,
+			// so with that option null resets fields without presence to
+			// zero.
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_bool":    cty.NullVal(cty.Bool),
+				"t_bytes":   cty.NullVal(cty.String),
+				"t_double":  cty.NullVal(cty.Number),
+				"t_fixed32": cty.NullVal(cty.Number),
+				"t_fixed64": cty.NullVal(cty.Number),
+				"t_float":   cty.NullVal(cty.Number),
+				"t_int32":   cty.NullVal(cty.Number),
+				"t_int64":   cty.NullVal(cty.Number),
+				"t_message": cty.NullVal(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_sfixed32": cty.NullVal(cty.Number),
+				"t_sfixed64": cty.NullVal(cty.Number),
+				"t_sint32":   cty.NullVal(cty.Number),
+				"t_sint64":   cty.NullVal(cty.Number),
+				"t_string":   cty.NullVal(cty.String),
+				"t_uint32":   cty.NullVal(cty.Number),
+				"t_uint64":   cty.NullVal(cty.Number),
+			}),
+			Into: &testproto.Assorted{
+				TBool:   true,
+				TInt32:  5,
+				TString: "previous",
+			},
+			Options: []Option{WithOmitDefaults()},
+			Want:    &testproto.Assorted{},
+		},
+		"assorted null without omitting defaults": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_bool":    cty.NullVal(cty.Bool),
+				"t_bytes":   cty.StringVal(""),
+				"t_double":  cty.NumberIntVal(0),
+				"t_fixed32": cty.NumberIntVal(0),
+				"t_fixed64": cty.NumberIntVal(0),
+				"t_float":   cty.NumberIntVal(0),
+				"t_int32":   cty.NumberIntVal(0),
+				"t_int64":   cty.NumberIntVal(0),
+				"t_message": cty.NullVal(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_sfixed32": cty.NumberIntVal(0),
+				"t_sfixed64": cty.NumberIntVal(0),
+				"t_sint32":   cty.NumberIntVal(0),
+				"t_sint64":   cty.NumberIntVal(0),
+				"t_string":   cty.StringVal(""),
+				"t_uint32":   cty.NumberIntVal(0),
+				"t_uint64":   cty.NumberIntVal(0),
+			}),
+			Into:    &testproto.Assorted{},
+			WantErr: "must not be null",
+		},
 		"assorted all set": {
 			Value: cty.ObjectVal(map[string]cty.Value{
 				"t_bool":    cty.True,
@@ -99,111 +182,523 @@ func TestToProtobufMessage(t *testing.T) {
 				TUint64:   10,
 			},
 		},
-		"optional all unset": {
+		"assorted fractional integer": {
 			Value: cty.ObjectVal(map[string]cty.Value{
-				"int32_opt":   cty.NullVal(cty.Number),
-				"int32_req":   cty.NumberIntVal(0),
-				"message_opt": cty.NullVal(cty.EmptyObject), // message fields always track presence
-				"message_req": cty.NullVal(cty.EmptyObject),
-				"string_opt":  cty.NullVal(cty.String),
-				"string_req":  cty.StringVal(""),
+				"t_bool":    cty.False,
+				"t_bytes":   cty.StringVal(""),
+				"t_double":  cty.NumberIntVal(0),
+				"t_fixed32": cty.NumberIntVal(0),
+				"t_fixed64": cty.NumberIntVal(0),
+				"t_float":   cty.NumberIntVal(0),
+				"t_int32":   cty.NumberIntVal(0),
+				"t_int64":   cty.NumberFloatVal(1.5),
+				"t_message": cty.NullVal(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_sfixed32": cty.NumberIntVal(0),
+				"t_sfixed64": cty.NumberIntVal(0),
+				"t_sint32":   cty.NumberIntVal(0),
+				"t_sint64":   cty.NumberIntVal(0),
+				"t_string":   cty.StringVal(""),
+				"t_uint32":   cty.NumberIntVal(0),
+				"t_uint64":   cty.NumberIntVal(0),
 			}),
-			Into: &testproto.WithOptional{},
-			Want: &testproto.WithOptional{},
+			Into:    &testproto.Assorted{},
+			WantErr: "value 1.5 is not a whole number",
 		},
-		"optional all set": {
+		"assorted infinite integer with truncation": {
 			Value: cty.ObjectVal(map[string]cty.Value{
-				"int32_opt":   cty.NumberIntVal(13),
-				"int32_req":   cty.NumberIntVal(12),
-				"message_opt": cty.EmptyObjectVal,
-				"message_req": cty.EmptyObjectVal,
-				"string_opt":  cty.StringVal("hi optional"),
-				"string_req":  cty.StringVal("hi required"),
+				"t_bool":    cty.False,
+				"t_bytes":   cty.StringVal(""),
+				"t_double":  cty.NumberIntVal(0),
+				"t_fixed32": cty.NumberIntVal(0),
+				"t_fixed64": cty.NumberIntVal(0),
+				"t_float":   cty.NumberIntVal(0),
+				"t_int32":   cty.NumberIntVal(0),
+				"t_int64":   cty.NegativeInfinity,
+				"t_message": cty.NullVal(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_sfixed32": cty.NumberIntVal(0),
+				"t_sfixed64": cty.NumberIntVal(0),
+				"t_sint32":   cty.NumberIntVal(0),
+				"t_sint64":   cty.NumberIntVal(0),
+				"t_string":   cty.StringVal(""),
+				"t_uint32":   cty.NumberIntVal(0),
+				"t_uint64":   cty.NumberIntVal(0),
 			}),
-			Into: &testproto.WithOptional{},
-			Want: &testproto.WithOptional{
-				StringReq:  "hi required",
-				StringOpt:  ptrString("hi optional"),
-				Int32Req:   12,
-				Int32Opt:   ptrInt32(13),
-				MessageReq: &testproto.WithOptional_Nested{},
-				MessageOpt: &testproto.WithOptional_Nested{},
-			},
+			Into:    &testproto.Assorted{},
+			Options: []Option{WithIntegerTruncation()},
+			WantErr: "value -Inf is not a whole number",
 		},
-		"oneof all unset": {
+		"assorted fractional integers with truncation": {
 			Value: cty.ObjectVal(map[string]cty.Value{
-				"a":       cty.NullVal(cty.String),
-				"b":       cty.NullVal(cty.String),
-				"outside": cty.StringVal(""),
+				"t_bool":    cty.False,
+				"t_bytes":   cty.StringVal(""),
+				"t_double":  cty.NumberFloatVal(1.5),
+				"t_fixed32": cty.NumberFloatVal(2.5),
+				"t_fixed64": cty.NumberFloatVal(3.5),
+				"t_float":   cty.NumberFloatVal(4.5),
+				"t_int32":   cty.NumberFloatVal(-5.5),
+				"t_int64":   cty.NumberFloatVal(-6.5),
+				"t_message": cty.NullVal(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_sfixed32": cty.NumberFloatVal(-7.9),
+				"t_sfixed64": cty.NumberFloatVal(-8.1),
+				"t_sint32":   cty.NumberFloatVal(9.9),
+				"t_sint64":   cty.NumberFloatVal(10.1),
+				"t_string":   cty.StringVal(""),
+				"t_uint32":   cty.NumberFloatVal(11.5),
+				"t_uint64":   cty.NumberFloatVal(0.5),
 			}),
-			Into: &testproto.WithOneOf{},
-			Want: &testproto.WithOneOf{},
+			Into:    &testproto.Assorted{},
+			Options: []Option{WithIntegerTruncation()},
+			Want: &testproto.Assorted{
+				TDouble:   1.5,
+				TFixed32:  2,
+				TFixed64:  3,
+				TFloat:    4.5,
+				TInt32:    -5,
+				TInt64:    -6,
+				TSfixed32: -7,
+				TSfixed64: -8,
+				TSint32:   9,
+				TSint64:   10,
+				TUint32:   11,
+			},
 		},
-		"oneof all set": {
+		"assorted bytes as capsule": {
 			Value: cty.ObjectVal(map[string]cty.Value{
-				"a":       cty.NullVal(cty.String),
-				"b":       cty.StringVal("boop"),
-				"outside": cty.StringVal("hello"),
+				"t_bool":    cty.False,
+				"t_bytes":   BytesCapsuleVal([]byte("HELLO COMPUTER")),
+				"t_double":  cty.NumberIntVal(0),
+				"t_fixed32": cty.NumberIntVal(0),
+				"t_fixed64": cty.NumberIntVal(0),
+				"t_float":   cty.NumberIntVal(0),
+				"t_int32":   cty.NumberIntVal(0),
+				"t_int64":   cty.NumberIntVal(0),
+				"t_message": cty.NullVal(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_sfixed32": cty.NumberIntVal(0),
+				"t_sfixed64": cty.NumberIntVal(0),
+				"t_sint32":   cty.NumberIntVal(0),
+				"t_sint64":   cty.NumberIntVal(0),
+				"t_string":   cty.StringVal(""),
+				"t_uint32":   cty.NumberIntVal(0),
+				"t_uint64":   cty.NumberIntVal(0),
 			}),
-			Into: &testproto.WithOneOf{},
-			Want: &testproto.WithOneOf{
-				Outside: "hello",
-				TOneof:  &testproto.WithOneOf_B{B: "boop"},
+			Into:    &testproto.Assorted{},
+			Options: []Option{WithBytesCapsule()},
+			Want: &testproto.Assorted{
+				TBytes: []byte("HELLO COMPUTER"),
 			},
 		},
-		"repeated all unset": {
+		"assorted bytes as string with capsule option": {
 			Value: cty.ObjectVal(map[string]cty.Value{
-				"t_map_number_bool": cty.SetValEmpty(cty.Object(map[string]cty.Type{
-					"key":   cty.Number,
-					"value": cty.Bool,
-				})),
-				"t_map_number_message": cty.SetValEmpty(cty.Object(map[string]cty.Type{
-					"key": cty.Number,
-					"value": cty.Object(map[string]cty.Type{
-						"t_nested_field": cty.String,
-					}),
-				})),
-				"t_map_string_bool": cty.MapValEmpty(cty.Bool),
-				"t_map_string_message": cty.MapValEmpty(cty.Object(map[string]cty.Type{
+				"t_bool":    cty.False,
+				"t_bytes":   cty.StringVal("SEVMTE8gQ09NUFVURVI="),
+				"t_double":  cty.NumberIntVal(0),
+				"t_fixed32": cty.NumberIntVal(0),
+				"t_fixed64": cty.NumberIntVal(0),
+				"t_float":   cty.NumberIntVal(0),
+				"t_int32":   cty.NumberIntVal(0),
+				"t_int64":   cty.NumberIntVal(0),
+				"t_message": cty.NullVal(cty.Object(map[string]cty.Type{
 					"t_nested_field": cty.String,
 				})),
-				"t_message": cty.ListValEmpty(cty.Object(map[string]cty.Type{
+				"t_sfixed32": cty.NumberIntVal(0),
+				"t_sfixed64": cty.NumberIntVal(0),
+				"t_sint32":   cty.NumberIntVal(0),
+				"t_sint64":   cty.NumberIntVal(0),
+				"t_string":   cty.StringVal(""),
+				"t_uint32":   cty.NumberIntVal(0),
+				"t_uint64":   cty.NumberIntVal(0),
+			}),
+			Into:    &testproto.Assorted{},
+			Options: []Option{WithBytesCapsule()},
+			WantErr: "a bytes value is required",
+		},
+		"assorted bytes as UTF-8": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_bool":    cty.False,
+				"t_bytes":   cty.StringVal("HELLO 🖥"),
+				"t_double":  cty.NumberIntVal(0),
+				"t_fixed32": cty.NumberIntVal(0),
+				"t_fixed64": cty.NumberIntVal(0),
+				"t_float":   cty.NumberIntVal(0),
+				"t_int32":   cty.NumberIntVal(0),
+				"t_int64":   cty.NumberIntVal(0),
+				"t_message": cty.NullVal(cty.Object(map[string]cty.Type{
 					"t_nested_field": cty.String,
 				})),
-				"t_strings": cty.ListValEmpty(cty.String),
+				"t_sfixed32": cty.NumberIntVal(0),
+				"t_sfixed64": cty.NumberIntVal(0),
+				"t_sint32":   cty.NumberIntVal(0),
+				"t_sint64":   cty.NumberIntVal(0),
+				"t_string":   cty.StringVal(""),
+				"t_uint32":   cty.NumberIntVal(0),
+				"t_uint64":   cty.NumberIntVal(0),
 			}),
-			Into: &testproto.WithRepeated{},
-			Want: &testproto.WithRepeated{},
+			Into:    &testproto.Assorted{},
+			Options: []Option{WithBytesAsUTF8Strings()},
+			Want: &testproto.Assorted{
+				TBytes: []byte("HELLO \xf0\x9f\x96\xa5"),
+			},
 		},
-		"repeated all set": {
+		"assorted bytes as number lists": {
 			Value: cty.ObjectVal(map[string]cty.Value{
-				"t_map_number_bool": cty.SetVal([]cty.Value{
-					cty.ObjectVal(map[string]cty.Value{
-						"key":   cty.NumberIntVal(1),
-						"value": cty.True,
-					}),
-					cty.ObjectVal(map[string]cty.Value{
-						"key":   cty.NumberIntVal(2),
-						"value": cty.False,
-					}),
+				"t_bool": cty.False,
+				"t_bytes": cty.ListVal([]cty.Value{
+					cty.NumberIntVal(0),
+					cty.NumberIntVal(1),
+					cty.NumberIntVal(255),
 				}),
-				"t_map_number_message": cty.SetVal([]cty.Value{
-					cty.ObjectVal(map[string]cty.Value{
-						"key": cty.NumberIntVal(1),
-						"value": cty.ObjectVal(map[string]cty.Value{
-							"t_nested_field": cty.StringVal(""),
-						}),
-					}),
-					cty.ObjectVal(map[string]cty.Value{
-						"key": cty.NumberIntVal(2),
-						"value": cty.ObjectVal(map[string]cty.Value{
-							"t_nested_field": cty.StringVal(""),
-						}),
-					}),
+				"t_double":  cty.NumberIntVal(0),
+				"t_fixed32": cty.NumberIntVal(0),
+				"t_fixed64": cty.NumberIntVal(0),
+				"t_float":   cty.NumberIntVal(0),
+				"t_int32":   cty.NumberIntVal(0),
+				"t_int64":   cty.NumberIntVal(0),
+				"t_message": cty.NullVal(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_sfixed32": cty.NumberIntVal(0),
+				"t_sfixed64": cty.NumberIntVal(0),
+				"t_sint32":   cty.NumberIntVal(0),
+				"t_sint64":   cty.NumberIntVal(0),
+				"t_string":   cty.StringVal(""),
+				"t_uint32":   cty.NumberIntVal(0),
+				"t_uint64":   cty.NumberIntVal(0),
+			}),
+			Into:    &testproto.Assorted{},
+			Options: []Option{WithBytesAsNumberLists()},
+			Want: &testproto.Assorted{
+				TBytes: []byte{0, 1, 255},
+			},
+		},
+		"assorted bytes as number tuple": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_bool": cty.False,
+				"t_bytes": cty.TupleVal([]cty.Value{
+					cty.NumberIntVal(104),
+					cty.NumberIntVal(105),
 				}),
-				"t_map_string_bool": cty.MapVal(map[string]cty.Value{
-					"a": cty.True,
-					"b": cty.False,
+				"t_double":  cty.NumberIntVal(0),
+				"t_fixed32": cty.NumberIntVal(0),
+				"t_fixed64": cty.NumberIntVal(0),
+				"t_float":   cty.NumberIntVal(0),
+				"t_int32":   cty.NumberIntVal(0),
+				"t_int64":   cty.NumberIntVal(0),
+				"t_message": cty.NullVal(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_sfixed32": cty.NumberIntVal(0),
+				"t_sfixed64": cty.NumberIntVal(0),
+				"t_sint32":   cty.NumberIntVal(0),
+				"t_sint64":   cty.NumberIntVal(0),
+				"t_string":   cty.StringVal(""),
+				"t_uint32":   cty.NumberIntVal(0),
+				"t_uint64":   cty.NumberIntVal(0),
+			}),
+			Into:    &testproto.Assorted{},
+			Options: []Option{WithBytesAsNumberLists()},
+			Want: &testproto.Assorted{
+				TBytes: []byte{104, 105},
+			},
+		},
+		"assorted bytes as number lists out of range": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_bool": cty.False,
+				"t_bytes": cty.ListVal([]cty.Value{
+					cty.NumberIntVal(1),
+					cty.NumberIntVal(256),
+				}),
+				"t_double":  cty.NumberIntVal(0),
+				"t_fixed32": cty.NumberIntVal(0),
+				"t_fixed64": cty.NumberIntVal(0),
+				"t_float":   cty.NumberIntVal(0),
+				"t_int32":   cty.NumberIntVal(0),
+				"t_int64":   cty.NumberIntVal(0),
+				"t_message": cty.NullVal(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_sfixed32": cty.NumberIntVal(0),
+				"t_sfixed64": cty.NumberIntVal(0),
+				"t_sint32":   cty.NumberIntVal(0),
+				"t_sint64":   cty.NumberIntVal(0),
+				"t_string":   cty.StringVal(""),
+				"t_uint32":   cty.NumberIntVal(0),
+				"t_uint64":   cty.NumberIntVal(0),
+			}),
+			Into:    &testproto.Assorted{},
+			Options: []Option{WithBytesAsNumberLists()},
+			WantErr: "value 256 is not a byte; must be a whole number between 0 and 255",
+		},
+		"assorted bytes as number lists wrong type": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_bool":    cty.False,
+				"t_bytes":   cty.StringVal("aGk="),
+				"t_double":  cty.NumberIntVal(0),
+				"t_fixed32": cty.NumberIntVal(0),
+				"t_fixed64": cty.NumberIntVal(0),
+				"t_float":   cty.NumberIntVal(0),
+				"t_int32":   cty.NumberIntVal(0),
+				"t_int64":   cty.NumberIntVal(0),
+				"t_message": cty.NullVal(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_sfixed32": cty.NumberIntVal(0),
+				"t_sfixed64": cty.NumberIntVal(0),
+				"t_sint32":   cty.NumberIntVal(0),
+				"t_sint64":   cty.NumberIntVal(0),
+				"t_string":   cty.StringVal(""),
+				"t_uint32":   cty.NumberIntVal(0),
+				"t_uint64":   cty.NumberIntVal(0),
+			}),
+			Into:    &testproto.Assorted{},
+			Options: []Option{WithBytesAsNumberLists()},
+			WantErr: "a list of numbers is required",
+		},
+		"assorted with field name func": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"BOOL":    cty.False,
+				"BYTES":   cty.StringVal(""),
+				"DOUBLE":  cty.NumberIntVal(0),
+				"FIXED32": cty.NumberIntVal(0),
+				"FIXED64": cty.NumberIntVal(0),
+				"FLOAT":   cty.NumberIntVal(0),
+				"INT32":   cty.NumberIntVal(0),
+				"INT64":   cty.NumberIntVal(0),
+				"MESSAGE": cty.ObjectVal(map[string]cty.Value{
+					"NESTED_FIELD": cty.StringVal("world"),
+				}),
+				"SFIXED32": cty.NumberIntVal(0),
+				"SFIXED64": cty.NumberIntVal(0),
+				"SINT32":   cty.NumberIntVal(0),
+				"SINT64":   cty.NumberIntVal(0),
+				"STRING":   cty.StringVal("hello"),
+				"UINT32":   cty.NumberIntVal(0),
+				"UINT64":   cty.NumberIntVal(0),
+			}),
+			Into:    &testproto.Assorted{},
+			Options: []Option{WithFieldNameFunc(stripFieldNamePrefix)},
+			Want: &testproto.Assorted{
+				TString: "hello",
+				TMessage: &testproto.Assorted_Nested{
+					TNestedField: "world",
+				},
+			},
+		},
+		"assorted with conflicting field names": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"same": cty.NumberIntVal(0),
+			}),
+			Into: &testproto.Assorted{},
+			Options: []Option{WithFieldNameFunc(func(field protoreflect.FieldDescriptor) string {
+				return "same"
+			})},
+			WantErr: `more than one field has the attribute name "same"`,
+		},
+		"optional all unset": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"int32_opt":   cty.NullVal(cty.Number),
+				"int32_req":   cty.NumberIntVal(0),
+				"message_opt": cty.NullVal(cty.EmptyObject), // message fields always track presence
+				"message_req": cty.NullVal(cty.EmptyObject),
+				"string_opt":  cty.NullVal(cty.String),
+				"string_req":  cty.StringVal(""),
+			}),
+			Into: &testproto.WithOptional{},
+			Want: &testproto.WithOptional{},
+		},
+		"assorted unset nested message clears existing": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_bool":    cty.False,
+				"t_bytes":   cty.StringVal(""),
+				"t_double":  cty.NumberIntVal(0),
+				"t_fixed32": cty.NumberIntVal(0),
+				"t_fixed64": cty.NumberIntVal(0),
+				"t_float":   cty.NumberIntVal(0),
+				"t_int32":   cty.NumberIntVal(0),
+				"t_int64":   cty.NumberIntVal(0),
+				"t_message": cty.NullVal(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_sfixed32": cty.NumberIntVal(0),
+				"t_sfixed64": cty.NumberIntVal(0),
+				"t_sint32":   cty.NumberIntVal(0),
+				"t_sint64":   cty.NumberIntVal(0),
+				"t_string":   cty.StringVal(""),
+				"t_uint32":   cty.NumberIntVal(0),
+				"t_uint64":   cty.NumberIntVal(0),
+			}),
+			Into: &testproto.Assorted{
+				TMessage: &testproto.Assorted_Nested{
+					TNestedField: "previous",
+				},
+			},
+			Want: &testproto.Assorted{},
+		},
+		"assorted unset nested message with other null types": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_bool":    cty.False,
+				"t_bytes":   cty.StringVal(""),
+				"t_double":  cty.NumberIntVal(0),
+				"t_fixed32": cty.NumberIntVal(0),
+				"t_fixed64": cty.NumberIntVal(0),
+				"t_float":   cty.NumberIntVal(0),
+				"t_int32":   cty.NumberIntVal(0),
+				"t_int64":   cty.NumberIntVal(0),
+				// Any null means "unset", regardless of its type.
+				"t_message":  cty.NullVal(cty.EmptyObject),
+				"t_sfixed32": cty.NumberIntVal(0),
+				"t_sfixed64": cty.NumberIntVal(0),
+				"t_sint32":   cty.NumberIntVal(0),
+				"t_sint64":   cty.NumberIntVal(0),
+				"t_string":   cty.StringVal(""),
+				"t_uint32":   cty.NumberIntVal(0),
+				"t_uint64":   cty.NumberIntVal(0),
+			}),
+			Into: &testproto.Assorted{
+				TMessage: &testproto.Assorted_Nested{
+					TNestedField: "previous",
+				},
+			},
+			Want: &testproto.Assorted{},
+		},
+		"optional all set": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"int32_opt":   cty.NumberIntVal(13),
+				"int32_req":   cty.NumberIntVal(12),
+				"message_opt": cty.EmptyObjectVal,
+				"message_req": cty.EmptyObjectVal,
+				"string_opt":  cty.StringVal("hi optional"),
+				"string_req":  cty.StringVal("hi required"),
+			}),
+			Into: &testproto.WithOptional{},
+			Want: &testproto.WithOptional{
+				StringReq:  "hi required",
+				StringOpt:  ptrString("hi optional"),
+				Int32Req:   12,
+				Int32Opt:   ptrInt32(13),
+				MessageReq: &testproto.WithOptional_Nested{},
+				MessageOpt: &testproto.WithOptional_Nested{},
+			},
+		},
+		"oneof all unset": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"a":       cty.NullVal(cty.String),
+				"b":       cty.NullVal(cty.String),
+				"outside": cty.StringVal(""),
+			}),
+			Into: &testproto.WithOneOf{},
+			Want: &testproto.WithOneOf{},
+		},
+		"oneof all set": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"a":       cty.NullVal(cty.String),
+				"b":       cty.StringVal("boop"),
+				"outside": cty.StringVal("hello"),
+			}),
+			Into: &testproto.WithOneOf{},
+			Want: &testproto.WithOneOf{
+				Outside: "hello",
+				TOneof:  &testproto.WithOneOf_B{B: "boop"},
+			},
+		},
+		"oneof conflict": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"a":       cty.StringVal("beep"),
+				"b":       cty.StringVal("boop"),
+				"outside": cty.StringVal("hello"),
+			}),
+			Into:    &testproto.WithOneOf{},
+			WantErr: `attributes "a" and "b" belong to the same oneof t_oneof, so at most one of them may be non-null`,
+		},
+		"oneof all unset clears existing": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"a":       cty.NullVal(cty.String),
+				"b":       cty.NullVal(cty.String),
+				"outside": cty.StringVal(""),
+			}),
+			Into: &testproto.WithOneOf{
+				TOneof: &testproto.WithOneOf_A{A: "beep"},
+			},
+			Want: &testproto.WithOneOf{},
+		},
+		"repeated all unset": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_map_number_bool": cty.SetValEmpty(cty.Object(map[string]cty.Type{
+					"key":   cty.Number,
+					"value": cty.Bool,
+				})),
+				"t_map_number_message": cty.SetValEmpty(cty.Object(map[string]cty.Type{
+					"key": cty.Number,
+					"value": cty.Object(map[string]cty.Type{
+						"t_nested_field": cty.String,
+					}),
+				})),
+				"t_map_string_bool": cty.MapValEmpty(cty.Bool),
+				"t_map_string_message": cty.MapValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_message": cty.ListValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_strings": cty.ListValEmpty(cty.String),
+			}),
+			Into: &testproto.WithRepeated{},
+			Want: &testproto.WithRepeated{},
+		},
+		"repeated all null": {
+			// Null is equivalent to an empty collection, and so clears
+			// any existing elements.
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_map_number_bool":    cty.NullVal(cty.DynamicPseudoType),
+				"t_map_number_message": cty.NullVal(cty.DynamicPseudoType),
+				"t_map_string_bool":    cty.NullVal(cty.Map(cty.Bool)),
+				"t_map_string_message": cty.NullVal(cty.DynamicPseudoType),
+				"t_message":            cty.NullVal(cty.DynamicPseudoType),
+				"t_strings":            cty.NullVal(cty.List(cty.String)),
+			}),
+			Into: &testproto.WithRepeated{
+				TStrings:       []string{"old"},
+				TMapStringBool: map[string]bool{"old": true},
+				TMapNumberBool: map[int64]bool{1: true},
+			},
+			Want: &testproto.WithRepeated{},
+		},
+		"repeated all set": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_map_number_bool": cty.SetVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"key":   cty.NumberIntVal(1),
+						"value": cty.True,
+					}),
+					cty.ObjectVal(map[string]cty.Value{
+						"key":   cty.NumberIntVal(2),
+						"value": cty.False,
+					}),
+				}),
+				"t_map_number_message": cty.SetVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"key": cty.NumberIntVal(1),
+						"value": cty.ObjectVal(map[string]cty.Value{
+							"t_nested_field": cty.StringVal(""),
+						}),
+					}),
+					cty.ObjectVal(map[string]cty.Value{
+						"key": cty.NumberIntVal(2),
+						"value": cty.ObjectVal(map[string]cty.Value{
+							"t_nested_field": cty.StringVal(""),
+						}),
+					}),
+				}),
+				"t_map_string_bool": cty.MapVal(map[string]cty.Value{
+					"a": cty.True,
+					"b": cty.False,
 				}),
 				"t_map_string_message": cty.MapVal(map[string]cty.Value{
 					"a": cty.ObjectVal(map[string]cty.Value{
@@ -237,121 +732,2090 @@ func TestToProtobufMessage(t *testing.T) {
 				},
 			},
 		},
-		"any none set": {
+		"repeated with extreme int64 map keys": {
 			Value: cty.ObjectVal(map[string]cty.Value{
-				"t_any": cty.NullVal(cty.Object(map[string]cty.Type{
-					"type_url": cty.String,
-					"value":    cty.String,
-				})),
-				"t_any_list": cty.ListValEmpty(cty.Object(map[string]cty.Type{
-					"type_url": cty.String,
-					"value":    cty.String,
-				})),
-				"t_any_map_number": cty.SetValEmpty(cty.Object(map[string]cty.Type{
+				"t_map_number_bool": cty.SetVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"key":   cty.NumberIntVal(math.MinInt64),
+						"value": cty.True,
+					}),
+					cty.ObjectVal(map[string]cty.Value{
+						"key":   cty.NumberIntVal(-1),
+						"value": cty.False,
+					}),
+					cty.ObjectVal(map[string]cty.Value{
+						"key":   cty.NumberIntVal(math.MaxInt64),
+						"value": cty.True,
+					}),
+				}),
+				"t_map_number_message": cty.SetValEmpty(cty.Object(map[string]cty.Type{
 					"key": cty.Number,
 					"value": cty.Object(map[string]cty.Type{
-						"type_url": cty.String,
-						"value":    cty.String,
+						"t_nested_field": cty.String,
 					}),
 				})),
-				"t_any_map_string": cty.MapValEmpty(cty.Object(map[string]cty.Type{
-					"type_url": cty.String,
-					"value":    cty.String,
+				"t_map_string_bool": cty.MapValEmpty(cty.Bool),
+				"t_map_string_message": cty.MapValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
 				})),
-				"t_string": cty.StringVal(""),
-			}),
-			Into: &testproto.WithAny{},
+				"t_message": cty.ListValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_strings": cty.ListValEmpty(cty.String),
+			}),
+			Into: &testproto.WithRepeated{},
+			Want: &testproto.WithRepeated{
+				TMapNumberBool: map[int64]bool{
+					math.MinInt64: true,
+					-1:            false,
+					math.MaxInt64: true,
+				},
+			},
+		},
+		"repeated with out of range int64 map key": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_map_number_bool": cty.SetVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"key":   cty.MustParseNumberVal("9223372036854775808"),
+						"value": cty.True,
+					}),
+				}),
+				"t_map_number_message": cty.SetValEmpty(cty.Object(map[string]cty.Type{
+					"key": cty.Number,
+					"value": cty.Object(map[string]cty.Type{
+						"t_nested_field": cty.String,
+					}),
+				})),
+				"t_map_string_bool": cty.MapValEmpty(cty.Bool),
+				"t_map_string_message": cty.MapValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_message": cty.ListValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_strings": cty.ListValEmpty(cty.String),
+			}),
+			Into:    &testproto.WithRepeated{},
+			WantErr: "invalid value 9223372036854775808 for int64 field: value must be a whole number, between -9223372036854775808 and 9223372036854775807",
+		},
+		"repeated map with duplicate keys": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_map_number_bool": cty.SetVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"key":   cty.NumberIntVal(1),
+						"value": cty.True,
+					}),
+					cty.ObjectVal(map[string]cty.Value{
+						"key":   cty.NumberIntVal(1),
+						"value": cty.False,
+					}),
+				}),
+				"t_map_number_message": cty.SetValEmpty(cty.Object(map[string]cty.Type{
+					"key": cty.Number,
+					"value": cty.Object(map[string]cty.Type{
+						"t_nested_field": cty.String,
+					}),
+				})),
+				"t_map_string_bool": cty.MapValEmpty(cty.Bool),
+				"t_map_string_message": cty.MapValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_message": cty.ListValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_strings": cty.ListValEmpty(cty.String),
+			}),
+			Into:    &testproto.WithRepeated{},
+			WantErr: "duplicate map key 1",
+		},
+		"repeated as tuple": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_map_number_bool": cty.SetValEmpty(cty.Object(map[string]cty.Type{
+					"key":   cty.Number,
+					"value": cty.Bool,
+				})),
+				"t_map_number_message": cty.SetValEmpty(cty.Object(map[string]cty.Type{
+					"key": cty.Number,
+					"value": cty.Object(map[string]cty.Type{
+						"t_nested_field": cty.String,
+					}),
+				})),
+				"t_map_string_bool": cty.MapValEmpty(cty.Bool),
+				"t_map_string_message": cty.MapValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_message": cty.ListValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_strings": cty.TupleVal([]cty.Value{
+					cty.StringVal("hello"),
+					cty.StringVal("world"),
+				}),
+			}),
+			Into: &testproto.WithRepeated{},
+			Want: &testproto.WithRepeated{
+				TStrings: []string{"hello", "world"},
+			},
+		},
+		"repeated as set": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_map_number_bool": cty.SetValEmpty(cty.Object(map[string]cty.Type{
+					"key":   cty.Number,
+					"value": cty.Bool,
+				})),
+				"t_map_number_message": cty.SetValEmpty(cty.Object(map[string]cty.Type{
+					"key": cty.Number,
+					"value": cty.Object(map[string]cty.Type{
+						"t_nested_field": cty.String,
+					}),
+				})),
+				"t_map_string_bool": cty.MapValEmpty(cty.Bool),
+				"t_map_string_message": cty.MapValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_message": cty.ListValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_strings": cty.SetVal([]cty.Value{
+					cty.StringVal("hello"),
+				}),
+			}),
+			Into: &testproto.WithRepeated{},
+			Want: &testproto.WithRepeated{
+				TStrings: []string{"hello"},
+			},
+		},
+		"repeated as tuple with wrong element type": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_map_number_bool": cty.SetValEmpty(cty.Object(map[string]cty.Type{
+					"key":   cty.Number,
+					"value": cty.Bool,
+				})),
+				"t_map_number_message": cty.SetValEmpty(cty.Object(map[string]cty.Type{
+					"key": cty.Number,
+					"value": cty.Object(map[string]cty.Type{
+						"t_nested_field": cty.String,
+					}),
+				})),
+				"t_map_string_bool": cty.MapValEmpty(cty.Bool),
+				"t_map_string_message": cty.MapValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_message": cty.ListValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_strings": cty.TupleVal([]cty.Value{
+					cty.StringVal("hello"),
+					cty.EmptyObjectVal,
+				}),
+			}),
+			Into:    &testproto.WithRepeated{},
+			WantErr: "a string is required",
+		},
+		"repeated as tuple with convertible element types": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_numbers": cty.TupleVal([]cty.Value{
+					cty.NumberIntVal(1),
+					cty.StringVal("2"),
+				}),
+			}),
+			Into: &testproto.WithRepeatedNumbers{},
+			Want: &testproto.WithRepeatedNumbers{
+				TNumbers: []int64{1, 2},
+			},
+		},
+		"repeated as tuple with unconvertible element value": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_numbers": cty.TupleVal([]cty.Value{
+					cty.NumberIntVal(1),
+					cty.StringVal("two"),
+				}),
+			}),
+			Into:    &testproto.WithRepeatedNumbers{},
+			WantErr: "number value is required",
+		},
+		"repeated as tuple with number for string field": {
+			// Tuple elements are only converted from strings to numbers,
+			// so a tuple is rejected wherever a list would be.
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_message": cty.ListValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_strings": cty.TupleVal([]cty.Value{
+					cty.StringVal("hello"),
+					cty.NumberIntVal(2),
+				}),
+			}),
+			Into:    &testproto.WithRepeated{},
+			WantErr: "a string is required",
+		},
+		"repeated as list with number for string field": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_message": cty.ListValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_strings": cty.ListVal([]cty.Value{
+					cty.NumberIntVal(2),
+				}),
+			}),
+			Into:    &testproto.WithRepeated{},
+			WantErr: "a string is required",
+		},
+		"repeated string map as map": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_map_number_bool": cty.SetValEmpty(cty.Object(map[string]cty.Type{
+					"key":   cty.Number,
+					"value": cty.Bool,
+				})),
+				"t_map_number_message": cty.SetValEmpty(cty.Object(map[string]cty.Type{
+					"key": cty.Number,
+					"value": cty.Object(map[string]cty.Type{
+						"t_nested_field": cty.String,
+					}),
+				})),
+				"t_map_string_bool": cty.MapVal(map[string]cty.Value{
+					"a": cty.True,
+					"b": cty.False,
+				}),
+				"t_map_string_message": cty.MapValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_message": cty.ListValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_strings": cty.ListValEmpty(cty.String),
+			}),
+			Into: &testproto.WithRepeated{},
+			Want: &testproto.WithRepeated{
+				TMapStringBool: map[string]bool{"a": true, "b": false},
+			},
+		},
+		"repeated string map as object": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_map_number_bool": cty.SetValEmpty(cty.Object(map[string]cty.Type{
+					"key":   cty.Number,
+					"value": cty.Bool,
+				})),
+				"t_map_number_message": cty.SetValEmpty(cty.Object(map[string]cty.Type{
+					"key": cty.Number,
+					"value": cty.Object(map[string]cty.Type{
+						"t_nested_field": cty.String,
+					}),
+				})),
+				"t_map_string_bool": cty.ObjectVal(map[string]cty.Value{
+					"a": cty.True,
+					"b": cty.False,
+				}),
+				"t_map_string_message": cty.MapValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_message": cty.ListValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_strings": cty.ListValEmpty(cty.String),
+			}),
+			Into: &testproto.WithRepeated{},
+			Want: &testproto.WithRepeated{
+				TMapStringBool: map[string]bool{"a": true, "b": false},
+			},
+		},
+		"repeated string map as object with wrong attribute type": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_map_number_bool": cty.SetValEmpty(cty.Object(map[string]cty.Type{
+					"key":   cty.Number,
+					"value": cty.Bool,
+				})),
+				"t_map_number_message": cty.SetValEmpty(cty.Object(map[string]cty.Type{
+					"key": cty.Number,
+					"value": cty.Object(map[string]cty.Type{
+						"t_nested_field": cty.String,
+					}),
+				})),
+				"t_map_string_bool": cty.ObjectVal(map[string]cty.Value{
+					"a": cty.True,
+					"b": cty.StringVal("nope"),
+				}),
+				"t_map_string_message": cty.MapValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_message": cty.ListValEmpty(cty.Object(map[string]cty.Type{
+					"t_nested_field": cty.String,
+				})),
+				"t_strings": cty.ListValEmpty(cty.String),
+			}),
+			Into:    &testproto.WithRepeated{},
+			WantErr: "a boolean value is required",
+		},
+		"any none set": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_any": cty.NullVal(cty.Object(map[string]cty.Type{
+					"type_url": cty.String,
+					"value":    cty.String,
+				})),
+				"t_any_list": cty.ListValEmpty(cty.Object(map[string]cty.Type{
+					"type_url": cty.String,
+					"value":    cty.String,
+				})),
+				"t_any_map_number": cty.SetValEmpty(cty.Object(map[string]cty.Type{
+					"key": cty.Number,
+					"value": cty.Object(map[string]cty.Type{
+						"type_url": cty.String,
+						"value":    cty.String,
+					}),
+				})),
+				"t_any_map_string": cty.MapValEmpty(cty.Object(map[string]cty.Type{
+					"type_url": cty.String,
+					"value":    cty.String,
+				})),
+				"t_string": cty.StringVal(""),
+			}),
+			Into: &testproto.WithAny{},
 			Want: &testproto.WithAny{},
 		},
-		"any all set": {
-			Value: cty.ObjectVal(map[string]cty.Value{
-				"t_any": cty.ObjectVal(map[string]cty.Value{
-					"type_url": cty.StringVal("type.googleapis.com/testproto.Simple"),
-					"value":    cty.StringVal("CgA="), // base64-encoded Simple message
-				}),
-				"t_any_list": cty.ListVal([]cty.Value{
-					cty.ObjectVal(map[string]cty.Value{
-						"type_url": cty.StringVal("type.googleapis.com/testproto.Empty"),
-						"value":    cty.StringVal(""),
-					}),
+		"any all set": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_any": cty.ObjectVal(map[string]cty.Value{
+					"type_url": cty.StringVal("type.googleapis.com/testproto.Simple"),
+					"value":    cty.StringVal("CgA="), // base64-encoded Simple message
+				}),
+				"t_any_list": cty.ListVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"type_url": cty.StringVal("type.googleapis.com/testproto.Empty"),
+						"value":    cty.StringVal(""),
+					}),
+				}),
+				"t_any_map_number": cty.SetVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"key": cty.NumberIntVal(4),
+						"value": cty.ObjectVal(map[string]cty.Value{
+							"type_url": cty.StringVal("type.googleapis.com/testproto.Empty"),
+							"value":    cty.StringVal(""),
+						}),
+					}),
+				}),
+				"t_any_map_string": cty.MapVal(map[string]cty.Value{
+					"a": cty.ObjectVal(map[string]cty.Value{
+						"type_url": cty.StringVal("type.googleapis.com/testproto.Empty"),
+						"value":    cty.StringVal(""),
+					}),
+				}),
+				"t_string": cty.StringVal("not an any"),
+			}),
+			Into: &testproto.WithAny{},
+			Want: &testproto.WithAny{
+				TString: "not an any",
+				TAny:    mustAny(&testproto.Simple{Foo: &testproto.Empty{}}),
+				TAnyList: []*anypb.Any{
+					mustAny(&testproto.Empty{}),
+				},
+				TAnyMapString: map[string]*anypb.Any{
+					"a": mustAny(&testproto.Empty{}),
+				},
+				TAnyMapNumber: map[int64]*anypb.Any{
+					4: mustAny(&testproto.Empty{}),
+				},
+			},
+		},
+		"enum all unset": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_enum":   cty.StringVal("A"),
+				"t_string": cty.StringVal(""),
+			}),
+			Into: &testproto.WithEnum{},
+			Want: &testproto.WithEnum{},
+		},
+		"enum all set": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_enum":   cty.StringVal("d"),
+				"t_string": cty.StringVal("hello"),
+			}),
+			Into: &testproto.WithEnum{},
+			Want: &testproto.WithEnum{
+				TString: "hello",
+				TEnum:   testproto.WithEnum_d,
+			},
+		},
+		"struct none set": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_struct":     cty.NullVal(cty.DynamicPseudoType),
+				"t_value":      cty.NullVal(cty.DynamicPseudoType),
+				"t_list_value": cty.NullVal(cty.DynamicPseudoType),
+			}),
+			Into:    &testproto.WithStruct{},
+			Options: []Option{WithWellKnownStruct()},
+			Want:    &testproto.WithStruct{},
+		},
+		"struct all set": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_struct": cty.ObjectVal(map[string]cty.Value{
+					"string": cty.StringVal("hello"),
+					"number": cty.NumberFloatVal(1.5),
+					"bool":   cty.True,
+					"null":   cty.NullVal(cty.String),
+					"object": cty.ObjectVal(map[string]cty.Value{
+						"nested": cty.StringVal("beep"),
+					}),
+					"map": cty.MapVal(map[string]cty.Value{
+						"a": cty.NumberIntVal(1),
+						"b": cty.NumberIntVal(2),
+					}),
+					"tuple": cty.TupleVal([]cty.Value{
+						cty.StringVal("boop"),
+						cty.False,
+						cty.EmptyObjectVal,
+					}),
+				}),
+				"t_value":      cty.StringVal("just a string"),
+				"t_list_value": cty.TupleVal([]cty.Value{cty.NumberIntVal(2), cty.StringVal("two")}),
+			}),
+			Into:    &testproto.WithStruct{},
+			Options: []Option{WithWellKnownStruct()},
+			Want: &testproto.WithStruct{
+				TStruct: mustStruct(map[string]interface{}{
+					"string": "hello",
+					"number": 1.5,
+					"bool":   true,
+					"null":   nil,
+					"object": map[string]interface{}{
+						"nested": "beep",
+					},
+					"map": map[string]interface{}{
+						"a": 1,
+						"b": 2,
+					},
+					"tuple": []interface{}{
+						"boop",
+						false,
+						map[string]interface{}{},
+					},
+				}),
+				TValue:     structpb.NewStringValue("just a string"),
+				TListValue: mustList([]interface{}{2, "two"}),
+			},
+		},
+		"struct from map of lists": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_struct": cty.MapVal(map[string]cty.Value{
+					"a": cty.ListVal([]cty.Value{
+						cty.StringVal("hello"),
+						cty.StringVal("world"),
+					}),
+					"b": cty.ListValEmpty(cty.String),
+					"c": cty.NullVal(cty.List(cty.String)),
+				}),
+				"t_value": cty.MapVal(map[string]cty.Value{
+					"nested": cty.ObjectVal(map[string]cty.Value{
+						"list": cty.ListVal([]cty.Value{
+							cty.NumberIntVal(1),
+						}),
+					}),
+				}),
+				"t_list_value": cty.ListVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"name": cty.StringVal("a"),
+					}),
+				}),
+			}),
+			Into:    &testproto.WithStruct{},
+			Options: []Option{WithWellKnownStruct()},
+			Want: &testproto.WithStruct{
+				TStruct: mustStruct(map[string]interface{}{
+					"a": []interface{}{"hello", "world"},
+					"b": []interface{}{},
+					"c": nil,
+				}),
+				TValue: structpb.NewStructValue(mustStruct(map[string]interface{}{
+					"nested": map[string]interface{}{
+						"list": []interface{}{1},
+					},
+				})),
+				TListValue: mustList([]interface{}{
+					map[string]interface{}{
+						"name": "a",
+					},
+				}),
+			},
+		},
+		"struct with set": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_struct": cty.ObjectVal(map[string]cty.Value{
+					"set": cty.SetVal([]cty.Value{cty.StringVal("hello")}),
+				}),
+				"t_value":      cty.NullVal(cty.DynamicPseudoType),
+				"t_list_value": cty.NullVal(cty.DynamicPseudoType),
+			}),
+			Into:    &testproto.WithStruct{},
+			Options: []Option{WithWellKnownStruct()},
+			WantErr: "cannot use a value of type set of string here",
+		},
+		"struct with capsule": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_struct":     cty.NullVal(cty.DynamicPseudoType),
+				"t_value":      BytesCapsuleVal([]byte("hello")),
+				"t_list_value": cty.NullVal(cty.DynamicPseudoType),
+			}),
+			Into:    &testproto.WithStruct{},
+			Options: []Option{WithWellKnownStruct()},
+			WantErr: "cannot use a value of type bytes here",
+		},
+		"struct with non-object": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_struct":     cty.StringVal("not an object"),
+				"t_value":      cty.NullVal(cty.DynamicPseudoType),
+				"t_list_value": cty.NullVal(cty.DynamicPseudoType),
+			}),
+			Into:    &testproto.WithStruct{},
+			Options: []Option{WithWellKnownStruct()},
+			WantErr: "an object is required",
+		},
+		"group set": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"thing": cty.ObjectVal(map[string]cty.Value{
+					"name": cty.StringVal("hello"),
+				}),
+			}),
+			Into: &testproto.WithGroup{},
+			Want: &testproto.WithGroup{
+				Thing: &testproto.WithGroup_Thing{Name: ptrString("hello")},
+			},
+		},
+		"extendable with extensions set": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"name":                 cty.StringVal("hello"),
+				"testproto.ext_string": cty.StringVal("world"),
+				"testproto.ext_numbers": cty.ListVal([]cty.Value{
+					cty.NumberIntVal(1),
+					cty.NumberIntVal(2),
+				}),
+			}),
+			Into:    &testproto.Extendable{},
+			Options: []Option{WithExtensions(testExtensionTypes())},
+			Want: func() protoreflect.ProtoMessage {
+				msg := &testproto.Extendable{Name: ptrString("hello")}
+				proto.SetExtension(msg, testproto.E_ExtString, "world")
+				proto.SetExtension(msg, testproto.E_ExtNumbers, []int32{1, 2})
+				return msg
+			}(),
+		},
+		"extendable with extension attribute missing": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"name":                 cty.StringVal("hello"),
+				"testproto.ext_string": cty.StringVal("world"),
+			}),
+			Into:    &testproto.Extendable{},
+			Options: []Option{WithExtensions(testExtensionTypes())},
+			WantErr: `missing required attribute "testproto.ext_numbers"`,
+		},
+		"enum with custom names": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_enum":   cty.StringVal("THING_D"),
+				"t_string": cty.StringVal(""),
+			}),
+			Into: &testproto.WithEnum{},
+			Options: []Option{
+				WithEnumValueFunc(thingsEnumValue),
+			},
+			Want: &testproto.WithEnum{
+				TEnum: testproto.WithEnum_d,
+			},
+		},
+		"enum with custom names and invalid value": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"t_enum":   cty.StringVal("d"),
+				"t_string": cty.StringVal(""),
+			}),
+			Into: &testproto.WithEnum{},
+			Options: []Option{
+				WithEnumValueFunc(thingsEnumValue),
+			},
+			WantErr: "value isn't one of the expected keywords",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			gotReflect := test.Into.ProtoReflect()
+			err := ToProtobufMessage(test.Value, gotReflect, test.Options...)
+
+			if test.WantErr != "" {
+				if err == nil {
+					t.Fatalf("succeeded; want error\nwant: %s", test.WantErr)
+				}
+				if got, want := err.Error(), test.WantErr; got != want {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+
+			got := gotReflect.Interface()
+			want := test.Want
+
+			if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("wrong result\n%s", diff)
+			}
+		})
+	}
+
+}
+
+func TestToProtobufMessageSignedZigzag(t *testing.T) {
+	tests := map[string]struct {
+		Attr    string
+		Value   cty.Value
+		Want    *testproto.Assorted
+		WantErr string
+	}{
+		"sint32 minimum": {
+			Attr:  "t_sint32",
+			Value: cty.NumberIntVal(math.MinInt32),
+			Want:  &testproto.Assorted{TSint32: math.MinInt32},
+		},
+		"sint32 maximum": {
+			Attr:  "t_sint32",
+			Value: cty.NumberIntVal(math.MaxInt32),
+			Want:  &testproto.Assorted{TSint32: math.MaxInt32},
+		},
+		"sint32 minus one": {
+			Attr:  "t_sint32",
+			Value: cty.NumberIntVal(-1),
+			Want:  &testproto.Assorted{TSint32: -1},
+		},
+		"sint32 below minimum": {
+			Attr:    "t_sint32",
+			Value:   cty.NumberIntVal(math.MinInt32 - 1),
+			WantErr: "invalid value -2147483649 for sint32 field, which has the same range as int32: value must be a whole number, between -2147483648 and 2147483647",
+		},
+		"sint64 minimum": {
+			Attr:  "t_sint64",
+			Value: cty.NumberIntVal(math.MinInt64),
+			Want:  &testproto.Assorted{TSint64: math.MinInt64},
+		},
+		"sint64 maximum": {
+			Attr:  "t_sint64",
+			Value: cty.NumberIntVal(math.MaxInt64),
+			Want:  &testproto.Assorted{TSint64: math.MaxInt64},
+		},
+		"sint64 minus one": {
+			Attr:  "t_sint64",
+			Value: cty.NumberIntVal(-1),
+			Want:  &testproto.Assorted{TSint64: -1},
+		},
+		"sint64 below minimum": {
+			Attr:    "t_sint64",
+			Value:   cty.MustParseNumberVal("-9223372036854775809"),
+			WantErr: "invalid value -9223372036854775809 for sint64 field, which has the same range as int64: value must be a whole number, between -9223372036854775808 and 9223372036854775807",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			v, err := FromProtobufMessage((&testproto.Assorted{}).ProtoReflect())
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			attrs := v.AsValueMap()
+			attrs[test.Attr] = test.Value
+
+			got := &testproto.Assorted{}
+			err = ToProtobufMessage(cty.ObjectVal(attrs), got.ProtoReflect())
+			if test.WantErr != "" {
+				if err == nil {
+					t.Fatalf("succeeded; want error\nwant: %s", test.WantErr)
+				}
+				if got, want := err.Error(), test.WantErr; got != want {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			if diff := cmp.Diff(test.Want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("wrong result\n%s", diff)
+			}
+
+			// The value must also survive the trip back to cty unchanged.
+			back, err := FromProtobufMessage(got.ProtoReflect())
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			if gotV := back.GetAttr(test.Attr); !test.Value.RawEquals(gotV) {
+				t.Errorf("wrong value after round-trip\ngot:  %#v\nwant: %#v", gotV, test.Value)
+			}
+		})
+	}
+}
+
+func TestToProtobufMessageFixedWidth(t *testing.T) {
+	// The fixed-width kinds share the number mapping of the other integer
+	// kinds, but the extremes of their ranges must still survive a round
+	// trip exactly.
+	tests := map[string]struct {
+		Attr    string
+		Value   cty.Value
+		Want    *testproto.Assorted
+		WantErr string
+	}{
+		"fixed32 maximum": {
+			Attr:  "t_fixed32",
+			Value: cty.NumberUIntVal(math.MaxUint32),
+			Want:  &testproto.Assorted{TFixed32: math.MaxUint32},
+		},
+		"fixed64 maximum": {
+			Attr:  "t_fixed64",
+			Value: cty.NumberUIntVal(math.MaxUint64),
+			Want:  &testproto.Assorted{TFixed64: math.MaxUint64},
+		},
+		"fixed64 above maximum": {
+			Attr:    "t_fixed64",
+			Value:   cty.MustParseNumberVal("18446744073709551616"),
+			WantErr: "invalid value 18446744073709551616 for fixed64 field: value must be a whole number, between 0 and 18446744073709551615 inclusive",
+		},
+		"fixed64 negative": {
+			Attr:    "t_fixed64",
+			Value:   cty.NumberIntVal(-1),
+			WantErr: "invalid value -1 for fixed64 field: value must be a whole number, between 0 and 18446744073709551615 inclusive",
+		},
+		"sfixed32 minimum": {
+			Attr:  "t_sfixed32",
+			Value: cty.NumberIntVal(math.MinInt32),
+			Want:  &testproto.Assorted{TSfixed32: math.MinInt32},
+		},
+		"sfixed64 minimum": {
+			Attr:  "t_sfixed64",
+			Value: cty.NumberIntVal(math.MinInt64),
+			Want:  &testproto.Assorted{TSfixed64: math.MinInt64},
+		},
+		"sfixed64 maximum": {
+			Attr:  "t_sfixed64",
+			Value: cty.NumberIntVal(math.MaxInt64),
+			Want:  &testproto.Assorted{TSfixed64: math.MaxInt64},
+		},
+		"sfixed64 above maximum": {
+			Attr:    "t_sfixed64",
+			Value:   cty.MustParseNumberVal("9223372036854775808"),
+			WantErr: "invalid value 9223372036854775808 for sfixed64 field: value must be a whole number, between -9223372036854775808 and 9223372036854775807",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			v, err := FromProtobufMessage((&testproto.Assorted{}).ProtoReflect())
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			attrs := v.AsValueMap()
+			attrs[test.Attr] = test.Value
+
+			got := &testproto.Assorted{}
+			err = ToProtobufMessage(cty.ObjectVal(attrs), got.ProtoReflect())
+			if test.WantErr != "" {
+				if err == nil {
+					t.Fatalf("succeeded; want error\nwant: %s", test.WantErr)
+				}
+				if got, want := err.Error(), test.WantErr; got != want {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			if diff := cmp.Diff(test.Want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("wrong result\n%s", diff)
+			}
+
+			back, err := FromProtobufMessage(got.ProtoReflect())
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			if gotV := back.GetAttr(test.Attr); !test.Value.RawEquals(gotV) {
+				t.Errorf("wrong value after round-trip\ngot:  %#v\nwant: %#v", gotV, test.Value)
+			}
+		})
+	}
+}
+
+func TestToProtobufMessageDeepPaths(t *testing.T) {
+	// The paths for nested attributes share backing arrays, which must not
+	// leak into the paths of errors or of recorded fields, even when the
+	// nesting is deep enough to need several larger arrays.
+	const depth = 40
+	build := func(leafName cty.Value) cty.Value {
+		v := cty.ObjectVal(map[string]cty.Value{
+			"name": leafName,
+			"next": cty.NullVal(cty.DynamicPseudoType),
+		})
+		for i := 1; i < depth; i++ {
+			v = cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal("link"),
+				"next": v,
+			})
+		}
+		return v
+	}
+	var wantPath cty.Path
+	for i := 1; i < depth; i++ {
+		wantPath = wantPath.GetAttr("next")
+	}
+	wantPath = wantPath.GetAttr("name")
+
+	err := ToProtobufMessage(build(cty.EmptyObjectVal), (&testproto.Recursive{}).ProtoReflect(), WithMaxDepth(depth))
+	if err == nil {
+		t.Fatalf("succeeded with invalid value; want error")
+	}
+	pathErr, ok := err.(cty.PathError)
+	if !ok {
+		t.Fatalf("error is %T, not cty.PathError: %s", err, err.Error())
+	}
+	if !pathErr.Path.Equals(wantPath) {
+		t.Errorf("wrong error path\ngot:  %#v\nwant: %#v", pathErr.Path, wantPath)
+	}
+
+	paths, err := ToProtobufMessageSetFields(build(cty.StringVal("leaf")), (&testproto.Recursive{}).ProtoReflect(), WithMaxDepth(depth))
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	var wantPaths []cty.Path
+	var prefix cty.Path
+	for i := 0; i < depth; i++ {
+		wantPaths = append(wantPaths, prefix.Copy().GetAttr("name"))
+		if i < depth-1 {
+			prefix = prefix.GetAttr("next")
+			wantPaths = append(wantPaths, prefix.Copy())
+		}
+	}
+	assertPathsEqual(t, paths, wantPaths)
+}
+
+func BenchmarkToProtobufMessageDeep(b *testing.B) {
+	const depth = 50
+	v, err := FromProtobufMessage(deepRecursiveMessage(depth).ProtoReflect(), WithMaxDepth(depth))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		err := ToProtobufMessage(v, (&testproto.Recursive{}).ProtoReflect(), WithMaxDepth(depth))
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestNewProtobufMessage(t *testing.T) {
+	desc := (*testproto.WithOptional)(nil).ProtoReflect().Descriptor()
+	obj := cty.ObjectVal(map[string]cty.Value{
+		"int32_opt":   cty.NumberIntVal(13),
+		"int32_req":   cty.NumberIntVal(12),
+		"message_opt": cty.EmptyObjectVal,
+		"message_req": cty.NullVal(cty.EmptyObject),
+		"string_opt":  cty.NullVal(cty.String),
+		"string_req":  cty.StringVal("hi required"),
+	})
+
+	got, err := NewProtobufMessage(obj, desc)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if got.Descriptor().FullName() != desc.FullName() {
+		t.Fatalf("wrong message type %s; want %s", got.Descriptor().FullName(), desc.FullName())
+	}
+
+	int32Opt := int32(13)
+	want := &testproto.WithOptional{
+		StringReq:  "hi required",
+		Int32Req:   12,
+		Int32Opt:   &int32Opt,
+		MessageOpt: &testproto.WithOptional_Nested{},
+	}
+	if diff := cmp.Diff(want, got.Interface(), protocmp.Transform()); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+
+	_, err = NewProtobufMessage(cty.EmptyObjectVal, desc)
+	if err == nil {
+		t.Fatalf("succeeded with invalid object; want error")
+	}
+	if got, want := err.Error(), `missing required attribute "string_req"`; got != want {
+		t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestToProtobufMessageTupleElementPath(t *testing.T) {
+	desc := (*testproto.WithRepeated)(nil).ProtoReflect().Descriptor()
+	ty, err := ImpliedTypeForMessageDesc(desc)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	attrs := make(map[string]cty.Value)
+	for name, aty := range ty.AttributeTypes() {
+		attrs[name] = cty.NullVal(aty)
+	}
+	attrs["t_strings"] = cty.TupleVal([]cty.Value{
+		cty.StringVal("hello"),
+		cty.EmptyObjectVal,
+	})
+
+	err = ToProtobufMessage(cty.ObjectVal(attrs), (&testproto.WithRepeated{}).ProtoReflect())
+	if err == nil {
+		t.Fatalf("succeeded with invalid element; want error")
+	}
+	pathErr, ok := err.(cty.PathError)
+	if !ok {
+		t.Fatalf("error is %T, not cty.PathError: %s", err, err.Error())
+	}
+	wantPath := cty.GetAttrPath("t_strings").IndexInt(1)
+	if !pathErr.Path.Equals(wantPath) {
+		t.Errorf("wrong error path\ngot:  %#v\nwant: %#v", pathErr.Path, wantPath)
+	}
+}
+
+func TestToProtobufMessageIndexedObjectLists(t *testing.T) {
+	desc := (*testproto.WithRepeated)(nil).ProtoReflect().Descriptor()
+	ty, err := ImpliedTypeForMessageDesc(desc)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	nested := func(s string) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{
+			"t_nested_field": cty.StringVal(s),
+		})
+	}
+	withMessages := func(v cty.Value) cty.Value {
+		attrs := make(map[string]cty.Value)
+		for name, aty := range ty.AttributeTypes() {
+			attrs[name] = cty.NullVal(aty)
+		}
+		attrs["t_message"] = v
+		return cty.ObjectVal(attrs)
+	}
+
+	tests := map[string]struct {
+		Value    cty.Value
+		Options  []Option
+		Want     []string
+		WantErr  string
+		WantPath cty.Path
+	}{
+		"object": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"2":  nested("c"),
+				"0":  nested("a"),
+				"10": nested("k"),
+				"1":  nested("b"),
+				"3":  nested("d"),
+				"4":  nested("e"),
+				"5":  nested("f"),
+				"6":  nested("g"),
+				"7":  nested("h"),
+				"8":  nested("i"),
+				"9":  nested("j"),
+			}),
+			Options: []Option{WithIndexedObjectLists()},
+			Want:    []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k"},
+		},
+		"map": {
+			Value: cty.MapVal(map[string]cty.Value{
+				"1": nested("b"),
+				"0": nested("a"),
+			}),
+			Options: []Option{WithIndexedObjectLists()},
+			Want:    []string{"a", "b"},
+		},
+		"empty object": {
+			Value:   cty.EmptyObjectVal,
+			Options: []Option{WithIndexedObjectLists()},
+			Want:    nil,
+		},
+		"list": {
+			Value:   cty.ListVal([]cty.Value{nested("a")}),
+			Options: []Option{WithIndexedObjectLists()},
+			Want:    []string{"a"},
+		},
+		"missing index": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"0":  nested("a"),
+				"1":  nested("b"),
+				"2":  nested("c"),
+				"10": nested("k"),
+			}),
+			Options:  []Option{WithIndexedObjectLists()},
+			WantErr:  "missing element 3",
+			WantPath: cty.GetAttrPath("t_message"),
+		},
+		"non-decimal key": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"0":  nested("a"),
+				"01": nested("b"),
+			}),
+			Options:  []Option{WithIndexedObjectLists()},
+			WantErr:  "element key must be a decimal index",
+			WantPath: cty.GetAttrPath("t_message").GetAttr("01"),
+		},
+		"invalid element": {
+			Value: cty.MapVal(map[string]cty.Value{
+				"0": cty.StringVal("a"),
+			}),
+			Options:  []Option{WithIndexedObjectLists()},
+			WantErr:  "an object is required",
+			WantPath: cty.GetAttrPath("t_message").Index(cty.StringVal("0")),
+		},
+		"without option": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"0": nested("a"),
+			}),
+			WantErr:  "a list is required",
+			WantPath: cty.GetAttrPath("t_message"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := &testproto.WithRepeated{}
+			err := ToProtobufMessage(withMessages(test.Value), got.ProtoReflect(), test.Options...)
+
+			if test.WantErr != "" {
+				if err == nil {
+					t.Fatalf("succeeded; want error\nwant: %s", test.WantErr)
+				}
+				if got, want := err.Error(), test.WantErr; got != want {
+					t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+				}
+				pathErr, ok := err.(cty.PathError)
+				if !ok {
+					t.Fatalf("error is %T, not cty.PathError: %s", err, err.Error())
+				}
+				if !pathErr.Path.Equals(test.WantPath) {
+					t.Errorf("wrong error path\ngot:  %#v\nwant: %#v", pathErr.Path, test.WantPath)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+
+			var names []string
+			for _, elem := range got.TMessage {
+				names = append(names, elem.TNestedField)
+			}
+			if diff := cmp.Diff(test.Want, names); diff != "" {
+				t.Errorf("wrong result\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestToProtobufMessageBytesAsNumberLists(t *testing.T) {
+	desc := (*testproto.Assorted)(nil).ProtoReflect().Descriptor()
+	ty, err := ImpliedTypeForMessageDesc(desc, WithBytesAsNumberLists())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if got, want := ty.AttributeType("t_bytes"), cty.List(cty.Number); !want.Equals(got) {
+		t.Errorf("wrong type for t_bytes\ngot:  %#v\nwant: %#v", got, want)
+	}
+	// WithOmitDefaults allows the other attributes to be null, so we can
+	// focus only on the bytes field.
+	opts := []Option{WithBytesAsNumberLists(), WithOmitDefaults()}
+	withBytes := func(v cty.Value) cty.Value {
+		attrs := make(map[string]cty.Value)
+		for name, aty := range ty.AttributeTypes() {
+			attrs[name] = cty.NullVal(aty)
+		}
+		attrs["t_bytes"] = v
+		return cty.ObjectVal(attrs)
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		got := &testproto.Assorted{TBytes: []byte{1}}
+		err := ToProtobufMessage(withBytes(cty.ListValEmpty(cty.Number)), got.ProtoReflect(), opts...)
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		if len(got.TBytes) != 0 {
+			t.Errorf("wrong result %#v; want no bytes", got.TBytes)
+		}
+	})
+	t.Run("out of range", func(t *testing.T) {
+		v := withBytes(cty.ListVal([]cty.Value{
+			cty.NumberIntVal(0),
+			cty.NumberIntVal(255),
+			cty.NumberIntVal(-1),
+		}))
+		err := ToProtobufMessage(v, (&testproto.Assorted{}).ProtoReflect(), opts...)
+		if err == nil {
+			t.Fatalf("succeeded with out-of-range byte; want error")
+		}
+		if got, want := err.Error(), "value -1 is not a byte; must be a whole number between 0 and 255"; got != want {
+			t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+		}
+		pathErr, ok := err.(cty.PathError)
+		if !ok {
+			t.Fatalf("error is %T, not cty.PathError: %s", err, err.Error())
+		}
+		wantPath := cty.GetAttrPath("t_bytes").IndexInt(2)
+		if !pathErr.Path.Equals(wantPath) {
+			t.Errorf("wrong error path\ngot:  %#v\nwant: %#v", pathErr.Path, wantPath)
+		}
+	})
+}
+
+func TestToProtobufMessageNumberMapTuple(t *testing.T) {
+	// FromProtobufMessage produces a tuple for a map with non-string keys
+	// when the values have different types, so we accept a tuple of map
+	// entry objects as well as a set.
+	entry := func(k int64, v cty.Value) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{
+			"key":   cty.NumberIntVal(k),
+			"value": v,
+		})
+	}
+	empty, err := FromProtobufMessage((&testproto.WithRepeated{}).ProtoReflect())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	withEntries := func(entries ...cty.Value) cty.Value {
+		attrs := empty.AsValueMap()
+		attrs["t_map_number_bool"] = cty.TupleVal(entries)
+		return cty.ObjectVal(attrs)
+	}
+
+	value := withEntries(
+		entry(1, cty.True),
+		entry(2, cty.False),
+	)
+	got := &testproto.WithRepeated{}
+	err = ToProtobufMessage(value, got.ProtoReflect())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	want := &testproto.WithRepeated{
+		TMapNumberBool: map[int64]bool{1: true, 2: false},
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+
+	t.Run("duplicate key", func(t *testing.T) {
+		value := withEntries(
+			entry(1, cty.True),
+			entry(1, cty.False),
+		)
+		err := ToProtobufMessage(value, (&testproto.WithRepeated{}).ProtoReflect())
+		if err == nil {
+			t.Fatalf("succeeded; want error")
+		}
+		if got, want := err.Error(), "duplicate map key 1"; got != want {
+			t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+		}
+	})
+	t.Run("wrong element type", func(t *testing.T) {
+		value := withEntries(
+			entry(1, cty.True),
+			cty.ObjectVal(map[string]cty.Value{"key": cty.NumberIntVal(2)}),
+		)
+		err := ToProtobufMessage(value, (&testproto.WithRepeated{}).ProtoReflect())
+		if err == nil {
+			t.Fatalf("succeeded; want error")
+		}
+		if got, want := err.Error(), `tuple element type must have attribute "value"`; got != want {
+			t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+		}
+	})
+}
+
+func TestNewProtobufMessageRuntimeDescriptor(t *testing.T) {
+	// This descriptor is built at runtime rather than generated by the
+	// protocol buffers compiler, so there is no concrete Go type for it
+	// and we can only work with it using dynamic messages.
+	desc := runtimeMessageDesc(t)
+
+	ty, err := ImpliedTypeForMessageDesc(desc)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	want := cty.ObjectVal(map[string]cty.Value{
+		"name": cty.StringVal("example"),
+		"size": cty.NumberIntVal(12),
+		"tags": cty.ListVal([]cty.Value{
+			cty.StringVal("a"),
+			cty.StringVal("b"),
+		}),
+		"counts": cty.MapVal(map[string]cty.Value{
+			"x": cty.NumberIntVal(1),
+		}),
+		"child": cty.ObjectVal(map[string]cty.Value{
+			"name": cty.StringVal("inner"),
+		}),
+	})
+	if !want.Type().Equals(ty) {
+		t.Fatalf("test value does not conform to implied type %#v", ty)
+	}
+
+	msg, err := NewProtobufMessage(want, desc)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	got, err := FromProtobufMessage(msg)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if !want.RawEquals(got) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestToProtobufMessageOptionalPresence(t *testing.T) {
+	obj := func(int32Opt, stringOpt cty.Value) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{
+			"int32_opt":   int32Opt,
+			"int32_req":   cty.NumberIntVal(0),
+			"message_opt": cty.NullVal(cty.EmptyObject),
+			"message_req": cty.NullVal(cty.EmptyObject),
+			"string_opt":  stringOpt,
+			"string_req":  cty.StringVal(""),
+		})
+	}
+	tests := map[string]struct {
+		Value   cty.Value
+		WantHas bool
+	}{
+		"zero": {
+			obj(cty.NumberIntVal(0), cty.StringVal("")),
+			true,
+		},
+		"null": {
+			obj(cty.NullVal(cty.Number), cty.NullVal(cty.String)),
+			false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// We start with the fields set to non-zero values so that
+			// we can also see that null unsets them.
+			int32Opt, stringOpt := int32(5), "hello"
+			msg := (&testproto.WithOptional{
+				Int32Opt:  &int32Opt,
+				StringOpt: &stringOpt,
+			}).ProtoReflect()
+			err := ToProtobufMessage(test.Value, msg)
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+
+			fields := msg.Descriptor().Fields()
+			for _, name := range []protoreflect.Name{"int32_opt", "string_opt"} {
+				if got, want := msg.Has(fields.ByName(name)), test.WantHas; got != want {
+					t.Errorf("wrong presence for %s\ngot:  %t\nwant: %t", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+// runtimeMessageDesc returns the descriptor for a message type constructed at runtime from a descriptor proto, in the same way as for
+// schemas that are not known until runtime.
+func runtimeMessageDesc(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+
+	field := func(name string, number int32, label descriptorpb.FieldDescriptorProto_Label, typ descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+		ret := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    label.Enum(),
+			Type:     typ.Enum(),
+		}
+		if typeName != "" {
+			ret.TypeName = proto.String(typeName)
+		}
+		return ret
+	}
+	const (
+		optional = descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+		repeated = descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+	)
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("runtime.proto"),
+		Package: proto.String("runtime"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Thing"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("name", 1, optional, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+					field("size", 2, optional, descriptorpb.FieldDescriptorProto_TYPE_INT64, ""),
+					field("tags", 3, repeated, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+					field("counts", 4, repeated, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".runtime.Thing.CountsEntry"),
+					field("child", 5, optional, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".runtime.Thing.Child"),
+				},
+				NestedType: []*descriptorpb.DescriptorProto{
+					{
+						Name: proto.String("Child"),
+						Field: []*descriptorpb.FieldDescriptorProto{
+							field("name", 1, optional, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+						},
+					},
+					{
+						Name: proto.String("CountsEntry"),
+						Field: []*descriptorpb.FieldDescriptorProto{
+							field("key", 1, optional, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+							field("value", 2, optional, descriptorpb.FieldDescriptorProto_TYPE_INT32, ""),
+						},
+						Options: &descriptorpb.MessageOptions{
+							MapEntry: proto.Bool(true),
+						},
+					},
+				},
+			},
+		},
+	}
+	fd, err := protodesc.NewFile(fdp, nil)
+	if err != nil {
+		t.Fatalf("invalid test descriptor: %s", err)
+	}
+	return fd.Messages().ByName("Thing")
+}
+
+func TestToProtobufMessageContext(t *testing.T) {
+	obj := cty.ObjectVal(map[string]cty.Value{
+		"foo": cty.EmptyObjectVal,
+	})
+
+	into := &testproto.Simple{}
+	err := ToProtobufMessageContext(context.Background(), obj, into.ProtoReflect())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	want := &testproto.Simple{
+		Foo: &testproto.Empty{},
+	}
+	if diff := cmp.Diff(want, into, protocmp.Transform()); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = ToProtobufMessageContext(ctx, obj, (&testproto.Simple{}).ProtoReflect())
+	if err != context.Canceled {
+		t.Errorf("wrong error\ngot:  %#v\nwant: %#v", err, context.Canceled)
+	}
+}
+
+// thingsEnumValue is an EnumValueFunc which accepts the names of the
+// values of any enumeration converted to uppercase and prefixed with
+// "THING_".
+func thingsEnumValue(enum protoreflect.EnumDescriptor, name string) protoreflect.EnumValueDescriptor {
+	if !strings.HasPrefix(name, "THING_") {
+		return nil
+	}
+	name = strings.TrimPrefix(name, "THING_")
+	values := enum.Values()
+	for i := 0; i < values.Len(); i++ {
+		value := values.Get(i)
+		if strings.ToUpper(string(value.Name())) == name {
+			return value
+		}
+	}
+	return nil
+}
+
+func TestToProtobufMessageComplexMap(t *testing.T) {
+	innerTy := cty.Object(map[string]cty.Type{
+		"name": cty.String,
+		"data": cty.String,
+	})
+	complexTy := cty.Object(map[string]cty.Type{
+		"inner":  innerTy,
+		"tags":   cty.List(cty.String),
+		"inners": cty.List(innerTy),
+	})
+	complexVal := func(name string, innerNames ...string) cty.Value {
+		inners := cty.ListValEmpty(innerTy)
+		if len(innerNames) != 0 {
+			elems := make([]cty.Value, len(innerNames))
+			for i, n := range innerNames {
+				elems[i] = cty.ObjectVal(map[string]cty.Value{
+					"name": cty.StringVal(n),
+					"data": cty.StringVal(""),
+				})
+			}
+			inners = cty.ListVal(elems)
+		}
+		return cty.ObjectVal(map[string]cty.Value{
+			"inner": cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal(name),
+				"data": cty.StringVal("AQI="),
+			}),
+			"tags":   cty.ListVal([]cty.Value{cty.StringVal("x"), cty.StringVal("y")}),
+			"inners": inners,
+		})
+	}
+	value := cty.ObjectVal(map[string]cty.Value{
+		"t_map_string_complex": cty.MapVal(map[string]cty.Value{
+			"a": complexVal("a", "a1", "a2"),
+			"b": complexVal("b"),
+		}),
+		"t_map_number_complex": cty.SetVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{
+				"key":   cty.NumberIntVal(-5),
+				"value": complexVal("minus five", "n1"),
+			}),
+		}),
+	})
+	complexMsg := func(name string, innerNames ...string) *testproto.WithComplexMap_Complex {
+		ret := &testproto.WithComplexMap_Complex{
+			Inner: &testproto.WithComplexMap_Complex_Inner{
+				Name: name,
+				Data: []byte{1, 2},
+			},
+			Tags: []string{"x", "y"},
+		}
+		for _, n := range innerNames {
+			ret.Inners = append(ret.Inners, &testproto.WithComplexMap_Complex_Inner{Name: n})
+		}
+		return ret
+	}
+	want := &testproto.WithComplexMap{
+		TMapStringComplex: map[string]*testproto.WithComplexMap_Complex{
+			"a": complexMsg("a", "a1", "a2"),
+			"b": complexMsg("b"),
+		},
+		TMapNumberComplex: map[int64]*testproto.WithComplexMap_Complex{
+			-5: complexMsg("minus five", "n1"),
+		},
+	}
+
+	got := &testproto.WithComplexMap{}
+	err := ToProtobufMessage(value, got.ProtoReflect())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+
+	back, err := FromProtobufMessage(got.ProtoReflect())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if !value.RawEquals(back) {
+		t.Errorf("wrong result after round-trip\ngot:  %#v\nwant: %#v", back, value)
+	}
+
+	// Errors deep inside map values must report the full path.
+	badInner := cty.ObjectVal(map[string]cty.Value{
+		"name": cty.EmptyObjectVal,
+		"data": cty.StringVal(""),
+	})
+	badComplex := cty.ObjectVal(map[string]cty.Value{
+		"inner": cty.NullVal(innerTy),
+		"tags":  cty.ListValEmpty(cty.String),
+		"inners": cty.TupleVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal("ok"),
+				"data": cty.StringVal(""),
+			}),
+			badInner,
+		}),
+	})
+	badEntry := cty.ObjectVal(map[string]cty.Value{
+		"key":   cty.NumberIntVal(1),
+		"value": badComplex,
+	})
+	tests := map[string]struct {
+		Value    cty.Value
+		WantPath cty.Path
+	}{
+		"string keys": {
+			cty.ObjectVal(map[string]cty.Value{
+				"t_map_string_complex": cty.ObjectVal(map[string]cty.Value{
+					"k": badComplex,
 				}),
-				"t_any_map_number": cty.SetVal([]cty.Value{
-					cty.ObjectVal(map[string]cty.Value{
-						"key": cty.NumberIntVal(4),
-						"value": cty.ObjectVal(map[string]cty.Value{
-							"type_url": cty.StringVal("type.googleapis.com/testproto.Empty"),
-							"value":    cty.StringVal(""),
-						}),
+				"t_map_number_complex": cty.SetValEmpty(cty.Object(map[string]cty.Type{
+					"key":   cty.Number,
+					"value": complexTy,
+				})),
+			}),
+			cty.GetAttrPath("t_map_string_complex").GetAttr("k").GetAttr("inners").IndexInt(1).GetAttr("name"),
+		},
+		"number keys": {
+			cty.ObjectVal(map[string]cty.Value{
+				"t_map_string_complex": cty.MapValEmpty(complexTy),
+				"t_map_number_complex": cty.SetVal([]cty.Value{badEntry}),
+			}),
+			cty.GetAttrPath("t_map_number_complex").Index(badEntry).GetAttr("value").GetAttr("inners").IndexInt(1).GetAttr("name"),
+		},
+		"number keys in tuple": {
+			cty.ObjectVal(map[string]cty.Value{
+				"t_map_string_complex": cty.MapValEmpty(complexTy),
+				"t_map_number_complex": cty.TupleVal([]cty.Value{badEntry}),
+			}),
+			cty.GetAttrPath("t_map_number_complex").IndexInt(0).GetAttr("value").GetAttr("inners").IndexInt(1).GetAttr("name"),
+		},
+		"null list element": {
+			// Protocol buffers can't represent null elements in repeated
+			// fields.
+			cty.ObjectVal(map[string]cty.Value{
+				"t_map_string_complex": cty.MapVal(map[string]cty.Value{
+					"k": cty.ObjectVal(map[string]cty.Value{
+						"inner":  cty.NullVal(innerTy),
+						"tags":   cty.ListValEmpty(cty.String),
+						"inners": cty.ListVal([]cty.Value{cty.NullVal(innerTy)}),
 					}),
 				}),
-				"t_any_map_string": cty.MapVal(map[string]cty.Value{
-					"a": cty.ObjectVal(map[string]cty.Value{
-						"type_url": cty.StringVal("type.googleapis.com/testproto.Empty"),
-						"value":    cty.StringVal(""),
+				"t_map_number_complex": cty.SetValEmpty(cty.Object(map[string]cty.Type{
+					"key":   cty.Number,
+					"value": complexTy,
+				})),
+			}),
+			cty.GetAttrPath("t_map_string_complex").Index(cty.StringVal("k")).GetAttr("inners").IndexInt(0),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ToProtobufMessage(test.Value, (&testproto.WithComplexMap{}).ProtoReflect())
+			if err == nil {
+				t.Fatalf("succeeded with invalid value; want error")
+			}
+			pathErr, ok := err.(cty.PathError)
+			if !ok {
+				t.Fatalf("error is %T, not cty.PathError: %s", err, err.Error())
+			}
+			if !pathErr.Path.Equals(test.WantPath) {
+				t.Errorf("wrong error path\ngot:  %#v\nwant: %#v", pathErr.Path, test.WantPath)
+			}
+		})
+	}
+}
+
+func TestToProtobufMessageMaxDepth(t *testing.T) {
+	const length = 100000
+	const maxDepth = 100
+	v := cty.NullVal(cty.DynamicPseudoType)
+	for i := 0; i < length; i++ {
+		v = cty.ObjectVal(map[string]cty.Value{
+			"name": cty.StringVal(""),
+			"next": v,
+		})
+	}
+
+	msg := &testproto.Recursive{}
+	err := ToProtobufMessage(v, msg.ProtoReflect(), WithMaxDepth(maxDepth))
+	if err == nil {
+		t.Fatalf("succeeded; want error")
+	}
+	if got, want := err.Error(), "message is nested more than 100 levels deep"; !strings.HasSuffix(got, want) {
+		t.Errorf("wrong error\ngot:  %s\nwant suffix: %s", got, want)
+	}
+	pathErr, ok := err.(cty.PathError)
+	if !ok {
+		t.Fatalf("error is %T, not cty.PathError: %s", err, err.Error())
+	}
+	if got, want := len(pathErr.Path), maxDepth+1; got != want {
+		t.Errorf("error path has %d steps; want %d", got, want)
+	}
+}
+
+func TestToProtobufMessageSetFields(t *testing.T) {
+	t.Run("optional", func(t *testing.T) {
+		obj := cty.ObjectVal(map[string]cty.Value{
+			"string_req":  cty.StringVal(""),
+			"string_opt":  cty.NullVal(cty.String),
+			"int32_req":   cty.NullVal(cty.Number),
+			"int32_opt":   cty.NumberIntVal(0),
+			"message_req": cty.EmptyObjectVal,
+			"message_opt": cty.NullVal(cty.EmptyObject),
+		})
+		got, err := ToProtobufMessageSetFields(obj, (&testproto.WithOptional{}).ProtoReflect(), WithOmitDefaults())
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		want := []cty.Path{
+			cty.GetAttrPath("string_req"),
+			cty.GetAttrPath("int32_opt"),
+			cty.GetAttrPath("message_req"),
+		}
+		assertPathsEqual(t, got, want)
+	})
+	t.Run("nested", func(t *testing.T) {
+		obj := cty.ObjectVal(map[string]cty.Value{
+			"name": cty.StringVal("a"),
+			"next": cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal("b"),
+				"next": cty.NullVal(cty.DynamicPseudoType),
+			}),
+		})
+		got, err := ToProtobufMessageSetFields(obj, (&testproto.Recursive{}).ProtoReflect())
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		want := []cty.Path{
+			cty.GetAttrPath("name"),
+			cty.GetAttrPath("next"),
+			cty.GetAttrPath("next").GetAttr("name"),
+		}
+		assertPathsEqual(t, got, want)
+	})
+	t.Run("map", func(t *testing.T) {
+		obj := cty.ObjectVal(map[string]cty.Value{
+			"t_map_string_complex": cty.MapVal(map[string]cty.Value{
+				"k": cty.ObjectVal(map[string]cty.Value{
+					"inner": cty.ObjectVal(map[string]cty.Value{
+						"name": cty.StringVal("n"),
+						"data": cty.StringVal(""),
 					}),
+					"tags":   cty.ListValEmpty(cty.String),
+					"inners": cty.ListValEmpty(cty.EmptyObject),
 				}),
-				"t_string": cty.StringVal("not an any"),
 			}),
-			Into: &testproto.WithAny{},
-			Want: &testproto.WithAny{
-				TString: "not an any",
-				TAny:    mustAny(&testproto.Simple{Foo: &testproto.Empty{}}),
-				TAnyList: []*anypb.Any{
-					mustAny(&testproto.Empty{}),
-				},
-				TAnyMapString: map[string]*anypb.Any{
-					"a": mustAny(&testproto.Empty{}),
-				},
-				TAnyMapNumber: map[int64]*anypb.Any{
-					4: mustAny(&testproto.Empty{}),
-				},
-			},
-		},
-		"enum all unset": {
-			Value: cty.ObjectVal(map[string]cty.Value{
-				"t_enum":   cty.StringVal("A"),
-				"t_string": cty.StringVal(""),
+			"t_map_number_complex": cty.SetValEmpty(cty.Object(map[string]cty.Type{
+				"key":   cty.Number,
+				"value": cty.EmptyObject,
+			})),
+		})
+		got, err := ToProtobufMessageSetFields(obj, (&testproto.WithComplexMap{}).ProtoReflect(), WithOmitDefaults())
+		if err != nil {
+			t.Fatalf("unexpected error\ngot: %s", err.Error())
+		}
+		mapPath := cty.GetAttrPath("t_map_string_complex").Index(cty.StringVal("k"))
+		want := []cty.Path{
+			cty.GetAttrPath("t_map_string_complex"),
+			mapPath.GetAttr("inner"),
+			mapPath.GetAttr("inner").GetAttr("name"),
+			mapPath.GetAttr("inner").GetAttr("data"),
+			mapPath.GetAttr("tags"),
+			mapPath.GetAttr("inners"),
+			cty.GetAttrPath("t_map_number_complex"),
+		}
+		assertPathsEqual(t, got, want)
+	})
+	t.Run("error", func(t *testing.T) {
+		got, err := ToProtobufMessageSetFields(cty.EmptyObjectVal, (&testproto.Recursive{}).ProtoReflect())
+		if err == nil {
+			t.Fatalf("succeeded; want error")
+		}
+		if got != nil {
+			t.Errorf("returned paths despite the error: %#v", got)
+		}
+	})
+}
+
+func assertPathsEqual(t *testing.T, got, want []cty.Path) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("wrong number of paths %d; want %d\ngot:  %#v\nwant: %#v", len(got), len(want), got, want)
+	}
+	for i := range want {
+		if !got[i].Equals(want[i]) {
+			t.Errorf("wrong path %d\ngot:  %#v\nwant: %#v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestToProtobufMessagePackedRepeated(t *testing.T) {
+	// The wire encoding of a repeated scalar field is decided by the schema
+	// rather than by us, but we'll make sure that the messages we produce
+	// are encoded exactly as protobuf itself would encode them.
+	numbers := cty.ListVal([]cty.Value{
+		cty.NumberIntVal(1),
+		cty.NumberIntVal(-2),
+		cty.NumberIntVal(300),
+	})
+	appendPacked := func(b []byte, num protowire.Number) []byte {
+		var payload []byte
+		for _, n := range []int64{1, -2, 300} {
+			payload = protowire.AppendVarint(payload, uint64(n))
+		}
+		b = protowire.AppendTag(b, num, protowire.BytesType)
+		return protowire.AppendBytes(b, payload)
+	}
+	appendUnpacked := func(b []byte, num protowire.Number) []byte {
+		for _, n := range []int64{1, -2, 300} {
+			b = protowire.AppendTag(b, num, protowire.VarintType)
+			b = protowire.AppendVarint(b, uint64(n))
+		}
+		return b
+	}
+
+	tests := map[string]struct {
+		Obj  cty.Value
+		Msg  proto.Message
+		Want []byte
+	}{
+		"proto2 unpacked by default and explicitly packed": {
+			cty.ObjectVal(map[string]cty.Value{
+				"t_unpacked": numbers,
+				"t_packed":   numbers,
 			}),
-			Into: &testproto.WithEnum{},
-			Want: &testproto.WithEnum{},
+			&testproto.WithPacking{
+				TUnpacked: []int32{1, -2, 300},
+				TPacked:   []int32{1, -2, 300},
+			},
+			appendPacked(appendUnpacked(nil, 1), 2),
 		},
-		"enum all set": {
-			Value: cty.ObjectVal(map[string]cty.Value{
-				"t_enum":   cty.StringVal("d"),
-				"t_string": cty.StringVal("hello"),
+		"proto3 packed by default": {
+			cty.ObjectVal(map[string]cty.Value{
+				"t_numbers": numbers,
 			}),
-			Into: &testproto.WithEnum{},
-			Want: &testproto.WithEnum{
-				TString: "hello",
-				TEnum:   testproto.WithEnum_d,
+			&testproto.WithRepeatedNumbers{
+				TNumbers: []int64{1, -2, 300},
 			},
+			appendPacked(nil, 1),
 		},
 	}
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			gotReflect := test.Into.ProtoReflect()
-			err := ToProtobufMessage(test.Value, gotReflect)
+			marshal := proto.MarshalOptions{Deterministic: true}
+			fromProtobuf, err := marshal.Marshal(test.Msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(fromProtobuf, test.Want) {
+				t.Fatalf("test expectation disagrees with protobuf\nprotobuf: %x\nwant:     %x", fromProtobuf, test.Want)
+			}
+
+			into := test.Msg.ProtoReflect().New()
+			if err := ToProtobufMessage(test.Obj, into); err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			got, err := marshal.Marshal(into.Interface())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, test.Want) {
+				t.Errorf("wrong encoding\ngot:  %x\nwant: %x", got, test.Want)
+			}
+
+			// A dynamic message must be encoded in the same way.
+			dyn := dynamicpb.NewMessage(into.Descriptor())
+			if err := ToProtobufMessage(test.Obj, dyn); err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			got, err = marshal.Marshal(dyn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, test.Want) {
+				t.Errorf("wrong encoding for dynamic message\ngot:  %x\nwant: %x", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestBase64Encoding(t *testing.T) {
+	// These bytes encode differently in each of the encodings, because
+	// they use the characters that differ between the alphabets and need
+	// padding.
+	raw := []byte{0xfb, 0xff}
+	tests := map[string]struct {
+		Encoding *base64.Encoding
+		Want     string
+	}{
+		"std":     {base64.StdEncoding, "+/8="},
+		"url":     {base64.URLEncoding, "-_8="},
+		"raw std": {base64.RawStdEncoding, "+/8"},
+		"raw url": {base64.RawURLEncoding, "-_8"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			opts := []Option{WithBase64Encoding(test.Encoding)}
+			msg := &testproto.Assorted{TBytes: raw}
+			v, err := FromProtobufMessage(msg.ProtoReflect(), opts...)
+			if err != nil {
+				t.Fatalf("unexpected error from FromProtobufMessage\ngot: %s", err.Error())
+			}
+			if got, want := v.GetAttr("t_bytes"), cty.StringVal(test.Want); !want.RawEquals(got) {
+				t.Errorf("wrong value\ngot:  %#v\nwant: %#v", got, want)
+			}
+
+			into := &testproto.Assorted{}
+			err = ToProtobufMessage(v, into.ProtoReflect(), opts...)
+			if err != nil {
+				t.Fatalf("unexpected error from ToProtobufMessage\ngot: %s", err.Error())
+			}
+			if diff := cmp.Diff(msg, into, protocmp.Transform()); diff != "" {
+				t.Errorf("wrong result after round-trip\n%s", diff)
+			}
+		})
+	}
+
+	// Padding is the most likely mistake when the two sides disagree about
+	// the encoding, so it gets a more specific error message.
+	errTests := map[string]struct {
+		Encoding *base64.Encoding
+		Input    string
+		WantErr  string
+	}{
+		"padding with raw encoding": {
+			base64.RawStdEncoding, "+/8=",
+			"string must contain base64-encoded bytes without padding",
+		},
+		"url alphabet with std encoding": {
+			base64.StdEncoding, "-_8=",
+			"string must contain base64-encoded bytes",
+		},
+		"missing padding with std encoding": {
+			base64.StdEncoding, "+/8",
+			"string must contain base64-encoded bytes",
+		},
+	}
+	for name, test := range errTests {
+		t.Run(name, func(t *testing.T) {
+			opts := makeOptions([]Option{WithBase64Encoding(test.Encoding)})
+			_, err := toProtobufBytes(cty.StringVal(test.Input), opts, nil)
+			if err == nil {
+				t.Fatalf("succeeded; want error")
+			}
+			if got, want := err.Error(), test.WantErr; got != want {
+				t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
+
+func TestToProtobufMessageNumberCoercion(t *testing.T) {
+	tests := map[string]struct {
+		Attr    string
+		Value   cty.Value
+		Options []Option
+		Want    *testproto.Assorted
+		WantErr string
+	}{
+		"int32 from string": {
+			Attr:    "t_int32",
+			Value:   cty.StringVal("42"),
+			Options: []Option{WithNumberCoercion()},
+			Want:    &testproto.Assorted{TInt32: 42},
+		},
+		"uint64 from string": {
+			Attr:    "t_uint64",
+			Value:   cty.StringVal("18446744073709551615"),
+			Options: []Option{WithNumberCoercion()},
+			Want:    &testproto.Assorted{TUint64: math.MaxUint64},
+		},
+		"double from string": {
+			Attr:    "t_double",
+			Value:   cty.StringVal("1.5"),
+			Options: []Option{WithNumberCoercion()},
+			Want:    &testproto.Assorted{TDouble: 1.5},
+		},
+		"number unchanged": {
+			Attr:    "t_int32",
+			Value:   cty.NumberIntVal(42),
+			Options: []Option{WithNumberCoercion()},
+			Want:    &testproto.Assorted{TInt32: 42},
+		},
+		"fraction for integer field": {
+			Attr:    "t_int32",
+			Value:   cty.StringVal("1.5"),
+			Options: []Option{WithNumberCoercion()},
+			WantErr: "value 1.5 is not a whole number",
+		},
+		"fraction for integer field with truncation": {
+			Attr:    "t_int32",
+			Value:   cty.StringVal("1.5"),
+			Options: []Option{WithNumberCoercion(), WithIntegerTruncation()},
+			Want:    &testproto.Assorted{TInt32: 1},
+		},
+		"not a number": {
+			Attr:    "t_int32",
+			Value:   cty.StringVal("not a number"),
+			Options: []Option{WithNumberCoercion()},
+			WantErr: `a number is required, or a string containing a number, but "not a number" is not a number`,
+		},
+		"empty string": {
+			Attr:    "t_uint64",
+			Value:   cty.StringVal(""),
+			Options: []Option{WithNumberCoercion()},
+			WantErr: `a number is required, or a string containing a number, but "" is not a number`,
+		},
+		"float from string": {
+			Attr:    "t_float",
+			Value:   cty.StringVal("-2.25"),
+			Options: []Option{WithNumberCoercion()},
+			Want:    &testproto.Assorted{TFloat: -2.25},
+		},
+		"sint64 from string beyond float precision": {
+			Attr:    "t_sint64",
+			Value:   cty.StringVal("-9223372036854775807"),
+			Options: []Option{WithNumberCoercion()},
+			Want:    &testproto.Assorted{TSint64: -9223372036854775807},
+		},
+		"string without option": {
+			Attr:    "t_int32",
+			Value:   cty.StringVal("42"),
+			WantErr: "number value is required",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			v, err := FromProtobufMessage((&testproto.Assorted{}).ProtoReflect())
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			attrs := v.AsValueMap()
+			attrs[test.Attr] = test.Value
 
+			got := &testproto.Assorted{}
+			err = ToProtobufMessage(cty.ObjectVal(attrs), got.ProtoReflect(), test.Options...)
 			if test.WantErr != "" {
 				if err == nil {
 					t.Fatalf("succeeded; want error\nwant: %s", test.WantErr)
 				}
 				if got, want := err.Error(), test.WantErr; got != want {
-					t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+					t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+				}
+				pathErr, ok := err.(cty.PathError)
+				if !ok {
+					t.Fatalf("error is %T, not cty.PathError: %s", err, err.Error())
+				}
+				if wantPath := cty.GetAttrPath(test.Attr); !pathErr.Path.Equals(wantPath) {
+					t.Errorf("wrong error path\ngot:  %#v\nwant: %#v", pathErr.Path, wantPath)
 				}
 				return
-			} else if err != nil {
+			}
+			if err != nil {
 				t.Fatalf("unexpected error\ngot: %s", err.Error())
 			}
+			if diff := cmp.Diff(test.Want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("wrong result\n%s", diff)
+			}
+		})
+	}
+}
 
-			got := gotReflect.Interface()
-			want := test.Want
+func TestToProtobufMessageBytesCapsuleCopied(t *testing.T) {
+	// The message must not share memory with the capsule value, or else
+	// modifying the message would also modify the supposedly-immutable
+	// cty value.
+	v, err := FromProtobufMessage((&testproto.Assorted{}).ProtoReflect(), WithBytesCapsule())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	attrs := v.AsValueMap()
+	attrs["t_bytes"] = BytesCapsuleVal([]byte("abc"))
+	obj := cty.ObjectVal(attrs)
 
-			if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+	got := &testproto.Assorted{}
+	err = ToProtobufMessage(obj, got.ProtoReflect(), WithBytesCapsule())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	copy(got.TBytes, "xyz")
+
+	if got, want := obj.GetAttr("t_bytes"), BytesCapsuleVal([]byte("abc")); !want.RawEquals(got) {
+		t.Errorf("capsule changed after modifying the message\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestToProtobufMessageJSONNameFallback(t *testing.T) {
+	msg := &testproto.Assorted{
+		TString: "hello",
+		TInt32:  5,
+		TMessage: &testproto.Assorted_Nested{
+			TNestedField: "nested",
+		},
+	}
+	protoNames, err := FromProtobufMessage(msg.ProtoReflect())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	jsonNames, err := FromProtobufMessage(msg.ProtoReflect(), WithFieldNameFunc(JSONFieldName))
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	rename := func(obj cty.Value, from, to string) cty.Value {
+		attrs := obj.AsValueMap()
+		attrs[to] = attrs[from]
+		delete(attrs, from)
+		return cty.ObjectVal(attrs)
+	}
+	with := func(obj cty.Value, name string, v cty.Value) cty.Value {
+		attrs := obj.AsValueMap()
+		attrs[name] = v
+		return cty.ObjectVal(attrs)
+	}
+
+	tests := map[string]struct {
+		Value    cty.Value
+		Options  []Option
+		WantErr  string
+		WantPath cty.Path
+	}{
+		"proto names": {
+			Value:   protoNames,
+			Options: []Option{WithJSONNameFallback()},
+		},
+		"JSON names": {
+			Value:   jsonNames,
+			Options: []Option{WithJSONNameFallback()},
+		},
+		"mixed names": {
+			Value: rename(
+				with(protoNames, "t_message", rename(protoNames.GetAttr("t_message"), "t_nested_field", "tNestedField")),
+				"t_string", "tString",
+			),
+			Options: []Option{WithJSONNameFallback()},
+		},
+		"JSON names without option": {
+			Value:    jsonNames,
+			WantErr:  `missing required attribute "t_double"`,
+			WantPath: cty.Path{},
+		},
+		"both names": {
+			Value:    with(protoNames, "tString", cty.StringVal("hello")),
+			Options:  []Option{WithJSONNameFallback()},
+			WantErr:  `attributes "t_string" and "tString" both represent field t_string, so only one of them may be present`,
+			WantPath: cty.Path{},
+		},
+		"both names nested": {
+			Value:    with(protoNames, "t_message", with(protoNames.GetAttr("t_message"), "tNestedField", cty.StringVal("nested"))),
+			Options:  []Option{WithJSONNameFallback()},
+			WantErr:  `attributes "t_nested_field" and "tNestedField" both represent field t_nested_field, so only one of them may be present`,
+			WantPath: cty.GetAttrPath("t_message"),
+		},
+		"error path uses JSON name": {
+			Value:    with(jsonNames, "tInt32", cty.StringVal("five")),
+			Options:  []Option{WithJSONNameFallback()},
+			WantErr:  "number value is required",
+			WantPath: cty.GetAttrPath("tInt32"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := &testproto.Assorted{}
+			err := ToProtobufMessage(test.Value, got.ProtoReflect(), test.Options...)
+			if test.WantErr != "" {
+				if err == nil {
+					t.Fatalf("succeeded; want error\nwant: %s", test.WantErr)
+				}
+				if got, want := err.Error(), test.WantErr; got != want {
+					t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+				}
+				pathErr, ok := err.(cty.PathError)
+				if !ok {
+					t.Fatalf("error is %T, not cty.PathError: %s", err, err.Error())
+				}
+				if !pathErr.Path.Equals(test.WantPath) {
+					t.Errorf("wrong error path\ngot:  %#v\nwant: %#v", pathErr.Path, test.WantPath)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+			if diff := cmp.Diff(msg, got, protocmp.Transform()); diff != "" {
 				t.Errorf("wrong result\n%s", diff)
 			}
 		})
 	}
-
 }
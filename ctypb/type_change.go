@@ -0,0 +1,106 @@
+package ctypb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// DescribeTypeChange compares the types that ImpliedTypeForMessageDesc
+// would return for the two given message descriptors, which are presumably
+// two versions of the same message type, and returns a human-readable
+// description of each difference between them.
+//
+// Protocol buffers has its own compatibility rules which allow, for example,
+// adding new fields to an existing message type without breaking existing
+// clients, but those rules don't apply to the corresponding cty types: any
+// difference between the types is potentially a breaking change for
+// applications that depend on the exact cty type. DescribeTypeChange can
+// help schema owners understand the impact of a change on cty-based
+// consumers before they ship it.
+//
+// Each string in the result starts with the path of the affected attribute,
+// followed by a colon and then a description of the change. The strings are
+// sorted, and the result is empty if the two types are equal.
+//
+// The given options are used when determining both of the implied types.
+func DescribeTypeChange(oldDesc, newDesc protoreflect.MessageDescriptor, opts ...Option) ([]string, error) {
+	oldTy, err := ImpliedTypeForMessageDesc(oldDesc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	newTy, err := ImpliedTypeForMessageDesc(newDesc, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []string
+	describeTypeChange(oldTy, newTy, nil, &changes)
+	sort.Strings(changes)
+	return changes, nil
+}
+
+func describeTypeChange(oldTy, newTy cty.Type, path cty.Path, changes *[]string) {
+	if oldTy.Equals(newTy) {
+		return
+	}
+
+	switch {
+	case oldTy.IsObjectType() && newTy.IsObjectType():
+		oldAtys := oldTy.AttributeTypes()
+		newAtys := newTy.AttributeTypes()
+		for name, oldAty := range oldAtys {
+			// Temporarily extend path with new attribute name
+			path := append(path, cty.GetAttrStep{Name: name})
+
+			newAty, exists := newAtys[name]
+			if !exists {
+				*changes = append(*changes, fmt.Sprintf("%s: attribute removed", formatTypePath(path)))
+				continue
+			}
+			describeTypeChange(oldAty, newAty, path, changes)
+		}
+		for name, newAty := range newAtys {
+			if _, exists := oldAtys[name]; exists {
+				continue // already handled above
+			}
+			path := append(path, cty.GetAttrStep{Name: name})
+			*changes = append(*changes, fmt.Sprintf("%s: attribute added, of type %s", formatTypePath(path), newAty.FriendlyName()))
+		}
+	case oldTy.IsListType() && newTy.IsListType(),
+		oldTy.IsSetType() && newTy.IsSetType(),
+		oldTy.IsMapType() && newTy.IsMapType():
+		// Temporarily extend path with placeholder for indexing.
+		path := append(path, cty.IndexStep{Key: cty.DynamicVal})
+		describeTypeChange(oldTy.ElementType(), newTy.ElementType(), path, changes)
+	default:
+		*changes = append(*changes, fmt.Sprintf(
+			"%s: type changed from %s to %s",
+			formatTypePath(path), oldTy.FriendlyName(), newTy.FriendlyName(),
+		))
+	}
+}
+
+// formatTypePath returns a string representation of the given path through
+// a type, using "[*]" to represent any element of a collection.
+func formatTypePath(path cty.Path) string {
+	if len(path) == 0 {
+		return "(root)"
+	}
+	var buf strings.Builder
+	for _, step := range path {
+		switch step := step.(type) {
+		case cty.GetAttrStep:
+			if buf.Len() != 0 {
+				buf.WriteByte('.')
+			}
+			buf.WriteString(step.Name)
+		case cty.IndexStep:
+			buf.WriteString("[*]")
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,92 @@
+package ctypb
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestDescribeTypeChange(t *testing.T) {
+	field := func(name string, num int32, ty descriptorpb.FieldDescriptorProto_Type, label descriptorpb.FieldDescriptorProto_Label, typeName string) *descriptorpb.FieldDescriptorProto {
+		ret := &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(name),
+			Number: proto.Int32(num),
+			Type:   ty.Enum(),
+			Label:  label.Enum(),
+		}
+		if typeName != "" {
+			ret.TypeName = proto.String(typeName)
+		}
+		return ret
+	}
+	makeDesc := func(fields ...*descriptorpb.FieldDescriptorProto) protoreflect.MessageDescriptor {
+		file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+			Name:    proto.String("evolution.proto"),
+			Package: proto.String("evolution"),
+			Syntax:  proto.String("proto3"),
+			MessageType: []*descriptorpb.DescriptorProto{
+				{
+					Name:  proto.String("Thing"),
+					Field: fields,
+				},
+				{
+					Name: proto.String("Nested"),
+					Field: []*descriptorpb.FieldDescriptorProto{
+						field("name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL, ""),
+					},
+				},
+			},
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return file.Messages().ByName("Thing")
+	}
+
+	oldDesc := makeDesc(
+		field("kept", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL, ""),
+		field("removed", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL, ""),
+		field("retyped", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING, descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL, ""),
+		field("renumbered", 4, descriptorpb.FieldDescriptorProto_TYPE_INT32, descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL, ""),
+		field("now_repeated", 5, descriptorpb.FieldDescriptorProto_TYPE_BOOL, descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL, ""),
+		field("nested", 6, descriptorpb.FieldDescriptorProto_TYPE_STRING, descriptorpb.FieldDescriptorProto_LABEL_REPEATED, ""),
+	)
+	newDesc := makeDesc(
+		field("kept", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL, ""),
+		field("retyped", 3, descriptorpb.FieldDescriptorProto_TYPE_BOOL, descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL, ""),
+		field("renumbered", 40, descriptorpb.FieldDescriptorProto_TYPE_INT64, descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL, ""),
+		field("now_repeated", 5, descriptorpb.FieldDescriptorProto_TYPE_BOOL, descriptorpb.FieldDescriptorProto_LABEL_REPEATED, ""),
+		field("nested", 6, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, descriptorpb.FieldDescriptorProto_LABEL_REPEATED, ".evolution.Nested"),
+		field("added", 7, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL, ".evolution.Nested"),
+	)
+
+	t.Run("changed", func(t *testing.T) {
+		got, err := DescribeTypeChange(oldDesc, newDesc)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		want := []string{
+			`added: attribute added, of type object`,
+			`nested[*]: type changed from string to object`,
+			`now_repeated: type changed from bool to list of bool`,
+			`removed: attribute removed`,
+			`retyped: type changed from string to bool`,
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("wrong result\n%s", diff)
+		}
+	})
+	t.Run("unchanged", func(t *testing.T) {
+		got, err := DescribeTypeChange(oldDesc, oldDesc)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if len(got) != 0 {
+			t.Errorf("unexpected changes: %#v", got)
+		}
+	})
+}
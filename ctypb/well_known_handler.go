@@ -0,0 +1,443 @@
+package ctypb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// WellKnownHandler is the interface implemented by special-case conversions
+// for particular message types, which can then represent those messages
+// using a cty type other than the usual object type.
+//
+// A handler is associated with a specific message type by registering it
+// in a WellKnownHandlers registry under the message type's full name, and
+// then passing that registry to the conversion functions using the
+// WithWellKnownHandlers option.
+//
+// Handlers apply to message-typed fields, including the elements of
+// repeated fields and the values of map fields, and also to the top-level
+// message given to the conversion functions. For example, with the default
+// handlers a top-level google.protobuf.StringValue message is represented
+// as a bare cty.String value rather than as an object. Top-level messages
+// of any type that has no handler are always represented as objects.
+type WellKnownHandler interface {
+	// Type returns the cty type that represents messages of the given
+	// type. If the second return value is false then the handler declines
+	// to handle the given message type, and the message is represented in
+	// the usual way instead.
+	Type(desc protoreflect.MessageDescriptor) (cty.Type, bool)
+
+	// FromProto returns a value representing the given message, which
+	// must conform to the type returned by Type.
+	FromProto(msg protoreflect.Message) (cty.Value, error)
+
+	// ToProto writes the given value into the given message, which is
+	// initially empty. The value is never null or unknown, but may be of
+	// any type that conforms to the type returned by Type.
+	ToProto(v cty.Value, msg protoreflect.Message) error
+}
+
+// WellKnownHandlers is a registry of WellKnownHandler implementations,
+// keyed by the full names of the message types they handle.
+//
+// A registry must not be modified after it's been used with any of the
+// conversion functions in this package, because implied types are cached
+// using the registry's identity.
+type WellKnownHandlers struct {
+	handlers map[protoreflect.FullName]WellKnownHandler
+}
+
+// NewWellKnownHandlers returns a new registry that initially contains
+// handlers for the following well-known message types:
+//   - google.protobuf.Timestamp, represented as a string in RFC 3339 format
+//   - google.protobuf.Duration, represented as a string containing a number
+//     of seconds with an "s" suffix, such as "1.5s"
+//   - The wrapper types from google/protobuf/wrappers.proto, represented
+//     as values of the type that a field of the wrapped kind would have,
+//     or null when the wrapper message is absent.
+//
+// Callers can register additional handlers, or replace the built-in ones,
+// using the Register and RegisterStringType methods.
+func NewWellKnownHandlers() *WellKnownHandlers {
+	ret := &WellKnownHandlers{
+		handlers: make(map[protoreflect.FullName]WellKnownHandler),
+	}
+	ret.Register(timestampFullName, timestampHandler{})
+	ret.Register(durationFullName, durationHandler{})
+	for _, name := range wrapperFullNames {
+		ret.Register(name, wrapperHandler{})
+	}
+	return ret
+}
+
+// Register associates the given handler with the message type that has
+// the given full name, replacing any handler previously registered for
+// that name. Registering a nil handler removes any existing handler.
+func (r *WellKnownHandlers) Register(name protoreflect.FullName, handler WellKnownHandler) {
+	if handler == nil {
+		delete(r.handlers, name)
+		return
+	}
+	r.handlers[name] = handler
+}
+
+// RegisterStringType is a convenience wrapper around Register for message
+// types that have a canonical string form, such as UUIDs or IP addresses,
+// which then have the type cty.String.
+//
+// The from function returns the string form of the given message. The to
+// function parses the given string and writes the result into the given
+// message, which is initially empty. Errors returned by either function
+// are reported against the path of the message being converted.
+func (r *WellKnownHandlers) RegisterStringType(name protoreflect.FullName, from func(protoreflect.Message) (string, error), to func(string, protoreflect.Message) error) {
+	r.Register(name, stringHandler{from: from, to: to})
+}
+
+// Handler returns the handler registered for the message type that has the
+// given full name, or nil if there is no such handler.
+func (r *WellKnownHandlers) Handler(name protoreflect.FullName) WellKnownHandler {
+	return r.handlers[name]
+}
+
+// wellKnownHandlerFor returns the handler to use for the given message
+// descriptor, along with the type that it uses to represent the message,
+// or nil if messages of this type should be represented in the usual way.
+func wellKnownHandlerFor(desc protoreflect.MessageDescriptor, opts *options) (WellKnownHandler, cty.Type) {
+	if ty, ok := opts.messageCapsules[desc.FullName()]; ok {
+		return messageCapsuleHandler{ty: ty}, ty
+	}
+	if opts.wellKnownHandlers == nil {
+		return nil, cty.NilType
+	}
+	handler := opts.wellKnownHandlers.Handler(desc.FullName())
+	if handler == nil {
+		return nil, cty.NilType
+	}
+	var ty cty.Type
+	var ok bool
+	if oh, isOpts := handler.(optionsWellKnownHandler); isOpts {
+		ty, ok = oh.typeWithOptions(desc, opts)
+	} else {
+		ty, ok = handler.Type(desc)
+	}
+	if !ok {
+		return nil, cty.NilType
+	}
+	return handler, ty
+}
+
+// optionsWellKnownHandler is implemented by built-in handlers whose
+// representation depends on the conversion options, which can't be
+// expressed through the WellKnownHandler interface itself. The conversion
+// functions call these methods instead of the corresponding methods of
+// WellKnownHandler, which then use the default options.
+type optionsWellKnownHandler interface {
+	WellKnownHandler
+
+	typeWithOptions(desc protoreflect.MessageDescriptor, opts *options) (cty.Type, bool)
+	fromProtoWithOptions(msg protoreflect.Message, opts *options) (cty.Value, error)
+	toProtoWithOptions(v cty.Value, msg protoreflect.Message, opts *options) error
+}
+
+// fromWellKnownHandler converts the given message using the given handler,
+// verifying that the result conforms to the type the handler declared.
+func fromWellKnownHandler(handler WellKnownHandler, ty cty.Type, msg protoreflect.Message, opts *options, path cty.Path) (cty.Value, error) {
+	var v cty.Value
+	var err error
+	if oh, ok := handler.(optionsWellKnownHandler); ok {
+		v, err = oh.fromProtoWithOptions(msg, opts)
+	} else {
+		v, err = handler.FromProto(msg)
+	}
+	if err != nil {
+		return cty.NilVal, path.NewError(err)
+	}
+	// A handler can declare a type that includes cty.DynamicPseudoType if
+	// the type of its values depends on their content.
+	if errs := v.Type().TestConformance(ty); len(errs) != 0 {
+		return cty.NilVal, path.NewErrorf("handler for %s returned %s, but should return %s", msg.Descriptor().FullName(), v.Type().FriendlyName(), ty.FriendlyName())
+	}
+	return v, nil
+}
+
+// toWellKnownHandler writes the given value into the given message using
+// the given handler, first rejecting null and unknown values because
+// handlers are not required to deal with those.
+func toWellKnownHandler(handler WellKnownHandler, v cty.Value, msg protoreflect.Message, opts *options, path cty.Path) error {
+	if v.IsNull() {
+		return path.NewErrorf("must not be null")
+	}
+	if !v.IsKnown() {
+		return path.NewErrorf("value must be known")
+	}
+	var err error
+	if oh, ok := handler.(optionsWellKnownHandler); ok {
+		err = oh.toProtoWithOptions(v, msg, opts)
+	} else {
+		err = handler.ToProto(v, msg)
+	}
+	if err != nil {
+		return path.NewError(err)
+	}
+	return nil
+}
+
+// These are the full names of the well-known message types that have
+// built-in handlers.
+const (
+	timestampFullName protoreflect.FullName = "google.protobuf.Timestamp"
+	durationFullName  protoreflect.FullName = "google.protobuf.Duration"
+)
+
+var wrapperFullNames = []protoreflect.FullName{
+	"google.protobuf.DoubleValue",
+	"google.protobuf.FloatValue",
+	"google.protobuf.Int64Value",
+	"google.protobuf.UInt64Value",
+	"google.protobuf.Int32Value",
+	"google.protobuf.UInt32Value",
+	"google.protobuf.BoolValue",
+	"google.protobuf.StringValue",
+	"google.protobuf.BytesValue",
+}
+
+// timestampHandler is the built-in WellKnownHandler for
+// google.protobuf.Timestamp.
+type timestampHandler struct{}
+
+func (timestampHandler) Type(desc protoreflect.MessageDescriptor) (cty.Type, bool) {
+	return cty.String, true
+}
+
+func (timestampHandler) FromProto(msg protoreflect.Message) (cty.Value, error) {
+	fields := msg.Descriptor().Fields()
+	secs := msg.Get(fields.ByName("seconds")).Int()
+	nanos := msg.Get(fields.ByName("nanos")).Int()
+	t := time.Unix(secs, nanos).UTC()
+	return cty.StringVal(t.Format(time.RFC3339Nano)), nil
+}
+
+func (timestampHandler) ToProto(v cty.Value, msg protoreflect.Message) error {
+	if !cty.String.Equals(v.Type()) {
+		return fmt.Errorf("a string is required")
+	}
+	t, err := time.Parse(time.RFC3339Nano, v.AsString())
+	if err != nil {
+		return fmt.Errorf("must be a timestamp in RFC 3339 format")
+	}
+	fields := msg.Descriptor().Fields()
+	msg.Set(fields.ByName("seconds"), protoreflect.ValueOfInt64(t.Unix()))
+	msg.Set(fields.ByName("nanos"), protoreflect.ValueOfInt32(int32(t.Nanosecond())))
+	return nil
+}
+
+// durationHandler is the built-in WellKnownHandler for
+// google.protobuf.Duration.
+type durationHandler struct{}
+
+// maxDurationSeconds is the largest number of seconds that a
+// google.protobuf.Duration message may represent, in either direction.
+const maxDurationSeconds = 315576000000
+
+func (durationHandler) Type(desc protoreflect.MessageDescriptor) (cty.Type, bool) {
+	return cty.String, true
+}
+
+func (durationHandler) FromProto(msg protoreflect.Message) (cty.Value, error) {
+	fields := msg.Descriptor().Fields()
+	secs := msg.Get(fields.ByName("seconds")).Int()
+	nanos := msg.Get(fields.ByName("nanos")).Int()
+	// These are the same rules that durationpb's CheckValid enforces.
+	switch {
+	case secs > maxDurationSeconds || secs < -maxDurationSeconds:
+		return cty.NilVal, fmt.Errorf("duration is out of range")
+	case nanos >= 1e9 || nanos <= -1e9:
+		return cty.NilVal, fmt.Errorf("duration has %d nanoseconds, which is more than a second", nanos)
+	case (secs > 0 && nanos < 0) || (secs < 0 && nanos > 0):
+		return cty.NilVal, fmt.Errorf("duration has seconds and nanoseconds with different signs")
+	}
+
+	var buf strings.Builder
+	if secs < 0 || nanos < 0 {
+		buf.WriteByte('-')
+		secs, nanos = -secs, -nanos
+	}
+	buf.WriteString(strconv.FormatInt(secs, 10))
+	if nanos != 0 {
+		frac := fmt.Sprintf("%09d", nanos)
+		buf.WriteByte('.')
+		buf.WriteString(strings.TrimRight(frac, "0"))
+	}
+	buf.WriteByte('s')
+	return cty.StringVal(buf.String()), nil
+}
+
+func (durationHandler) ToProto(v cty.Value, msg protoreflect.Message) error {
+	if !cty.String.Equals(v.Type()) {
+		return fmt.Errorf("a string is required")
+	}
+	secs, nanos, ok := parseDurationSeconds(v.AsString())
+	if !ok {
+		// We also accept the more flexible syntax that Go itself uses
+		// for durations, such as "1h30m", although that can't represent
+		// the full range of a protobuf duration.
+		d, err := time.ParseDuration(v.AsString())
+		if err != nil {
+			return fmt.Errorf("must be a duration, such as \"1.5s\"")
+		}
+		secs = int64(d / time.Second)
+		nanos = int32(d % time.Second)
+	}
+	if secs > maxDurationSeconds || secs < -maxDurationSeconds {
+		return fmt.Errorf("duration is out of range")
+	}
+	fields := msg.Descriptor().Fields()
+	msg.Set(fields.ByName("seconds"), protoreflect.ValueOfInt64(secs))
+	msg.Set(fields.ByName("nanos"), protoreflect.ValueOfInt32(nanos))
+	return nil
+}
+
+// parseDurationSeconds parses a duration in the syntax that durationHandler
+// produces, which is a decimal number of seconds with at most nine
+// fractional digits, followed by an "s" suffix.
+func parseDurationSeconds(s string) (secs int64, nanos int32, ok bool) {
+	body := strings.TrimSuffix(s, "s")
+	if body == s {
+		return 0, 0, false
+	}
+	neg := strings.HasPrefix(body, "-")
+	if neg {
+		body = body[1:]
+	}
+	intPart, fracPart := body, ""
+	if dot := strings.IndexByte(body, '.'); dot >= 0 {
+		intPart, fracPart = body[:dot], body[dot+1:]
+	}
+	if intPart == "" || len(fracPart) > 9 || !isDecimalDigits(intPart) || !isDecimalDigits(fracPart) {
+		return 0, 0, false
+	}
+	secs, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	if fracPart != "" {
+		n, err := strconv.ParseInt(fracPart+strings.Repeat("0", 9-len(fracPart)), 10, 32)
+		if err != nil {
+			return 0, 0, false
+		}
+		nanos = int32(n)
+	}
+	if neg {
+		secs, nanos = -secs, -nanos
+	}
+	return secs, nanos, true
+}
+
+func isDecimalDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// stringHandler is the WellKnownHandler that RegisterStringType registers.
+type stringHandler struct {
+	from func(protoreflect.Message) (string, error)
+	to   func(string, protoreflect.Message) error
+}
+
+func (stringHandler) Type(desc protoreflect.MessageDescriptor) (cty.Type, bool) {
+	return cty.String, true
+}
+
+func (h stringHandler) FromProto(msg protoreflect.Message) (cty.Value, error) {
+	s, err := h.from(msg)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	return cty.StringVal(s), nil
+}
+
+func (h stringHandler) ToProto(v cty.Value, msg protoreflect.Message) error {
+	if !cty.String.Equals(v.Type()) {
+		return fmt.Errorf("a string is required")
+	}
+	return h.to(v.AsString(), msg)
+}
+
+// wrapperHandler is the built-in WellKnownHandler for the wrapper message
+// types, which each have a single field named "value".
+//
+// The wrapped value is converted using the default options, regardless
+// of the options used for the conversion as a whole, except that the
+// value of a google.protobuf.BytesValue uses the same representation as
+// any other field of the bytes kind, so that it behaves like an optional
+// bytes field, and likewise for the 64-bit integer wrappers with
+// WithInt64AsStrings. The limits set by WithMaxStringLen and
+// WithMaxBytesLen also apply to the wrapped values.
+type wrapperHandler struct{}
+
+var _ optionsWellKnownHandler = wrapperHandler{}
+
+func (h wrapperHandler) Type(desc protoreflect.MessageDescriptor) (cty.Type, bool) {
+	return h.typeWithOptions(desc, defaultOptions)
+}
+
+func (h wrapperHandler) FromProto(msg protoreflect.Message) (cty.Value, error) {
+	return h.fromProtoWithOptions(msg, defaultOptions)
+}
+
+func (h wrapperHandler) ToProto(v cty.Value, msg protoreflect.Message) error {
+	return h.toProtoWithOptions(v, msg, defaultOptions)
+}
+
+func (wrapperHandler) typeWithOptions(desc protoreflect.MessageDescriptor, opts *options) (cty.Type, bool) {
+	field := desc.Fields().ByName("value")
+	if field == nil {
+		return cty.NilType, false
+	}
+	ty, err := impliedTypeForFieldKind(field, wrapperOptions(opts), nil)
+	if err != nil {
+		return cty.NilType, false
+	}
+	return ty, true
+}
+
+func (wrapperHandler) fromProtoWithOptions(msg protoreflect.Message, opts *options) (cty.Value, error) {
+	field := msg.Descriptor().Fields().ByName("value")
+	return fromProtobufFieldKindValue(msg.Get(field), field, wrapperOptions(opts), nil)
+}
+
+func (wrapperHandler) toProtoWithOptions(v cty.Value, msg protoreflect.Message, opts *options) error {
+	field := msg.Descriptor().Fields().ByName("value")
+	vProto, err := toProtobufValue(v, field, nil, wrapperOptions(opts), nil)
+	if err != nil {
+		return err
+	}
+	msg.Set(field, vProto)
+	return nil
+}
+
+// wrapperOptions returns the options that wrapperHandler uses to convert
+// wrapped values, given the options for the conversion as a whole.
+func wrapperOptions(opts *options) *options {
+	if !(opts.bytesCapsule || opts.bytesAsUTF8 || opts.bytesAsNumbers || opts.base64Encoding != nil || opts.int64AsStrings || opts.maxStringLen > 0 || opts.maxBytesLen > 0) {
+		return defaultOptions
+	}
+	return &options{
+		bytesCapsule:   opts.bytesCapsule,
+		bytesAsUTF8:    opts.bytesAsUTF8,
+		bytesAsNumbers: opts.bytesAsNumbers,
+		base64Encoding: opts.base64Encoding,
+		int64AsStrings: opts.int64AsStrings,
+		maxStringLen:   opts.maxStringLen,
+		maxBytesLen:    opts.maxBytesLen,
+	}
+}
@@ -0,0 +1,683 @@
+package ctypb
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty-debug/ctydebug"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/zclconf/go-cty-protobuf/internal/testproto"
+)
+
+func TestWellKnownHandlersBuiltin(t *testing.T) {
+	opts := []Option{WithWellKnownHandlers(NewWellKnownHandlers())}
+	desc := (*testproto.WithWellKnown)(nil).ProtoReflect().Descriptor()
+
+	wantTy := cty.Object(map[string]cty.Type{
+		"t_bool_value":   cty.Bool,
+		"t_duration":     cty.String,
+		"t_int64_value":  cty.Number,
+		"t_string_value": cty.String,
+		"t_timestamp":    cty.String,
+	})
+	gotTy, err := ImpliedTypeForMessageDesc(desc, opts...)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if !wantTy.Equals(gotTy) {
+		t.Fatalf(
+			"wrong type\ngot: %s\nwant: %s",
+			ctydebug.TypeString(gotTy),
+			ctydebug.TypeString(wantTy),
+		)
+	}
+
+	tests := map[string]struct {
+		Msg *testproto.WithWellKnown
+		Obj cty.Value
+	}{
+		"all unset": {
+			// Absent wrapper messages are null, just like absent
+			// scalar fields that track presence.
+			&testproto.WithWellKnown{},
+			cty.ObjectVal(map[string]cty.Value{
+				"t_bool_value":   cty.NullVal(cty.Bool),
+				"t_duration":     cty.NullVal(cty.String),
+				"t_int64_value":  cty.NullVal(cty.Number),
+				"t_string_value": cty.NullVal(cty.String),
+				"t_timestamp":    cty.NullVal(cty.String),
+			}),
+		},
+		"all set": {
+			&testproto.WithWellKnown{
+				TTimestamp:   &timestamppb.Timestamp{Seconds: 1136214245, Nanos: 500000000},
+				TDuration:    &durationpb.Duration{Seconds: -90, Nanos: -250000000},
+				TStringValue: wrapperspb.String(""),
+				TInt64Value:  wrapperspb.Int64(-5),
+				TBoolValue:   wrapperspb.Bool(false),
+			},
+			cty.ObjectVal(map[string]cty.Value{
+				"t_bool_value":   cty.False,
+				"t_duration":     cty.StringVal("-90.25s"),
+				"t_int64_value":  cty.NumberIntVal(-5),
+				"t_string_value": cty.StringVal(""),
+				"t_timestamp":    cty.StringVal("2006-01-02T15:04:05.5Z"),
+			}),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := FromProtobufMessage(test.Msg.ProtoReflect(), opts...)
+			if err != nil {
+				t.Fatalf("unexpected error from FromProtobufMessage\ngot: %s", err.Error())
+			}
+			if diff := cmp.Diff(test.Obj, got, ctydebug.CmpOptions); diff != "" {
+				t.Errorf("wrong FromProtobufMessage result\n%s", diff)
+			}
+
+			into := &testproto.WithWellKnown{}
+			err = ToProtobufMessage(test.Obj, into.ProtoReflect(), opts...)
+			if err != nil {
+				t.Fatalf("unexpected error from ToProtobufMessage\ngot: %s", err.Error())
+			}
+			if diff := cmp.Diff(test.Msg, into, protocmp.Transform()); diff != "" {
+				t.Errorf("wrong ToProtobufMessage result\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWellKnownHandlersToProto(t *testing.T) {
+	opts := []Option{WithWellKnownHandlers(NewWellKnownHandlers())}
+	obj := func(timestamp, duration cty.Value) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{
+			"t_bool_value":   cty.NullVal(cty.Bool),
+			"t_duration":     duration,
+			"t_int64_value":  cty.NullVal(cty.Number),
+			"t_string_value": cty.NullVal(cty.String),
+			"t_timestamp":    timestamp,
+		})
+	}
+
+	tests := map[string]struct {
+		Value   cty.Value
+		Want    *testproto.WithWellKnown
+		WantErr string
+	}{
+		"timestamp with offset": {
+			Value: obj(cty.StringVal("2006-01-02T15:04:05-07:00"), cty.NullVal(cty.String)),
+			Want: &testproto.WithWellKnown{
+				TTimestamp: &timestamppb.Timestamp{Seconds: 1136239445},
+			},
+		},
+		"invalid timestamp": {
+			Value:   obj(cty.StringVal("yesterday"), cty.NullVal(cty.String)),
+			WantErr: "must be a timestamp in RFC 3339 format",
+		},
+		"duration in seconds": {
+			Value: obj(cty.NullVal(cty.String), cty.StringVal("1.000000001s")),
+			Want: &testproto.WithWellKnown{
+				TDuration: &durationpb.Duration{Seconds: 1, Nanos: 1},
+			},
+		},
+		"duration in Go syntax": {
+			Value: obj(cty.NullVal(cty.String), cty.StringVal("1h30m")),
+			Want: &testproto.WithWellKnown{
+				TDuration: &durationpb.Duration{Seconds: 5400},
+			},
+		},
+		"duration out of range": {
+			Value:   obj(cty.NullVal(cty.String), cty.StringVal("315576000001s")),
+			WantErr: "duration is out of range",
+		},
+		"invalid duration": {
+			Value:   obj(cty.NullVal(cty.String), cty.StringVal("soon")),
+			WantErr: `must be a duration, such as "1.5s"`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			into := &testproto.WithWellKnown{}
+			err := ToProtobufMessage(test.Value, into.ProtoReflect(), opts...)
+
+			if test.WantErr != "" {
+				if err == nil {
+					t.Fatalf("succeeded; want error\nwant: %s", test.WantErr)
+				}
+				if got, want := err.Error(), test.WantErr; got != want {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+				}
+				if _, ok := err.(cty.PathError); !ok {
+					t.Errorf("error is %T, not cty.PathError", err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error\ngot: %s", err.Error())
+			}
+
+			if diff := cmp.Diff(test.Want, into, protocmp.Transform()); diff != "" {
+				t.Errorf("wrong result\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWellKnownHandlersCollections(t *testing.T) {
+	// The handlers apply to the elements of repeated fields and to the
+	// values of map fields, just as they do to singular fields.
+	opts := []Option{WithWellKnownHandlers(NewWellKnownHandlers())}
+	desc := (*testproto.WithWellKnownCollections)(nil).ProtoReflect().Descriptor()
+
+	wantTy := cty.Object(map[string]cty.Type{
+		"t_timestamps": cty.List(cty.String),
+		"t_durations":  cty.Map(cty.String),
+	})
+	gotTy, err := ImpliedTypeForMessageDesc(desc, opts...)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if !wantTy.Equals(gotTy) {
+		t.Fatalf(
+			"wrong type\ngot: %s\nwant: %s",
+			ctydebug.TypeString(gotTy),
+			ctydebug.TypeString(wantTy),
+		)
+	}
+
+	tests := map[string]struct {
+		Msg *testproto.WithWellKnownCollections
+		Obj cty.Value
+	}{
+		"empty": {
+			&testproto.WithWellKnownCollections{},
+			cty.ObjectVal(map[string]cty.Value{
+				"t_timestamps": cty.ListValEmpty(cty.String),
+				"t_durations":  cty.MapValEmpty(cty.String),
+			}),
+		},
+		"populated": {
+			&testproto.WithWellKnownCollections{
+				TTimestamps: []*timestamppb.Timestamp{
+					{Seconds: 1136214245},
+					{Seconds: 0, Nanos: 500000000},
+				},
+				TDurations: map[string]*durationpb.Duration{
+					"short": {Nanos: 1000000},
+					"long":  {Seconds: 5400},
+				},
+			},
+			cty.ObjectVal(map[string]cty.Value{
+				"t_timestamps": cty.ListVal([]cty.Value{
+					cty.StringVal("2006-01-02T15:04:05Z"),
+					cty.StringVal("1970-01-01T00:00:00.5Z"),
+				}),
+				"t_durations": cty.MapVal(map[string]cty.Value{
+					"short": cty.StringVal("0.001s"),
+					"long":  cty.StringVal("5400s"),
+				}),
+			}),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := FromProtobufMessage(test.Msg.ProtoReflect(), opts...)
+			if err != nil {
+				t.Fatalf("unexpected error from FromProtobufMessage\ngot: %s", err.Error())
+			}
+			if diff := cmp.Diff(test.Obj, got, ctydebug.CmpOptions); diff != "" {
+				t.Errorf("wrong FromProtobufMessage result\n%s", diff)
+			}
+
+			into := &testproto.WithWellKnownCollections{}
+			err = ToProtobufMessage(test.Obj, into.ProtoReflect(), opts...)
+			if err != nil {
+				t.Fatalf("unexpected error from ToProtobufMessage\ngot: %s", err.Error())
+			}
+			if diff := cmp.Diff(test.Msg, into, protocmp.Transform()); diff != "" {
+				t.Errorf("wrong ToProtobufMessage result\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("invalid element", func(t *testing.T) {
+		obj := cty.ObjectVal(map[string]cty.Value{
+			"t_timestamps": cty.ListValEmpty(cty.String),
+			"t_durations": cty.MapVal(map[string]cty.Value{
+				"bad": cty.StringVal("soon"),
+			}),
+		})
+		err := ToProtobufMessage(obj, (&testproto.WithWellKnownCollections{}).ProtoReflect(), opts...)
+		if err == nil {
+			t.Fatalf("succeeded with invalid duration; want error")
+		}
+		pathErr, ok := err.(cty.PathError)
+		if !ok {
+			t.Fatalf("error is %T, not cty.PathError: %s", err, err.Error())
+		}
+		wantPath := cty.GetAttrPath("t_durations").Index(cty.StringVal("bad"))
+		if !pathErr.Path.Equals(wantPath) {
+			t.Errorf("wrong error path\ngot:  %#v\nwant: %#v", pathErr.Path, wantPath)
+		}
+	})
+}
+
+func TestWellKnownHandlersCustom(t *testing.T) {
+	handlers := NewWellKnownHandlers()
+	handlers.Register("testproto.Assorted.Nested", nestedFieldHandler{})
+	opts := []Option{WithWellKnownHandlers(handlers)}
+
+	msg := &testproto.Assorted{
+		TMessage: &testproto.Assorted_Nested{TNestedField: "hello"},
+	}
+	got, err := FromProtobufMessage(msg.ProtoReflect(), opts...)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if got, want := got.GetAttr("t_message"), cty.StringVal("hello"); !want.RawEquals(got) {
+		t.Errorf("wrong t_message\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	into := &testproto.Assorted{}
+	err = ToProtobufMessage(got, into.ProtoReflect(), opts...)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if diff := cmp.Diff(msg, into, protocmp.Transform()); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+
+	// Registering a nil handler removes the handler, so the message type
+	// is represented as an object again.
+	handlers = NewWellKnownHandlers()
+	handlers.Register("testproto.Assorted.Nested", nestedFieldHandler{})
+	handlers.Register("testproto.Assorted.Nested", nil)
+	got, err = FromProtobufMessage(msg.ProtoReflect(), WithWellKnownHandlers(handlers))
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if got := got.GetAttr("t_message").Type(); !got.IsObjectType() {
+		t.Errorf("wrong t_message type %#v; want object type", got)
+	}
+}
+
+func TestWellKnownHandlersDynamicType(t *testing.T) {
+	// A handler can declare cty.DynamicPseudoType and then return values
+	// of whatever type suits their content.
+	handlers := NewWellKnownHandlers()
+	handlers.Register("testproto.Assorted.Nested", numberOrStringHandler{})
+	handlers.Register("testproto.WithRepeated.Nested", numberOrStringHandler{})
+	opts := []Option{WithWellKnownHandlers(handlers)}
+
+	msg := &testproto.Assorted{
+		TMessage: &testproto.Assorted_Nested{TNestedField: "5"},
+	}
+	got, err := FromProtobufMessage(msg.ProtoReflect(), opts...)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if got, want := got.GetAttr("t_message"), cty.NumberIntVal(5); !want.RawEquals(got) {
+		t.Errorf("wrong t_message\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	// The elements of a repeated field can then have different types, and
+	// so they become a tuple.
+	repeated := &testproto.WithRepeated{
+		TMessage: []*testproto.WithRepeated_Nested{
+			{TNestedField: "5"},
+			{TNestedField: "five"},
+		},
+	}
+	got, err = FromProtobufMessage(repeated.ProtoReflect(), opts...)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	want := cty.TupleVal([]cty.Value{cty.NumberIntVal(5), cty.StringVal("five")})
+	if got := got.GetAttr("t_message"); !want.RawEquals(got) {
+		t.Errorf("wrong t_message\ngot:  %#v\nwant: %#v", got, want)
+	}
+	ty, err := ImpliedTypeForMessageDesc(repeated.ProtoReflect().Descriptor(), opts...)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if errs := got.Type().TestConformance(ty); len(errs) != 0 {
+		t.Errorf("result does not conform to the implied type: %s", errs[0])
+	}
+
+	into := &testproto.WithRepeated{}
+	err = ToProtobufMessage(got, into.ProtoReflect(), opts...)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if diff := cmp.Diff(repeated, into, protocmp.Transform()); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+}
+
+func TestWellKnownHandlersInvalidDuration(t *testing.T) {
+	opts := []Option{WithWellKnownHandlers(NewWellKnownHandlers())}
+	tests := map[string]struct {
+		Duration *durationpb.Duration
+		WantErr  string
+	}{
+		"mixed signs": {
+			&durationpb.Duration{Seconds: -1, Nanos: 5},
+			"duration has seconds and nanoseconds with different signs",
+		},
+		"seconds out of range": {
+			&durationpb.Duration{Seconds: maxDurationSeconds + 1},
+			"duration is out of range",
+		},
+		"nanoseconds out of range": {
+			&durationpb.Duration{Seconds: 1, Nanos: 1e9},
+			"duration has 1000000000 nanoseconds, which is more than a second",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			msg := &testproto.WithWellKnown{TDuration: test.Duration}
+			_, err := FromProtobufMessage(msg.ProtoReflect(), opts...)
+			if err == nil {
+				t.Fatalf("succeeded; want error")
+			}
+			if got := err.Error(); got != test.WantErr {
+				t.Errorf("wrong error\ngot:  %s\nwant: %s", got, test.WantErr)
+			}
+			pathErr, ok := err.(cty.PathError)
+			if !ok {
+				t.Fatalf("error is %T, not cty.PathError: %s", err, err.Error())
+			}
+			if want := cty.GetAttrPath("t_duration"); !pathErr.Path.Equals(want) {
+				t.Errorf("wrong error path\ngot:  %#v\nwant: %#v", pathErr.Path, want)
+			}
+		})
+	}
+}
+
+func TestWellKnownHandlersTopLevel(t *testing.T) {
+	opts := []Option{WithWellKnownHandlers(NewWellKnownHandlers())}
+	msg := wrapperspb.String("hello")
+
+	ty, err := ImpliedTypeForMessageDesc(msg.ProtoReflect().Descriptor(), opts...)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if !ty.Equals(cty.String) {
+		t.Errorf("wrong type %#v; want cty.String", ty)
+	}
+
+	got, err := FromProtobufMessage(msg.ProtoReflect(), opts...)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if want := cty.StringVal("hello"); !want.RawEquals(got) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	into := &wrapperspb.StringValue{}
+	err = ToProtobufMessage(cty.StringVal("hello"), into.ProtoReflect(), opts...)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if diff := cmp.Diff(msg, into, protocmp.Transform()); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+
+	err = ToProtobufMessage(cty.NullVal(cty.String), (&wrapperspb.StringValue{}).ProtoReflect(), opts...)
+	if err == nil {
+		t.Fatalf("succeeded with null value; want error")
+	}
+	if got, want := err.Error(), "must not be null"; got != want {
+		t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+
+	// Without the handlers, the top-level message is an object as usual.
+	got, err = FromProtobufMessage(msg.ProtoReflect())
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if want := cty.ObjectVal(map[string]cty.Value{"value": cty.StringVal("hello")}); !want.RawEquals(got) {
+		t.Errorf("wrong result without handlers\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestWellKnownHandlersRegisterStringType(t *testing.T) {
+	handlers := NewWellKnownHandlers()
+	handlers.RegisterStringType(
+		"testproto.Assorted.Nested",
+		func(msg protoreflect.Message) (string, error) {
+			field := msg.Descriptor().Fields().ByName("t_nested_field")
+			s := msg.Get(field).String()
+			if s == "" {
+				return "", fmt.Errorf("nested field is empty")
+			}
+			return "nested:" + s, nil
+		},
+		func(s string, msg protoreflect.Message) error {
+			if !strings.HasPrefix(s, "nested:") {
+				return fmt.Errorf("must start with \"nested:\"")
+			}
+			field := msg.Descriptor().Fields().ByName("t_nested_field")
+			msg.Set(field, protoreflect.ValueOfString(strings.TrimPrefix(s, "nested:")))
+			return nil
+		},
+	)
+	opts := []Option{WithWellKnownHandlers(handlers)}
+
+	ty, err := ImpliedTypeForMessageDesc((&testproto.Assorted{}).ProtoReflect().Descriptor(), opts...)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if got := ty.AttributeType("t_message"); !got.Equals(cty.String) {
+		t.Errorf("wrong t_message type %#v; want cty.String", got)
+	}
+
+	msg := &testproto.Assorted{
+		TMessage: &testproto.Assorted_Nested{TNestedField: "hello"},
+	}
+	got, err := FromProtobufMessage(msg.ProtoReflect(), opts...)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if got, want := got.GetAttr("t_message"), cty.StringVal("nested:hello"); !want.RawEquals(got) {
+		t.Errorf("wrong t_message\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	into := &testproto.Assorted{}
+	err = ToProtobufMessage(got, into.ProtoReflect(), opts...)
+	if err != nil {
+		t.Fatalf("unexpected error\ngot: %s", err.Error())
+	}
+	if diff := cmp.Diff(msg, into, protocmp.Transform()); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+
+	t.Run("from error", func(t *testing.T) {
+		msg := &testproto.Assorted{
+			TMessage: &testproto.Assorted_Nested{},
+		}
+		_, err := FromProtobufMessage(msg.ProtoReflect(), opts...)
+		if err == nil {
+			t.Fatalf("succeeded; want error")
+		}
+		if got, want := err.Error(), "nested field is empty"; got != want {
+			t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+		}
+		pathErr, ok := err.(cty.PathError)
+		if !ok {
+			t.Fatalf("error is %T, not cty.PathError: %s", err, err.Error())
+		}
+		if wantPath := cty.GetAttrPath("t_message"); !pathErr.Path.Equals(wantPath) {
+			t.Errorf("wrong error path\ngot:  %#v\nwant: %#v", pathErr.Path, wantPath)
+		}
+	})
+	t.Run("to error", func(t *testing.T) {
+		attrs := got.AsValueMap()
+		attrs["t_message"] = cty.StringVal("hello")
+		err := ToProtobufMessage(cty.ObjectVal(attrs), (&testproto.Assorted{}).ProtoReflect(), opts...)
+		if err == nil {
+			t.Fatalf("succeeded; want error")
+		}
+		if got, want := err.Error(), `must start with "nested:"`; got != want {
+			t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+		}
+		pathErr, ok := err.(cty.PathError)
+		if !ok {
+			t.Fatalf("error is %T, not cty.PathError: %s", err, err.Error())
+		}
+		if wantPath := cty.GetAttrPath("t_message"); !pathErr.Path.Equals(wantPath) {
+			t.Errorf("wrong error path\ngot:  %#v\nwant: %#v", pathErr.Path, wantPath)
+		}
+	})
+}
+
+// nestedFieldHandler is a WellKnownHandler that represents a message with
+// a single string field named "t_nested_field" as just a string.
+type nestedFieldHandler struct{}
+
+func (nestedFieldHandler) Type(desc protoreflect.MessageDescriptor) (cty.Type, bool) {
+	return cty.String, true
+}
+
+func (nestedFieldHandler) FromProto(msg protoreflect.Message) (cty.Value, error) {
+	field := msg.Descriptor().Fields().ByName("t_nested_field")
+	return cty.StringVal(msg.Get(field).String()), nil
+}
+
+func (nestedFieldHandler) ToProto(v cty.Value, msg protoreflect.Message) error {
+	if !cty.String.Equals(v.Type()) {
+		return fmt.Errorf("a string is required")
+	}
+	field := msg.Descriptor().Fields().ByName("t_nested_field")
+	msg.Set(field, protoreflect.ValueOfString(v.AsString()))
+	return nil
+}
+
+// numberOrStringHandler is a WellKnownHandler for the nested test message
+// types which represents the single field of each message as a number if it
+// contains one, or as a string otherwise.
+type numberOrStringHandler struct{}
+
+func (numberOrStringHandler) Type(desc protoreflect.MessageDescriptor) (cty.Type, bool) {
+	return cty.DynamicPseudoType, true
+}
+
+func (numberOrStringHandler) FromProto(msg protoreflect.Message) (cty.Value, error) {
+	s := msg.Get(msg.Descriptor().Fields().ByName("t_nested_field")).String()
+	if n, err := cty.ParseNumberVal(s); err == nil {
+		return n, nil
+	}
+	return cty.StringVal(s), nil
+}
+
+func (numberOrStringHandler) ToProto(v cty.Value, msg protoreflect.Message) error {
+	s, err := convert.Convert(v, cty.String)
+	if err != nil {
+		return err
+	}
+	msg.Set(msg.Descriptor().Fields().ByName("t_nested_field"), protoreflect.ValueOfString(s.AsString()))
+	return nil
+}
+
+func TestWellKnownHandlersBytesValue(t *testing.T) {
+	// A BytesValue uses the same representation as a bytes field, which
+	// depends on the options.
+	handlers := WithWellKnownHandlers(NewWellKnownHandlers())
+	tests := map[string]struct {
+		Options []Option
+		Msg     *testproto.WithBytesValue
+		Want    cty.Value
+	}{
+		"absent": {
+			[]Option{handlers},
+			&testproto.WithBytesValue{},
+			cty.NullVal(cty.String),
+		},
+		"empty": {
+			[]Option{handlers},
+			&testproto.WithBytesValue{TBytesValue: wrapperspb.Bytes(nil)},
+			cty.StringVal(""),
+		},
+		"base64": {
+			[]Option{handlers},
+			&testproto.WithBytesValue{TBytesValue: wrapperspb.Bytes([]byte("hi"))},
+			cty.StringVal("aGk="),
+		},
+		"url-safe base64 absent": {
+			[]Option{handlers, WithBase64Encoding(base64.URLEncoding)},
+			&testproto.WithBytesValue{},
+			cty.NullVal(cty.String),
+		},
+		"url-safe base64": {
+			[]Option{handlers, WithBase64Encoding(base64.URLEncoding)},
+			&testproto.WithBytesValue{TBytesValue: wrapperspb.Bytes([]byte{0xfb, 0xff})},
+			cty.StringVal("-_8="),
+		},
+		"utf8 absent": {
+			[]Option{handlers, WithBytesAsUTF8Strings()},
+			&testproto.WithBytesValue{},
+			cty.NullVal(cty.String),
+		},
+		"utf8": {
+			[]Option{handlers, WithBytesAsUTF8Strings()},
+			&testproto.WithBytesValue{TBytesValue: wrapperspb.Bytes([]byte("hi"))},
+			cty.StringVal("hi"),
+		},
+		"numbers absent": {
+			[]Option{handlers, WithBytesAsNumberLists()},
+			&testproto.WithBytesValue{},
+			cty.NullVal(cty.List(cty.Number)),
+		},
+		"numbers": {
+			[]Option{handlers, WithBytesAsNumberLists()},
+			&testproto.WithBytesValue{TBytesValue: wrapperspb.Bytes([]byte("hi"))},
+			cty.ListVal([]cty.Value{cty.NumberIntVal('h'), cty.NumberIntVal('i')}),
+		},
+		"capsule absent": {
+			[]Option{handlers, WithBytesCapsule()},
+			&testproto.WithBytesValue{},
+			cty.NullVal(BytesCapsuleType),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			desc := test.Msg.ProtoReflect().Descriptor()
+			ty, err := ImpliedTypeForMessageDesc(desc, test.Options...)
+			if err != nil {
+				t.Fatalf("unexpected error from ImpliedTypeForMessageDesc\ngot: %s", err.Error())
+			}
+			if got, want := ty.AttributeType("t_bytes_value"), test.Want.Type(); !want.Equals(got) {
+				t.Errorf("wrong implied type\ngot:  %#v\nwant: %#v", got, want)
+			}
+
+			got, err := FromProtobufMessage(test.Msg.ProtoReflect(), test.Options...)
+			if err != nil {
+				t.Fatalf("unexpected error from FromProtobufMessage\ngot: %s", err.Error())
+			}
+			if got := got.GetAttr("t_bytes_value"); !test.Want.RawEquals(got) {
+				t.Errorf("wrong FromProtobufMessage result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+
+			into := &testproto.WithBytesValue{}
+			err = ToProtobufMessage(got, into.ProtoReflect(), test.Options...)
+			if err != nil {
+				t.Fatalf("unexpected error from ToProtobufMessage\ngot: %s", err.Error())
+			}
+			if diff := cmp.Diff(test.Msg, into, protocmp.Transform()); diff != "" {
+				t.Errorf("wrong ToProtobufMessage result\n%s", diff)
+			}
+		})
+	}
+}
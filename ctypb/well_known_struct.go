@@ -0,0 +1,255 @@
+package ctypb
+
+import (
+	"math"
+
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// These are the full names of the well-known message types that together
+// represent arbitrary JSON-like data in protocol buffers, which we treat
+// specially when the WithWellKnownStruct option is in effect.
+const (
+	structFullName    protoreflect.FullName = "google.protobuf.Struct"
+	valueFullName     protoreflect.FullName = "google.protobuf.Value"
+	listValueFullName protoreflect.FullName = "google.protobuf.ListValue"
+	nullValueFullName protoreflect.FullName = "google.protobuf.NullValue"
+)
+
+// isWellKnownStruct returns true if the given message descriptor is one of
+// the well-known types google.protobuf.Struct, google.protobuf.Value, or
+// google.protobuf.ListValue.
+func isWellKnownStruct(desc protoreflect.MessageDescriptor) bool {
+	switch desc.FullName() {
+	case structFullName, valueFullName, listValueFullName:
+		return true
+	default:
+		return false
+	}
+}
+
+// isWellKnownNullValue returns true if the given field is of the enum kind
+// with the well-known enum type google.protobuf.NullValue, and the
+// WithWellKnownStruct option is in effect. Such a field is represented by
+// cty.NullVal(cty.DynamicPseudoType), because its only value represents
+// null.
+func isWellKnownNullValue(field protoreflect.FieldDescriptor, opts *options) bool {
+	if !opts.wellKnownStruct || field.Kind() != protoreflect.EnumKind {
+		return false
+	}
+	return field.Enum().FullName() == nullValueFullName
+}
+
+// fromWellKnownStructMessage returns the cty representation of the given
+// message, which must be of one of the message types accepted by
+// isWellKnownStruct.
+//
+// A Struct message becomes an object, a ListValue message becomes a tuple,
+// and a Value message becomes a value of whatever type corresponds with
+// the kind of value it contains. The result therefore conforms to
+// cty.DynamicPseudoType, which is the implied type of all of these message
+// types when the WithWellKnownStruct option is in effect.
+func fromWellKnownStructMessage(msg protoreflect.Message, path cty.Path) (cty.Value, error) {
+	switch msg.Descriptor().FullName() {
+	case structFullName:
+		return fromWellKnownStructFields(msg, path)
+	case listValueFullName:
+		return fromWellKnownListValues(msg, path)
+	default:
+		return fromWellKnownValue(msg, path)
+	}
+}
+
+// fromWellKnownValue returns the cty representation of the given message,
+// which must be a google.protobuf.Value message.
+func fromWellKnownValue(msg protoreflect.Message, path cty.Path) (cty.Value, error) {
+	oneof := msg.Descriptor().Oneofs().ByName("kind")
+	field := msg.WhichOneof(oneof)
+	if field == nil {
+		// A Value with no kind set at all is invalid, but we'll treat it
+		// as null because that's the closest equivalent.
+		return cty.NullVal(cty.DynamicPseudoType), nil
+	}
+	rawV := msg.Get(field)
+	switch field.Name() {
+	case "string_value":
+		return cty.StringVal(rawV.String()), nil
+	case "number_value":
+		f := rawV.Float()
+		if math.IsNaN(f) {
+			return cty.NilVal, path.NewErrorf("cannot represent NaN as a number")
+		}
+		return cty.NumberFloatVal(f), nil
+	case "bool_value":
+		return cty.BoolVal(rawV.Bool()), nil
+	case "struct_value":
+		return fromWellKnownStructFields(rawV.Message(), path)
+	case "list_value":
+		return fromWellKnownListValues(rawV.Message(), path)
+	default: // "null_value"
+		return cty.NullVal(cty.DynamicPseudoType), nil
+	}
+}
+
+// fromWellKnownStructFields returns an object value with one attribute for
+// each element of the "fields" map of the given message, which must be a
+// google.protobuf.Struct message.
+func fromWellKnownStructFields(msg protoreflect.Message, path cty.Path) (cty.Value, error) {
+	protoMap := msg.Get(msg.Descriptor().Fields().ByName("fields")).Map()
+	if protoMap.Len() == 0 {
+		return cty.EmptyObjectVal, nil
+	}
+	attrs := make(map[string]cty.Value, protoMap.Len())
+	var err error
+	protoMap.Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+		name := k.String()
+
+		// Temporarily extend path with new attribute name
+		path := append(path, cty.GetAttrStep{Name: name})
+
+		attrs[name], err = fromWellKnownValue(v.Message(), path)
+		return err == nil
+	})
+	if err != nil {
+		return cty.NilVal, err
+	}
+	return cty.ObjectVal(attrs), nil
+}
+
+// fromWellKnownListValues returns a tuple value with one element for each
+// element of the "values" list of the given message, which must be a
+// google.protobuf.ListValue message.
+func fromWellKnownListValues(msg protoreflect.Message, path cty.Path) (cty.Value, error) {
+	protoList := msg.Get(msg.Descriptor().Fields().ByName("values")).List()
+	if protoList.Len() == 0 {
+		return cty.EmptyTupleVal, nil
+	}
+	elems := make([]cty.Value, protoList.Len())
+	for i := range elems {
+		// Temporarily extend path with index
+		path := append(path, cty.IndexStep{Key: cty.NumberIntVal(int64(i))})
+
+		ev, err := fromWellKnownValue(protoList.Get(i).Message(), path)
+		if err != nil {
+			return cty.NilVal, err
+		}
+		elems[i] = ev
+	}
+	return cty.TupleVal(elems), nil
+}
+
+// toWellKnownStructMessage writes the given value into the given message,
+// which must be of one of the message types accepted by isWellKnownStruct.
+//
+// Unlike for other message types, the given value need not have any
+// particular type, because these message types are able to represent
+// any value that has an equivalent in JSON.
+func toWellKnownStructMessage(v cty.Value, msg protoreflect.Message, path cty.Path) error {
+	ty := v.Type()
+	switch msg.Descriptor().FullName() {
+	case structFullName:
+		if !(ty.IsObjectType() || ty.IsMapType()) {
+			return path.NewErrorf("an object is required")
+		}
+		return toWellKnownStructFields(v, msg, path)
+	case listValueFullName:
+		if !(ty.IsTupleType() || ty.IsListType()) {
+			return path.NewErrorf("a list or tuple is required")
+		}
+		return toWellKnownListValues(v, msg, path)
+	default:
+		return toWellKnownValue(v, msg, path)
+	}
+}
+
+// toWellKnownValue writes the given value into the given message, which
+// must be a google.protobuf.Value message.
+func toWellKnownValue(v cty.Value, msg protoreflect.Message, path cty.Path) error {
+	fields := msg.Descriptor().Fields()
+	if v.IsNull() {
+		// The only member of the NullValue enumeration is zero.
+		msg.Set(fields.ByName("null_value"), protoreflect.ValueOfEnum(0))
+		return nil
+	}
+	if !v.IsKnown() {
+		return path.NewErrorf("value must be known")
+	}
+
+	ty := v.Type()
+	switch {
+	case cty.String.Equals(ty):
+		msg.Set(fields.ByName("string_value"), protoreflect.ValueOfString(v.AsString()))
+	case cty.Number.Equals(ty):
+		f, _ := v.AsBigFloat().Float64()
+		msg.Set(fields.ByName("number_value"), protoreflect.ValueOfFloat64(f))
+	case cty.Bool.Equals(ty):
+		msg.Set(fields.ByName("bool_value"), protoreflect.ValueOfBool(v.True()))
+	case ty.IsObjectType() || ty.IsMapType():
+		sub := msg.Mutable(fields.ByName("struct_value")).Message()
+		return toWellKnownStructFields(v, sub, path)
+	case ty.IsTupleType() || ty.IsListType():
+		sub := msg.Mutable(fields.ByName("list_value")).Message()
+		return toWellKnownListValues(v, sub, path)
+	default:
+		return path.NewErrorf("cannot use a value of type %s here", ty.FriendlyName())
+	}
+	return nil
+}
+
+// toWellKnownStructFields writes the attributes or elements of the given
+// object or map value into the "fields" map of the given message, which
+// must be a google.protobuf.Struct message.
+func toWellKnownStructFields(v cty.Value, msg protoreflect.Message, path cty.Path) error {
+	if !v.IsKnown() {
+		return path.NewErrorf("value must be known")
+	}
+	if v.IsNull() {
+		return path.NewErrorf("must not be null")
+	}
+	protoMap := msg.Mutable(msg.Descriptor().Fields().ByName("fields")).Map()
+	for it := v.ElementIterator(); it.Next(); {
+		ek, ev := it.Element()
+		key := ek.AsString()
+
+		// Temporarily extend path with new attribute name or index
+		var step cty.PathStep = cty.IndexStep{Key: ek}
+		if v.Type().IsObjectType() {
+			step = cty.GetAttrStep{Name: key}
+		}
+		path := append(path, step)
+
+		evProto := protoMap.Mutable(protoreflect.MapKey(protoreflect.ValueOfString(key))).Message()
+		err := toWellKnownValue(ev, evProto, path)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// toWellKnownListValues writes the elements of the given list or tuple
+// value into the "values" list of the given message, which must be a
+// google.protobuf.ListValue message.
+func toWellKnownListValues(v cty.Value, msg protoreflect.Message, path cty.Path) error {
+	if !v.IsKnown() {
+		return path.NewErrorf("value must be known")
+	}
+	if v.IsNull() {
+		return path.NewErrorf("must not be null")
+	}
+	protoList := msg.Mutable(msg.Descriptor().Fields().ByName("values")).List()
+	for it := v.ElementIterator(); it.Next(); {
+		ek, ev := it.Element()
+
+		// Temporarily extend path with index
+		path := append(path, cty.IndexStep{Key: ek})
+
+		evProto := protoList.AppendMutable().Message()
+		err := toWellKnownValue(ev, evProto, path)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -21,6 +21,10 @@ import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	anypb "google.golang.org/protobuf/types/known/anypb"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	wrapperspb "google.golang.org/protobuf/types/known/wrapperspb"
 	reflect "reflect"
 	sync "sync"
 )
@@ -36,6 +40,55 @@ const (
 // of the legacy proto package is being used.
 const _ = proto.ProtoPackageIsVersion4
 
+type Color int32
+
+const (
+	Color_COLOR_UNSPECIFIED Color = 0
+	Color_RED               Color = 1
+	Color_GREEN             Color = 2
+)
+
+// Enum value maps for Color.
+var (
+	Color_name = map[int32]string{
+		0: "COLOR_UNSPECIFIED",
+		1: "RED",
+		2: "GREEN",
+	}
+	Color_value = map[string]int32{
+		"COLOR_UNSPECIFIED": 0,
+		"RED":               1,
+		"GREEN":             2,
+	}
+)
+
+func (x Color) Enum() *Color {
+	p := new(Color)
+	*p = x
+	return p
+}
+
+func (x Color) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Color) Descriptor() protoreflect.EnumDescriptor {
+	return file_testproto_proto_enumTypes[0].Descriptor()
+}
+
+func (Color) Type() protoreflect.EnumType {
+	return &file_testproto_proto_enumTypes[0]
+}
+
+func (x Color) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Color.Descriptor instead.
+func (Color) EnumDescriptor() ([]byte, []int) {
+	return file_testproto_proto_rawDescGZIP(), []int{0}
+}
+
 type WithEnum_Things int32
 
 const (
@@ -72,11 +125,11 @@ func (x WithEnum_Things) String() string {
 }
 
 func (WithEnum_Things) Descriptor() protoreflect.EnumDescriptor {
-	return file_testproto_proto_enumTypes[0].Descriptor()
+	return file_testproto_proto_enumTypes[1].Descriptor()
 }
 
 func (WithEnum_Things) Type() protoreflect.EnumType {
-	return &file_testproto_proto_enumTypes[0]
+	return &file_testproto_proto_enumTypes[1]
 }
 
 func (x WithEnum_Things) Number() protoreflect.EnumNumber {
@@ -736,16 +789,18 @@ func (x *Simple) GetFoo() *Empty {
 	return nil
 }
 
-type Assorted_Nested struct {
+type WithStruct struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	TNestedField string `protobuf:"bytes,1,opt,name=t_nested_field,json=tNestedField,proto3" json:"t_nested_field,omitempty"`
+	TStruct    *structpb.Struct    `protobuf:"bytes,1,opt,name=t_struct,json=tStruct,proto3" json:"t_struct,omitempty"`
+	TValue     *structpb.Value     `protobuf:"bytes,2,opt,name=t_value,json=tValue,proto3" json:"t_value,omitempty"`
+	TListValue *structpb.ListValue `protobuf:"bytes,3,opt,name=t_list_value,json=tListValue,proto3" json:"t_list_value,omitempty"`
 }
 
-func (x *Assorted_Nested) Reset() {
-	*x = Assorted_Nested{}
+func (x *WithStruct) Reset() {
+	*x = WithStruct{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_testproto_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -753,13 +808,13 @@ func (x *Assorted_Nested) Reset() {
 	}
 }
 
-func (x *Assorted_Nested) String() string {
+func (x *WithStruct) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Assorted_Nested) ProtoMessage() {}
+func (*WithStruct) ProtoMessage() {}
 
-func (x *Assorted_Nested) ProtoReflect() protoreflect.Message {
+func (x *WithStruct) ProtoReflect() protoreflect.Message {
 	mi := &file_testproto_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -771,26 +826,45 @@ func (x *Assorted_Nested) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Assorted_Nested.ProtoReflect.Descriptor instead.
-func (*Assorted_Nested) Descriptor() ([]byte, []int) {
-	return file_testproto_proto_rawDescGZIP(), []int{0, 0}
+// Deprecated: Use WithStruct.ProtoReflect.Descriptor instead.
+func (*WithStruct) Descriptor() ([]byte, []int) {
+	return file_testproto_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *Assorted_Nested) GetTNestedField() string {
+func (x *WithStruct) GetTStruct() *structpb.Struct {
 	if x != nil {
-		return x.TNestedField
+		return x.TStruct
 	}
-	return ""
+	return nil
 }
 
-type WithOptional_Nested struct {
+func (x *WithStruct) GetTValue() *structpb.Value {
+	if x != nil {
+		return x.TValue
+	}
+	return nil
+}
+
+func (x *WithStruct) GetTListValue() *structpb.ListValue {
+	if x != nil {
+		return x.TListValue
+	}
+	return nil
+}
+
+type WithStructCollections struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	TValueMap       map[string]*structpb.Value `protobuf:"bytes,1,rep,name=t_value_map,json=tValueMap,proto3" json:"t_value_map,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	TValueNumberMap map[int64]*structpb.Value  `protobuf:"bytes,2,rep,name=t_value_number_map,json=tValueNumberMap,proto3" json:"t_value_number_map,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	TValues         []*structpb.Value          `protobuf:"bytes,3,rep,name=t_values,json=tValues,proto3" json:"t_values,omitempty"`
+	TStructs        []*WithStruct              `protobuf:"bytes,4,rep,name=t_structs,json=tStructs,proto3" json:"t_structs,omitempty"`
 }
 
-func (x *WithOptional_Nested) Reset() {
-	*x = WithOptional_Nested{}
+func (x *WithStructCollections) Reset() {
+	*x = WithStructCollections{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_testproto_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -798,13 +872,13 @@ func (x *WithOptional_Nested) Reset() {
 	}
 }
 
-func (x *WithOptional_Nested) String() string {
+func (x *WithStructCollections) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*WithOptional_Nested) ProtoMessage() {}
+func (*WithStructCollections) ProtoMessage() {}
 
-func (x *WithOptional_Nested) ProtoReflect() protoreflect.Message {
+func (x *WithStructCollections) ProtoReflect() protoreflect.Message {
 	mi := &file_testproto_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -816,21 +890,53 @@ func (x *WithOptional_Nested) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use WithOptional_Nested.ProtoReflect.Descriptor instead.
-func (*WithOptional_Nested) Descriptor() ([]byte, []int) {
-	return file_testproto_proto_rawDescGZIP(), []int{1, 0}
+// Deprecated: Use WithStructCollections.ProtoReflect.Descriptor instead.
+func (*WithStructCollections) Descriptor() ([]byte, []int) {
+	return file_testproto_proto_rawDescGZIP(), []int{9}
 }
 
-type WithRepeated_Nested struct {
+func (x *WithStructCollections) GetTValueMap() map[string]*structpb.Value {
+	if x != nil {
+		return x.TValueMap
+	}
+	return nil
+}
+
+func (x *WithStructCollections) GetTValueNumberMap() map[int64]*structpb.Value {
+	if x != nil {
+		return x.TValueNumberMap
+	}
+	return nil
+}
+
+func (x *WithStructCollections) GetTValues() []*structpb.Value {
+	if x != nil {
+		return x.TValues
+	}
+	return nil
+}
+
+func (x *WithStructCollections) GetTStructs() []*WithStruct {
+	if x != nil {
+		return x.TStructs
+	}
+	return nil
+}
+
+type WithWellKnown struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	TNestedField string `protobuf:"bytes,1,opt,name=t_nested_field,json=tNestedField,proto3" json:"t_nested_field,omitempty"`
+	TTimestamp   *timestamppb.Timestamp  `protobuf:"bytes,1,opt,name=t_timestamp,json=tTimestamp,proto3" json:"t_timestamp,omitempty"`
+	TDuration    *durationpb.Duration    `protobuf:"bytes,2,opt,name=t_duration,json=tDuration,proto3" json:"t_duration,omitempty"`
+	TStringValue *wrapperspb.StringValue `protobuf:"bytes,3,opt,name=t_string_value,json=tStringValue,proto3" json:"t_string_value,omitempty"`
+	TInt64Value  *wrapperspb.Int64Value  `protobuf:"bytes,4,opt,name=t_int64_value,json=tInt64Value,proto3" json:"t_int64_value,omitempty"`
+	TBoolValue   *wrapperspb.BoolValue   `protobuf:"bytes,5,opt,name=t_bool_value,json=tBoolValue,proto3" json:"t_bool_value,omitempty"`
 }
 
-func (x *WithRepeated_Nested) Reset() {
-	*x = WithRepeated_Nested{}
+func (x *WithWellKnown) Reset() {
+	*x = WithWellKnown{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_testproto_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -838,13 +944,13 @@ func (x *WithRepeated_Nested) Reset() {
 	}
 }
 
-func (x *WithRepeated_Nested) String() string {
+func (x *WithWellKnown) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*WithRepeated_Nested) ProtoMessage() {}
+func (*WithWellKnown) ProtoMessage() {}
 
-func (x *WithRepeated_Nested) ProtoReflect() protoreflect.Message {
+func (x *WithWellKnown) ProtoReflect() protoreflect.Message {
 	mi := &file_testproto_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -856,282 +962,1459 @@ func (x *WithRepeated_Nested) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use WithRepeated_Nested.ProtoReflect.Descriptor instead.
-func (*WithRepeated_Nested) Descriptor() ([]byte, []int) {
-	return file_testproto_proto_rawDescGZIP(), []int{3, 0}
+// Deprecated: Use WithWellKnown.ProtoReflect.Descriptor instead.
+func (*WithWellKnown) Descriptor() ([]byte, []int) {
+	return file_testproto_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *WithRepeated_Nested) GetTNestedField() string {
+func (x *WithWellKnown) GetTTimestamp() *timestamppb.Timestamp {
 	if x != nil {
-		return x.TNestedField
+		return x.TTimestamp
 	}
-	return ""
+	return nil
 }
 
-var File_testproto_proto protoreflect.FileDescriptor
+func (x *WithWellKnown) GetTDuration() *durationpb.Duration {
+	if x != nil {
+		return x.TDuration
+	}
+	return nil
+}
 
-var file_testproto_proto_rawDesc = []byte{
-	0x0a, 0x0f, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x12, 0x09, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x19, 0x67, 0x6f,
-	0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x61, 0x6e,
-	0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x88, 0x04, 0x0a, 0x08, 0x41, 0x73, 0x73, 0x6f,
-	0x72, 0x74, 0x65, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x74, 0x5f, 0x64, 0x6f, 0x75, 0x62, 0x6c, 0x65,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x74, 0x44, 0x6f, 0x75, 0x62, 0x6c, 0x65, 0x12,
-	0x17, 0x0a, 0x07, 0x74, 0x5f, 0x66, 0x6c, 0x6f, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x02,
-	0x52, 0x06, 0x74, 0x46, 0x6c, 0x6f, 0x61, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x74, 0x5f, 0x69, 0x6e,
-	0x74, 0x33, 0x32, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x74, 0x49, 0x6e, 0x74, 0x33,
-	0x32, 0x12, 0x17, 0x0a, 0x07, 0x74, 0x5f, 0x69, 0x6e, 0x74, 0x36, 0x34, 0x18, 0x04, 0x20, 0x01,
-	0x28, 0x03, 0x52, 0x06, 0x74, 0x49, 0x6e, 0x74, 0x36, 0x34, 0x12, 0x19, 0x0a, 0x08, 0x74, 0x5f,
-	0x75, 0x69, 0x6e, 0x74, 0x33, 0x32, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x74, 0x55,
-	0x69, 0x6e, 0x74, 0x33, 0x32, 0x12, 0x19, 0x0a, 0x08, 0x74, 0x5f, 0x75, 0x69, 0x6e, 0x74, 0x36,
-	0x34, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x74, 0x55, 0x69, 0x6e, 0x74, 0x36, 0x34,
-	0x12, 0x19, 0x0a, 0x08, 0x74, 0x5f, 0x73, 0x69, 0x6e, 0x74, 0x33, 0x32, 0x18, 0x07, 0x20, 0x01,
-	0x28, 0x11, 0x52, 0x07, 0x74, 0x53, 0x69, 0x6e, 0x74, 0x33, 0x32, 0x12, 0x19, 0x0a, 0x08, 0x74,
-	0x5f, 0x73, 0x69, 0x6e, 0x74, 0x36, 0x34, 0x18, 0x08, 0x20, 0x01, 0x28, 0x12, 0x52, 0x07, 0x74,
-	0x53, 0x69, 0x6e, 0x74, 0x36, 0x34, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x5f, 0x66, 0x69, 0x78, 0x65,
-	0x64, 0x33, 0x32, 0x18, 0x09, 0x20, 0x01, 0x28, 0x07, 0x52, 0x08, 0x74, 0x46, 0x69, 0x78, 0x65,
-	0x64, 0x33, 0x32, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x5f, 0x66, 0x69, 0x78, 0x65, 0x64, 0x36, 0x34,
-	0x18, 0x0a, 0x20, 0x01, 0x28, 0x06, 0x52, 0x08, 0x74, 0x46, 0x69, 0x78, 0x65, 0x64, 0x36, 0x34,
-	0x12, 0x1d, 0x0a, 0x0a, 0x74, 0x5f, 0x73, 0x66, 0x69, 0x78, 0x65, 0x64, 0x33, 0x32, 0x18, 0x0b,
-	0x20, 0x01, 0x28, 0x0f, 0x52, 0x09, 0x74, 0x53, 0x66, 0x69, 0x78, 0x65, 0x64, 0x33, 0x32, 0x12,
-	0x1d, 0x0a, 0x0a, 0x74, 0x5f, 0x73, 0x66, 0x69, 0x78, 0x65, 0x64, 0x36, 0x34, 0x18, 0x0c, 0x20,
-	0x01, 0x28, 0x10, 0x52, 0x09, 0x74, 0x53, 0x66, 0x69, 0x78, 0x65, 0x64, 0x36, 0x34, 0x12, 0x15,
-	0x0a, 0x06, 0x74, 0x5f, 0x62, 0x6f, 0x6f, 0x6c, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05,
-	0x74, 0x42, 0x6f, 0x6f, 0x6c, 0x12, 0x19, 0x0a, 0x08, 0x74, 0x5f, 0x73, 0x74, 0x72, 0x69, 0x6e,
-	0x67, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x74, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67,
-	0x12, 0x17, 0x0a, 0x07, 0x74, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x0f, 0x20, 0x01, 0x28,
-	0x0c, 0x52, 0x06, 0x74, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x37, 0x0a, 0x09, 0x74, 0x5f, 0x6d,
-	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x74,
-	0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x41, 0x73, 0x73, 0x6f, 0x72, 0x74, 0x65,
-	0x64, 0x2e, 0x4e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x52, 0x08, 0x74, 0x4d, 0x65, 0x73, 0x73, 0x61,
-	0x67, 0x65, 0x1a, 0x2e, 0x0a, 0x06, 0x4e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x12, 0x24, 0x0a, 0x0e,
-	0x74, 0x5f, 0x6e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x5f, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x74, 0x4e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x46, 0x69, 0x65,
-	0x6c, 0x64, 0x22, 0xce, 0x02, 0x0a, 0x0c, 0x57, 0x69, 0x74, 0x68, 0x4f, 0x70, 0x74, 0x69, 0x6f,
-	0x6e, 0x61, 0x6c, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x5f, 0x72, 0x65,
-	0x71, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x52,
-	0x65, 0x71, 0x12, 0x22, 0x0a, 0x0a, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x5f, 0x6f, 0x70, 0x74,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x09, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67,
-	0x4f, 0x70, 0x74, 0x88, 0x01, 0x01, 0x12, 0x1b, 0x0a, 0x09, 0x69, 0x6e, 0x74, 0x33, 0x32, 0x5f,
-	0x72, 0x65, 0x71, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x69, 0x6e, 0x74, 0x33, 0x32,
-	0x52, 0x65, 0x71, 0x12, 0x20, 0x0a, 0x09, 0x69, 0x6e, 0x74, 0x33, 0x32, 0x5f, 0x6f, 0x70, 0x74,
-	0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x48, 0x01, 0x52, 0x08, 0x69, 0x6e, 0x74, 0x33, 0x32, 0x4f,
-	0x70, 0x74, 0x88, 0x01, 0x01, 0x12, 0x3f, 0x0a, 0x0b, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
-	0x5f, 0x72, 0x65, 0x71, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x74, 0x65, 0x73,
-	0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x57, 0x69, 0x74, 0x68, 0x4f, 0x70, 0x74, 0x69, 0x6f,
-	0x6e, 0x61, 0x6c, 0x2e, 0x4e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x52, 0x0a, 0x6d, 0x65, 0x73, 0x73,
-	0x61, 0x67, 0x65, 0x52, 0x65, 0x71, 0x12, 0x44, 0x0a, 0x0b, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
-	0x65, 0x5f, 0x6f, 0x70, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x74, 0x65,
-	0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x57, 0x69, 0x74, 0x68, 0x4f, 0x70, 0x74, 0x69,
-	0x6f, 0x6e, 0x61, 0x6c, 0x2e, 0x4e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x48, 0x02, 0x52, 0x0a, 0x6d,
-	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4f, 0x70, 0x74, 0x88, 0x01, 0x01, 0x1a, 0x08, 0x0a, 0x06,
-	0x4e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x73, 0x74, 0x72, 0x69, 0x6e,
-	0x67, 0x5f, 0x6f, 0x70, 0x74, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x69, 0x6e, 0x74, 0x33, 0x32, 0x5f,
-	0x6f, 0x70, 0x74, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x5f,
-	0x6f, 0x70, 0x74, 0x22, 0x50, 0x0a, 0x09, 0x57, 0x69, 0x74, 0x68, 0x4f, 0x6e, 0x65, 0x4f, 0x66,
-	0x12, 0x18, 0x0a, 0x07, 0x6f, 0x75, 0x74, 0x73, 0x69, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x07, 0x6f, 0x75, 0x74, 0x73, 0x69, 0x64, 0x65, 0x12, 0x0e, 0x0a, 0x01, 0x61, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x01, 0x61, 0x12, 0x0e, 0x0a, 0x01, 0x62, 0x18,
-	0x03, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x01, 0x62, 0x42, 0x09, 0x0a, 0x07, 0x74, 0x5f,
-	0x6f, 0x6e, 0x65, 0x6f, 0x66, 0x22, 0xdc, 0x06, 0x0a, 0x0c, 0x57, 0x69, 0x74, 0x68, 0x52, 0x65,
-	0x70, 0x65, 0x61, 0x74, 0x65, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x5f, 0x73, 0x74, 0x72, 0x69,
-	0x6e, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x74, 0x53, 0x74, 0x72, 0x69,
-	0x6e, 0x67, 0x73, 0x12, 0x3b, 0x0a, 0x09, 0x74, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
-	0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x2e, 0x57, 0x69, 0x74, 0x68, 0x52, 0x65, 0x70, 0x65, 0x61, 0x74, 0x65, 0x64, 0x2e,
-	0x4e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x52, 0x08, 0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
-	0x12, 0x56, 0x0a, 0x11, 0x74, 0x5f, 0x6d, 0x61, 0x70, 0x5f, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67,
-	0x5f, 0x62, 0x6f, 0x6f, 0x6c, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2b, 0x2e, 0x74, 0x65,
-	0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x57, 0x69, 0x74, 0x68, 0x52, 0x65, 0x70, 0x65,
-	0x61, 0x74, 0x65, 0x64, 0x2e, 0x54, 0x4d, 0x61, 0x70, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x42,
-	0x6f, 0x6f, 0x6c, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0e, 0x74, 0x4d, 0x61, 0x70, 0x53, 0x74,
-	0x72, 0x69, 0x6e, 0x67, 0x42, 0x6f, 0x6f, 0x6c, 0x12, 0x56, 0x0a, 0x11, 0x74, 0x5f, 0x6d, 0x61,
-	0x70, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x5f, 0x62, 0x6f, 0x6f, 0x6c, 0x18, 0x04, 0x20,
-	0x03, 0x28, 0x0b, 0x32, 0x2b, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
-	0x57, 0x69, 0x74, 0x68, 0x52, 0x65, 0x70, 0x65, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x54, 0x4d, 0x61,
-	0x70, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x42, 0x6f, 0x6f, 0x6c, 0x45, 0x6e, 0x74, 0x72, 0x79,
-	0x52, 0x0e, 0x74, 0x4d, 0x61, 0x70, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x42, 0x6f, 0x6f, 0x6c,
-	0x12, 0x5f, 0x0a, 0x14, 0x74, 0x5f, 0x6d, 0x61, 0x70, 0x5f, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67,
-	0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2e,
-	0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x57, 0x69, 0x74, 0x68, 0x52,
-	0x65, 0x70, 0x65, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x54, 0x4d, 0x61, 0x70, 0x53, 0x74, 0x72, 0x69,
-	0x6e, 0x67, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x11,
-	0x74, 0x4d, 0x61, 0x70, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67,
-	0x65, 0x12, 0x5f, 0x0a, 0x14, 0x74, 0x5f, 0x6d, 0x61, 0x70, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65,
-	0x72, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32,
-	0x2e, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x57, 0x69, 0x74, 0x68,
-	0x52, 0x65, 0x70, 0x65, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x54, 0x4d, 0x61, 0x70, 0x4e, 0x75, 0x6d,
-	0x62, 0x65, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52,
-	0x11, 0x74, 0x4d, 0x61, 0x70, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61,
-	0x67, 0x65, 0x1a, 0x2e, 0x0a, 0x06, 0x4e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x12, 0x24, 0x0a, 0x0e,
-	0x74, 0x5f, 0x6e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x5f, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x74, 0x4e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x46, 0x69, 0x65,
-	0x6c, 0x64, 0x1a, 0x41, 0x0a, 0x13, 0x54, 0x4d, 0x61, 0x70, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67,
-	0x42, 0x6f, 0x6f, 0x6c, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76,
-	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
-	0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x41, 0x0a, 0x13, 0x54, 0x4d, 0x61, 0x70, 0x4e, 0x75, 0x6d,
-	0x62, 0x65, 0x72, 0x42, 0x6f, 0x6f, 0x6c, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03,
-	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14,
-	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x76,
-	0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x64, 0x0a, 0x16, 0x54, 0x4d, 0x61, 0x70,
-	0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x45, 0x6e, 0x74,
-	0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x03, 0x6b, 0x65, 0x79, 0x12, 0x34, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
-	0x57, 0x69, 0x74, 0x68, 0x52, 0x65, 0x70, 0x65, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x4e, 0x65, 0x73,
-	0x74, 0x65, 0x64, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x64,
-	0x0a, 0x16, 0x54, 0x4d, 0x61, 0x70, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x4d, 0x65, 0x73, 0x73,
-	0x61, 0x67, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x34, 0x0a, 0x05, 0x76, 0x61,
-	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x74, 0x65, 0x73, 0x74,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x57, 0x69, 0x74, 0x68, 0x52, 0x65, 0x70, 0x65, 0x61, 0x74,
-	0x65, 0x64, 0x2e, 0x4e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
-	0x3a, 0x02, 0x38, 0x01, 0x22, 0xd3, 0x03, 0x0a, 0x07, 0x57, 0x69, 0x74, 0x68, 0x41, 0x6e, 0x79,
-	0x12, 0x19, 0x0a, 0x08, 0x74, 0x5f, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x07, 0x74, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x12, 0x29, 0x0a, 0x05, 0x74,
-	0x5f, 0x61, 0x6e, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x6f,
-	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x41, 0x6e, 0x79,
-	0x52, 0x04, 0x74, 0x41, 0x6e, 0x79, 0x12, 0x32, 0x0a, 0x0a, 0x74, 0x5f, 0x61, 0x6e, 0x79, 0x5f,
-	0x6c, 0x69, 0x73, 0x74, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x6f,
-	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x41, 0x6e, 0x79,
-	0x52, 0x08, 0x74, 0x41, 0x6e, 0x79, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x4e, 0x0a, 0x10, 0x74, 0x5f,
-	0x61, 0x6e, 0x79, 0x5f, 0x6d, 0x61, 0x70, 0x5f, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x18, 0x04,
-	0x20, 0x03, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x2e, 0x57, 0x69, 0x74, 0x68, 0x41, 0x6e, 0x79, 0x2e, 0x54, 0x41, 0x6e, 0x79, 0x4d, 0x61, 0x70,
-	0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0d, 0x74, 0x41, 0x6e,
-	0x79, 0x4d, 0x61, 0x70, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x12, 0x4e, 0x0a, 0x10, 0x74, 0x5f,
-	0x61, 0x6e, 0x79, 0x5f, 0x6d, 0x61, 0x70, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x05,
-	0x20, 0x03, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x2e, 0x57, 0x69, 0x74, 0x68, 0x41, 0x6e, 0x79, 0x2e, 0x54, 0x41, 0x6e, 0x79, 0x4d, 0x61, 0x70,
-	0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0d, 0x74, 0x41, 0x6e,
-	0x79, 0x4d, 0x61, 0x70, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x1a, 0x56, 0x0a, 0x12, 0x54, 0x41,
-	0x6e, 0x79, 0x4d, 0x61, 0x70, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x45, 0x6e, 0x74, 0x72, 0x79,
-	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b,
-	0x65, 0x79, 0x12, 0x2a, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x62, 0x75, 0x66, 0x2e, 0x41, 0x6e, 0x79, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02,
-	0x38, 0x01, 0x1a, 0x56, 0x0a, 0x12, 0x54, 0x41, 0x6e, 0x79, 0x4d, 0x61, 0x70, 0x4e, 0x75, 0x6d,
-	0x62, 0x65, 0x72, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x2a, 0x0a, 0x05, 0x76, 0x61,
-	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
-	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x41, 0x6e, 0x79, 0x52,
-	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x7e, 0x0a, 0x08, 0x57, 0x69,
-	0x74, 0x68, 0x45, 0x6e, 0x75, 0x6d, 0x12, 0x19, 0x0a, 0x08, 0x74, 0x5f, 0x73, 0x74, 0x72, 0x69,
-	0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x74, 0x53, 0x74, 0x72, 0x69, 0x6e,
-	0x67, 0x12, 0x31, 0x0a, 0x06, 0x74, 0x5f, 0x65, 0x6e, 0x75, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x0e, 0x32, 0x1a, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x57, 0x69,
-	0x74, 0x68, 0x45, 0x6e, 0x75, 0x6d, 0x2e, 0x54, 0x68, 0x69, 0x6e, 0x67, 0x73, 0x52, 0x05, 0x74,
-	0x45, 0x6e, 0x75, 0x6d, 0x22, 0x24, 0x0a, 0x06, 0x54, 0x68, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x05,
-	0x0a, 0x01, 0x41, 0x10, 0x00, 0x12, 0x05, 0x0a, 0x01, 0x62, 0x10, 0x01, 0x12, 0x05, 0x0a, 0x01,
-	0x43, 0x10, 0x02, 0x12, 0x05, 0x0a, 0x01, 0x64, 0x10, 0x03, 0x22, 0x07, 0x0a, 0x05, 0x45, 0x6d,
-	0x70, 0x74, 0x79, 0x22, 0x2c, 0x0a, 0x06, 0x53, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x12, 0x22, 0x0a,
-	0x03, 0x66, 0x6f, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x74, 0x65, 0x73,
-	0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x52, 0x03, 0x66, 0x6f,
-	0x6f, 0x42, 0x37, 0x5a, 0x35, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
-	0x7a, 0x63, 0x6c, 0x63, 0x6f, 0x6e, 0x66, 0x2f, 0x67, 0x6f, 0x2d, 0x63, 0x74, 0x79, 0x2d, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c,
-	0x2f, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x33,
+func (x *WithWellKnown) GetTStringValue() *wrapperspb.StringValue {
+	if x != nil {
+		return x.TStringValue
+	}
+	return nil
 }
 
-var (
-	file_testproto_proto_rawDescOnce sync.Once
-	file_testproto_proto_rawDescData = file_testproto_proto_rawDesc
-)
+func (x *WithWellKnown) GetTInt64Value() *wrapperspb.Int64Value {
+	if x != nil {
+		return x.TInt64Value
+	}
+	return nil
+}
 
-func file_testproto_proto_rawDescGZIP() []byte {
-	file_testproto_proto_rawDescOnce.Do(func() {
-		file_testproto_proto_rawDescData = protoimpl.X.CompressGZIP(file_testproto_proto_rawDescData)
-	})
-	return file_testproto_proto_rawDescData
+func (x *WithWellKnown) GetTBoolValue() *wrapperspb.BoolValue {
+	if x != nil {
+		return x.TBoolValue
+	}
+	return nil
 }
 
-var file_testproto_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_testproto_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
-var file_testproto_proto_goTypes = []interface{}{
-	(WithEnum_Things)(0),        // 0: testproto.WithEnum.Things
-	(*Assorted)(nil),            // 1: testproto.Assorted
-	(*WithOptional)(nil),        // 2: testproto.WithOptional
-	(*WithOneOf)(nil),           // 3: testproto.WithOneOf
-	(*WithRepeated)(nil),        // 4: testproto.WithRepeated
-	(*WithAny)(nil),             // 5: testproto.WithAny
-	(*WithEnum)(nil),            // 6: testproto.WithEnum
-	(*Empty)(nil),               // 7: testproto.Empty
-	(*Simple)(nil),              // 8: testproto.Simple
-	(*Assorted_Nested)(nil),     // 9: testproto.Assorted.Nested
-	(*WithOptional_Nested)(nil), // 10: testproto.WithOptional.Nested
-	(*WithRepeated_Nested)(nil), // 11: testproto.WithRepeated.Nested
-	nil,                         // 12: testproto.WithRepeated.TMapStringBoolEntry
-	nil,                         // 13: testproto.WithRepeated.TMapNumberBoolEntry
-	nil,                         // 14: testproto.WithRepeated.TMapStringMessageEntry
-	nil,                         // 15: testproto.WithRepeated.TMapNumberMessageEntry
-	nil,                         // 16: testproto.WithAny.TAnyMapStringEntry
-	nil,                         // 17: testproto.WithAny.TAnyMapNumberEntry
-	(*anypb.Any)(nil),           // 18: google.protobuf.Any
+type WithWellKnownCollections struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TTimestamps []*timestamppb.Timestamp        `protobuf:"bytes,1,rep,name=t_timestamps,json=tTimestamps,proto3" json:"t_timestamps,omitempty"`
+	TDurations  map[string]*durationpb.Duration `protobuf:"bytes,2,rep,name=t_durations,json=tDurations,proto3" json:"t_durations,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 }
-var file_testproto_proto_depIdxs = []int32{
-	9,  // 0: testproto.Assorted.t_message:type_name -> testproto.Assorted.Nested
-	10, // 1: testproto.WithOptional.message_req:type_name -> testproto.WithOptional.Nested
-	10, // 2: testproto.WithOptional.message_opt:type_name -> testproto.WithOptional.Nested
-	11, // 3: testproto.WithRepeated.t_message:type_name -> testproto.WithRepeated.Nested
-	12, // 4: testproto.WithRepeated.t_map_string_bool:type_name -> testproto.WithRepeated.TMapStringBoolEntry
-	13, // 5: testproto.WithRepeated.t_map_number_bool:type_name -> testproto.WithRepeated.TMapNumberBoolEntry
-	14, // 6: testproto.WithRepeated.t_map_string_message:type_name -> testproto.WithRepeated.TMapStringMessageEntry
-	15, // 7: testproto.WithRepeated.t_map_number_message:type_name -> testproto.WithRepeated.TMapNumberMessageEntry
-	18, // 8: testproto.WithAny.t_any:type_name -> google.protobuf.Any
-	18, // 9: testproto.WithAny.t_any_list:type_name -> google.protobuf.Any
-	16, // 10: testproto.WithAny.t_any_map_string:type_name -> testproto.WithAny.TAnyMapStringEntry
-	17, // 11: testproto.WithAny.t_any_map_number:type_name -> testproto.WithAny.TAnyMapNumberEntry
-	0,  // 12: testproto.WithEnum.t_enum:type_name -> testproto.WithEnum.Things
-	7,  // 13: testproto.Simple.foo:type_name -> testproto.Empty
-	11, // 14: testproto.WithRepeated.TMapStringMessageEntry.value:type_name -> testproto.WithRepeated.Nested
-	11, // 15: testproto.WithRepeated.TMapNumberMessageEntry.value:type_name -> testproto.WithRepeated.Nested
-	18, // 16: testproto.WithAny.TAnyMapStringEntry.value:type_name -> google.protobuf.Any
-	18, // 17: testproto.WithAny.TAnyMapNumberEntry.value:type_name -> google.protobuf.Any
-	18, // [18:18] is the sub-list for method output_type
-	18, // [18:18] is the sub-list for method input_type
-	18, // [18:18] is the sub-list for extension type_name
-	18, // [18:18] is the sub-list for extension extendee
-	0,  // [0:18] is the sub-list for field type_name
+
+func (x *WithWellKnownCollections) Reset() {
+	*x = WithWellKnownCollections{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_testproto_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
 }
 
-func init() { file_testproto_proto_init() }
-func file_testproto_proto_init() {
-	if File_testproto_proto != nil {
-		return
+func (x *WithWellKnownCollections) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WithWellKnownCollections) ProtoMessage() {}
+
+func (x *WithWellKnownCollections) ProtoReflect() protoreflect.Message {
+	mi := &file_testproto_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_testproto_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Assorted); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WithWellKnownCollections.ProtoReflect.Descriptor instead.
+func (*WithWellKnownCollections) Descriptor() ([]byte, []int) {
+	return file_testproto_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *WithWellKnownCollections) GetTTimestamps() []*timestamppb.Timestamp {
+	if x != nil {
+		return x.TTimestamps
+	}
+	return nil
+}
+
+func (x *WithWellKnownCollections) GetTDurations() map[string]*durationpb.Duration {
+	if x != nil {
+		return x.TDurations
+	}
+	return nil
+}
+
+type WithComplexMap struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TMapStringComplex map[string]*WithComplexMap_Complex `protobuf:"bytes,1,rep,name=t_map_string_complex,json=tMapStringComplex,proto3" json:"t_map_string_complex,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	TMapNumberComplex map[int64]*WithComplexMap_Complex  `protobuf:"bytes,2,rep,name=t_map_number_complex,json=tMapNumberComplex,proto3" json:"t_map_number_complex,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *WithComplexMap) Reset() {
+	*x = WithComplexMap{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_testproto_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WithComplexMap) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WithComplexMap) ProtoMessage() {}
+
+func (x *WithComplexMap) ProtoReflect() protoreflect.Message {
+	mi := &file_testproto_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_testproto_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*WithOptional); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WithComplexMap.ProtoReflect.Descriptor instead.
+func (*WithComplexMap) Descriptor() ([]byte, []int) {
+	return file_testproto_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *WithComplexMap) GetTMapStringComplex() map[string]*WithComplexMap_Complex {
+	if x != nil {
+		return x.TMapStringComplex
+	}
+	return nil
+}
+
+func (x *WithComplexMap) GetTMapNumberComplex() map[int64]*WithComplexMap_Complex {
+	if x != nil {
+		return x.TMapNumberComplex
+	}
+	return nil
+}
+
+type WithRepeatedNumbers struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TNumbers []int64 `protobuf:"varint,1,rep,packed,name=t_numbers,json=tNumbers,proto3" json:"t_numbers,omitempty"`
+}
+
+func (x *WithRepeatedNumbers) Reset() {
+	*x = WithRepeatedNumbers{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_testproto_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WithRepeatedNumbers) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WithRepeatedNumbers) ProtoMessage() {}
+
+func (x *WithRepeatedNumbers) ProtoReflect() protoreflect.Message {
+	mi := &file_testproto_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_testproto_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*WithOneOf); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WithRepeatedNumbers.ProtoReflect.Descriptor instead.
+func (*WithRepeatedNumbers) Descriptor() ([]byte, []int) {
+	return file_testproto_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *WithRepeatedNumbers) GetTNumbers() []int64 {
+	if x != nil {
+		return x.TNumbers
+	}
+	return nil
+}
+
+type Recursive struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string     `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Next *Recursive `protobuf:"bytes,2,opt,name=next,proto3" json:"next,omitempty"`
+}
+
+func (x *Recursive) Reset() {
+	*x = Recursive{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_testproto_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Recursive) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Recursive) ProtoMessage() {}
+
+func (x *Recursive) ProtoReflect() protoreflect.Message {
+	mi := &file_testproto_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Recursive.ProtoReflect.Descriptor instead.
+func (*Recursive) Descriptor() ([]byte, []int) {
+	return file_testproto_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Recursive) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Recursive) GetNext() *Recursive {
+	if x != nil {
+		return x.Next
+	}
+	return nil
+}
+
+type WithBytesValue struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TBytesValue *wrapperspb.BytesValue `protobuf:"bytes,1,opt,name=t_bytes_value,json=tBytesValue,proto3" json:"t_bytes_value,omitempty"`
+}
+
+func (x *WithBytesValue) Reset() {
+	*x = WithBytesValue{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_testproto_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WithBytesValue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WithBytesValue) ProtoMessage() {}
+
+func (x *WithBytesValue) ProtoReflect() protoreflect.Message {
+	mi := &file_testproto_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WithBytesValue.ProtoReflect.Descriptor instead.
+func (*WithBytesValue) Descriptor() ([]byte, []int) {
+	return file_testproto_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *WithBytesValue) GetTBytesValue() *wrapperspb.BytesValue {
+	if x != nil {
+		return x.TBytesValue
+	}
+	return nil
+}
+
+type WithNullValue struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TNull  structpb.NullValue   `protobuf:"varint,1,opt,name=t_null,json=tNull,proto3,enum=google.protobuf.NullValue" json:"t_null,omitempty"`
+	TNulls []structpb.NullValue `protobuf:"varint,2,rep,packed,name=t_nulls,json=tNulls,proto3,enum=google.protobuf.NullValue" json:"t_nulls,omitempty"`
+}
+
+func (x *WithNullValue) Reset() {
+	*x = WithNullValue{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_testproto_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WithNullValue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WithNullValue) ProtoMessage() {}
+
+func (x *WithNullValue) ProtoReflect() protoreflect.Message {
+	mi := &file_testproto_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WithNullValue.ProtoReflect.Descriptor instead.
+func (*WithNullValue) Descriptor() ([]byte, []int) {
+	return file_testproto_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *WithNullValue) GetTNull() structpb.NullValue {
+	if x != nil {
+		return x.TNull
+	}
+	return structpb.NullValue(0)
+}
+
+func (x *WithNullValue) GetTNulls() []structpb.NullValue {
+	if x != nil {
+		return x.TNulls
+	}
+	return nil
+}
+
+type WithListMap struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TMap map[string]*WithListMap_HasList `protobuf:"bytes,1,rep,name=t_map,json=tMap,proto3" json:"t_map,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *WithListMap) Reset() {
+	*x = WithListMap{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_testproto_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WithListMap) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WithListMap) ProtoMessage() {}
+
+func (x *WithListMap) ProtoReflect() protoreflect.Message {
+	mi := &file_testproto_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WithListMap.ProtoReflect.Descriptor instead.
+func (*WithListMap) Descriptor() ([]byte, []int) {
+	return file_testproto_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *WithListMap) GetTMap() map[string]*WithListMap_HasList {
+	if x != nil {
+		return x.TMap
+	}
+	return nil
+}
+
+type WithReserved struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TString string `protobuf:"bytes,1,opt,name=t_string,json=tString,proto3" json:"t_string,omitempty"`
+}
+
+func (x *WithReserved) Reset() {
+	*x = WithReserved{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_testproto_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WithReserved) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WithReserved) ProtoMessage() {}
+
+func (x *WithReserved) ProtoReflect() protoreflect.Message {
+	mi := &file_testproto_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WithReserved.ProtoReflect.Descriptor instead.
+func (*WithReserved) Descriptor() ([]byte, []int) {
+	return file_testproto_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *WithReserved) GetTString() string {
+	if x != nil {
+		return x.TString
+	}
+	return ""
+}
+
+type WithEmpty struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TEmpty     *Empty            `protobuf:"bytes,1,opt,name=t_empty,json=tEmpty,proto3" json:"t_empty,omitempty"`
+	TEmptyMap  map[string]*Empty `protobuf:"bytes,2,rep,name=t_empty_map,json=tEmptyMap,proto3" json:"t_empty_map,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	TEmptyList []*Empty          `protobuf:"bytes,3,rep,name=t_empty_list,json=tEmptyList,proto3" json:"t_empty_list,omitempty"`
+	// Types that are assignable to TChoice:
+	//	*WithEmpty_TEmptyChoice
+	//	*WithEmpty_TOtherChoice
+	TChoice isWithEmpty_TChoice `protobuf_oneof:"t_choice"`
+}
+
+func (x *WithEmpty) Reset() {
+	*x = WithEmpty{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_testproto_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WithEmpty) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WithEmpty) ProtoMessage() {}
+
+func (x *WithEmpty) ProtoReflect() protoreflect.Message {
+	mi := &file_testproto_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WithEmpty.ProtoReflect.Descriptor instead.
+func (*WithEmpty) Descriptor() ([]byte, []int) {
+	return file_testproto_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *WithEmpty) GetTEmpty() *Empty {
+	if x != nil {
+		return x.TEmpty
+	}
+	return nil
+}
+
+func (x *WithEmpty) GetTEmptyMap() map[string]*Empty {
+	if x != nil {
+		return x.TEmptyMap
+	}
+	return nil
+}
+
+func (x *WithEmpty) GetTEmptyList() []*Empty {
+	if x != nil {
+		return x.TEmptyList
+	}
+	return nil
+}
+
+func (m *WithEmpty) GetTChoice() isWithEmpty_TChoice {
+	if m != nil {
+		return m.TChoice
+	}
+	return nil
+}
+
+func (x *WithEmpty) GetTEmptyChoice() *Empty {
+	if x, ok := x.GetTChoice().(*WithEmpty_TEmptyChoice); ok {
+		return x.TEmptyChoice
+	}
+	return nil
+}
+
+func (x *WithEmpty) GetTOtherChoice() string {
+	if x, ok := x.GetTChoice().(*WithEmpty_TOtherChoice); ok {
+		return x.TOtherChoice
+	}
+	return ""
+}
+
+type isWithEmpty_TChoice interface {
+	isWithEmpty_TChoice()
+}
+
+type WithEmpty_TEmptyChoice struct {
+	TEmptyChoice *Empty `protobuf:"bytes,4,opt,name=t_empty_choice,json=tEmptyChoice,proto3,oneof"`
+}
+
+type WithEmpty_TOtherChoice struct {
+	TOtherChoice string `protobuf:"bytes,5,opt,name=t_other_choice,json=tOtherChoice,proto3,oneof"`
+}
+
+func (*WithEmpty_TEmptyChoice) isWithEmpty_TChoice() {}
+
+func (*WithEmpty_TOtherChoice) isWithEmpty_TChoice() {}
+
+type WithEnumMap struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TColors         map[string]Color `protobuf:"bytes,1,rep,name=t_colors,json=tColors,proto3" json:"t_colors,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3,enum=testproto.Color"`
+	TColorsByNumber map[int64]Color  `protobuf:"bytes,2,rep,name=t_colors_by_number,json=tColorsByNumber,proto3" json:"t_colors_by_number,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3,enum=testproto.Color"`
+}
+
+func (x *WithEnumMap) Reset() {
+	*x = WithEnumMap{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_testproto_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WithEnumMap) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WithEnumMap) ProtoMessage() {}
+
+func (x *WithEnumMap) ProtoReflect() protoreflect.Message {
+	mi := &file_testproto_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WithEnumMap.ProtoReflect.Descriptor instead.
+func (*WithEnumMap) Descriptor() ([]byte, []int) {
+	return file_testproto_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *WithEnumMap) GetTColors() map[string]Color {
+	if x != nil {
+		return x.TColors
+	}
+	return nil
+}
+
+func (x *WithEnumMap) GetTColorsByNumber() map[int64]Color {
+	if x != nil {
+		return x.TColorsByNumber
+	}
+	return nil
+}
+
+type Assorted_Nested struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TNestedField string `protobuf:"bytes,1,opt,name=t_nested_field,json=tNestedField,proto3" json:"t_nested_field,omitempty"`
+}
+
+func (x *Assorted_Nested) Reset() {
+	*x = Assorted_Nested{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_testproto_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Assorted_Nested) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Assorted_Nested) ProtoMessage() {}
+
+func (x *Assorted_Nested) ProtoReflect() protoreflect.Message {
+	mi := &file_testproto_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Assorted_Nested.ProtoReflect.Descriptor instead.
+func (*Assorted_Nested) Descriptor() ([]byte, []int) {
+	return file_testproto_proto_rawDescGZIP(), []int{0, 0}
+}
+
+func (x *Assorted_Nested) GetTNestedField() string {
+	if x != nil {
+		return x.TNestedField
+	}
+	return ""
+}
+
+type WithOptional_Nested struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *WithOptional_Nested) Reset() {
+	*x = WithOptional_Nested{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_testproto_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WithOptional_Nested) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WithOptional_Nested) ProtoMessage() {}
+
+func (x *WithOptional_Nested) ProtoReflect() protoreflect.Message {
+	mi := &file_testproto_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WithOptional_Nested.ProtoReflect.Descriptor instead.
+func (*WithOptional_Nested) Descriptor() ([]byte, []int) {
+	return file_testproto_proto_rawDescGZIP(), []int{1, 0}
+}
+
+type WithRepeated_Nested struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TNestedField string `protobuf:"bytes,1,opt,name=t_nested_field,json=tNestedField,proto3" json:"t_nested_field,omitempty"`
+}
+
+func (x *WithRepeated_Nested) Reset() {
+	*x = WithRepeated_Nested{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_testproto_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WithRepeated_Nested) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WithRepeated_Nested) ProtoMessage() {}
+
+func (x *WithRepeated_Nested) ProtoReflect() protoreflect.Message {
+	mi := &file_testproto_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WithRepeated_Nested.ProtoReflect.Descriptor instead.
+func (*WithRepeated_Nested) Descriptor() ([]byte, []int) {
+	return file_testproto_proto_rawDescGZIP(), []int{3, 0}
+}
+
+func (x *WithRepeated_Nested) GetTNestedField() string {
+	if x != nil {
+		return x.TNestedField
+	}
+	return ""
+}
+
+type WithComplexMap_Complex struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Inner  *WithComplexMap_Complex_Inner   `protobuf:"bytes,1,opt,name=inner,proto3" json:"inner,omitempty"`
+	Tags   []string                        `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty"`
+	Inners []*WithComplexMap_Complex_Inner `protobuf:"bytes,3,rep,name=inners,proto3" json:"inners,omitempty"`
+}
+
+func (x *WithComplexMap_Complex) Reset() {
+	*x = WithComplexMap_Complex{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_testproto_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WithComplexMap_Complex) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WithComplexMap_Complex) ProtoMessage() {}
+
+func (x *WithComplexMap_Complex) ProtoReflect() protoreflect.Message {
+	mi := &file_testproto_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WithComplexMap_Complex.ProtoReflect.Descriptor instead.
+func (*WithComplexMap_Complex) Descriptor() ([]byte, []int) {
+	return file_testproto_proto_rawDescGZIP(), []int{12, 0}
+}
+
+func (x *WithComplexMap_Complex) GetInner() *WithComplexMap_Complex_Inner {
+	if x != nil {
+		return x.Inner
+	}
+	return nil
+}
+
+func (x *WithComplexMap_Complex) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *WithComplexMap_Complex) GetInners() []*WithComplexMap_Complex_Inner {
+	if x != nil {
+		return x.Inners
+	}
+	return nil
+}
+
+type WithComplexMap_Complex_Inner struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Data []byte `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (x *WithComplexMap_Complex_Inner) Reset() {
+	*x = WithComplexMap_Complex_Inner{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_testproto_proto_msgTypes[36]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WithComplexMap_Complex_Inner) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WithComplexMap_Complex_Inner) ProtoMessage() {}
+
+func (x *WithComplexMap_Complex_Inner) ProtoReflect() protoreflect.Message {
+	mi := &file_testproto_proto_msgTypes[36]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WithComplexMap_Complex_Inner.ProtoReflect.Descriptor instead.
+func (*WithComplexMap_Complex_Inner) Descriptor() ([]byte, []int) {
+	return file_testproto_proto_rawDescGZIP(), []int{12, 0, 0}
+}
+
+func (x *WithComplexMap_Complex_Inner) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *WithComplexMap_Complex_Inner) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type WithListMap_HasList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Items []string `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+}
+
+func (x *WithListMap_HasList) Reset() {
+	*x = WithListMap_HasList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_testproto_proto_msgTypes[37]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WithListMap_HasList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WithListMap_HasList) ProtoMessage() {}
+
+func (x *WithListMap_HasList) ProtoReflect() protoreflect.Message {
+	mi := &file_testproto_proto_msgTypes[37]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WithListMap_HasList.ProtoReflect.Descriptor instead.
+func (*WithListMap_HasList) Descriptor() ([]byte, []int) {
+	return file_testproto_proto_rawDescGZIP(), []int{17, 0}
+}
+
+func (x *WithListMap_HasList) GetItems() []string {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+var File_testproto_proto protoreflect.FileDescriptor
+
+var file_testproto_proto_rawDesc = []byte{
+	0x0a, 0x0f, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x12, 0x09, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x19, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x61, 0x6e,
+	0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1c, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x77, 0x72, 0x61, 0x70, 0x70, 0x65, 0x72, 0x73,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x88, 0x04, 0x0a, 0x08, 0x41, 0x73, 0x73, 0x6f, 0x72,
+	0x74, 0x65, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x74, 0x5f, 0x64, 0x6f, 0x75, 0x62, 0x6c, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x74, 0x44, 0x6f, 0x75, 0x62, 0x6c, 0x65, 0x12, 0x17,
+	0x0a, 0x07, 0x74, 0x5f, 0x66, 0x6c, 0x6f, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x02, 0x52,
+	0x06, 0x74, 0x46, 0x6c, 0x6f, 0x61, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x74, 0x5f, 0x69, 0x6e, 0x74,
+	0x33, 0x32, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x74, 0x49, 0x6e, 0x74, 0x33, 0x32,
+	0x12, 0x17, 0x0a, 0x07, 0x74, 0x5f, 0x69, 0x6e, 0x74, 0x36, 0x34, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x06, 0x74, 0x49, 0x6e, 0x74, 0x36, 0x34, 0x12, 0x19, 0x0a, 0x08, 0x74, 0x5f, 0x75,
+	0x69, 0x6e, 0x74, 0x33, 0x32, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x74, 0x55, 0x69,
+	0x6e, 0x74, 0x33, 0x32, 0x12, 0x19, 0x0a, 0x08, 0x74, 0x5f, 0x75, 0x69, 0x6e, 0x74, 0x36, 0x34,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x74, 0x55, 0x69, 0x6e, 0x74, 0x36, 0x34, 0x12,
+	0x19, 0x0a, 0x08, 0x74, 0x5f, 0x73, 0x69, 0x6e, 0x74, 0x33, 0x32, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x11, 0x52, 0x07, 0x74, 0x53, 0x69, 0x6e, 0x74, 0x33, 0x32, 0x12, 0x19, 0x0a, 0x08, 0x74, 0x5f,
+	0x73, 0x69, 0x6e, 0x74, 0x36, 0x34, 0x18, 0x08, 0x20, 0x01, 0x28, 0x12, 0x52, 0x07, 0x74, 0x53,
+	0x69, 0x6e, 0x74, 0x36, 0x34, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x5f, 0x66, 0x69, 0x78, 0x65, 0x64,
+	0x33, 0x32, 0x18, 0x09, 0x20, 0x01, 0x28, 0x07, 0x52, 0x08, 0x74, 0x46, 0x69, 0x78, 0x65, 0x64,
+	0x33, 0x32, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x5f, 0x66, 0x69, 0x78, 0x65, 0x64, 0x36, 0x34, 0x18,
+	0x0a, 0x20, 0x01, 0x28, 0x06, 0x52, 0x08, 0x74, 0x46, 0x69, 0x78, 0x65, 0x64, 0x36, 0x34, 0x12,
+	0x1d, 0x0a, 0x0a, 0x74, 0x5f, 0x73, 0x66, 0x69, 0x78, 0x65, 0x64, 0x33, 0x32, 0x18, 0x0b, 0x20,
+	0x01, 0x28, 0x0f, 0x52, 0x09, 0x74, 0x53, 0x66, 0x69, 0x78, 0x65, 0x64, 0x33, 0x32, 0x12, 0x1d,
+	0x0a, 0x0a, 0x74, 0x5f, 0x73, 0x66, 0x69, 0x78, 0x65, 0x64, 0x36, 0x34, 0x18, 0x0c, 0x20, 0x01,
+	0x28, 0x10, 0x52, 0x09, 0x74, 0x53, 0x66, 0x69, 0x78, 0x65, 0x64, 0x36, 0x34, 0x12, 0x15, 0x0a,
+	0x06, 0x74, 0x5f, 0x62, 0x6f, 0x6f, 0x6c, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x74,
+	0x42, 0x6f, 0x6f, 0x6c, 0x12, 0x19, 0x0a, 0x08, 0x74, 0x5f, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67,
+	0x18, 0x0e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x74, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x12,
+	0x17, 0x0a, 0x07, 0x74, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x06, 0x74, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x37, 0x0a, 0x09, 0x74, 0x5f, 0x6d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x74, 0x65,
+	0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x41, 0x73, 0x73, 0x6f, 0x72, 0x74, 0x65, 0x64,
+	0x2e, 0x4e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x52, 0x08, 0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x1a, 0x2e, 0x0a, 0x06, 0x4e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x12, 0x24, 0x0a, 0x0e, 0x74,
+	0x5f, 0x6e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x5f, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0c, 0x74, 0x4e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x46, 0x69, 0x65, 0x6c,
+	0x64, 0x22, 0xce, 0x02, 0x0a, 0x0c, 0x57, 0x69, 0x74, 0x68, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x61, 0x6c, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x5f, 0x72, 0x65, 0x71,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x52, 0x65,
+	0x71, 0x12, 0x22, 0x0a, 0x0a, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x5f, 0x6f, 0x70, 0x74, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x09, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x4f,
+	0x70, 0x74, 0x88, 0x01, 0x01, 0x12, 0x1b, 0x0a, 0x09, 0x69, 0x6e, 0x74, 0x33, 0x32, 0x5f, 0x72,
+	0x65, 0x71, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x69, 0x6e, 0x74, 0x33, 0x32, 0x52,
+	0x65, 0x71, 0x12, 0x20, 0x0a, 0x09, 0x69, 0x6e, 0x74, 0x33, 0x32, 0x5f, 0x6f, 0x70, 0x74, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x05, 0x48, 0x01, 0x52, 0x08, 0x69, 0x6e, 0x74, 0x33, 0x32, 0x4f, 0x70,
+	0x74, 0x88, 0x01, 0x01, 0x12, 0x3f, 0x0a, 0x0b, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x5f,
+	0x72, 0x65, 0x71, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x74, 0x65, 0x73, 0x74,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x57, 0x69, 0x74, 0x68, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x61, 0x6c, 0x2e, 0x4e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x52, 0x0a, 0x6d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x52, 0x65, 0x71, 0x12, 0x44, 0x0a, 0x0b, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x5f, 0x6f, 0x70, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x74, 0x65, 0x73,
+	0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x57, 0x69, 0x74, 0x68, 0x4f, 0x70, 0x74, 0x69, 0x6f,
+	0x6e, 0x61, 0x6c, 0x2e, 0x4e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x48, 0x02, 0x52, 0x0a, 0x6d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x4f, 0x70, 0x74, 0x88, 0x01, 0x01, 0x1a, 0x08, 0x0a, 0x06, 0x4e,
+	0x65, 0x73, 0x74, 0x65, 0x64, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67,
+	0x5f, 0x6f, 0x70, 0x74, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x69, 0x6e, 0x74, 0x33, 0x32, 0x5f, 0x6f,
+	0x70, 0x74, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x5f, 0x6f,
+	0x70, 0x74, 0x22, 0x50, 0x0a, 0x09, 0x57, 0x69, 0x74, 0x68, 0x4f, 0x6e, 0x65, 0x4f, 0x66, 0x12,
+	0x18, 0x0a, 0x07, 0x6f, 0x75, 0x74, 0x73, 0x69, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x6f, 0x75, 0x74, 0x73, 0x69, 0x64, 0x65, 0x12, 0x0e, 0x0a, 0x01, 0x61, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x01, 0x61, 0x12, 0x0e, 0x0a, 0x01, 0x62, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x01, 0x62, 0x42, 0x09, 0x0a, 0x07, 0x74, 0x5f, 0x6f,
+	0x6e, 0x65, 0x6f, 0x66, 0x22, 0xdc, 0x06, 0x0a, 0x0c, 0x57, 0x69, 0x74, 0x68, 0x52, 0x65, 0x70,
+	0x65, 0x61, 0x74, 0x65, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x5f, 0x73, 0x74, 0x72, 0x69, 0x6e,
+	0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x74, 0x53, 0x74, 0x72, 0x69, 0x6e,
+	0x67, 0x73, 0x12, 0x3b, 0x0a, 0x09, 0x74, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x2e, 0x57, 0x69, 0x74, 0x68, 0x52, 0x65, 0x70, 0x65, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x4e,
+	0x65, 0x73, 0x74, 0x65, 0x64, 0x52, 0x08, 0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12,
+	0x56, 0x0a, 0x11, 0x74, 0x5f, 0x6d, 0x61, 0x70, 0x5f, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x5f,
+	0x62, 0x6f, 0x6f, 0x6c, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2b, 0x2e, 0x74, 0x65, 0x73,
+	0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x57, 0x69, 0x74, 0x68, 0x52, 0x65, 0x70, 0x65, 0x61,
+	0x74, 0x65, 0x64, 0x2e, 0x54, 0x4d, 0x61, 0x70, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x42, 0x6f,
+	0x6f, 0x6c, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0e, 0x74, 0x4d, 0x61, 0x70, 0x53, 0x74, 0x72,
+	0x69, 0x6e, 0x67, 0x42, 0x6f, 0x6f, 0x6c, 0x12, 0x56, 0x0a, 0x11, 0x74, 0x5f, 0x6d, 0x61, 0x70,
+	0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x5f, 0x62, 0x6f, 0x6f, 0x6c, 0x18, 0x04, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x2b, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x57,
+	0x69, 0x74, 0x68, 0x52, 0x65, 0x70, 0x65, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x54, 0x4d, 0x61, 0x70,
+	0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x42, 0x6f, 0x6f, 0x6c, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52,
+	0x0e, 0x74, 0x4d, 0x61, 0x70, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x42, 0x6f, 0x6f, 0x6c, 0x12,
+	0x5f, 0x0a, 0x14, 0x74, 0x5f, 0x6d, 0x61, 0x70, 0x5f, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x5f,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2e, 0x2e,
+	0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x57, 0x69, 0x74, 0x68, 0x52, 0x65,
+	0x70, 0x65, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x54, 0x4d, 0x61, 0x70, 0x53, 0x74, 0x72, 0x69, 0x6e,
+	0x67, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x11, 0x74,
+	0x4d, 0x61, 0x70, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x12, 0x5f, 0x0a, 0x14, 0x74, 0x5f, 0x6d, 0x61, 0x70, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72,
+	0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2e,
+	0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x57, 0x69, 0x74, 0x68, 0x52,
+	0x65, 0x70, 0x65, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x54, 0x4d, 0x61, 0x70, 0x4e, 0x75, 0x6d, 0x62,
+	0x65, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x11,
+	0x74, 0x4d, 0x61, 0x70, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x1a, 0x2e, 0x0a, 0x06, 0x4e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x12, 0x24, 0x0a, 0x0e, 0x74,
+	0x5f, 0x6e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x5f, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0c, 0x74, 0x4e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x46, 0x69, 0x65, 0x6c,
+	0x64, 0x1a, 0x41, 0x0a, 0x13, 0x54, 0x4d, 0x61, 0x70, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x42,
+	0x6f, 0x6f, 0x6c, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x3a, 0x02, 0x38, 0x01, 0x1a, 0x41, 0x0a, 0x13, 0x54, 0x4d, 0x61, 0x70, 0x4e, 0x75, 0x6d, 0x62,
+	0x65, 0x72, 0x42, 0x6f, 0x6f, 0x6c, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b,
+	0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x64, 0x0a, 0x16, 0x54, 0x4d, 0x61, 0x70, 0x53,
+	0x74, 0x72, 0x69, 0x6e, 0x67, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x6b, 0x65, 0x79, 0x12, 0x34, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x57,
+	0x69, 0x74, 0x68, 0x52, 0x65, 0x70, 0x65, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x4e, 0x65, 0x73, 0x74,
+	0x65, 0x64, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x64, 0x0a,
+	0x16, 0x54, 0x4d, 0x61, 0x70, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x34, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x57, 0x69, 0x74, 0x68, 0x52, 0x65, 0x70, 0x65, 0x61, 0x74, 0x65,
+	0x64, 0x2e, 0x4e, 0x65, 0x73, 0x74, 0x65, 0x64, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a,
+	0x02, 0x38, 0x01, 0x22, 0xd3, 0x03, 0x0a, 0x07, 0x57, 0x69, 0x74, 0x68, 0x41, 0x6e, 0x79, 0x12,
+	0x19, 0x0a, 0x08, 0x74, 0x5f, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x74, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x12, 0x29, 0x0a, 0x05, 0x74, 0x5f,
+	0x61, 0x6e, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x41, 0x6e, 0x79, 0x52,
+	0x04, 0x74, 0x41, 0x6e, 0x79, 0x12, 0x32, 0x0a, 0x0a, 0x74, 0x5f, 0x61, 0x6e, 0x79, 0x5f, 0x6c,
+	0x69, 0x73, 0x74, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x41, 0x6e, 0x79, 0x52,
+	0x08, 0x74, 0x41, 0x6e, 0x79, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x4e, 0x0a, 0x10, 0x74, 0x5f, 0x61,
+	0x6e, 0x79, 0x5f, 0x6d, 0x61, 0x70, 0x5f, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x18, 0x04, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x57, 0x69, 0x74, 0x68, 0x41, 0x6e, 0x79, 0x2e, 0x54, 0x41, 0x6e, 0x79, 0x4d, 0x61, 0x70, 0x53,
+	0x74, 0x72, 0x69, 0x6e, 0x67, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0d, 0x74, 0x41, 0x6e, 0x79,
+	0x4d, 0x61, 0x70, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x12, 0x4e, 0x0a, 0x10, 0x74, 0x5f, 0x61,
+	0x6e, 0x79, 0x5f, 0x6d, 0x61, 0x70, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x05, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x57, 0x69, 0x74, 0x68, 0x41, 0x6e, 0x79, 0x2e, 0x54, 0x41, 0x6e, 0x79, 0x4d, 0x61, 0x70, 0x4e,
+	0x75, 0x6d, 0x62, 0x65, 0x72, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0d, 0x74, 0x41, 0x6e, 0x79,
+	0x4d, 0x61, 0x70, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x1a, 0x56, 0x0a, 0x12, 0x54, 0x41, 0x6e,
+	0x79, 0x4d, 0x61, 0x70, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12,
+	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65,
+	0x79, 0x12, 0x2a, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x14, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x41, 0x6e, 0x79, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38,
+	0x01, 0x1a, 0x56, 0x0a, 0x12, 0x54, 0x41, 0x6e, 0x79, 0x4d, 0x61, 0x70, 0x4e, 0x75, 0x6d, 0x62,
+	0x65, 0x72, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x2a, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x41, 0x6e, 0x79, 0x52, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x7e, 0x0a, 0x08, 0x57, 0x69, 0x74,
+	0x68, 0x45, 0x6e, 0x75, 0x6d, 0x12, 0x19, 0x0a, 0x08, 0x74, 0x5f, 0x73, 0x74, 0x72, 0x69, 0x6e,
+	0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x74, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67,
+	0x12, 0x31, 0x0a, 0x06, 0x74, 0x5f, 0x65, 0x6e, 0x75, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x1a, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x57, 0x69, 0x74,
+	0x68, 0x45, 0x6e, 0x75, 0x6d, 0x2e, 0x54, 0x68, 0x69, 0x6e, 0x67, 0x73, 0x52, 0x05, 0x74, 0x45,
+	0x6e, 0x75, 0x6d, 0x22, 0x24, 0x0a, 0x06, 0x54, 0x68, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x05, 0x0a,
+	0x01, 0x41, 0x10, 0x00, 0x12, 0x05, 0x0a, 0x01, 0x62, 0x10, 0x01, 0x12, 0x05, 0x0a, 0x01, 0x43,
+	0x10, 0x02, 0x12, 0x05, 0x0a, 0x01, 0x64, 0x10, 0x03, 0x22, 0x07, 0x0a, 0x05, 0x45, 0x6d, 0x70,
+	0x74, 0x79, 0x22, 0x2c, 0x0a, 0x06, 0x53, 0x69, 0x6d, 0x70, 0x6c, 0x65, 0x12, 0x22, 0x0a, 0x03,
+	0x66, 0x6f, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x74, 0x65, 0x73, 0x74,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x52, 0x03, 0x66, 0x6f, 0x6f,
+	0x22, 0xaf, 0x01, 0x0a, 0x0a, 0x57, 0x69, 0x74, 0x68, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x12,
+	0x32, 0x0a, 0x08, 0x74, 0x5f, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x17, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x52, 0x07, 0x74, 0x53, 0x74, 0x72,
+	0x75, 0x63, 0x74, 0x12, 0x2f, 0x0a, 0x07, 0x74, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x06, 0x74, 0x56,
+	0x61, 0x6c, 0x75, 0x65, 0x12, 0x3c, 0x0a, 0x0c, 0x74, 0x5f, 0x6c, 0x69, 0x73, 0x74, 0x5f, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x4c, 0x69, 0x73,
+	0x74, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x0a, 0x74, 0x4c, 0x69, 0x73, 0x74, 0x56, 0x61, 0x6c,
+	0x75, 0x65, 0x22, 0xe5, 0x03, 0x0a, 0x15, 0x57, 0x69, 0x74, 0x68, 0x53, 0x74, 0x72, 0x75, 0x63,
+	0x74, 0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x4f, 0x0a, 0x0b,
+	0x74, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x5f, 0x6d, 0x61, 0x70, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x2f, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x57, 0x69,
+	0x74, 0x68, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x2e, 0x54, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x4d, 0x61, 0x70, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x52, 0x09, 0x74, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x4d, 0x61, 0x70, 0x12, 0x62, 0x0a,
+	0x12, 0x74, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x5f,
+	0x6d, 0x61, 0x70, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x35, 0x2e, 0x74, 0x65, 0x73, 0x74,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x57, 0x69, 0x74, 0x68, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74,
+	0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x54, 0x56, 0x61, 0x6c,
+	0x75, 0x65, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x4d, 0x61, 0x70, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x52, 0x0f, 0x74, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x4d, 0x61,
+	0x70, 0x12, 0x31, 0x0a, 0x08, 0x74, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x03, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x07, 0x74, 0x56, 0x61,
+	0x6c, 0x75, 0x65, 0x73, 0x12, 0x32, 0x0a, 0x09, 0x74, 0x5f, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74,
+	0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x2e, 0x57, 0x69, 0x74, 0x68, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x52, 0x08,
+	0x74, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x73, 0x1a, 0x54, 0x0a, 0x0e, 0x54, 0x56, 0x61, 0x6c,
+	0x75, 0x65, 0x4d, 0x61, 0x70, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65,
+	0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x2c, 0x0a, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x56, 0x61,
+	0x6c, 0x75, 0x65, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x5a,
+	0x0a, 0x14, 0x54, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x4d, 0x61,
+	0x70, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x2c, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xc9, 0x02, 0x0a, 0x0d, 0x57,
+	0x69, 0x74, 0x68, 0x57, 0x65, 0x6c, 0x6c, 0x4b, 0x6e, 0x6f, 0x77, 0x6e, 0x12, 0x3b, 0x0a, 0x0b,
+	0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0a, 0x74,
+	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x38, 0x0a, 0x0a, 0x74, 0x5f, 0x64,
+	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x09, 0x74, 0x44, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x42, 0x0a, 0x0e, 0x74, 0x5f, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x5f,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x74,
+	0x72, 0x69, 0x6e, 0x67, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x0c, 0x74, 0x53, 0x74, 0x72, 0x69,
+	0x6e, 0x67, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x3f, 0x0a, 0x0d, 0x74, 0x5f, 0x69, 0x6e, 0x74,
+	0x36, 0x34, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x49, 0x6e, 0x74, 0x36, 0x34, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x0b, 0x74, 0x49, 0x6e,
+	0x74, 0x36, 0x34, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x3c, 0x0a, 0x0c, 0x74, 0x5f, 0x62, 0x6f,
+	0x6f, 0x6c, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x42, 0x6f, 0x6f, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x0a, 0x74, 0x42, 0x6f, 0x6f,
+	0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x89, 0x02, 0x0a, 0x18, 0x57, 0x69, 0x74, 0x68, 0x57,
+	0x65, 0x6c, 0x6c, 0x4b, 0x6e, 0x6f, 0x77, 0x6e, 0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x12, 0x3d, 0x0a, 0x0c, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0b, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x73, 0x12, 0x54, 0x0a, 0x0b, 0x74, 0x5f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x33, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x2e, 0x57, 0x69, 0x74, 0x68, 0x57, 0x65, 0x6c, 0x6c, 0x4b, 0x6e, 0x6f, 0x77,
+	0x6e, 0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x54, 0x44, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0a, 0x74, 0x44,
+	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x1a, 0x58, 0x0a, 0x0f, 0x54, 0x44, 0x75, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b,
+	0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x2f, 0x0a,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44,
+	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02,
+	0x38, 0x01, 0x22, 0xf9, 0x04, 0x0a, 0x0e, 0x57, 0x69, 0x74, 0x68, 0x43, 0x6f, 0x6d, 0x70, 0x6c,
+	0x65, 0x78, 0x4d, 0x61, 0x70, 0x12, 0x61, 0x0a, 0x14, 0x74, 0x5f, 0x6d, 0x61, 0x70, 0x5f, 0x73,
+	0x74, 0x72, 0x69, 0x6e, 0x67, 0x5f, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x78, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x30, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x57, 0x69, 0x74, 0x68, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x78, 0x4d, 0x61, 0x70, 0x2e, 0x54,
+	0x4d, 0x61, 0x70, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x78,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x11, 0x74, 0x4d, 0x61, 0x70, 0x53, 0x74, 0x72, 0x69, 0x6e,
+	0x67, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x78, 0x12, 0x61, 0x0a, 0x14, 0x74, 0x5f, 0x6d, 0x61,
+	0x70, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x5f, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x78,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x30, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2e, 0x57, 0x69, 0x74, 0x68, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x78, 0x4d, 0x61,
+	0x70, 0x2e, 0x54, 0x4d, 0x61, 0x70, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x43, 0x6f, 0x6d, 0x70,
+	0x6c, 0x65, 0x78, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x11, 0x74, 0x4d, 0x61, 0x70, 0x4e, 0x75,
+	0x6d, 0x62, 0x65, 0x72, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x78, 0x1a, 0xce, 0x01, 0x0a, 0x07,
+	0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x78, 0x12, 0x3d, 0x0a, 0x05, 0x69, 0x6e, 0x6e, 0x65, 0x72,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2e, 0x57, 0x69, 0x74, 0x68, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x78, 0x4d, 0x61,
+	0x70, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x78, 0x2e, 0x49, 0x6e, 0x6e, 0x65, 0x72, 0x52,
+	0x05, 0x69, 0x6e, 0x6e, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x12, 0x3f, 0x0a, 0x06, 0x69, 0x6e,
+	0x6e, 0x65, 0x72, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x74, 0x65, 0x73,
+	0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x57, 0x69, 0x74, 0x68, 0x43, 0x6f, 0x6d, 0x70, 0x6c,
+	0x65, 0x78, 0x4d, 0x61, 0x70, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x78, 0x2e, 0x49, 0x6e,
+	0x6e, 0x65, 0x72, 0x52, 0x06, 0x69, 0x6e, 0x6e, 0x65, 0x72, 0x73, 0x1a, 0x2f, 0x0a, 0x05, 0x49,
+	0x6e, 0x6e, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x1a, 0x67, 0x0a, 0x16,
+	0x54, 0x4d, 0x61, 0x70, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65,
+	0x78, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x37, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x2e, 0x57, 0x69, 0x74, 0x68, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x78, 0x4d,
+	0x61, 0x70, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x78, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x67, 0x0a, 0x16, 0x54, 0x4d, 0x61, 0x70, 0x4e, 0x75, 0x6d,
+	0x62, 0x65, 0x72, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x78, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12,
+	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x6b, 0x65,
+	0x79, 0x12, 0x37, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x21, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x57, 0x69, 0x74,
+	0x68, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x78, 0x4d, 0x61, 0x70, 0x2e, 0x43, 0x6f, 0x6d, 0x70,
+	0x6c, 0x65, 0x78, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x32,
+	0x0a, 0x13, 0x57, 0x69, 0x74, 0x68, 0x52, 0x65, 0x70, 0x65, 0x61, 0x74, 0x65, 0x64, 0x4e, 0x75,
+	0x6d, 0x62, 0x65, 0x72, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65,
+	0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x03, 0x52, 0x08, 0x74, 0x4e, 0x75, 0x6d, 0x62, 0x65,
+	0x72, 0x73, 0x22, 0x49, 0x0a, 0x09, 0x52, 0x65, 0x63, 0x75, 0x72, 0x73, 0x69, 0x76, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x12, 0x28, 0x0a, 0x04, 0x6e, 0x65, 0x78, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x14, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x52, 0x65,
+	0x63, 0x75, 0x72, 0x73, 0x69, 0x76, 0x65, 0x52, 0x04, 0x6e, 0x65, 0x78, 0x74, 0x22, 0x51, 0x0a,
+	0x0e, 0x57, 0x69, 0x74, 0x68, 0x42, 0x79, 0x74, 0x65, 0x73, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12,
+	0x3f, 0x0a, 0x0d, 0x74, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x42, 0x79, 0x74, 0x65, 0x73, 0x56, 0x61,
+	0x6c, 0x75, 0x65, 0x52, 0x0b, 0x74, 0x42, 0x79, 0x74, 0x65, 0x73, 0x56, 0x61, 0x6c, 0x75, 0x65,
+	0x22, 0x77, 0x0a, 0x0d, 0x57, 0x69, 0x74, 0x68, 0x4e, 0x75, 0x6c, 0x6c, 0x56, 0x61, 0x6c, 0x75,
+	0x65, 0x12, 0x31, 0x0a, 0x06, 0x74, 0x5f, 0x6e, 0x75, 0x6c, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x4e, 0x75, 0x6c, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x05, 0x74,
+	0x4e, 0x75, 0x6c, 0x6c, 0x12, 0x33, 0x0a, 0x07, 0x74, 0x5f, 0x6e, 0x75, 0x6c, 0x6c, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x0e, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x4e, 0x75, 0x6c, 0x6c, 0x56, 0x61, 0x6c, 0x75,
+	0x65, 0x52, 0x06, 0x74, 0x4e, 0x75, 0x6c, 0x6c, 0x73, 0x22, 0xbe, 0x01, 0x0a, 0x0b, 0x57, 0x69,
+	0x74, 0x68, 0x4c, 0x69, 0x73, 0x74, 0x4d, 0x61, 0x70, 0x12, 0x35, 0x0a, 0x05, 0x74, 0x5f, 0x6d,
+	0x61, 0x70, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x57, 0x69, 0x74, 0x68, 0x4c, 0x69, 0x73, 0x74, 0x4d, 0x61, 0x70,
+	0x2e, 0x54, 0x4d, 0x61, 0x70, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x04, 0x74, 0x4d, 0x61, 0x70,
+	0x1a, 0x1f, 0x0a, 0x07, 0x48, 0x61, 0x73, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x69,
+	0x74, 0x65, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x69, 0x74, 0x65, 0x6d,
+	0x73, 0x1a, 0x57, 0x0a, 0x09, 0x54, 0x4d, 0x61, 0x70, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10,
+	0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79,
+	0x12, 0x34, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1e, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x57, 0x69, 0x74, 0x68,
+	0x4c, 0x69, 0x73, 0x74, 0x4d, 0x61, 0x70, 0x2e, 0x48, 0x61, 0x73, 0x4c, 0x69, 0x73, 0x74, 0x52,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x4b, 0x0a, 0x0c, 0x57, 0x69,
+	0x74, 0x68, 0x52, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x74, 0x5f,
+	0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x74, 0x53,
+	0x74, 0x72, 0x69, 0x6e, 0x67, 0x4a, 0x04, 0x08, 0x02, 0x10, 0x03, 0x4a, 0x04, 0x08, 0x09, 0x10,
+	0x0c, 0x52, 0x08, 0x6f, 0x6c, 0x64, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x52, 0x0a, 0x6f, 0x6c, 0x64,
+	0x65, 0x72, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0xed, 0x02, 0x0a, 0x09, 0x57, 0x69, 0x74, 0x68,
+	0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x29, 0x0a, 0x07, 0x74, 0x5f, 0x65, 0x6d, 0x70, 0x74, 0x79,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x52, 0x06, 0x74, 0x45, 0x6d, 0x70, 0x74, 0x79,
+	0x12, 0x43, 0x0a, 0x0b, 0x74, 0x5f, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x5f, 0x6d, 0x61, 0x70, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x2e, 0x57, 0x69, 0x74, 0x68, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x54, 0x45, 0x6d, 0x70,
+	0x74, 0x79, 0x4d, 0x61, 0x70, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x09, 0x74, 0x45, 0x6d, 0x70,
+	0x74, 0x79, 0x4d, 0x61, 0x70, 0x12, 0x32, 0x0a, 0x0c, 0x74, 0x5f, 0x65, 0x6d, 0x70, 0x74, 0x79,
+	0x5f, 0x6c, 0x69, 0x73, 0x74, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x74, 0x65,
+	0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x52, 0x0a, 0x74,
+	0x45, 0x6d, 0x70, 0x74, 0x79, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x38, 0x0a, 0x0e, 0x74, 0x5f, 0x65,
+	0x6d, 0x70, 0x74, 0x79, 0x5f, 0x63, 0x68, 0x6f, 0x69, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x10, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x6d,
+	0x70, 0x74, 0x79, 0x48, 0x00, 0x52, 0x0c, 0x74, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x43, 0x68, 0x6f,
+	0x69, 0x63, 0x65, 0x12, 0x26, 0x0a, 0x0e, 0x74, 0x5f, 0x6f, 0x74, 0x68, 0x65, 0x72, 0x5f, 0x63,
+	0x68, 0x6f, 0x69, 0x63, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x0c, 0x74,
+	0x4f, 0x74, 0x68, 0x65, 0x72, 0x43, 0x68, 0x6f, 0x69, 0x63, 0x65, 0x1a, 0x4e, 0x0a, 0x0e, 0x54,
+	0x45, 0x6d, 0x70, 0x74, 0x79, 0x4d, 0x61, 0x70, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a,
+	0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12,
+	0x26, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10,
+	0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79,
+	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x42, 0x0a, 0x0a, 0x08, 0x74,
+	0x5f, 0x63, 0x68, 0x6f, 0x69, 0x63, 0x65, 0x22, 0xcb, 0x02, 0x0a, 0x0b, 0x57, 0x69, 0x74, 0x68,
+	0x45, 0x6e, 0x75, 0x6d, 0x4d, 0x61, 0x70, 0x12, 0x3e, 0x0a, 0x08, 0x74, 0x5f, 0x63, 0x6f, 0x6c,
+	0x6f, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x74, 0x65, 0x73, 0x74,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x57, 0x69, 0x74, 0x68, 0x45, 0x6e, 0x75, 0x6d, 0x4d, 0x61,
+	0x70, 0x2e, 0x54, 0x43, 0x6f, 0x6c, 0x6f, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07,
+	0x74, 0x43, 0x6f, 0x6c, 0x6f, 0x72, 0x73, 0x12, 0x58, 0x0a, 0x12, 0x74, 0x5f, 0x63, 0x6f, 0x6c,
+	0x6f, 0x72, 0x73, 0x5f, 0x62, 0x79, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x2b, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x57, 0x69, 0x74, 0x68, 0x45, 0x6e, 0x75, 0x6d, 0x4d, 0x61, 0x70, 0x2e, 0x54, 0x43, 0x6f, 0x6c,
+	0x6f, 0x72, 0x73, 0x42, 0x79, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x52, 0x0f, 0x74, 0x43, 0x6f, 0x6c, 0x6f, 0x72, 0x73, 0x42, 0x79, 0x4e, 0x75, 0x6d, 0x62, 0x65,
+	0x72, 0x1a, 0x4c, 0x0a, 0x0c, 0x54, 0x43, 0x6f, 0x6c, 0x6f, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x6b, 0x65, 0x79, 0x12, 0x26, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x10, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43,
+	0x6f, 0x6c, 0x6f, 0x72, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a,
+	0x54, 0x0a, 0x14, 0x54, 0x43, 0x6f, 0x6c, 0x6f, 0x72, 0x73, 0x42, 0x79, 0x4e, 0x75, 0x6d, 0x62,
+	0x65, 0x72, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x26, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x10, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x6f, 0x6c, 0x6f, 0x72, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x3a, 0x02, 0x38, 0x01, 0x2a, 0x32, 0x0a, 0x05, 0x43, 0x6f, 0x6c, 0x6f, 0x72, 0x12, 0x15,
+	0x0a, 0x11, 0x43, 0x4f, 0x4c, 0x4f, 0x52, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46,
+	0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x07, 0x0a, 0x03, 0x52, 0x45, 0x44, 0x10, 0x01, 0x12, 0x09,
+	0x0a, 0x05, 0x47, 0x52, 0x45, 0x45, 0x4e, 0x10, 0x02, 0x42, 0x37, 0x5a, 0x35, 0x67, 0x69, 0x74,
+	0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x7a, 0x63, 0x6c, 0x63, 0x6f, 0x6e, 0x66, 0x2f,
+	0x67, 0x6f, 0x2d, 0x63, 0x74, 0x79, 0x2d, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f,
+	0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_testproto_proto_rawDescOnce sync.Once
+	file_testproto_proto_rawDescData = file_testproto_proto_rawDesc
+)
+
+func file_testproto_proto_rawDescGZIP() []byte {
+	file_testproto_proto_rawDescOnce.Do(func() {
+		file_testproto_proto_rawDescData = protoimpl.X.CompressGZIP(file_testproto_proto_rawDescData)
+	})
+	return file_testproto_proto_rawDescData
+}
+
+var file_testproto_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_testproto_proto_msgTypes = make([]protoimpl.MessageInfo, 42)
+var file_testproto_proto_goTypes = []interface{}{
+	(Color)(0),                           // 0: testproto.Color
+	(WithEnum_Things)(0),                 // 1: testproto.WithEnum.Things
+	(*Assorted)(nil),                     // 2: testproto.Assorted
+	(*WithOptional)(nil),                 // 3: testproto.WithOptional
+	(*WithOneOf)(nil),                    // 4: testproto.WithOneOf
+	(*WithRepeated)(nil),                 // 5: testproto.WithRepeated
+	(*WithAny)(nil),                      // 6: testproto.WithAny
+	(*WithEnum)(nil),                     // 7: testproto.WithEnum
+	(*Empty)(nil),                        // 8: testproto.Empty
+	(*Simple)(nil),                       // 9: testproto.Simple
+	(*WithStruct)(nil),                   // 10: testproto.WithStruct
+	(*WithStructCollections)(nil),        // 11: testproto.WithStructCollections
+	(*WithWellKnown)(nil),                // 12: testproto.WithWellKnown
+	(*WithWellKnownCollections)(nil),     // 13: testproto.WithWellKnownCollections
+	(*WithComplexMap)(nil),               // 14: testproto.WithComplexMap
+	(*WithRepeatedNumbers)(nil),          // 15: testproto.WithRepeatedNumbers
+	(*Recursive)(nil),                    // 16: testproto.Recursive
+	(*WithBytesValue)(nil),               // 17: testproto.WithBytesValue
+	(*WithNullValue)(nil),                // 18: testproto.WithNullValue
+	(*WithListMap)(nil),                  // 19: testproto.WithListMap
+	(*WithReserved)(nil),                 // 20: testproto.WithReserved
+	(*WithEmpty)(nil),                    // 21: testproto.WithEmpty
+	(*WithEnumMap)(nil),                  // 22: testproto.WithEnumMap
+	(*Assorted_Nested)(nil),              // 23: testproto.Assorted.Nested
+	(*WithOptional_Nested)(nil),          // 24: testproto.WithOptional.Nested
+	(*WithRepeated_Nested)(nil),          // 25: testproto.WithRepeated.Nested
+	nil,                                  // 26: testproto.WithRepeated.TMapStringBoolEntry
+	nil,                                  // 27: testproto.WithRepeated.TMapNumberBoolEntry
+	nil,                                  // 28: testproto.WithRepeated.TMapStringMessageEntry
+	nil,                                  // 29: testproto.WithRepeated.TMapNumberMessageEntry
+	nil,                                  // 30: testproto.WithAny.TAnyMapStringEntry
+	nil,                                  // 31: testproto.WithAny.TAnyMapNumberEntry
+	nil,                                  // 32: testproto.WithStructCollections.TValueMapEntry
+	nil,                                  // 33: testproto.WithStructCollections.TValueNumberMapEntry
+	nil,                                  // 34: testproto.WithWellKnownCollections.TDurationsEntry
+	(*WithComplexMap_Complex)(nil),       // 35: testproto.WithComplexMap.Complex
+	nil,                                  // 36: testproto.WithComplexMap.TMapStringComplexEntry
+	nil,                                  // 37: testproto.WithComplexMap.TMapNumberComplexEntry
+	(*WithComplexMap_Complex_Inner)(nil), // 38: testproto.WithComplexMap.Complex.Inner
+	(*WithListMap_HasList)(nil),          // 39: testproto.WithListMap.HasList
+	nil,                                  // 40: testproto.WithListMap.TMapEntry
+	nil,                                  // 41: testproto.WithEmpty.TEmptyMapEntry
+	nil,                                  // 42: testproto.WithEnumMap.TColorsEntry
+	nil,                                  // 43: testproto.WithEnumMap.TColorsByNumberEntry
+	(*anypb.Any)(nil),                    // 44: google.protobuf.Any
+	(*structpb.Struct)(nil),              // 45: google.protobuf.Struct
+	(*structpb.Value)(nil),               // 46: google.protobuf.Value
+	(*structpb.ListValue)(nil),           // 47: google.protobuf.ListValue
+	(*timestamppb.Timestamp)(nil),        // 48: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),          // 49: google.protobuf.Duration
+	(*wrapperspb.StringValue)(nil),       // 50: google.protobuf.StringValue
+	(*wrapperspb.Int64Value)(nil),        // 51: google.protobuf.Int64Value
+	(*wrapperspb.BoolValue)(nil),         // 52: google.protobuf.BoolValue
+	(*wrapperspb.BytesValue)(nil),        // 53: google.protobuf.BytesValue
+	(structpb.NullValue)(0),              // 54: google.protobuf.NullValue
+}
+var file_testproto_proto_depIdxs = []int32{
+	23, // 0: testproto.Assorted.t_message:type_name -> testproto.Assorted.Nested
+	24, // 1: testproto.WithOptional.message_req:type_name -> testproto.WithOptional.Nested
+	24, // 2: testproto.WithOptional.message_opt:type_name -> testproto.WithOptional.Nested
+	25, // 3: testproto.WithRepeated.t_message:type_name -> testproto.WithRepeated.Nested
+	26, // 4: testproto.WithRepeated.t_map_string_bool:type_name -> testproto.WithRepeated.TMapStringBoolEntry
+	27, // 5: testproto.WithRepeated.t_map_number_bool:type_name -> testproto.WithRepeated.TMapNumberBoolEntry
+	28, // 6: testproto.WithRepeated.t_map_string_message:type_name -> testproto.WithRepeated.TMapStringMessageEntry
+	29, // 7: testproto.WithRepeated.t_map_number_message:type_name -> testproto.WithRepeated.TMapNumberMessageEntry
+	44, // 8: testproto.WithAny.t_any:type_name -> google.protobuf.Any
+	44, // 9: testproto.WithAny.t_any_list:type_name -> google.protobuf.Any
+	30, // 10: testproto.WithAny.t_any_map_string:type_name -> testproto.WithAny.TAnyMapStringEntry
+	31, // 11: testproto.WithAny.t_any_map_number:type_name -> testproto.WithAny.TAnyMapNumberEntry
+	1,  // 12: testproto.WithEnum.t_enum:type_name -> testproto.WithEnum.Things
+	8,  // 13: testproto.Simple.foo:type_name -> testproto.Empty
+	45, // 14: testproto.WithStruct.t_struct:type_name -> google.protobuf.Struct
+	46, // 15: testproto.WithStruct.t_value:type_name -> google.protobuf.Value
+	47, // 16: testproto.WithStruct.t_list_value:type_name -> google.protobuf.ListValue
+	32, // 17: testproto.WithStructCollections.t_value_map:type_name -> testproto.WithStructCollections.TValueMapEntry
+	33, // 18: testproto.WithStructCollections.t_value_number_map:type_name -> testproto.WithStructCollections.TValueNumberMapEntry
+	46, // 19: testproto.WithStructCollections.t_values:type_name -> google.protobuf.Value
+	10, // 20: testproto.WithStructCollections.t_structs:type_name -> testproto.WithStruct
+	48, // 21: testproto.WithWellKnown.t_timestamp:type_name -> google.protobuf.Timestamp
+	49, // 22: testproto.WithWellKnown.t_duration:type_name -> google.protobuf.Duration
+	50, // 23: testproto.WithWellKnown.t_string_value:type_name -> google.protobuf.StringValue
+	51, // 24: testproto.WithWellKnown.t_int64_value:type_name -> google.protobuf.Int64Value
+	52, // 25: testproto.WithWellKnown.t_bool_value:type_name -> google.protobuf.BoolValue
+	48, // 26: testproto.WithWellKnownCollections.t_timestamps:type_name -> google.protobuf.Timestamp
+	34, // 27: testproto.WithWellKnownCollections.t_durations:type_name -> testproto.WithWellKnownCollections.TDurationsEntry
+	36, // 28: testproto.WithComplexMap.t_map_string_complex:type_name -> testproto.WithComplexMap.TMapStringComplexEntry
+	37, // 29: testproto.WithComplexMap.t_map_number_complex:type_name -> testproto.WithComplexMap.TMapNumberComplexEntry
+	16, // 30: testproto.Recursive.next:type_name -> testproto.Recursive
+	53, // 31: testproto.WithBytesValue.t_bytes_value:type_name -> google.protobuf.BytesValue
+	54, // 32: testproto.WithNullValue.t_null:type_name -> google.protobuf.NullValue
+	54, // 33: testproto.WithNullValue.t_nulls:type_name -> google.protobuf.NullValue
+	40, // 34: testproto.WithListMap.t_map:type_name -> testproto.WithListMap.TMapEntry
+	8,  // 35: testproto.WithEmpty.t_empty:type_name -> testproto.Empty
+	41, // 36: testproto.WithEmpty.t_empty_map:type_name -> testproto.WithEmpty.TEmptyMapEntry
+	8,  // 37: testproto.WithEmpty.t_empty_list:type_name -> testproto.Empty
+	8,  // 38: testproto.WithEmpty.t_empty_choice:type_name -> testproto.Empty
+	42, // 39: testproto.WithEnumMap.t_colors:type_name -> testproto.WithEnumMap.TColorsEntry
+	43, // 40: testproto.WithEnumMap.t_colors_by_number:type_name -> testproto.WithEnumMap.TColorsByNumberEntry
+	25, // 41: testproto.WithRepeated.TMapStringMessageEntry.value:type_name -> testproto.WithRepeated.Nested
+	25, // 42: testproto.WithRepeated.TMapNumberMessageEntry.value:type_name -> testproto.WithRepeated.Nested
+	44, // 43: testproto.WithAny.TAnyMapStringEntry.value:type_name -> google.protobuf.Any
+	44, // 44: testproto.WithAny.TAnyMapNumberEntry.value:type_name -> google.protobuf.Any
+	46, // 45: testproto.WithStructCollections.TValueMapEntry.value:type_name -> google.protobuf.Value
+	46, // 46: testproto.WithStructCollections.TValueNumberMapEntry.value:type_name -> google.protobuf.Value
+	49, // 47: testproto.WithWellKnownCollections.TDurationsEntry.value:type_name -> google.protobuf.Duration
+	38, // 48: testproto.WithComplexMap.Complex.inner:type_name -> testproto.WithComplexMap.Complex.Inner
+	38, // 49: testproto.WithComplexMap.Complex.inners:type_name -> testproto.WithComplexMap.Complex.Inner
+	35, // 50: testproto.WithComplexMap.TMapStringComplexEntry.value:type_name -> testproto.WithComplexMap.Complex
+	35, // 51: testproto.WithComplexMap.TMapNumberComplexEntry.value:type_name -> testproto.WithComplexMap.Complex
+	39, // 52: testproto.WithListMap.TMapEntry.value:type_name -> testproto.WithListMap.HasList
+	8,  // 53: testproto.WithEmpty.TEmptyMapEntry.value:type_name -> testproto.Empty
+	0,  // 54: testproto.WithEnumMap.TColorsEntry.value:type_name -> testproto.Color
+	0,  // 55: testproto.WithEnumMap.TColorsByNumberEntry.value:type_name -> testproto.Color
+	56, // [56:56] is the sub-list for method output_type
+	56, // [56:56] is the sub-list for method input_type
+	56, // [56:56] is the sub-list for extension type_name
+	56, // [56:56] is the sub-list for extension extendee
+	0,  // [0:56] is the sub-list for field type_name
+}
+
+func init() { file_testproto_proto_init() }
+func file_testproto_proto_init() {
+	if File_testproto_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_testproto_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Assorted); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_testproto_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WithOptional); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_testproto_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WithOneOf); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
 			case 2:
 				return &v.unknownFields
 			default:
@@ -1199,7 +2482,7 @@ func file_testproto_proto_init() {
 			}
 		}
 		file_testproto_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Assorted_Nested); i {
+			switch v := v.(*WithStruct); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1211,7 +2494,7 @@ func file_testproto_proto_init() {
 			}
 		}
 		file_testproto_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*WithOptional_Nested); i {
+			switch v := v.(*WithStructCollections); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1223,6 +2506,162 @@ func file_testproto_proto_init() {
 			}
 		}
 		file_testproto_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WithWellKnown); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_testproto_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WithWellKnownCollections); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_testproto_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WithComplexMap); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_testproto_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WithRepeatedNumbers); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_testproto_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Recursive); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_testproto_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WithBytesValue); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_testproto_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WithNullValue); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_testproto_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WithListMap); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_testproto_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WithReserved); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_testproto_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WithEmpty); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_testproto_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WithEnumMap); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_testproto_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Assorted_Nested); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_testproto_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WithOptional_Nested); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_testproto_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*WithRepeated_Nested); i {
 			case 0:
 				return &v.state
@@ -1234,19 +2673,59 @@ func file_testproto_proto_init() {
 				return nil
 			}
 		}
+		file_testproto_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WithComplexMap_Complex); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_testproto_proto_msgTypes[36].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WithComplexMap_Complex_Inner); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_testproto_proto_msgTypes[37].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WithListMap_HasList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	file_testproto_proto_msgTypes[1].OneofWrappers = []interface{}{}
 	file_testproto_proto_msgTypes[2].OneofWrappers = []interface{}{
 		(*WithOneOf_A)(nil),
 		(*WithOneOf_B)(nil),
 	}
+	file_testproto_proto_msgTypes[19].OneofWrappers = []interface{}{
+		(*WithEmpty_TEmptyChoice)(nil),
+		(*WithEmpty_TOtherChoice)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_testproto_proto_rawDesc,
-			NumEnums:      1,
-			NumMessages:   17,
+			NumEnums:      2,
+			NumMessages:   42,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
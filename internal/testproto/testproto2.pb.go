@@ -0,0 +1,396 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.25.0
+// 	protoc        v3.14.0
+// source: testproto2.proto
+
+// This is here only to give us some convenient-to-access message types
+// for use in this module's unit tests, covering features that are available
+// only in proto2 syntax.
+//
+// To regenerate the .pb.go file:
+// protoc --go_out=. --go_opt=paths=source_relative testproto2.proto
+
+package testproto
+
+import (
+	proto "github.com/golang/protobuf/proto"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+type Extendable struct {
+	state           protoimpl.MessageState
+	sizeCache       protoimpl.SizeCache
+	unknownFields   protoimpl.UnknownFields
+	extensionFields protoimpl.ExtensionFields
+
+	Name *string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+}
+
+func (x *Extendable) Reset() {
+	*x = Extendable{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_testproto2_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Extendable) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Extendable) ProtoMessage() {}
+
+func (x *Extendable) ProtoReflect() protoreflect.Message {
+	mi := &file_testproto2_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Extendable.ProtoReflect.Descriptor instead.
+func (*Extendable) Descriptor() ([]byte, []int) {
+	return file_testproto2_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Extendable) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+type WithGroup struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Thing *WithGroup_Thing `protobuf:"group,1,opt,name=Thing,json=thing" json:"thing,omitempty"`
+}
+
+func (x *WithGroup) Reset() {
+	*x = WithGroup{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_testproto2_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WithGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WithGroup) ProtoMessage() {}
+
+func (x *WithGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_testproto2_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WithGroup.ProtoReflect.Descriptor instead.
+func (*WithGroup) Descriptor() ([]byte, []int) {
+	return file_testproto2_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *WithGroup) GetThing() *WithGroup_Thing {
+	if x != nil {
+		return x.Thing
+	}
+	return nil
+}
+
+type WithPacking struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TUnpacked []int32 `protobuf:"varint,1,rep,name=t_unpacked,json=tUnpacked" json:"t_unpacked,omitempty"`
+	TPacked   []int32 `protobuf:"varint,2,rep,packed,name=t_packed,json=tPacked" json:"t_packed,omitempty"`
+}
+
+func (x *WithPacking) Reset() {
+	*x = WithPacking{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_testproto2_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WithPacking) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WithPacking) ProtoMessage() {}
+
+func (x *WithPacking) ProtoReflect() protoreflect.Message {
+	mi := &file_testproto2_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WithPacking.ProtoReflect.Descriptor instead.
+func (*WithPacking) Descriptor() ([]byte, []int) {
+	return file_testproto2_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *WithPacking) GetTUnpacked() []int32 {
+	if x != nil {
+		return x.TUnpacked
+	}
+	return nil
+}
+
+func (x *WithPacking) GetTPacked() []int32 {
+	if x != nil {
+		return x.TPacked
+	}
+	return nil
+}
+
+type WithGroup_Thing struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name *string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+}
+
+func (x *WithGroup_Thing) Reset() {
+	*x = WithGroup_Thing{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_testproto2_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WithGroup_Thing) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WithGroup_Thing) ProtoMessage() {}
+
+func (x *WithGroup_Thing) ProtoReflect() protoreflect.Message {
+	mi := &file_testproto2_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WithGroup_Thing.ProtoReflect.Descriptor instead.
+func (*WithGroup_Thing) Descriptor() ([]byte, []int) {
+	return file_testproto2_proto_rawDescGZIP(), []int{1, 0}
+}
+
+func (x *WithGroup_Thing) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+var file_testproto2_proto_extTypes = []protoimpl.ExtensionInfo{
+	{
+		ExtendedType:  (*Extendable)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         100,
+		Name:          "testproto.ext_string",
+		Tag:           "bytes,100,opt,name=ext_string",
+		Filename:      "testproto2.proto",
+	},
+	{
+		ExtendedType:  (*Extendable)(nil),
+		ExtensionType: ([]int32)(nil),
+		Field:         101,
+		Name:          "testproto.ext_numbers",
+		Tag:           "varint,101,rep,name=ext_numbers",
+		Filename:      "testproto2.proto",
+	},
+}
+
+// Extension fields to Extendable.
+var (
+	// optional string ext_string = 100;
+	E_ExtString = &file_testproto2_proto_extTypes[0]
+	// repeated int32 ext_numbers = 101;
+	E_ExtNumbers = &file_testproto2_proto_extTypes[1]
+)
+
+var File_testproto2_proto protoreflect.FileDescriptor
+
+var file_testproto2_proto_rawDesc = []byte{
+	0x0a, 0x10, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x32, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x12, 0x09, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x27, 0x0a,
+	0x0a, 0x45, 0x78, 0x74, 0x65, 0x6e, 0x64, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x2a,
+	0x05, 0x08, 0x64, 0x10, 0xc8, 0x01, 0x22, 0x5a, 0x0a, 0x09, 0x57, 0x69, 0x74, 0x68, 0x47, 0x72,
+	0x6f, 0x75, 0x70, 0x12, 0x30, 0x0a, 0x05, 0x74, 0x68, 0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0a, 0x32, 0x1a, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x57,
+	0x69, 0x74, 0x68, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x2e, 0x54, 0x68, 0x69, 0x6e, 0x67, 0x52, 0x05,
+	0x74, 0x68, 0x69, 0x6e, 0x67, 0x1a, 0x1b, 0x0a, 0x05, 0x54, 0x68, 0x69, 0x6e, 0x67, 0x12, 0x12,
+	0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x22, 0x4b, 0x0a, 0x0b, 0x57, 0x69, 0x74, 0x68, 0x50, 0x61, 0x63, 0x6b, 0x69, 0x6e,
+	0x67, 0x12, 0x1d, 0x0a, 0x0a, 0x74, 0x5f, 0x75, 0x6e, 0x70, 0x61, 0x63, 0x6b, 0x65, 0x64, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x05, 0x52, 0x09, 0x74, 0x55, 0x6e, 0x70, 0x61, 0x63, 0x6b, 0x65, 0x64,
+	0x12, 0x1d, 0x0a, 0x08, 0x74, 0x5f, 0x70, 0x61, 0x63, 0x6b, 0x65, 0x64, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x05, 0x42, 0x02, 0x10, 0x01, 0x52, 0x07, 0x74, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x64, 0x3a,
+	0x34, 0x0a, 0x0a, 0x65, 0x78, 0x74, 0x5f, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x12, 0x15, 0x2e,
+	0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x78, 0x74, 0x65, 0x6e, 0x64,
+	0x61, 0x62, 0x6c, 0x65, 0x18, 0x64, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x65, 0x78, 0x74, 0x53,
+	0x74, 0x72, 0x69, 0x6e, 0x67, 0x3a, 0x36, 0x0a, 0x0b, 0x65, 0x78, 0x74, 0x5f, 0x6e, 0x75, 0x6d,
+	0x62, 0x65, 0x72, 0x73, 0x12, 0x15, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2e, 0x45, 0x78, 0x74, 0x65, 0x6e, 0x64, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x65, 0x20, 0x03, 0x28,
+	0x05, 0x52, 0x0a, 0x65, 0x78, 0x74, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x73, 0x42, 0x37, 0x5a,
+	0x35, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x7a, 0x63, 0x6c, 0x63,
+	0x6f, 0x6e, 0x66, 0x2f, 0x67, 0x6f, 0x2d, 0x63, 0x74, 0x79, 0x2d, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x74, 0x65, 0x73,
+	0x74, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+}
+
+var (
+	file_testproto2_proto_rawDescOnce sync.Once
+	file_testproto2_proto_rawDescData = file_testproto2_proto_rawDesc
+)
+
+func file_testproto2_proto_rawDescGZIP() []byte {
+	file_testproto2_proto_rawDescOnce.Do(func() {
+		file_testproto2_proto_rawDescData = protoimpl.X.CompressGZIP(file_testproto2_proto_rawDescData)
+	})
+	return file_testproto2_proto_rawDescData
+}
+
+var file_testproto2_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_testproto2_proto_goTypes = []interface{}{
+	(*Extendable)(nil),      // 0: testproto.Extendable
+	(*WithGroup)(nil),       // 1: testproto.WithGroup
+	(*WithPacking)(nil),     // 2: testproto.WithPacking
+	(*WithGroup_Thing)(nil), // 3: testproto.WithGroup.Thing
+}
+var file_testproto2_proto_depIdxs = []int32{
+	3, // 0: testproto.WithGroup.thing:type_name -> testproto.WithGroup.Thing
+	0, // 1: testproto.ext_string:extendee -> testproto.Extendable
+	0, // 2: testproto.ext_numbers:extendee -> testproto.Extendable
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	1, // [1:3] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_testproto2_proto_init() }
+func file_testproto2_proto_init() {
+	if File_testproto2_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_testproto2_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Extendable); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			case 3:
+				return &v.extensionFields
+			default:
+				return nil
+			}
+		}
+		file_testproto2_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WithGroup); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_testproto2_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WithPacking); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_testproto2_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WithGroup_Thing); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_testproto2_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 2,
+			NumServices:   0,
+		},
+		GoTypes:           file_testproto2_proto_goTypes,
+		DependencyIndexes: file_testproto2_proto_depIdxs,
+		MessageInfos:      file_testproto2_proto_msgTypes,
+		ExtensionInfos:    file_testproto2_proto_extTypes,
+	}.Build()
+	File_testproto2_proto = out.File
+	file_testproto2_proto_rawDesc = nil
+	file_testproto2_proto_goTypes = nil
+	file_testproto2_proto_depIdxs = nil
+}